@@ -0,0 +1,129 @@
+import React, { useEffect, useMemo } from 'react'
+
+import { RouteComponentProps } from 'react-router'
+import { catchError } from 'rxjs/operators'
+
+import { ErrorAlert } from '@sourcegraph/branded/src/components/alerts'
+import { asError, ErrorLike, isErrorLike } from '@sourcegraph/common'
+import { Badge, Code, H2, H3, Link, LoadingSpinner, Text, useObservable } from '@sourcegraph/wildcard'
+
+import { PageTitle } from '../components/PageTitle'
+import { EncryptedTableFields } from '../graphql-operations'
+import { eventLogger } from '../tracking/eventLogger'
+
+import { fetchEncryptionStatus as defaultFetchEncryptionStatus } from './backend'
+
+export interface SiteAdminEncryptionPageProps extends RouteComponentProps<{}> {
+    fetchEncryptionStatus?: typeof defaultFetchEncryptionStatus
+}
+
+/**
+ * A page displaying the number of rows of each encrypted table by version of the key they are
+ * encrypted with.
+ */
+export const SiteAdminEncryptionPage: React.FunctionComponent<
+    React.PropsWithChildren<SiteAdminEncryptionPageProps>
+> = ({ fetchEncryptionStatus = defaultFetchEncryptionStatus }) => {
+    useEffect(() => {
+        eventLogger.logViewEvent('SiteAdminEncryption')
+    }, [])
+
+    const tablesOrError = useObservable(
+        useMemo(
+            () => fetchEncryptionStatus().pipe(catchError((error): [ErrorLike] => [asError(error)])),
+            [fetchEncryptionStatus]
+        )
+    )
+
+    return (
+        <div className="site-admin-encryption-page">
+            <PageTitle title="Encryption - Admin" />
+            <H2>Encryption</H2>
+            <Text>
+                The number of rows of each table with encrypted columns by version of the key they are encrypted with.
+                Once a key is rotated, rows encrypted with a previous version of the key are re-encrypted in the
+                background. <Link to="/help/admin/config/encryption#key-rotation">Learn more</Link>
+            </Text>
+            {isErrorLike(tablesOrError) ? (
+                <ErrorAlert prefix="Error loading the encryption status" error={tablesOrError} />
+            ) : tablesOrError === undefined ? (
+                <LoadingSpinner />
+            ) : (
+                tablesOrError.map(table => <EncryptedTable key={table.name} table={table} />)
+            )}
+        </div>
+    )
+}
+
+const EncryptedTable: React.FunctionComponent<React.PropsWithChildren<{ table: EncryptedTableFields }>> = ({
+    table,
+}) => (
+    <div className="mb-4">
+        <H3>
+            <Code>{table.name}</Code>
+        </H3>
+        <Text className="text-muted">
+            Encrypted columns: {table.columns.join(', ')}
+            {table.currentKeyVersion === null ? (
+                <> &middot; no key configured</>
+            ) : (
+                <>
+                    {' '}
+                    &middot; current key version <Code>{table.currentKeyVersion}</Code>
+                </>
+            )}
+        </Text>
+        {table.progress !== null && (
+            <div className="d-flex align-items-center mb-2">
+                <meter
+                    min={0}
+                    low={0.2}
+                    high={0.8}
+                    max={1}
+                    optimum={1}
+                    value={table.progress}
+                    aria-label={`re-encryption progress of ${table.name}`}
+                />
+                <span className="ml-2">
+                    {Math.floor(table.progress * 100)}% encrypted with the current key version
+                </span>
+            </div>
+        )}
+        {table.keyVersions.length === 0 ? (
+            <Text>No rows.</Text>
+        ) : (
+            <table className="table">
+                <thead>
+                    <tr>
+                        <th>Key version</th>
+                        <th className="text-right">Rows</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {table.keyVersions.map(version => (
+                        <tr key={version.keyVersion}>
+                            <td>
+                                {version.keyVersion === '' ? (
+                                    <span className="text-muted">not encrypted</span>
+                                ) : (
+                                    <Code>{version.keyVersion}</Code>
+                                )}
+                                {version.current && (
+                                    <Badge variant="success" className="ml-2">
+                                        current
+                                    </Badge>
+                                )}
+                                {version.keyVersion !== '' && !version.encrypted && (
+                                    <Badge variant="secondary" className="ml-2">
+                                        unknown version
+                                    </Badge>
+                                )}
+                            </td>
+                            <td className="text-right">{version.count}</td>
+                        </tr>
+                    ))}
+                </tbody>
+            </table>
+        )}
+    </div>
+)
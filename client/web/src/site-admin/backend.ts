@@ -50,6 +50,9 @@ import {
     OutOfBandMigrationFields,
     OutOfBandMigrationsResult,
     OutOfBandMigrationsVariables,
+    EncryptionStatusResult,
+    EncryptionStatusVariables,
+    EncryptedTableFields,
     OrgRepositoriesVariables,
     OrgRepositoriesResult,
     OrgRepositoriesTotalCountVariables,
@@ -1056,6 +1059,37 @@ export function fetchAllOutOfBandMigrations(): Observable<OutOfBandMigrationFiel
     )
 }
 
+/**
+ * Fetches the number of rows of each encrypted table by key version.
+ */
+export function fetchEncryptionStatus(): Observable<EncryptedTableFields[]> {
+    return requestGraphQL<EncryptionStatusResult, EncryptionStatusVariables>(
+        gql`
+            query EncryptionStatus {
+                encryptionStatus {
+                    ...EncryptedTableFields
+                }
+            }
+
+            fragment EncryptedTableFields on EncryptedTable {
+                name
+                columns
+                currentKeyVersion
+                progress
+                keyVersions {
+                    keyVersion
+                    encrypted
+                    current
+                    count
+                }
+            }
+        `
+    ).pipe(
+        map(dataOrThrowErrors),
+        map(data => data.encryptionStatus)
+    )
+}
+
 /**
  * Fetches all feature flags.
  */
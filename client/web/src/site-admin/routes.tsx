@@ -119,6 +119,11 @@ export const siteAdminAreaRoutes: readonly SiteAdminAreaRoute[] = [
         exact: true,
         render: lazyComponent(() => import('./SiteAdminMigrationsPage'), 'SiteAdminMigrationsPage'),
     },
+    {
+        path: '/encryption',
+        exact: true,
+        render: lazyComponent(() => import('./SiteAdminEncryptionPage'), 'SiteAdminEncryptionPage'),
+    },
     {
         path: '/feature-flags',
         exact: true,
@@ -114,6 +114,10 @@ export const maintenanceGroup: SiteAdminSideBarGroup = {
             label: 'Migrations',
             to: '/site-admin/migrations',
         },
+        {
+            label: 'Encryption',
+            to: '/site-admin/encryption',
+        },
         {
             label: 'Instrumentation',
             to: '/-/debug/',
@@ -21,9 +21,12 @@ type Services struct {
 	BitbucketServerWebhook        http.Handler
 	BitbucketCloudWebhook         http.Handler
 	NewCodeIntelUploadHandler     NewCodeIntelUploadHandler
+	NewCodeIntelExportHandler     NewCodeIntelExportHandler
 	NewExecutorProxyHandler       NewExecutorProxyHandler
 	NewGitHubAppCloudSetupHandler NewGitHubAppCloudSetupHandler
 	NewComputeStreamHandler       NewComputeStreamHandler
+	NewNotebookExportHandler      NewNotebookExportHandler
+	NewInsightsExportHandler      NewInsightsExportHandler
 	AuthzResolver                 graphqlbackend.AuthzResolver
 	BatchChangesResolver          graphqlbackend.BatchChangesResolver
 	CodeIntelResolver             graphqlbackend.CodeIntelResolver
@@ -41,6 +44,9 @@ type Services struct {
 // resulting handler skips auth checks when the internal flag is true.
 type NewCodeIntelUploadHandler func(internal bool) http.Handler
 
+// NewCodeIntelExportHandler creates a new handler for the precise index export endpoint.
+type NewCodeIntelExportHandler func() http.Handler
+
 // NewExecutorProxyHandler creates a new proxy handler for routes accessible to the
 // executor services deployed separately from the k8s cluster. This handler is protected
 // via a shared username and password.
@@ -53,6 +59,12 @@ type NewGitHubAppCloudSetupHandler func() http.Handler
 // NewComputeStreamHandler creates a new handler for the Sourcegraph Compute streaming endpoint.
 type NewComputeStreamHandler func() http.Handler
 
+// NewNotebookExportHandler creates a new handler for the notebook Markdown export endpoint.
+type NewNotebookExportHandler func() http.Handler
+
+// NewInsightsExportHandler creates a new handler for the code insights data export endpoint.
+type NewInsightsExportHandler func() http.Handler
+
 // DefaultServices creates a new Services value that has default implementations for all services.
 func DefaultServices() Services {
 	return Services{
@@ -61,9 +73,12 @@ func DefaultServices() Services {
 		BitbucketServerWebhook:        makeNotFoundHandler("bitbucket server webhook"),
 		BitbucketCloudWebhook:         makeNotFoundHandler("bitbucket cloud webhook"),
 		NewCodeIntelUploadHandler:     func(_ bool) http.Handler { return makeNotFoundHandler("code intel upload") },
+		NewCodeIntelExportHandler:     func() http.Handler { return makeNotFoundHandler("code intel export") },
 		NewExecutorProxyHandler:       func() http.Handler { return makeNotFoundHandler("executor proxy") },
 		NewGitHubAppCloudSetupHandler: func() http.Handler { return makeNotFoundHandler("Sourcegraph Cloud GitHub App setup") },
 		NewComputeStreamHandler:       func() http.Handler { return makeNotFoundHandler("compute streaming endpoint") },
+		NewNotebookExportHandler:      func() http.Handler { return makeNotFoundHandler("notebook export") },
+		NewInsightsExportHandler:      func() http.Handler { return makeNotFoundHandler("code insights export") },
 	}
 }
 
@@ -23,6 +23,8 @@ type CodeIntelResolver interface {
 	GitTreeCodeIntelInfo(ctx context.Context, args *GitTreeEntryCodeIntelInfoArgs) (GitTreeCodeIntelSupportResolver, error)
 
 	RepositorySummary(ctx context.Context, id graphql.ID) (CodeIntelRepositorySummaryResolver, error)
+	APIDiff(ctx context.Context, id graphql.ID, args *CodeIntelAPIDiffArgs) ([]CodeIntelAPIDiffResolver, error)
+	SendCodeIntelAPIDiffAlert(ctx context.Context, args *SendCodeIntelAPIDiffAlertArgs) ([]CodeIntelAPIDiffResolver, error)
 	NodeResolvers() map[string]NodeByIDFunc
 
 	RequestLanguageSupport(ctx context.Context, args *RequestLanguageSupportArgs) (*EmptyResponse, error)
@@ -340,6 +342,45 @@ type CodeIntelRepositorySummaryResolver interface {
 	LastIndexScan() *DateTime
 }
 
+type CodeIntelAPIDiffArgs struct {
+	Base    string
+	Head    string
+	Root    *string
+	Indexer *string
+}
+
+type SendCodeIntelAPIDiffAlertArgs struct {
+	CodeIntelAPIDiffArgs
+	Repository  graphql.ID
+	CodeMonitor graphql.ID
+}
+
+type CodeIntelAPIDiffResolver interface {
+	BaseUpload() LSIFUploadResolver
+	HeadUpload() LSIFUploadResolver
+	Added() []CodeIntelExportedSymbolResolver
+	Removed() []CodeIntelExportedSymbolResolver
+	Changed() []CodeIntelChangedSymbolResolver
+	BatchSpec(ctx context.Context, args *CodeIntelAPIDiffBatchSpecArgs) (*string, error)
+}
+
+type CodeIntelAPIDiffBatchSpecArgs struct {
+	Name string
+}
+
+type CodeIntelExportedSymbolResolver interface {
+	Scheme() string
+	Identifier() string
+	Signature() string
+	Hover() HoverResolver
+	Location(ctx context.Context) (LocationResolver, error)
+}
+
+type CodeIntelChangedSymbolResolver interface {
+	Base() CodeIntelExportedSymbolResolver
+	Head() CodeIntelExportedSymbolResolver
+}
+
 type LSIFUploadsWithRepositoryNamespaceResolver interface {
 	Root() string
 	Indexer() CodeIntelIndexerResolver
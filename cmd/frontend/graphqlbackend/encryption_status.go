@@ -0,0 +1,89 @@
+package graphqlbackend
+
+import (
+	"context"
+	"sort"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/internal/encryption/keyring"
+	"github.com/sourcegraph/sourcegraph/internal/encryption/rotation"
+)
+
+// EncryptionStatus resolves the number of rows of each table with encrypted columns by key version.
+func (r *schemaResolver) EncryptionStatus(ctx context.Context) ([]*encryptedTableResolver, error) {
+	// 🚨 SECURITY: Only site admins may view the encryption status.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	store := rotation.NewStore(r.db)
+	ring := keyring.Default()
+
+	resolvers := make([]*encryptedTableResolver, 0, len(rotation.Tables))
+	for _, t := range rotation.Tables {
+		status, err := store.Status(ctx, t, ring)
+		if err != nil {
+			return nil, err
+		}
+		resolvers = append(resolvers, &encryptedTableResolver{s: status})
+	}
+
+	return resolvers, nil
+}
+
+// encryptedTableResolver implements the GraphQL type EncryptedTable.
+type encryptedTableResolver struct {
+	s *rotation.Status
+}
+
+func (r *encryptedTableResolver) Name() string { return r.s.Table.Name }
+
+func (r *encryptedTableResolver) Columns() []string {
+	columns := make([]string, 0, len(r.s.Table.Columns))
+	for _, c := range r.s.Table.Columns {
+		columns = append(columns, c.Name)
+	}
+	return columns
+}
+
+func (r *encryptedTableResolver) CurrentKeyVersion() *string {
+	if r.s.CurrentKeyID == "" {
+		return nil
+	}
+	return &r.s.CurrentKeyID
+}
+
+func (r *encryptedTableResolver) KeyVersions() []*encryptionKeyVersionCountResolver {
+	counts := make([]*encryptionKeyVersionCountResolver, 0, len(r.s.Counts))
+	for keyID, count := range r.s.Counts {
+		counts = append(counts, &encryptionKeyVersionCountResolver{
+			keyVersion: keyID,
+			encrypted:  r.s.Table.Encrypted(keyID),
+			current:    keyID != "" && keyID == r.s.CurrentKeyID,
+			count:      int32(count),
+		})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].keyVersion < counts[j].keyVersion })
+	return counts
+}
+
+func (r *encryptedTableResolver) Progress() *float64 {
+	if r.s.CurrentKeyID == "" {
+		return nil
+	}
+	progress := r.s.Progress()
+	return &progress
+}
+
+// encryptionKeyVersionCountResolver implements the GraphQL type EncryptionKeyVersionCount.
+type encryptionKeyVersionCountResolver struct {
+	keyVersion string
+	encrypted  bool
+	current    bool
+	count      int32
+}
+
+func (r *encryptionKeyVersionCountResolver) KeyVersion() string { return r.keyVersion }
+func (r *encryptionKeyVersionCountResolver) Encrypted() bool    { return r.encrypted }
+func (r *encryptionKeyVersionCountResolver) Current() bool      { return r.current }
+func (r *encryptionKeyVersionCountResolver) Count() int32       { return r.count }
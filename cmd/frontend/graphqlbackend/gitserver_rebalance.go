@@ -0,0 +1,56 @@
+package graphqlbackend
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/migration"
+)
+
+// GitserverRebalance resolves the most recent move of repositories between gitserver instances.
+func (r *schemaResolver) GitserverRebalance(ctx context.Context) (*gitserverRebalanceResolver, error) {
+	// 🚨 SECURITY: Only site admins may view the gitserver topology.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	rebalance, err := migration.NewRebalanceStore(r.db).Latest(ctx)
+	if err != nil || rebalance == nil {
+		return nil, err
+	}
+
+	return &gitserverRebalanceResolver{r: rebalance}, nil
+}
+
+// gitserverRebalanceResolver implements the GraphQL type GitserverRebalance.
+type gitserverRebalanceResolver struct {
+	r *migration.Rebalance
+}
+
+func (r *gitserverRebalanceResolver) State() string {
+	switch {
+	case !r.r.Finished():
+		return "IN_PROGRESS"
+	case conf.ExperimentalFeatures().GitServerRebalancing && !r.r.Settled(conf.Get().ServiceConnections().GitServers):
+		return "PENDING"
+	default:
+		return "COMPLETED"
+	}
+}
+
+func (r *gitserverRebalanceResolver) PreviousAddresses() []string { return r.r.PreviousAddrs }
+func (r *gitserverRebalanceResolver) TargetAddresses() []string   { return r.r.TargetAddrs }
+func (r *gitserverRebalanceResolver) TotalRepositories() int32    { return int32(r.r.TotalRepos) }
+func (r *gitserverRebalanceResolver) MovedRepositories() int32    { return int32(r.r.MovedRepos) }
+func (r *gitserverRebalanceResolver) FailedRepositories() int32   { return int32(r.r.FailedRepos) }
+func (r *gitserverRebalanceResolver) StartedAt() DateTime         { return DateTime{r.r.StartedAt} }
+func (r *gitserverRebalanceResolver) UpdatedAt() DateTime         { return DateTime{r.r.UpdatedAt} }
+func (r *gitserverRebalanceResolver) FinishedAt() *DateTime       { return DateTimeOrNil(r.r.FinishedAt) }
+
+func (r *gitserverRebalanceResolver) Progress() float64 {
+	if r.r.Finished() || r.r.TotalRepos == 0 {
+		return 1
+	}
+	return float64(r.r.MovedRepos+r.r.FailedRepos) / float64(r.r.TotalRepos)
+}
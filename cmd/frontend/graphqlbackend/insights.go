@@ -39,6 +39,11 @@ type InsightsResolver interface {
 	// Admin Management
 	UpdateInsightSeries(ctx context.Context, args *UpdateInsightSeriesArgs) (InsightSeriesMetadataPayloadResolver, error)
 	InsightSeriesQueryStatus(ctx context.Context) ([]InsightSeriesQueryStatusResolver, error)
+
+	// Alerts
+	InsightSeriesAlerts(ctx context.Context, args *InsightSeriesAlertsArgs) ([]InsightSeriesAlertResolver, error)
+	CreateInsightSeriesAlert(ctx context.Context, args *CreateInsightSeriesAlertArgs) (InsightSeriesAlertResolver, error)
+	DeleteInsightSeriesAlert(ctx context.Context, args *DeleteInsightSeriesAlertArgs) (*EmptyResponse, error)
 }
 
 type SearchInsightLivePreviewArgs struct {
@@ -93,12 +98,24 @@ type InsightsPointsArgs struct {
 	ExcludeRepoRegex *string
 }
 
+type InsightSeriesBreakdownArgs struct {
+	GroupBy string
+	First   *int32
+}
+
 type InsightSeriesResolver interface {
 	SeriesId() string
 	Label() string
 	Points(ctx context.Context, args *InsightsPointsArgs) ([]InsightsDataPointResolver, error)
 	Status(ctx context.Context) (InsightStatusResolver, error)
 	DirtyMetadata(ctx context.Context) ([]InsightDirtyQueryResolver, error)
+	Breakdown(ctx context.Context, args *InsightSeriesBreakdownArgs) ([]InsightSeriesBreakdownResolver, error)
+}
+
+type InsightSeriesBreakdownResolver interface {
+	Name() string
+	LatestValue() float64
+	Points() []InsightsDataPointResolver
 }
 
 type InsightResolver interface {
@@ -223,6 +240,7 @@ type SearchInsightDataSeriesDefinitionResolver interface {
 	RepositoryScope(ctx context.Context) (InsightRepositoryScopeResolver, error)
 	TimeScope(ctx context.Context) (InsightTimeScope, error)
 	GeneratedFromCaptureGroups() (bool, error)
+	GeneratedFromPreciseReferences() (bool, error)
 	IsCalculated() (bool, error)
 }
 
@@ -393,12 +411,13 @@ type InsightViewFiltersInput struct {
 }
 
 type LineChartSearchInsightDataSeriesInput struct {
-	SeriesId                   *string
-	Query                      string
-	TimeScope                  TimeScopeInput
-	RepositoryScope            RepositoryScopeInput
-	Options                    LineChartDataSeriesOptionsInput
-	GeneratedFromCaptureGroups *bool
+	SeriesId                       *string
+	Query                          string
+	TimeScope                      TimeScopeInput
+	RepositoryScope                RepositoryScopeInput
+	Options                        LineChartDataSeriesOptionsInput
+	GeneratedFromCaptureGroups     *bool
+	GeneratedFromPreciseReferences *bool
 }
 
 type LineChartDataSeriesOptionsInput struct {
@@ -444,3 +463,33 @@ type SearchInsightLivePreviewSeriesResolver interface {
 	Points(ctx context.Context) ([]InsightsDataPointResolver, error)
 	Label(ctx context.Context) (string, error)
 }
+
+type InsightSeriesAlertsArgs struct {
+	SeriesId string
+}
+
+type CreateInsightSeriesAlertArgs struct {
+	Input CreateInsightSeriesAlertInput
+}
+
+type CreateInsightSeriesAlertInput struct {
+	SeriesId    string
+	Kind        string
+	Threshold   float64
+	Intervals   *int32
+	CodeMonitor graphql.ID
+}
+
+type DeleteInsightSeriesAlertArgs struct {
+	Id graphql.ID
+}
+
+type InsightSeriesAlertResolver interface {
+	ID() graphql.ID
+	SeriesId() string
+	Kind() string
+	Threshold() float64
+	Intervals() int32
+	CodeMonitor() graphql.ID
+	LastFiredAt() *DateTime
+}
@@ -21,6 +21,8 @@ type NotebooksResolver interface {
 	CreateNotebook(ctx context.Context, args CreateNotebookInputArgs) (NotebookResolver, error)
 	UpdateNotebook(ctx context.Context, args UpdateNotebookInputArgs) (NotebookResolver, error)
 	DeleteNotebook(ctx context.Context, args DeleteNotebookArgs) (*EmptyResponse, error)
+	CreateNotebookFromMarkdown(ctx context.Context, args CreateNotebookFromMarkdownArgs) (NotebookResolver, error)
+	UpdateNotebookFromMarkdown(ctx context.Context, args UpdateNotebookFromMarkdownArgs) (NotebookResolver, error)
 	Notebooks(ctx context.Context, args ListNotebooksArgs) (NotebookConnectionResolver, error)
 
 	CreateNotebookStar(ctx context.Context, args CreateNotebookStarInputArgs) (NotebookStarResolver, error)
@@ -50,6 +52,9 @@ type NotebookResolver interface {
 	ID() graphql.ID
 	Title(ctx context.Context) string
 	Blocks(ctx context.Context) []NotebookBlockResolver
+	Variables(ctx context.Context) []NotebookVariableResolver
+	RenderedBlocks(ctx context.Context, args RenderedNotebookBlocksArgs) ([]NotebookBlockResolver, error)
+	Markdown(ctx context.Context) (string, error)
 	Creator(ctx context.Context) (*UserResolver, error)
 	Updater(ctx context.Context) (*UserResolver, error)
 	Namespace(ctx context.Context) (*NamespaceResolver, error)
@@ -61,6 +66,11 @@ type NotebookResolver interface {
 	Stars(ctx context.Context, args ListNotebookStarsArgs) (NotebookStarConnectionResolver, error)
 }
 
+type NotebookVariableResolver interface {
+	Name() string
+	DefaultValue() string
+}
+
 type NotebookBlockResolver interface {
 	ToMarkdownBlock() (MarkdownBlockResolver, bool)
 	ToQueryBlock() (QueryBlockResolver, bool)
@@ -139,11 +149,42 @@ type DeleteNotebookArgs struct {
 	ID graphql.ID `json:"id"`
 }
 
+type CreateNotebookFromMarkdownArgs struct {
+	Notebook NotebookMarkdownInputArgs `json:"notebook"`
+}
+
+type UpdateNotebookFromMarkdownArgs struct {
+	ID       graphql.ID `json:"id"`
+	Markdown string     `json:"markdown"`
+}
+
+type NotebookMarkdownInputArgs struct {
+	Title     string     `json:"title"`
+	Markdown  string     `json:"markdown"`
+	Namespace graphql.ID `json:"namespace"`
+	Public    bool       `json:"public"`
+}
+
 type NotebookInputArgs struct {
 	Title     string                         `json:"title"`
 	Blocks    []CreateNotebookBlockInputArgs `json:"blocks"`
 	Public    bool                           `json:"public"`
 	Namespace graphql.ID                     `json:"namespace"`
+	Variables *[]NotebookVariableInput       `json:"variables"`
+}
+
+type NotebookVariableInput struct {
+	Name         string `json:"name"`
+	DefaultValue string `json:"defaultValue"`
+}
+
+type NotebookVariableValueInput struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type RenderedNotebookBlocksArgs struct {
+	Variables *[]NotebookVariableValueInput `json:"variables"`
 }
 
 type CreateNotebookBlockInputArgs struct {
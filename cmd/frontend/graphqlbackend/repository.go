@@ -352,6 +352,10 @@ func (r *RepositoryResolver) CodeIntelSummary(ctx context.Context) (CodeIntelRep
 	return EnterpriseResolvers.codeIntelResolver.RepositorySummary(ctx, r.ID())
 }
 
+func (r *RepositoryResolver) CodeIntelAPIDiff(ctx context.Context, args *CodeIntelAPIDiffArgs) ([]CodeIntelAPIDiffResolver, error) {
+	return EnterpriseResolvers.codeIntelResolver.APIDiff(ctx, r.ID(), args)
+}
+
 func (r *RepositoryResolver) PreviewGitObjectFilter(ctx context.Context, args *PreviewGitObjectFilterArgs) ([]GitObjectFilterPreviewResolver, error) {
 	return EnterpriseResolvers.codeIntelResolver.PreviewGitObjectFilter(ctx, r.ID(), args)
 }
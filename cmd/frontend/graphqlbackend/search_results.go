@@ -166,6 +166,11 @@ func matchesToResolvers(db database.DB, matches []result.Match) []SearchResultRe
 				db:          db,
 				CommitMatch: *v,
 			})
+		case *result.CommitDiffMatch:
+			resolvers = append(resolvers, &CommitSearchResultResolver{
+				db:          db,
+				CommitMatch: *v.ToCommitMatch(),
+			})
 		}
 	}
 	return resolvers
@@ -308,6 +313,8 @@ loop:
 		case *result.CommitMatch:
 			// Diff searches are cheap, because we implicitly have author date info.
 			addPoint(m.Commit.Author.Date)
+		case *result.CommitDiffMatch:
+			addPoint(m.Commit.Author.Date)
 		case *result.FileMatch:
 			// File match searches are more expensive, because we must blame the
 			// (first) line in order to know its placement in our sparkline.
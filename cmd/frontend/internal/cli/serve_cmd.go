@@ -323,7 +323,10 @@ func makeExternalAPI(db database.DB, schema *graphql.Schema, enterprise enterpri
 			BitbucketServerWebhook:    enterprise.BitbucketServerWebhook,
 			BitbucketCloudWebhook:     enterprise.BitbucketCloudWebhook,
 			NewCodeIntelUploadHandler: enterprise.NewCodeIntelUploadHandler,
+			NewCodeIntelExportHandler: enterprise.NewCodeIntelExportHandler,
 			NewComputeStreamHandler:   enterprise.NewComputeStreamHandler,
+			NewNotebookExportHandler:  enterprise.NewNotebookExportHandler,
+			NewInsightsExportHandler:  enterprise.NewInsightsExportHandler,
 		},
 		enterprise.NewExecutorProxyHandler,
 		enterprise.NewGitHubAppCloudSetupHandler,
@@ -31,7 +31,10 @@ func newTest() *httptestutil.Client {
 			BitbucketServerWebhook:    enterpriseServices.BitbucketServerWebhook,
 			BitbucketCloudWebhook:     enterpriseServices.BitbucketCloudWebhook,
 			NewCodeIntelUploadHandler: enterpriseServices.NewCodeIntelUploadHandler,
+			NewCodeIntelExportHandler: enterpriseServices.NewCodeIntelExportHandler,
 			NewComputeStreamHandler:   enterpriseServices.NewComputeStreamHandler,
+			NewNotebookExportHandler:  enterpriseServices.NewNotebookExportHandler,
+			NewInsightsExportHandler:  enterpriseServices.NewInsightsExportHandler,
 		},
 	))
 }
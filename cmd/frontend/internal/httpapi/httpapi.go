@@ -43,7 +43,10 @@ type Handlers struct {
 	BitbucketServerWebhook    http.Handler
 	BitbucketCloudWebhook     http.Handler
 	NewCodeIntelUploadHandler enterprise.NewCodeIntelUploadHandler
+	NewCodeIntelExportHandler enterprise.NewCodeIntelExportHandler
 	NewComputeStreamHandler   enterprise.NewComputeStreamHandler
+	NewNotebookExportHandler  enterprise.NewNotebookExportHandler
+	NewInsightsExportHandler  enterprise.NewInsightsExportHandler
 }
 
 // NewHandler returns a new API handler that uses the provided API
@@ -92,7 +95,10 @@ func NewHandler(
 	m.Get(apirouter.BitbucketServerWebhooks).Handler(trace.Route(webhookMiddleware.Logger(handlers.BitbucketServerWebhook)))
 	m.Get(apirouter.BitbucketCloudWebhooks).Handler(trace.Route(webhookMiddleware.Logger(handlers.BitbucketCloudWebhook)))
 	m.Get(apirouter.LSIFUpload).Handler(trace.Route(handlers.NewCodeIntelUploadHandler(false)))
+	m.Get(apirouter.LSIFExport).Handler(trace.Route(handlers.NewCodeIntelExportHandler()))
 	m.Get(apirouter.ComputeStream).Handler(trace.Route(handlers.NewComputeStreamHandler()))
+	m.Get(apirouter.NotebookExport).Handler(trace.Route(handlers.NewNotebookExportHandler()))
+	m.Get(apirouter.InsightsExport).Handler(trace.Route(handlers.NewInsightsExportHandler()))
 
 	if envvar.SourcegraphDotComMode() {
 		m.Path("/updates").Methods("GET", "POST").Name("updatecheck").Handler(trace.Route(http.HandlerFunc(updatecheck.Handler)))
@@ -9,11 +9,15 @@ import (
 
 const (
 	LSIFUpload = "lsif.upload"
+	LSIFExport = "lsif.export"
 	GraphQL    = "graphql"
 
 	SearchStream  = "search.stream"
 	ComputeStream = "compute.stream"
 
+	NotebookExport = "notebook.export"
+	InsightsExport = "insights.export"
+
 	SrcCliVersion  = "src-cli.version"
 	SrcCliDownload = "src-cli.download"
 
@@ -68,8 +72,11 @@ func New(base *mux.Router) *mux.Router {
 	base.Path("/bitbucket-server-webhooks").Methods("POST").Name(BitbucketServerWebhooks)
 	base.Path("/bitbucket-cloud-webhooks").Methods("POST").Name(BitbucketCloudWebhooks)
 	base.Path("/lsif/upload").Methods("POST").Name(LSIFUpload)
+	base.Path("/lsif/uploads/{UploadID:[0-9]+}/export").Methods("GET").Name(LSIFExport)
 	base.Path("/search/stream").Methods("GET").Name(SearchStream)
 	base.Path("/compute/stream").Methods("GET", "POST").Name(ComputeStream)
+	base.Path("/notebooks/{ID}/markdown").Methods("GET").Name(NotebookExport)
+	base.Path("/insights/{ID}/export").Methods("GET").Name(InsightsExport)
 	base.Path("/src-cli/version").Methods("GET").Name(SrcCliVersion)
 	base.Path("/src-cli/{rest:.*}").Methods("GET").Name(SrcCliDownload)
 
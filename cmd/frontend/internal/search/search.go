@@ -419,6 +419,8 @@ func fromMatch(match result.Match, repoCache map[api.RepoID]*types.SearchedRepo)
 		return fromRepository(v, repoCache)
 	case *result.CommitMatch:
 		return fromCommit(v, repoCache)
+	case *result.CommitDiffMatch:
+		return fromCommit(v.ToCommitMatch(), repoCache)
 	default:
 		panic(fmt.Sprintf("unknown match type %T", v))
 	}
@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -128,6 +129,8 @@ func main() {
 		logger.Fatal("failed to initialise keyring", zap.Error(err))
 	}
 
+	lfsServices := &gitLFSServices{}
+
 	gitserver := server.Server{
 		Logger:             logger,
 		ReposDir:           reposDir,
@@ -136,7 +139,7 @@ func main() {
 			return getRemoteURLFunc(ctx, externalServiceStore, repoStore, nil, repo)
 		},
 		GetVCSSyncer: func(ctx context.Context, repo api.RepoName) (server.VCSSyncer, error) {
-			return getVCSSyncer(ctx, externalServiceStore, repoStore, depsSvc, repo)
+			return getVCSSyncer(ctx, externalServiceStore, repoStore, depsSvc, lfsServices, repo)
 		},
 		Hostname:                hostname.Get(),
 		DB:                      db,
@@ -179,6 +182,7 @@ func main() {
 	}
 
 	go syncRateLimiters(ctx, externalServiceStore, rateLimitSyncerLimitPerSecond)
+	go syncGitLFSServices(ctx, externalServiceStore, lfsServices)
 	go debugserver.NewServerRoutine(ready).Start()
 	go gitserver.Janitor(janitorInterval)
 	go gitserver.SyncRepoState(syncRepoStateInterval, syncRepoStateBatchSize, syncRepoStateUpsertPerSecond)
@@ -407,6 +411,7 @@ func getVCSSyncer(
 	externalServiceStore database.ExternalServiceStore,
 	repoStore database.RepoStore,
 	depsSvc *dependencies.Service,
+	lfsServices *gitLFSServices,
 	repo api.RepoName,
 ) (server.VCSSyncer, error) {
 	// We need an internal actor in case we are trying to access a private repo. We
@@ -446,6 +451,15 @@ func getVCSSyncer(
 			Client:       c.P4Client,
 			FusionConfig: configureFusionClient(c),
 		}, nil
+	case extsvc.TypeSubversion:
+		var c schema.SubversionConnection
+		if _, err := extractOptions(&c); err != nil {
+			return nil, err
+		}
+		return &server.SubversionRepoSyncer{
+			Layout:  c.Layout,
+			Authors: c.Authors,
+		}, nil
 	case extsvc.TypeJVMPackages:
 		var c schema.JVMPackagesConnection
 		if _, err := extractOptions(&c); err != nil {
@@ -484,10 +498,109 @@ func getVCSSyncer(
 		}
 		cli := crates.NewClient(urn, httpcli.ExternalDoer)
 		return server.NewRustPackagesSyncer(&c, depsSvc, cli), nil
+	case extsvc.TypeGitHub, extsvc.TypeGitLab, extsvc.TypeBitbucketServer, extsvc.TypeBitbucketCloud, extsvc.TypeOther:
+		return &server.GitRepoSyncer{LFS: lfsServices.options(r)}, nil
 	}
 	return &server.GitRepoSyncer{}, nil
 }
 
+// gitLFSServices tracks the Git LFS options of the external services that enable Git
+// LFS, so that the syncers of Git repositories are resolved without reading and
+// decrypting the configuration of their external services on every clone and fetch.
+type gitLFSServices struct {
+	mu   sync.RWMutex
+	byID map[int64]*server.GitLFSOptions
+}
+
+// gitLFSKinds are the kinds of the external services supporting Git LFS.
+var gitLFSKinds = []string{
+	extsvc.KindGitHub,
+	extsvc.KindGitLab,
+	extsvc.KindBitbucketServer,
+	extsvc.KindBitbucketCloud,
+	extsvc.KindOther,
+}
+
+// options returns the Git LFS options of the first external service of r that enables
+// Git LFS, or nil if none does.
+func (s *gitLFSServices) options(r *types.Repo) *server.GitLFSOptions {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, info := range r.Sources {
+		if opts, ok := s.byID[info.ExternalServiceID()]; ok {
+			return opts
+		}
+	}
+	return nil
+}
+
+// sync reloads the external services that enable Git LFS. External services whose
+// configuration is invalid are ignored.
+func (s *gitLFSServices) sync(ctx context.Context, store database.ExternalServiceStore) error {
+	svcs, err := store.List(ctx, database.ExternalServicesListOptions{Kinds: gitLFSKinds})
+	if err != nil {
+		return errors.Wrap(err, "listing external services")
+	}
+
+	byID := make(map[int64]*server.GitLFSOptions)
+	for _, svc := range svcs {
+		normalized, err := jsonc.Parse(svc.Config)
+		if err != nil {
+			continue
+		}
+		var c gitLFSConnection
+		if err := jsoniter.Unmarshal(normalized, &c); err != nil || c.GitLFS == nil || !c.GitLFS.Enabled {
+			continue
+		}
+		maxFileSize := c.GitLFS.MaxFileSize
+		if maxFileSize <= 0 {
+			maxFileSize = defaultGitLFSMaxFileSize
+		}
+		byID[svc.ID] = &server.GitLFSOptions{MaxFileSize: maxFileSize}
+	}
+
+	s.mu.Lock()
+	s.byID = byID
+	s.mu.Unlock()
+	return nil
+}
+
+// syncGitLFSServices periodically reloads the external services that enable Git LFS.
+// Until the first successful sync, Git LFS objects are not fetched.
+func syncGitLFSServices(ctx context.Context, store database.ExternalServiceStore, lfsServices *gitLFSServices) {
+	logger := log.Scoped("syncGitLFSServices", "sync the external services that enable Git LFS")
+
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+	for {
+		if err := lfsServices.sync(ctx, store); err != nil {
+			logger.Warn("error syncing Git LFS external services", log.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// defaultGitLFSMaxFileSize is the default maximum size of the Git LFS objects to
+// fetch, as documented in the code host configuration schemas.
+const defaultGitLFSMaxFileSize = 1024 * 1024
+
+// gitLFSConnection is the Git LFS configuration shared by the Git code hosts that
+// support it, such as schema.GitHubConnection.
+type gitLFSConnection struct {
+	GitLFS *struct {
+		Enabled     bool  `json:"enabled"`
+		MaxFileSize int64 `json:"maxFileSize"`
+	} `json:"gitLFS"`
+}
+
 func syncSiteLevelExternalServiceRateLimiters(ctx context.Context, store database.ExternalServiceStore) error {
 	svcs, err := store.List(ctx, database.ExternalServicesListOptions{NoNamespace: true})
 	if err != nil {
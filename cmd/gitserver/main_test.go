@@ -176,7 +176,7 @@ func TestGetVCSSyncer(t *testing.T) {
 		}, nil
 	})
 
-	s, err := getVCSSyncer(context.Background(), extsvcStore, repoStore, depsSvc, repo)
+	s, err := getVCSSyncer(context.Background(), extsvcStore, repoStore, depsSvc, nil, repo)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -186,3 +186,65 @@ func TestGetVCSSyncer(t *testing.T) {
 		t.Fatalf("Want *server.PerforceDepotSyncer, got %T", s)
 	}
 }
+
+func TestGetVCSSyncer_GitLFS(t *testing.T) {
+	repo := api.RepoName("github.com/foo/bar")
+	extsvcStore := database.NewMockExternalServiceStore()
+	repoStore := database.NewMockRepoStore()
+	depsSvc := new(dependencies.Service)
+
+	repoStore.GetByNameFunc.SetDefaultHook(func(ctx context.Context, name api.RepoName) (*types.Repo, error) {
+		return &types.Repo{
+			ExternalRepo: api.ExternalRepoSpec{
+				ServiceType: extsvc.TypeGitHub,
+			},
+			Sources: map[string]*types.SourceInfo{
+				"extsvc:github:1": {
+					ID:       "extsvc:github:1",
+					CloneURL: "https://github.com/foo/bar",
+				},
+			},
+		}, nil
+	})
+
+	for _, tc := range []struct {
+		config string
+		want   *server.GitLFSOptions
+	}{
+		{config: `{}`},
+		{config: `invalid`},
+		{config: `{"gitLFS": {"enabled": false, "maxFileSize": 10}}`},
+		{config: `{"gitLFS": {"enabled": true}}`, want: &server.GitLFSOptions{MaxFileSize: 1024 * 1024}},
+		{config: `{"gitLFS": {"enabled": true, "maxFileSize": 10}}`, want: &server.GitLFSOptions{MaxFileSize: 10}},
+	} {
+		t.Run(tc.config, func(t *testing.T) {
+			extsvcStore.ListFunc.SetDefaultHook(func(ctx context.Context, opts database.ExternalServicesListOptions) ([]*types.ExternalService, error) {
+				return []*types.ExternalService{{
+					ID:          1,
+					Kind:        extsvc.KindGitHub,
+					DisplayName: "test",
+					Config:      tc.config,
+				}}, nil
+			})
+			lfsServices := &gitLFSServices{}
+			require.NoError(t, lfsServices.sync(context.Background(), extsvcStore))
+
+			s, err := getVCSSyncer(context.Background(), extsvcStore, repoStore, depsSvc, lfsServices, repo)
+			require.NoError(t, err)
+			gs, ok := s.(*server.GitRepoSyncer)
+			require.True(t, ok, "want *server.GitRepoSyncer, got %T", s)
+			assert.Equal(t, tc.want, gs.LFS)
+		})
+	}
+
+	t.Run("external service lookups", func(t *testing.T) {
+		// The configuration of external services is not read to resolve the syncer of
+		// Git repositories, so that failures to read it do not fail clones.
+		extsvcStore.GetByIDFunc.SetDefaultReturn(nil, errors.New("unavailable"))
+
+		s, err := getVCSSyncer(context.Background(), extsvcStore, repoStore, depsSvc, &gitLFSServices{}, repo)
+		require.NoError(t, err)
+		assert.Equal(t, &server.GitRepoSyncer{}, s)
+		assert.Empty(t, extsvcStore.GetByIDFunc.History())
+	})
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/env"
 	"github.com/sourcegraph/sourcegraph/internal/fileutil"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/migration"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
 	"github.com/sourcegraph/sourcegraph/internal/types"
@@ -138,6 +139,7 @@ const reposStatsName = "repos-stats.json"
 // 9. Perform sg-maintenance
 // 10. Git prune
 // 11. Only during first run: Set sizes of repos which don't have it in a database.
+// 12. Remove Git LFS objects which are not referenced by any repo.
 func (s *Server) cleanupRepos(gitServerAddrs []string) {
 	janitorRunning.Set(1)
 	janitorStart := time.Now()
@@ -161,6 +163,13 @@ func (s *Server) cleanupRepos(gitServerAddrs []string) {
 	bCtx, bCancel := s.serverContext()
 	defer bCancel()
 
+	// Repos on the wrong shard may still be served from this instance while they are moved to
+	// their new owner, so we must not delete them before the rebalance finished.
+	rebalancing := s.rebalancing(bCtx, gitServerAddrs)
+	if rebalancing {
+		s.Logger.Info("repositories are being moved between gitserver shards, will not delete repos", log.String("current-hostname", s.Hostname))
+	}
+
 	stats := protocol.ReposStats{
 		UpdatedAt: time.Now(),
 	}
@@ -194,7 +203,7 @@ func (s *Server) cleanupRepos(gitServerAddrs []string) {
 		if !s.hostnameMatch(addr) {
 			wrongShardRepoCount++
 			wrongShardRepoSize += size
-			if isKnownGitServerShard && wrongShardReposDeleteLimit > 0 && wrongShardReposDeleted < int64(wrongShardReposDeleteLimit) {
+			if isKnownGitServerShard && !rebalancing && wrongShardReposDeleteLimit > 0 && wrongShardReposDeleted < int64(wrongShardReposDeleteLimit) {
 				s.Logger.Info("removing repo cloned on the wrong shard", log.String("dir", string(dir)), log.String("target-shard", addr), log.String("current-shard", s.Hostname), log.Int64("size-bytes", size))
 				if err := s.removeRepoDirectory(dir); err != nil {
 					return false, err
@@ -284,10 +293,10 @@ func (s *Server) cleanupRepos(gitServerAddrs []string) {
 			}
 		}
 
-		// We believe converting a Perforce depot to a Git repository is generally a
-		// very expensive operation, therefore we do not try to re-clone/redo the
-		// conversion only because it is old or slow to do "git gc".
-		if repoType == "perforce" && reason != maybeCorrupt {
+		// We believe converting a Perforce depot or a Subversion repository to a Git
+		// repository is generally a very expensive operation, therefore we do not try
+		// to re-clone/redo the conversion only because it is old or slow to do "git gc".
+		if (repoType == "perforce" || repoType == "subversion") && reason != maybeCorrupt {
 			reason = ""
 		}
 
@@ -469,6 +478,10 @@ func (s *Server) cleanupRepos(gitServerAddrs []string) {
 		}
 	})
 
+	if err := s.cleanupLFSObjects(); err != nil {
+		cleanupLogger.Error("error removing unreferenced Git LFS objects", log.Error(err))
+	}
+
 	if s.DiskSizer == nil {
 		s.DiskSizer = &StatDiskSizer{}
 	}
@@ -606,6 +619,11 @@ func (s *Server) freeUpSpace(howManyBytesToFree int64) error {
 		return dirModTimes[gitDirs[i]].Before(dirModTimes[gitDirs[j]])
 	})
 
+	// Git LFS objects are shared between repos, so they are only removed with the
+	// last repo referencing them.
+	lfsRefs := lfsReferences(gitDirs)
+	store := s.lfsStore()
+
 	// Remove repos until howManyBytesToFree is met or exceeded.
 	var spaceFreed int64
 	diskSizeBytes, err := s.DiskSizer.DiskSizeBytes(s.ReposDir)
@@ -617,9 +635,15 @@ func (s *Server) freeUpSpace(howManyBytesToFree int64) error {
 			return nil
 		}
 		delta := dirSize(d.Path("."))
+		lfsObjects := readLFSObjects(d)
 		if err := s.removeRepoDirectory(d); err != nil {
 			return errors.Wrap(err, "removing repo directory")
 		}
+		for _, oid := range lfsObjects {
+			if lfsRefs[oid]--; lfsRefs[oid] == 0 {
+				delta += store.remove(oid)
+			}
+		}
 		spaceFreed += delta
 		reposRemovedDiskPressure.Inc()
 
@@ -1285,3 +1309,19 @@ func removeFileOlderThan(path string, maxAge time.Duration) error {
 	}
 	return nil
 }
+
+// rebalancing returns true if repositories are being moved between gitserver instances or are
+// about to be moved because the list of instances changed.
+func (s *Server) rebalancing(ctx context.Context, gitServerAddrs []string) bool {
+	if !conf.ExperimentalFeatures().GitServerRebalancing || s.DB == nil {
+		return false
+	}
+
+	r, err := migration.GetRebalance(ctx, s.DB)
+	if err != nil {
+		// Err on the side of keeping repos around.
+		s.Logger.Warn("failed to get gitserver rebalance state", log.Error(err))
+		return true
+	}
+	return r != nil && !r.Settled(gitServerAddrs)
+}
@@ -0,0 +1,809 @@
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+const (
+	// lfsDirName is the name of the directory under ReposDir that stores the Git LFS
+	// objects of all repositories, addressed by their SHA-256.
+	lfsDirName = ".lfs"
+
+	// lfsObjectsFile lists the OIDs of the Git LFS objects referenced by a
+	// repository, one per line. It is written in the Git directory, and pointer files
+	// are only replaced by the content of the objects listed in it.
+	lfsObjectsFile = "sourcegraph-lfs-objects"
+
+	// lfsBinarySuffix is the suffix of the empty files marking the objects that were
+	// downloaded but are not text, so that they are not downloaded again.
+	lfsBinarySuffix = ".binary"
+
+	// lfsPointerMaxSize is the maximum size of a pointer file, as in git-lfs.
+	lfsPointerMaxSize = 1024
+
+	// lfsBatchSize is the number of objects requested per batch API request.
+	lfsBatchSize = 100
+
+	// lfsObjectMinAge is how long objects that are not referenced by any repository
+	// are kept. It covers the objects of clones in progress, whose repositories are
+	// not in ReposDir yet.
+	lfsObjectMinAge = 24 * time.Hour
+)
+
+// GitLFSOptions configures the fetching of the Git LFS objects of a repository.
+type GitLFSOptions struct {
+	// MaxFileSize is the maximum size in bytes of the objects to fetch.
+	MaxFileSize int64
+}
+
+var lfsObjectsBytes = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "src_gitserver_lfs_objects_bytes",
+	Help: "Amount of bytes used by the Git LFS objects stored on gitserver.",
+})
+
+// lfsHTTPClient is the client used to download Git LFS objects. Unlike
+// httpcli.ExternalDoer, it does not cache responses since objects are stored on disk
+// and can be large.
+var lfsHTTPClient, _ = httpcli.NewFactory(
+	httpcli.NewMiddleware(
+		httpcli.ContextErrorMiddleware,
+		httpcli.HeadersMiddleware("User-Agent", "Sourcegraph-Bot"),
+	),
+	httpcli.ExternalTransportOpt,
+	httpcli.TracedTransportOpt,
+).Doer()
+
+// gitLFSOptions returns the Git LFS options of syncer, or nil if Git LFS objects are
+// not fetched for its repositories.
+func gitLFSOptions(syncer VCSSyncer) *GitLFSOptions {
+	if s, ok := syncer.(*GitRepoSyncer); ok {
+		return s.LFS
+	}
+	return nil
+}
+
+func (s *Server) lfsStore() lfsStore {
+	return lfsStore(filepath.Join(s.ReposDir, lfsDirName))
+}
+
+// fetchLFSObjects downloads the Git LFS objects of the text files of HEAD which are
+// not stored yet, and writes the list of objects referenced by the repository in dir.
+// If opts is nil or the repository is a partial clone, Git LFS is disabled for the
+// repository and the list is removed, so that its objects are eventually removed by
+// the janitor.
+func (s *Server) fetchLFSObjects(ctx context.Context, dir GitDir, remoteURL *vcs.URL, opts *GitLFSOptions) error {
+	if isPartialClone(dir) {
+		// Pointer files are only found by reading the blobs, which partial clones
+		// fetch on demand.
+		opts = nil
+	}
+	if opts == nil {
+		if err := os.Remove(dir.Path(lfsObjectsFile)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	pointers, err := lfsPointers(ctx, dir)
+	if err != nil {
+		return errors.Wrap(err, "list Git LFS pointers")
+	}
+
+	// 🚨 SECURITY: Objects are stored once for all repositories, and a repository is
+	// served the objects it references. Only the objects that were referenced before
+	// or that were downloaded for the repository and match their OID are referenced, so
+	// that the content of the objects of other repositories cannot be read by committing
+	// pointer files to them.
+	store := s.lfsStore()
+	previous := make(map[string]struct{})
+	for _, oid := range readLFSObjects(dir) {
+		previous[oid] = struct{}{}
+	}
+	var referenced []string
+	var unverified []lfsPointer
+	for _, p := range pointers {
+		if p.Size > opts.MaxFileSize {
+			continue
+		}
+		if _, ok := previous[p.OID]; ok && store.has(p.OID) {
+			referenced = append(referenced, p.OID)
+		} else {
+			unverified = append(unverified, p)
+		}
+	}
+
+	var downloadErr error
+	if len(unverified) > 0 {
+		endpoint, err := lfsEndpoint(ctx, dir, remoteURL)
+		downloadErr = err
+		if err == nil {
+			c := &lfsClient{doer: lfsHTTPClient, endpoint: endpoint, store: store}
+			for len(unverified) > 0 {
+				batch := unverified
+				if len(batch) > lfsBatchSize {
+					batch = batch[:lfsBatchSize]
+				}
+				unverified = unverified[len(batch):]
+				// Objects downloaded in the meantime are not removed by the janitor, since
+				// they are more recent than lfsObjectMinAge.
+				oids, err := c.download(ctx, batch)
+				referenced = append(referenced, oids...)
+				if err != nil {
+					downloadErr = errors.Wrapf(err, "download Git LFS objects from %s", newURLRedactor(remoteURL).redact(c.endpoint.String()))
+					break
+				}
+			}
+		}
+	}
+
+	sort.Strings(referenced)
+	var oids bytes.Buffer
+	for _, oid := range referenced {
+		oids.WriteString(oid + "\n")
+	}
+	if err := os.WriteFile(dir.Path(lfsObjectsFile), oids.Bytes(), 0600); err != nil {
+		return errors.Wrap(err, "write Git LFS objects list")
+	}
+	return downloadErr
+}
+
+// lfsPointers returns the pointer files of the tree of HEAD, sorted by OID.
+func lfsPointers(ctx context.Context, dir GitDir) ([]lfsPointer, error) {
+	cmd := exec.CommandContext(ctx, "git", "ls-tree", "-r", "-l", "-z", "HEAD")
+	dir.Set(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, wrapCmdError(cmd, err)
+	}
+
+	// Only blobs small enough to be pointer files are read.
+	seen := make(map[string]struct{})
+	var blobs []string
+	for _, entry := range bytes.Split(out, []byte{0}) {
+		// Example: 100644 blob 3b18e512dba79e4c8300dd08aeb37f8e728b8dad     130	data/model.json
+		info, _, ok := bytes.Cut(entry, []byte{'\t'})
+		fields := strings.Fields(string(info))
+		if !ok || len(fields) != 4 || fields[1] != "blob" {
+			continue
+		}
+		if size, err := strconv.ParseInt(fields[3], 10, 64); err != nil || size > lfsPointerMaxSize {
+			continue
+		}
+		if _, ok := seen[fields[2]]; !ok {
+			seen[fields[2]] = struct{}{}
+			blobs = append(blobs, fields[2])
+		}
+	}
+	if len(blobs) == 0 {
+		return nil, nil
+	}
+
+	cmd = exec.CommandContext(ctx, "git", "cat-file", "--batch")
+	dir.Set(cmd)
+	cmd.Stdin = strings.NewReader(strings.Join(blobs, "\n") + "\n")
+	out, err = cmd.Output()
+	if err != nil {
+		return nil, wrapCmdError(cmd, err)
+	}
+
+	pointers := make(map[string]lfsPointer)
+	r := bufio.NewReader(bytes.NewReader(out))
+	for range blobs {
+		// Example: 3b18e512dba79e4c8300dd08aeb37f8e728b8dad blob 130
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, errors.Wrap(err, "read git cat-file header")
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			// Missing objects only have 2 fields and no content.
+			continue
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse git cat-file header %q", header)
+		}
+		content := make([]byte, size+1) // the content is followed by a newline
+		if _, err := io.ReadFull(r, content); err != nil {
+			return nil, errors.Wrap(err, "read git cat-file content")
+		}
+		if p, ok := parseLFSPointer(content[:size]); ok {
+			pointers[p.OID] = p
+		}
+	}
+
+	sorted := make([]lfsPointer, 0, len(pointers))
+	for _, p := range pointers {
+		sorted = append(sorted, p)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].OID < sorted[j].OID })
+	return sorted, nil
+}
+
+// lfsEndpoint returns the URL of the Git LFS server of the repository in dir. Like
+// git-lfs, it is the lfs.url setting of the .lfsconfig file of the repository if it
+// has one, or else derived from the remote URL. Unlike git-lfs, lfs.url must have the
+// scheme and host of the remote URL. Only HTTP(S) remotes are supported, since the SSH
+// transfer of git-lfs requires git-lfs-authenticate.
+func lfsEndpoint(ctx context.Context, dir GitDir, remoteURL *vcs.URL) (*url.URL, error) {
+	if remoteURL.Scheme != "http" && remoteURL.Scheme != "https" {
+		return nil, errors.Errorf("unsupported remote URL scheme %q: Git LFS objects can only be fetched over HTTP(S)", remoteURL.Scheme)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "config", "--blob", "HEAD:.lfsconfig", "--get", "lfs.url")
+	dir.Set(cmd)
+	if out, err := cmd.Output(); err == nil && len(bytes.TrimSpace(out)) > 0 {
+		u, err := url.Parse(string(bytes.TrimSpace(out)))
+		if err != nil {
+			return nil, errors.Wrap(err, "parse lfs.url of .lfsconfig")
+		}
+		// 🚨 SECURITY: The .lfsconfig file is written by the users of the repository, so
+		// it cannot direct requests, and the credentials of the code host, to other hosts.
+		if u.Scheme != remoteURL.Scheme || u.Host != remoteURL.Host {
+			return nil, errors.Errorf("lfs.url of .lfsconfig must be on the host of the remote URL %s://%s", remoteURL.Scheme, remoteURL.Host)
+		}
+		if u.User == nil {
+			u.User = remoteURL.User
+		}
+		return u, nil
+	}
+
+	u := remoteURL.URL
+	u.RawQuery = ""
+	u.Fragment = ""
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	if !strings.HasSuffix(u.Path, ".git") {
+		u.Path += ".git"
+	}
+	u.Path += "/info/lfs"
+	return &u, nil
+}
+
+// lfsClient downloads Git LFS objects with the basic transfer of the batch API. See
+// https://github.com/git-lfs/git-lfs/blob/main/docs/api/batch.md.
+type lfsClient struct {
+	doer     httpcli.Doer
+	endpoint *url.URL
+	store    lfsStore
+}
+
+type lfsBatchRequest struct {
+	Operation string        `json:"operation"`
+	Transfers []string      `json:"transfers"`
+	Objects   []lfsBatchObj `json:"objects"`
+}
+
+type lfsBatchResponse struct {
+	Objects []lfsBatchObj `json:"objects"`
+}
+
+type lfsBatchObj struct {
+	OID     string `json:"oid"`
+	Size    int64  `json:"size"`
+	Actions *struct {
+		Download *struct {
+			Href   string            `json:"href"`
+			Header map[string]string `json:"header"`
+		} `json:"download"`
+	} `json:"actions,omitempty"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+const lfsMediaType = "application/vnd.git-lfs+json"
+
+// download downloads the objects of the pointers into the store, and returns the OIDs
+// of the objects whose content was downloaded and matches their OID. Objects the server
+// reports errors for are skipped.
+func (c *lfsClient) download(ctx context.Context, pointers []lfsPointer) (oids []string, _ error) {
+	batch := lfsBatchRequest{Operation: "download", Transfers: []string{"basic"}}
+	for _, p := range pointers {
+		batch.Objects = append(batch.Objects, lfsBatchObj{OID: p.OID, Size: p.Size})
+	}
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	batchURL := *c.endpoint
+	batchURL.Path = strings.TrimSuffix(batchURL.Path, "/") + "/objects/batch"
+	req, err := c.newRequest(ctx, http.MethodPost, &batchURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+
+	var resp lfsBatchResponse
+	if err := c.do(req, func(r io.Reader) error { return json.NewDecoder(r).Decode(&resp) }); err != nil {
+		return nil, errors.Wrap(err, "batch request")
+	}
+
+	for _, obj := range resp.Objects {
+		if obj.Error != nil || obj.Actions == nil || obj.Actions.Download == nil {
+			continue
+		}
+		// 🚨 SECURITY: Objects are downloaded and checked against their OID even if they
+		// are already stored, since the server of the repository may not have them.
+		href, err := url.Parse(obj.Actions.Download.Href)
+		if err != nil {
+			return oids, errors.Wrapf(err, "parse download URL of %s", obj.OID)
+		}
+		req, err := c.newRequest(ctx, http.MethodGet, href, nil)
+		if err != nil {
+			return oids, err
+		}
+		for k, v := range obj.Actions.Download.Header {
+			req.Header.Set(k, v)
+		}
+		if err := c.do(req, func(r io.Reader) error { return c.store.put(obj.OID, obj.Size, r) }); err != nil {
+			return oids, errors.Wrapf(err, "download %s", obj.OID)
+		}
+		oids = append(oids, obj.OID)
+	}
+	return oids, nil
+}
+
+// newRequest returns a request to u, authenticated with the credentials of u if it
+// is a URL of the Git LFS server.
+func (c *lfsClient) newRequest(ctx context.Context, method string, u *url.URL, body io.Reader) (*http.Request, error) {
+	withoutUser := *u
+	withoutUser.User = nil
+	req, err := http.NewRequestWithContext(ctx, method, withoutUser.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if user := c.endpoint.User; user != nil && u.Host == c.endpoint.Host {
+		password, _ := user.Password()
+		req.SetBasicAuth(user.Username(), password)
+	}
+	return req, nil
+}
+
+func (c *lfsClient) do(req *http.Request, read func(io.Reader) error) error {
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(b))
+	}
+	return read(resp.Body)
+}
+
+var lfsOIDPattern = lazyregexp.New(`^[0-9a-f]{64}$`)
+
+// lfsPointer is the content of a Git LFS pointer file. See
+// https://github.com/git-lfs/git-lfs/blob/main/docs/spec.md.
+type lfsPointer struct {
+	OID  string
+	Size int64
+}
+
+// parseLFSPointer parses the content of a Git LFS pointer file.
+func parseLFSPointer(b []byte) (p lfsPointer, ok bool) {
+	const version = "version https://git-lfs.github.com/spec/v1\n"
+	if len(b) > lfsPointerMaxSize || !bytes.HasPrefix(b, []byte(version)) || !bytes.HasSuffix(b, []byte("\n")) {
+		return p, false
+	}
+	p.Size = -1
+	for _, line := range strings.Split(string(b[len(version):len(b)-1]), "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "oid":
+			if oid := strings.TrimPrefix(value, "sha256:"); oid != value && lfsOIDPattern.MatchString(oid) {
+				p.OID = oid
+			}
+		case "size":
+			if size, err := strconv.ParseInt(value, 10, 64); err == nil && size >= 0 {
+				p.Size = size
+			}
+		}
+	}
+	return p, p.OID != "" && p.Size >= 0
+}
+
+// lfsStore is a directory storing Git LFS objects by OID. Only text objects are
+// stored, binary objects are recorded with an empty marker file.
+type lfsStore string
+
+func (s lfsStore) path(oid string) string {
+	return filepath.Join(string(s), oid[:2], oid[2:4], oid)
+}
+
+// has returns whether the object was downloaded.
+func (s lfsStore) has(oid string) bool {
+	for _, path := range []string{s.path(oid), s.path(oid) + lfsBinarySuffix} {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// open opens the object, which does not exist if it was not downloaded or is binary.
+func (s lfsStore) open(oid string) (*os.File, error) {
+	if !lfsOIDPattern.MatchString(oid) {
+		return nil, os.ErrNotExist
+	}
+	return os.Open(s.path(oid))
+}
+
+// put stores the object read from r after checking its size and OID.
+func (s lfsStore) put(oid string, size int64, r io.Reader) error {
+	if !lfsOIDPattern.MatchString(oid) {
+		return errors.Errorf("invalid OID %q", oid)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path(oid)), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(string(s), "tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(r, size+1))
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return errors.Errorf("expected %d bytes, got %d", size, n)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != oid {
+		return errors.Errorf("content does not match OID, got %s", got)
+	}
+
+	head := make([]byte, 8000)
+	n2, err := f.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if isBinary(head[:n2]) {
+		return os.WriteFile(s.path(oid)+lfsBinarySuffix, nil, 0600)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.path(oid))
+}
+
+// remove removes the object and returns the number of bytes freed.
+func (s lfsStore) remove(oid string) (freed int64) {
+	if !lfsOIDPattern.MatchString(oid) {
+		return 0
+	}
+	for _, path := range []string{s.path(oid), s.path(oid) + lfsBinarySuffix} {
+		if fi, err := os.Stat(path); err == nil && os.Remove(path) == nil {
+			freed += fi.Size()
+		}
+	}
+	return freed
+}
+
+// removeUnreferenced removes the objects and temporary files older than minAge that are
+// not referenced, and returns the number of bytes used by the remaining objects.
+func (s lfsStore) removeUnreferenced(referenced map[string]int, minAge time.Duration) (used int64) {
+	_ = bestEffortWalk(string(s), func(path string, fi fs.FileInfo) error {
+		if fi.IsDir() {
+			return nil
+		}
+		oid := strings.TrimSuffix(fi.Name(), lfsBinarySuffix)
+		if _, ok := referenced[oid]; !ok && time.Since(fi.ModTime()) > minAge {
+			if os.Remove(path) == nil {
+				return nil
+			}
+		}
+		used += fi.Size()
+		return nil
+	})
+	return used
+}
+
+// isBinary reports whether b, the beginning of a file, is binary data, using the
+// same heuristic as git.
+func isBinary(b []byte) bool {
+	return bytes.IndexByte(b, 0) >= 0
+}
+
+// readLFSObjects returns the OIDs of the Git LFS objects referenced by the
+// repository in dir.
+func readLFSObjects(dir GitDir) []string {
+	b, err := os.ReadFile(dir.Path(lfsObjectsFile))
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(b))
+}
+
+// lfsRepoObjects gives access to the stored Git LFS objects referenced by a
+// repository. Objects are stored once for all repositories, so a repository must
+// only be served the objects it references: a pointer file to the object of
+// another repository could otherwise be committed to read its content.
+type lfsRepoObjects struct {
+	store lfsStore
+	oids  map[string]struct{}
+}
+
+func newLFSRepoObjects(dir GitDir, store lfsStore) lfsRepoObjects {
+	oids := make(map[string]struct{})
+	for _, oid := range readLFSObjects(dir) {
+		oids[oid] = struct{}{}
+	}
+	return lfsRepoObjects{store: store, oids: oids}
+}
+
+// open opens the stored object with the given OID if the repository references it.
+func (o lfsRepoObjects) open(oid string) (*os.File, error) {
+	if _, ok := o.oids[oid]; !ok {
+		return nil, os.ErrNotExist
+	}
+	return o.store.open(oid)
+}
+
+// lfsReferences counts the repositories referencing each Git LFS object.
+func lfsReferences(dirs []GitDir) map[string]int {
+	refs := make(map[string]int)
+	for _, dir := range dirs {
+		for _, oid := range readLFSObjects(dir) {
+			refs[oid]++
+		}
+	}
+	return refs
+}
+
+// cleanupLFSObjects removes the Git LFS objects no repository references anymore.
+func (s *Server) cleanupLFSObjects() error {
+	gitDirs, err := s.findGitDirs()
+	if err != nil {
+		return errors.Wrap(err, "finding git dirs")
+	}
+	lfsObjectsBytes.Set(float64(s.lfsStore().removeUnreferenced(lfsReferences(gitDirs), lfsObjectMinAge)))
+	return nil
+}
+
+// lfsSmudge returns the arguments and the output to use for a git show or git archive
+// command run in dir, such that the pointer files of the stored Git LFS objects are
+// replaced by the content of the objects. The output must be closed once the command
+// completed. It returns false if the command and its output are left as is.
+func (s *Server) lfsSmudge(dir GitDir, args []string, w io.Writer) ([]string, io.WriteCloser, bool) {
+	if len(args) == 0 || (args[0] != "show" && args[0] != "archive") {
+		return args, nil, false
+	}
+	if _, err := os.Stat(dir.Path(lfsObjectsFile)); err != nil {
+		return args, nil, false
+	}
+
+	switch args[0] {
+	case "show":
+		// Only git show <rev>:<path>, which reads a blob.
+		if len(args) != 2 || !strings.Contains(args[1], ":") {
+			return args, nil, false
+		}
+		return args, &lfsBlobWriter{w: w, objects: newLFSRepoObjects(dir, s.lfsStore())}, true
+
+	case "archive":
+		// Zip archives are converted from a tar archive, which is simpler to rewrite.
+		var format string
+		smudged := make([]string, 0, len(args))
+		for i, arg := range args {
+			if arg == "--" {
+				smudged = append(smudged, args[i:]...)
+				break
+			}
+			switch arg {
+			case "--format=tar":
+				format = "tar"
+			case "--format=zip":
+				format = "zip"
+				arg = "--format=tar"
+			case "-0":
+				// Compression levels are not supported for tar archives, zip
+				// archives are written without compression.
+				continue
+			}
+			smudged = append(smudged, arg)
+		}
+		if format == "" {
+			return args, nil, false
+		}
+		return smudged, newLFSArchiveWriter(w, newLFSRepoObjects(dir, s.lfsStore()), format), true
+	}
+	return args, nil, false
+}
+
+// lfsBlobWriter writes the content of a blob, or the content of the Git LFS object it
+// points to if it is the pointer file of a stored object of the repository.
+type lfsBlobWriter struct {
+	w       io.Writer
+	objects lfsRepoObjects
+
+	buf  []byte
+	blob bool // true once the content is known not to be a pointer file
+}
+
+func (w *lfsBlobWriter) Write(p []byte) (int, error) {
+	if w.blob {
+		return w.w.Write(p)
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) > lfsPointerMaxSize {
+		w.blob = true
+		if _, err := w.w.Write(w.buf); err != nil {
+			return 0, err
+		}
+		w.buf = nil
+	}
+	return len(p), nil
+}
+
+func (w *lfsBlobWriter) Close() error {
+	if w.blob {
+		return nil
+	}
+	if p, ok := parseLFSPointer(w.buf); ok {
+		if f, err := w.objects.open(p.OID); err == nil {
+			defer f.Close()
+			_, err = io.Copy(w.w, f)
+			return err
+		}
+	}
+	_, err := w.w.Write(w.buf)
+	return err
+}
+
+// lfsArchiveWriter rewrites the tar archive written to it into w, in the given
+// format, replacing the pointer files of the stored Git LFS objects of the repository
+// by the content of the objects.
+type lfsArchiveWriter struct {
+	pw   *io.PipeWriter
+	done chan error
+}
+
+func newLFSArchiveWriter(w io.Writer, objects lfsRepoObjects, format string) *lfsArchiveWriter {
+	pr, pw := io.Pipe()
+	aw := &lfsArchiveWriter{pw: pw, done: make(chan error, 1)}
+	go func() {
+		err := rewriteLFSArchive(w, pr, objects, format)
+		if err == nil {
+			// Discard the padding following the end of the archive.
+			_, err = io.Copy(io.Discard, pr)
+		}
+		// Fail the writes of the command if the archive could not be rewritten.
+		pr.CloseWithError(err)
+		aw.done <- err
+	}()
+	return aw
+}
+
+func (w *lfsArchiveWriter) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+func (w *lfsArchiveWriter) Close() error {
+	w.pw.Close()
+	return <-w.done
+}
+
+func rewriteLFSArchive(w io.Writer, r io.Reader, objects lfsRepoObjects, format string) error {
+	var add func(hdr *tar.Header, content io.Reader) error
+	var finish func() error
+	switch format {
+	case "tar":
+		tw := tar.NewWriter(w)
+		add = func(hdr *tar.Header, content io.Reader) error {
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			_, err := io.Copy(tw, content)
+			return err
+		}
+		finish = tw.Close
+	case "zip":
+		zw := zip.NewWriter(w)
+		add = func(hdr *tar.Header, content io.Reader) error {
+			if hdr.Typeflag == tar.TypeXGlobalHeader {
+				// git archive stores the commit ID in the comment of zip archives.
+				if comment, ok := hdr.PAXRecords["comment"]; ok {
+					return zw.SetComment(comment)
+				}
+				return nil
+			}
+			fh, err := zip.FileInfoHeader(hdr.FileInfo())
+			if err != nil {
+				return err
+			}
+			fh.Name = hdr.Name
+			fh.Method = zip.Store
+			if hdr.Typeflag == tar.TypeDir && !strings.HasSuffix(fh.Name, "/") {
+				fh.Name += "/"
+			}
+			fw, err := zw.CreateHeader(fh)
+			if err != nil {
+				return err
+			}
+			if hdr.Typeflag == tar.TypeSymlink {
+				content = strings.NewReader(hdr.Linkname)
+			}
+			_, err = io.Copy(fw, content)
+			return err
+		}
+		finish = zw.Close
+	default:
+		return errors.Errorf("unsupported archive format %q", format)
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return finish()
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg || hdr.Size > lfsPointerMaxSize {
+			if err := add(hdr, tr); err != nil {
+				return err
+			}
+			continue
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		if err := addLFSObject(hdr, content, objects, add); err != nil {
+			return err
+		}
+	}
+}
+
+// addLFSObject adds the file of hdr to the archive, with the content of the Git LFS
+// object it points to if it is the pointer file of a stored object of the repository.
+func addLFSObject(hdr *tar.Header, content []byte, objects lfsRepoObjects, add func(*tar.Header, io.Reader) error) error {
+	if p, ok := parseLFSPointer(content); ok {
+		if f, err := objects.open(p.OID); err == nil {
+			defer f.Close()
+			fi, err := f.Stat()
+			if err != nil {
+				return err
+			}
+			hdr.Size = fi.Size()
+			return add(hdr, f)
+		}
+	}
+	return add(hdr, bytes.NewReader(content))
+}
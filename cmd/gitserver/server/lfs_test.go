@@ -0,0 +1,529 @@
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/log/logtest"
+
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+)
+
+func TestParseLFSPointer(t *testing.T) {
+	const oid = "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393"
+	tests := []struct {
+		name    string
+		content string
+		want    lfsPointer
+		wantOK  bool
+	}{
+		{
+			name:    "pointer",
+			content: "version https://git-lfs.github.com/spec/v1\noid sha256:" + oid + "\nsize 12345\n",
+			want:    lfsPointer{OID: oid, Size: 12345},
+			wantOK:  true,
+		},
+		{
+			name:    "pointer with extensions",
+			content: "version https://git-lfs.github.com/spec/v1\next-0-foo sha256:" + oid + "\noid sha256:" + oid + "\nsize 0\n",
+			want:    lfsPointer{OID: oid, Size: 0},
+			wantOK:  true,
+		},
+		{
+			name:    "missing size",
+			content: "version https://git-lfs.github.com/spec/v1\noid sha256:" + oid + "\n",
+		},
+		{
+			name:    "invalid oid",
+			content: "version https://git-lfs.github.com/spec/v1\noid sha256:../../etc/passwd\nsize 1\n",
+		},
+		{
+			name:    "text file",
+			content: "hello world\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := parseLFSPointer([]byte(test.content))
+			if ok != test.wantOK {
+				t.Fatalf("got ok %v, want %v", ok, test.wantOK)
+			}
+			if ok && got != test.want {
+				t.Fatalf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+type lfsTestObject struct {
+	content string
+	oid     string
+}
+
+func newLFSTestObject(content string) lfsTestObject {
+	h := sha256.Sum256([]byte(content))
+	return lfsTestObject{content: content, oid: hex.EncodeToString(h[:])}
+}
+
+func (o lfsTestObject) pointer() string {
+	return fmt.Sprintf("version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize %d\n", o.oid, len(o.content))
+}
+
+// newLFSTestServer returns a Git LFS server serving objects for the repository at
+// /repo.git to the user "user" with the password "pass".
+func newLFSTestServer(t *testing.T, objects ...lfsTestObject) *httptest.Server {
+	byOID := make(map[string]lfsTestObject)
+	for _, o := range objects {
+		byOID[o.oid] = o
+	}
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/repo.git/info/lfs/objects/batch":
+			var req lfsBatchRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Operation != "download" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			var resp struct {
+				Objects []map[string]any `json:"objects"`
+			}
+			for _, obj := range req.Objects {
+				if _, ok := byOID[obj.OID]; !ok {
+					resp.Objects = append(resp.Objects, map[string]any{
+						"oid": obj.OID, "size": obj.Size,
+						"error": map[string]any{"code": 404, "message": "Object does not exist"},
+					})
+					continue
+				}
+				resp.Objects = append(resp.Objects, map[string]any{
+					"oid": obj.OID, "size": obj.Size,
+					"actions": map[string]any{
+						"download": map[string]any{"href": srv.URL + "/objects/" + obj.OID},
+					},
+				})
+			}
+			w.Header().Set("Content-Type", lfsMediaType)
+			_ = json.NewEncoder(w).Encode(resp)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/objects/"):
+			o, ok := byOID[strings.TrimPrefix(r.URL.Path, "/objects/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = io.WriteString(w, o.content)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestServer_fetchLFSObjects(t *testing.T) {
+	var (
+		text    = newLFSTestObject("{\"hello\": \"world\"}\n")
+		binary  = newLFSTestObject("\x89PNG\x00\x01\x02")
+		large   = newLFSTestObject(strings.Repeat("large\n", 100))
+		missing = newLFSTestObject("missing\n")
+	)
+	srv := newLFSTestServer(t, text, binary, large)
+
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	if err := os.MkdirAll(src, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		".gitattributes": "*.json filter=lfs diff=lfs merge=lfs -text\n*.png filter=lfs diff=lfs merge=lfs -text\n",
+		"README.md":      "hello\n",
+		"data.json":      text.pointer(),
+		"copy.json":      text.pointer(),
+		"image.png":      binary.pointer(),
+		"large.json":     large.pointer(),
+		"missing.json":   missing.pointer(),
+	} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := func(name string, arg ...string) string {
+		t.Helper()
+		return runCmd(t, src, name, arg...)
+	}
+	cmd("git", "init", ".")
+	cmd("git", "add", "-A")
+	cmd("git", "commit", "-m", "lfs")
+
+	reposDir := filepath.Join(root, "repos")
+	dir := GitDir(filepath.Join(reposDir, "repo", ".git"))
+	runCmd(t, root, "git", "clone", "--bare", src, string(dir))
+
+	s := &Server{Logger: logtest.Scoped(t), ReposDir: reposDir}
+	remoteURL, err := vcs.ParseURL(strings.Replace(srv.URL, "http://", "http://user:pass@", 1) + "/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := s.fetchLFSObjects(ctx, dir, remoteURL, &GitLFSOptions{MaxFileSize: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Objects over the size limit and the objects missing on the server are neither
+	// referenced nor downloaded.
+	wantObjects := []string{binary.oid, text.oid}
+	sort.Strings(wantObjects)
+	if diff := cmp.Diff(wantObjects, readLFSObjects(dir)); diff != "" {
+		t.Fatalf("unexpected referenced objects (-want +got):\n%s", diff)
+	}
+	store := s.lfsStore()
+	if !store.has(text.oid) || !store.has(binary.oid) || store.has(large.oid) || store.has(missing.oid) {
+		t.Fatal("unexpected objects in store")
+	}
+	if _, err := store.open(binary.oid); !os.IsNotExist(err) {
+		t.Fatalf("binary objects should not be stored, got err %v", err)
+	}
+
+	git := func(args ...string) []byte {
+		t.Helper()
+		var out bytes.Buffer
+		args, lfsOut, ok := s.lfsSmudge(dir, args, &out)
+		if !ok {
+			t.Fatalf("expected %v to be smudged", args)
+		}
+		c := exec.Command("git", args...)
+		dir.Set(c)
+		c.Stdout = lfsOut
+		if err := c.Run(); err != nil {
+			t.Fatal(err)
+		}
+		if err := lfsOut.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return out.Bytes()
+	}
+
+	t.Run("show", func(t *testing.T) {
+		for name, want := range map[string]string{
+			"data.json":    text.content,
+			"image.png":    binary.pointer(),
+			"large.json":   large.pointer(),
+			"missing.json": missing.pointer(),
+			"README.md":    "hello\n",
+		} {
+			if diff := cmp.Diff(want, string(git("show", "HEAD:"+name))); diff != "" {
+				t.Errorf("unexpected content of %s (-want +got):\n%s", name, diff)
+			}
+		}
+	})
+
+	wantFiles := map[string]string{
+		".gitattributes": "*.json filter=lfs diff=lfs merge=lfs -text\n*.png filter=lfs diff=lfs merge=lfs -text\n",
+		"README.md":      "hello\n",
+		"copy.json":      text.content,
+		"data.json":      text.content,
+		"image.png":      binary.pointer(),
+		"large.json":     large.pointer(),
+		"missing.json":   missing.pointer(),
+	}
+
+	t.Run("tar archive", func(t *testing.T) {
+		files := make(map[string]string)
+		tr := tar.NewReader(bytes.NewReader(git("archive", "--worktree-attributes", "--format=tar", "HEAD", "--")))
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hdr.Typeflag != tar.TypeReg {
+				continue
+			}
+			b, err := io.ReadAll(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			files[hdr.Name] = string(b)
+		}
+		if diff := cmp.Diff(wantFiles, files); diff != "" {
+			t.Fatalf("unexpected archive files (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("zip archive", func(t *testing.T) {
+		b := git("archive", "--worktree-attributes", "--format=zip", "-0", "HEAD", "--")
+		zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if zr.Comment != strings.TrimSpace(runCmd(t, src, "git", "rev-parse", "HEAD")) {
+			t.Errorf("expected the commit ID as comment, got %q", zr.Comment)
+		}
+		files := make(map[string]string)
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			files[f.Name] = string(b)
+		}
+		if diff := cmp.Diff(wantFiles, files); diff != "" {
+			t.Fatalf("unexpected archive files (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("other commands", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"show", "HEAD"},
+			{"log", "-p"},
+			{"archive", "--format=tar.gz", "HEAD"},
+		} {
+			if _, _, ok := s.lfsSmudge(dir, args, io.Discard); ok {
+				t.Errorf("expected %v not to be smudged", args)
+			}
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		if err := s.fetchLFSObjects(ctx, dir, remoteURL, nil); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, ok := s.lfsSmudge(dir, []string{"show", "HEAD:data.json"}, io.Discard); ok {
+			t.Fatal("expected pointer files not to be replaced once Git LFS is disabled")
+		}
+	})
+}
+
+func TestServer_lfsSharedObjects(t *testing.T) {
+	secret := newLFSTestObject("secret\n")
+
+	root := t.TempDir()
+	reposDir := filepath.Join(root, "repos")
+	s := &Server{Logger: logtest.Scoped(t), ReposDir: reposDir}
+	ctx := context.Background()
+
+	// newRepo clones a repository with a pointer file to the secret object, and
+	// fetches its Git LFS objects from srv.
+	newRepo := func(name string, srv *httptest.Server, wantErr bool) GitDir {
+		t.Helper()
+		src := filepath.Join(root, name+"-src")
+		if err := os.MkdirAll(src, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(src, "data.json"), []byte(secret.pointer()), 0600); err != nil {
+			t.Fatal(err)
+		}
+		runCmd(t, src, "git", "init", ".")
+		runCmd(t, src, "git", "add", "-A")
+		runCmd(t, src, "git", "commit", "-m", "lfs")
+
+		dir := GitDir(filepath.Join(reposDir, name, ".git"))
+		runCmd(t, root, "git", "clone", "--bare", src, string(dir))
+
+		remoteURL, err := vcs.ParseURL(strings.Replace(srv.URL, "http://", "http://user:pass@", 1) + "/repo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.fetchLFSObjects(ctx, dir, remoteURL, &GitLFSOptions{MaxFileSize: 100}); (err != nil) != wantErr {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return dir
+	}
+
+	show := func(dir GitDir) string {
+		t.Helper()
+		var out bytes.Buffer
+		args, lfsOut, ok := s.lfsSmudge(dir, []string{"show", "HEAD:data.json"}, &out)
+		if !ok {
+			t.Fatal("expected git show to be smudged")
+		}
+		c := exec.Command("git", args...)
+		dir.Set(c)
+		c.Stdout = lfsOut
+		if err := c.Run(); err != nil {
+			t.Fatal(err)
+		}
+		if err := lfsOut.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return out.String()
+	}
+
+	private := newRepo("private", newLFSTestServer(t, secret), false)
+	if diff := cmp.Diff(secret.content, show(private)); diff != "" {
+		t.Fatalf("unexpected content of the private repository (-want +got):\n%s", diff)
+	}
+
+	// The Git LFS server of the other repository does not provide the object, so
+	// the object stored for the private repository must not be served.
+	other := newRepo("other", newLFSTestServer(t), false)
+	if oids := readLFSObjects(other); len(oids) != 0 {
+		t.Fatalf("expected no referenced objects, got %v", oids)
+	}
+	if diff := cmp.Diff(secret.pointer(), show(other)); diff != "" {
+		t.Fatalf("unexpected content of the other repository (-want +got):\n%s", diff)
+	}
+
+	// Neither must it if the Git LFS server of the other repository claims to provide
+	// the object, since the content it serves does not match the OID.
+	forged := newRepo("forged", newLFSTestServer(t, lfsTestObject{content: "forged\n", oid: secret.oid}), true)
+	if oids := readLFSObjects(forged); len(oids) != 0 {
+		t.Fatalf("expected no referenced objects, got %v", oids)
+	}
+	if diff := cmp.Diff(secret.pointer(), show(forged)); diff != "" {
+		t.Fatalf("unexpected content of the forged repository (-want +got):\n%s", diff)
+	}
+
+	// Objects are only served to the repositories referencing them, even if the
+	// list was written before.
+	if err := os.WriteFile(other.Path(lfsObjectsFile), []byte(newLFSTestObject("other\n").oid+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(secret.pointer(), show(other)); diff != "" {
+		t.Fatalf("unexpected content of the other repository (-want +got):\n%s", diff)
+	}
+}
+
+func TestLFSEndpoint(t *testing.T) {
+	root := t.TempDir()
+	cmd := func(name string, arg ...string) string {
+		t.Helper()
+		return runCmd(t, root, name, arg...)
+	}
+	cmd("git", "init", ".")
+	cmd("sh", "-c", "echo hello > README")
+	cmd("git", "add", "README")
+	cmd("git", "commit", "-m", "init")
+	dir := GitDir(filepath.Join(root, ".git"))
+
+	endpoint := func(remote string) string {
+		t.Helper()
+		remoteURL, err := vcs.ParseURL(remote)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := lfsEndpoint(context.Background(), dir, remoteURL)
+		if err != nil {
+			return "error"
+		}
+		return u.String()
+	}
+
+	if got, want := endpoint("https://token@github.com/foo/bar"), "https://token@github.com/foo/bar.git/info/lfs"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := endpoint("https://gitlab.example.com/foo/bar.git/"), "https://gitlab.example.com/foo/bar.git/info/lfs"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := endpoint("ssh://git@github.com/foo/bar"), "error"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// The lfs.url setting can only point to the host of the remote.
+	cmd("git", "config", "-f", ".lfsconfig", "lfs.url", "https://github.com/foo/lfs")
+	cmd("git", "add", ".lfsconfig")
+	cmd("git", "commit", "-m", "lfsconfig")
+	if got, want := endpoint("https://token@github.com/foo/bar"), "https://token@github.com/foo/lfs"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := endpoint("http://github.com/foo/bar"), "error"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	cmd("git", "config", "-f", ".lfsconfig", "lfs.url", "https://lfs.example.com/foo/bar")
+	cmd("git", "commit", "-am", "lfsconfig")
+	if got, want := endpoint("https://token@github.com/foo/bar"), "error"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCleanupLFSObjects(t *testing.T) {
+	reposDir := t.TempDir()
+	s := &Server{Logger: logtest.Scoped(t), ReposDir: reposDir, DiskSizer: &fakeDiskSizer{}}
+	store := s.lfsStore()
+
+	shared, own, unreferenced := newLFSTestObject("shared\n"), newLFSTestObject("own\n"), newLFSTestObject("unreferenced\n")
+	for _, o := range []lfsTestObject{shared, own, unreferenced} {
+		if err := store.put(o.oid, int64(len(o.content)), strings.NewReader(o.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, objects := range map[string][]lfsTestObject{
+		"repo1": {shared, own},
+		"repo2": {shared},
+	} {
+		if err := makeFakeRepo(filepath.Join(reposDir, name), 10); err != nil {
+			t.Fatal(err)
+		}
+		var list string
+		for _, o := range objects {
+			list += o.oid + "\n"
+		}
+		writeFile(t, filepath.Join(reposDir, name, ".git", lfsObjectsFile), []byte(list))
+	}
+	// repo1 is the least recently used repo.
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(reposDir, "repo1", ".git", "HEAD"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// Recently downloaded objects are kept, in case they belong to a clone in progress.
+	if err := s.cleanupLFSObjects(); err != nil {
+		t.Fatal(err)
+	}
+	if !store.has(unreferenced.oid) {
+		t.Fatal("expected recent unreferenced object to be kept")
+	}
+
+	old = time.Now().Add(-2 * lfsObjectMinAge)
+	if err := os.Chtimes(store.path(unreferenced.oid), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.cleanupLFSObjects(); err != nil {
+		t.Fatal(err)
+	}
+	if store.has(unreferenced.oid) || !store.has(shared.oid) || !store.has(own.oid) {
+		t.Fatal("expected only the old unreferenced object to be removed")
+	}
+
+	// Removing repo1 frees its own objects, but not the ones shared with repo2.
+	if err := s.freeUpSpace(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(reposDir, "repo1")); !os.IsNotExist(err) {
+		t.Fatal("expected repo1 to be removed")
+	}
+	if store.has(own.oid) || !store.has(shared.oid) {
+		t.Fatal("expected only the objects of repo1 to be removed")
+	}
+}
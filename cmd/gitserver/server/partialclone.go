@@ -0,0 +1,326 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sourcegraph/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const (
+	// promisorRemote is the name of the remote partial clones fetch their missing
+	// objects from. Its URL is only set in the environment of the git commands, see
+	// promisorRemoteEnv.
+	promisorRemote = "origin"
+
+	// partialCloneFetchBatchSize is the number of objects fetched per git fetch
+	// command when fetching missing objects up front.
+	partialCloneFetchBatchSize = 10000
+)
+
+var (
+	lazyFetchedObjects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "src_gitserver_lazy_fetched_objects_total",
+		Help: "Number of objects of partial clones fetched from the code host on demand.",
+	}, []string{"endpoint"})
+	lazyFetches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "src_gitserver_lazy_fetches_total",
+		Help: "Number of fetches of missing objects of partial clones from the code host.",
+	}, []string{"endpoint"})
+)
+
+var gitPartialClone = conf.Cached(func() any {
+	exp := conf.ExperimentalFeatures()
+	return buildPartialCloneMappings(exp.GitPartialClone)
+})
+
+// partialCloneOptions configures a partial clone, which only fetches some of the
+// blobs of a repository up front. Its other blobs are fetched on demand.
+type partialCloneOptions struct {
+	// BlobSizeLimit is the size in bytes up to which blobs are fetched up front. No
+	// blob is fetched up front if it is zero.
+	BlobSizeLimit int64
+	// SparsePaths are the paths whose blobs at HEAD are fetched up front, whatever
+	// their size.
+	SparsePaths []string
+}
+
+// filter returns the object filter of the git fetch commands of the partial clone.
+func (o *partialCloneOptions) filter() string {
+	if o.BlobSizeLimit > 0 {
+		return "blob:limit=" + strconv.FormatInt(o.BlobSizeLimit, 10)
+	}
+	return "blob:none"
+}
+
+func buildPartialCloneMappings(c []*schema.GitPartialCloneMapping) map[string]*partialCloneOptions {
+	pcm := map[string]*partialCloneOptions{}
+	for _, mapping := range c {
+		pcm[mapping.DomainPath] = &partialCloneOptions{
+			BlobSizeLimit: int64(mapping.BlobSizeLimit),
+			SparsePaths:   mapping.SparsePaths,
+		}
+	}
+	return pcm
+}
+
+// partialCloneOpts returns the partial clone options of the repository with
+// remoteURL, or nil if it is cloned in full.
+func partialCloneOpts(remoteURL *vcs.URL) *partialCloneOptions {
+	pcm := gitPartialClone().(map[string]*partialCloneOptions)
+	if len(pcm) == 0 {
+		return nil
+	}
+	return pcm[path.Join(remoteURL.Host, remoteURL.Path)]
+}
+
+// configurePartialClone configures the new repository in dir to be cloned
+// partially.
+func configurePartialClone(dir GitDir, opts *partialCloneOptions) error {
+	for _, kv := range [][2]string{
+		// Extensions require version 1 of the repository format.
+		{"core.repositoryformatversion", "1"},
+		{"extensions.partialClone", promisorRemote},
+		{"remote." + promisorRemote + ".promisor", "true"},
+		{"remote." + promisorRemote + ".partialclonefilter", opts.filter()},
+	} {
+		if err := gitConfigSet(dir, kv[0], kv[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isPartialClone returns whether the repository in dir is a partial clone, that is
+// whether it has objects fetched from a promisor remote.
+func isPartialClone(dir GitDir) bool {
+	return len(promisorPacks(dir)) > 0
+}
+
+// promisorPacks returns the paths of the promisor packs of the repository in dir,
+// which store the objects fetched from the promisor remote. Each fetch stores its
+// objects in a new pack.
+func promisorPacks(dir GitDir) map[string]struct{} {
+	matches, _ := filepath.Glob(dir.Path("objects", "pack", "*.promisor"))
+	packs := make(map[string]struct{}, len(matches))
+	for _, m := range matches {
+		packs[strings.TrimSuffix(m, ".promisor")] = struct{}{}
+	}
+	return packs
+}
+
+// packObjectCount returns the number of objects of the pack with the given path,
+// read from the fan-out table of its index.
+func packObjectCount(pack string) (int, error) {
+	f, err := os.Open(pack + ".idx")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	// Version 2 indexes start with a magic number and their version, followed by
+	// the fan-out table. Version 1 indexes start with the fan-out table. Its last
+	// entry is the number of objects. See
+	// https://git-scm.com/docs/pack-format#_pack_idx_files_have_the_following_format.
+	header := make([]byte, 8+256*4)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return 0, err
+	}
+	fanout := header
+	if bytes.Equal(header[:4], []byte{0xff, 't', 'O', 'c'}) {
+		fanout = header[8:]
+	}
+	return int(binary.BigEndian.Uint32(fanout[255*4:])), nil
+}
+
+// promisorRemoteEnv returns the environment variables to run git commands that
+// fetch objects from the promisor remote of a partial clone of remoteURL. The URL is
+// set in the environment rather than in the Git config of the repository since it
+// can contain credentials. Like runWithRemoteOpts, it disables prompts and applies
+// the TLS configuration, so that the objects can also be fetched by git commands
+// that are not run with remote options, like the git commands reading missing
+// blobs.
+func promisorRemoteEnv(remoteURL *vcs.URL) []string {
+	cmd := exec.Command("git")
+	configureRemoteGitCommand(cmd, tlsExternal().(*tlsConfig))
+
+	config := [][2]string{
+		{"remote." + promisorRemote + ".url", remoteURL.String()},
+		// Same as the flags of configureRemoteGitCommand, which are only set for the
+		// commands run with remote options.
+		{"credential.helper", ""},
+		{"protocol.version", "2"},
+	}
+	env := append(cmd.Env, "GIT_CONFIG_COUNT="+strconv.Itoa(len(config)))
+	for i, kv := range config {
+		env = append(env,
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", i, kv[0]),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", i, kv[1]),
+		)
+	}
+	return env
+}
+
+// missingObjects returns the objects of revs which are missing from the partial
+// clone in dir, restricted to the given paths if any. Unlike the objects read by
+// most git commands, they are not fetched on demand.
+func missingObjects(ctx context.Context, dir GitDir, revs, paths []string) ([]string, error) {
+	args := append([]string{"rev-list", "--objects", "--missing=print", "--no-walk"}, revs...)
+	args = append(args, "--")
+	args = append(args, paths...)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	dir.Set(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, wrapCmdError(cmd, err)
+	}
+
+	// Example: ?3b18e512dba79e4c8300dd08aeb37f8e728b8dad
+	var oids []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "?") {
+			oids = append(oids, line[1:])
+		}
+	}
+	return oids, scanner.Err()
+}
+
+// fetchObjects fetches the given objects of the partial clone in dir from remoteURL.
+// Git fetches missing objects on demand one at a time, so fetching the objects a
+// command reads beforehand saves as many requests to the code host.
+func fetchObjects(ctx context.Context, dir GitDir, remoteURL *vcs.URL, oids []string) error {
+	for len(oids) > 0 {
+		batch := oids
+		if len(batch) > partialCloneFetchBatchSize {
+			batch = batch[:partialCloneFetchBatchSize]
+		}
+		oids = oids[len(batch):]
+
+		// The same command as the one git runs to fetch missing objects on demand.
+		cmd := exec.CommandContext(ctx, "git", "-c", "fetch.negotiationAlgorithm=noop",
+			"fetch", "--no-tags", "--no-write-fetch-head", "--recurse-submodules=no",
+			"--filter=blob:none", "--stdin", promisorRemote)
+		cmd.Env = append(os.Environ(), promisorRemoteEnv(remoteURL)...)
+		dir.Set(cmd)
+		cmd.Stdin = strings.NewReader(strings.Join(batch, "\n") + "\n")
+		if output, err := runWithRemoteOpts(ctx, cmd, nil); err != nil {
+			return errors.Wrapf(err, "failed to fetch missing objects with output %q", newURLRedactor(remoteURL).redact(string(output)))
+		}
+	}
+	return nil
+}
+
+// fetchSparsePaths fetches the blobs of the sparse paths at HEAD of the partial clone
+// of remoteURL in dir which are missing. It does nothing if the repository is not a
+// partial clone or has no sparse paths.
+func fetchSparsePaths(ctx context.Context, dir GitDir, remoteURL *vcs.URL) error {
+	opts := partialCloneOpts(remoteURL)
+	if opts == nil || len(opts.SparsePaths) == 0 || !isPartialClone(dir) {
+		return nil
+	}
+
+	oids, err := missingObjects(ctx, dir, []string{"HEAD"}, opts.SparsePaths)
+	if err != nil {
+		return errors.Wrap(err, "list missing blobs")
+	}
+	return fetchObjects(ctx, dir, remoteURL, oids)
+}
+
+// lazyFetcher fetches the missing objects of a partial clone while serving a
+// request, and records how many were fetched.
+type lazyFetcher struct {
+	dir       GitDir
+	remoteURL *vcs.URL
+	endpoint  string
+	packs     map[string]struct{}
+}
+
+// newLazyFetcher returns a lazyFetcher for the repository in dir, or nil if it is not
+// a partial clone. A nil lazyFetcher does not fetch anything.
+func (s *Server) newLazyFetcher(ctx context.Context, repo api.RepoName, dir GitDir, endpoint string) *lazyFetcher {
+	packs := promisorPacks(dir)
+	if len(packs) == 0 {
+		return nil
+	}
+	remoteURL, err := s.getRemoteURL(ctx, repo)
+	if err != nil {
+		// The git commands fail if they read missing objects.
+		s.Logger.Warn("failed to determine Git remote URL to fetch missing objects", log.String("repo", string(repo)), log.Error(err))
+		return nil
+	}
+	return &lazyFetcher{dir: dir, remoteURL: remoteURL, endpoint: endpoint, packs: packs}
+}
+
+// env returns the environment variables for git commands to fetch the objects they
+// read on demand.
+func (f *lazyFetcher) env() []string {
+	if f == nil {
+		return nil
+	}
+	return promisorRemoteEnv(f.remoteURL)
+}
+
+// prefetch fetches the missing objects of revs, restricted to the given paths if
+// any.
+func (f *lazyFetcher) prefetch(ctx context.Context, revs, paths []string) error {
+	if f == nil {
+		return nil
+	}
+	oids, err := missingObjects(ctx, f.dir, revs, paths)
+	if err != nil {
+		return errors.Wrap(err, "list missing objects")
+	}
+	return fetchObjects(ctx, f.dir, f.remoteURL, oids)
+}
+
+// done records the objects fetched since the lazyFetcher was created. Objects fetched
+// for concurrent requests to the same repository are recorded for each of them.
+func (f *lazyFetcher) done() {
+	if f == nil {
+		return
+	}
+	for pack := range promisorPacks(f.dir) {
+		if _, ok := f.packs[pack]; ok {
+			continue
+		}
+		n, err := packObjectCount(pack)
+		if err != nil {
+			// The pack was removed by a repack since.
+			continue
+		}
+		lazyFetches.WithLabelValues(f.endpoint).Inc()
+		lazyFetchedObjects.WithLabelValues(f.endpoint).Add(float64(n))
+	}
+}
+
+// archivePrefetchArgs returns the tree-ish and the paths of git archive args.
+func archivePrefetchArgs(args []string) (treeish string, paths []string, ok bool) {
+	if len(args) == 0 || args[0] != "archive" {
+		return "", nil, false
+	}
+	for i, arg := range args {
+		if arg == "--" && i > 1 {
+			return args[i-1], args[i+1:], true
+		}
+	}
+	return "", nil, false
+}
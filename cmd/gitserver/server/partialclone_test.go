@@ -0,0 +1,234 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sourcegraph/log/logtest"
+
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestPartialCloneOptions_filter(t *testing.T) {
+	for _, tc := range []struct {
+		opts partialCloneOptions
+		want string
+	}{
+		{opts: partialCloneOptions{}, want: "blob:none"},
+		{opts: partialCloneOptions{SparsePaths: []string{"docs"}}, want: "blob:none"},
+		{opts: partialCloneOptions{BlobSizeLimit: 1024}, want: "blob:limit=1024"},
+	} {
+		if got := tc.opts.filter(); got != tc.want {
+			t.Errorf("got filter %q for %+v, want %q", got, tc.opts, tc.want)
+		}
+	}
+}
+
+func TestArchivePrefetchArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args        []string
+		wantTreeish string
+		wantPaths   []string
+		wantOK      bool
+	}{
+		{
+			args:        []string{"archive", "--worktree-attributes", "--format=tar", "HEAD", "--", "a", "b"},
+			wantTreeish: "HEAD",
+			wantPaths:   []string{"a", "b"},
+			wantOK:      true,
+		},
+		{
+			args:        []string{"archive", "--format=zip", "-0", "abc123", "--"},
+			wantTreeish: "abc123",
+			wantPaths:   []string{},
+			wantOK:      true,
+		},
+		{args: []string{"archive", "--"}},
+		{args: []string{"show", "HEAD:a", "--"}},
+		{args: nil},
+	} {
+		treeish, paths, ok := archivePrefetchArgs(tc.args)
+		if ok != tc.wantOK || treeish != tc.wantTreeish || !cmp.Equal(paths, tc.wantPaths) {
+			t.Errorf("archivePrefetchArgs(%q) = %q, %q, %v, want %q, %q, %v", tc.args, treeish, paths, ok, tc.wantTreeish, tc.wantPaths, tc.wantOK)
+		}
+	}
+}
+
+func TestPartialClone(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	if err := os.MkdirAll(filepath.Join(src, "sparse"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	large := strings.Repeat("large\n", 100)
+	for name, content := range map[string]string{
+		"small.txt":        "small\n",
+		"large.txt":        large,
+		"sparse/large.txt": large + "sparse\n",
+	} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := func(name string, arg ...string) string {
+		t.Helper()
+		return runCmd(t, src, name, arg...)
+	}
+	cmd("git", "init", ".")
+	cmd("git", "config", "uploadpack.allowFilter", "true")
+	cmd("git", "config", "uploadpack.allowAnySHA1InWant", "true")
+	cmd("git", "add", "-A")
+	cmd("git", "commit", "-m", "partial")
+
+	remoteURL, err := vcs.ParseURL("file://" + filepath.ToSlash(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gitPartialClone = func() any {
+		return buildPartialCloneMappings([]*schema.GitPartialCloneMapping{{
+			DomainPath:    filepath.ToSlash(src),
+			BlobSizeLimit: 100,
+			SparsePaths:   []string{"sparse"},
+		}})
+	}
+	t.Cleanup(func() {
+		gitPartialClone = func() any { return buildPartialCloneMappings(nil) }
+	})
+
+	ctx := context.Background()
+	syncer := &GitRepoSyncer{}
+	dir := GitDir(filepath.Join(root, "repos", "repo", ".git"))
+	cloneCmd, err := syncer.CloneCommand(ctx, remoteURL, string(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, err := runWithRemoteOpts(ctx, cloneCmd, nil); err != nil {
+		t.Fatalf("clone failed: %s\nOutput: %s", err, out)
+	}
+	runCmd(t, string(dir), "git", "symbolic-ref", "HEAD", "refs/heads/master")
+
+	if !isPartialClone(dir) {
+		t.Fatal("expected a partial clone")
+	}
+	// The URL can contain credentials, so it must not be stored.
+	if url, err := gitConfigGet(dir, "remote."+promisorRemote+".url"); err != nil || url != "" {
+		t.Fatalf("expected the remote URL not to be stored, got %q (err %v)", url, err)
+	}
+
+	missingBlobs := func() []string {
+		t.Helper()
+		oids, err := missingObjects(ctx, dir, []string{"HEAD"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var paths []string
+		for _, oid := range oids {
+			paths = append(paths, blobPath(t, dir, oid))
+		}
+		sort.Strings(paths)
+		return paths
+	}
+
+	// Only the blobs up to the size limit are fetched.
+	if diff := cmp.Diff([]string{"large.txt", "sparse/large.txt"}, missingBlobs()); diff != "" {
+		t.Fatalf("unexpected missing blobs after clone (-want +got):\n%s", diff)
+	}
+
+	// As well as the blobs of the sparse paths.
+	if err := fetchSparsePaths(ctx, dir, remoteURL); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"large.txt"}, missingBlobs()); diff != "" {
+		t.Fatalf("unexpected missing blobs after fetching sparse paths (-want +got):\n%s", diff)
+	}
+
+	// Fetches keep the filter.
+	if err := os.WriteFile(filepath.Join(src, "new.txt"), []byte(large+"new\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cmd("git", "add", "-A")
+	cmd("git", "commit", "-m", "new")
+	if err := syncer.Fetch(ctx, remoteURL, dir); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"large.txt", "new.txt"}, missingBlobs()); diff != "" {
+		t.Fatalf("unexpected missing blobs after fetch (-want +got):\n%s", diff)
+	}
+
+	s := &Server{Logger: logtest.Scoped(t), GetRemoteURLFunc: staticGetRemoteURL(remoteURL.String())}
+
+	t.Run("on demand", func(t *testing.T) {
+		before := testutil.ToFloat64(lazyFetchedObjects.WithLabelValues("exec"))
+
+		f := s.newLazyFetcher(ctx, "repo", dir, "exec")
+		c := exec.Command("git", "show", "HEAD:large.txt")
+		c.Env = append(os.Environ(), f.env()...)
+		dir.Set(c)
+		out, err := c.Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != large {
+			t.Fatalf("unexpected content %q", out)
+		}
+		f.done()
+
+		if got := testutil.ToFloat64(lazyFetchedObjects.WithLabelValues("exec")) - before; got != 1 {
+			t.Fatalf("got %v lazily fetched objects, want 1", got)
+		}
+	})
+
+	t.Run("prefetch", func(t *testing.T) {
+		before := testutil.ToFloat64(lazyFetches.WithLabelValues("archive"))
+
+		f := s.newLazyFetcher(ctx, "repo", dir, "archive")
+		if err := f.prefetch(ctx, []string{"HEAD"}, nil); err != nil {
+			t.Fatal(err)
+		}
+		f.done()
+
+		if missing := missingBlobs(); len(missing) != 0 {
+			t.Fatalf("expected no missing blobs, got %q", missing)
+		}
+		if got := testutil.ToFloat64(lazyFetches.WithLabelValues("archive")) - before; got != 1 {
+			t.Fatalf("got %v fetches, want a single one", got)
+		}
+	})
+
+	t.Run("full clone", func(t *testing.T) {
+		dir := GitDir(filepath.Join(root, "repos", "full", ".git"))
+		runCmd(t, root, "git", "clone", "--bare", src, string(dir))
+		if isPartialClone(dir) {
+			t.Fatal("expected a full clone")
+		}
+		if f := s.newLazyFetcher(ctx, "full", dir, "exec"); f != nil {
+			t.Fatal("expected no lazy fetcher for a full clone")
+		}
+	})
+}
+
+// blobPath returns the path of the blob with the given oid at HEAD.
+func blobPath(t *testing.T, dir GitDir, oid string) string {
+	t.Helper()
+	c := exec.Command("git", "ls-tree", "-r", "HEAD")
+	dir.Set(c)
+	out, err := c.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		// Example: 100644 blob 3b18e512dba79e4c8300dd08aeb37f8e728b8dad	data/model.json
+		if info, path, ok := strings.Cut(line, "\t"); ok && strings.HasSuffix(info, " "+oid) {
+			return path
+		}
+	}
+	return oid
+}
@@ -783,11 +783,12 @@ func (s *Server) tempDir(prefix string) (name string, err error) {
 }
 
 func (s *Server) ignorePath(path string) bool {
-	// We ignore any path which starts with .tmp in ReposDir
+	// We ignore any path which starts with .tmp in ReposDir, as well as the Git LFS
+	// objects store.
 	if filepath.Dir(path) != s.ReposDir {
 		return false
 	}
-	return strings.HasPrefix(filepath.Base(path), tempDirName)
+	return strings.HasPrefix(filepath.Base(path), tempDirName) || filepath.Base(path) == lfsDirName
 }
 
 func (s *Server) handleIsRepoCloneable(w http.ResponseWriter, r *http.Request) {
@@ -1040,6 +1041,7 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 		ev.AddField("revisions", args.Revisions)
 		ev.AddField("include_diff", args.IncludeDiff)
 		ev.AddField("include_modified_files", args.IncludeModifiedFiles)
+		ev.AddField("range_diff", args.RangeDiff)
 		ev.AddField("actor", act.UIDString())
 		ev.AddField("query", args.Query.String())
 		ev.AddField("limit", args.Limit)
@@ -1113,6 +1115,10 @@ func (s *Server) search(ctx context.Context, args *protocol.SearchRequest, match
 		}
 	}
 
+	// Partial clones fetch the blobs of the diffs they are missing from the code host.
+	lazyFetcher := s.newLazyFetcher(ctx, args.Repo, dir, "search")
+	defer lazyFetcher.done()
+
 	g, ctx := errgroup.WithContext(ctx)
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -1128,21 +1134,39 @@ func (s *Server) search(ctx context.Context, args *protocol.SearchRequest, match
 			return err
 		}
 
+		onMatch := func(match *protocol.CommitMatch) {
+			select {
+			case <-done:
+			case resultChan <- match:
+			}
+		}
+
+		if args.RangeDiff {
+			if len(args.Revisions) != 1 {
+				return errors.New("range diff search requires exactly one revision range")
+			}
+			searcher := &search.RangeDiffSearcher{
+				Logger:  s.Logger,
+				RepoDir: dir.Path(),
+				Env:     lazyFetcher.env(),
+				Query:   mt,
+				RevSpec: args.Revisions[0].RevSpec,
+				Limit:   args.Limit,
+			}
+			return searcher.Search(ctx, onMatch)
+		}
+
 		searcher := &search.CommitSearcher{
 			Logger:               s.Logger,
 			RepoDir:              dir.Path(),
+			Env:                  lazyFetcher.env(),
 			Revisions:            args.Revisions,
 			Query:                mt,
 			IncludeDiff:          args.IncludeDiff,
 			IncludeModifiedFiles: args.IncludeModifiedFiles,
 		}
 
-		return searcher.Search(ctx, func(match *protocol.CommitMatch) {
-			select {
-			case <-done:
-			case resultChan <- match:
-			}
-		})
+		return searcher.Search(ctx, onMatch)
 	})
 
 	// Write matching commits to the stream, flushing occasionally
@@ -1573,13 +1597,43 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 	stdoutW := &writeCounter{w: w}
 	stderrW := &writeCounter{w: &limitWriter{W: &stderrBuf, N: 1024}}
 
+	// Replace the pointer files of Git LFS objects by their content in blobs and
+	// archives.
+	args, lfsStdout, smudgeLFS := s.lfsSmudge(dir, req.Args, stdoutW)
+
+	// Partial clones fetch the objects they are missing from the code host. Archives
+	// read many blobs, which are fetched beforehand in a single request.
+	treeish, paths, isArchive := archivePrefetchArgs(args)
+	endpoint := "exec"
+	if isArchive {
+		endpoint = "archive"
+	}
+	lazyFetcher := s.newLazyFetcher(ctx, req.Repo, dir, endpoint)
+	defer lazyFetcher.done()
+	if isArchive {
+		if err := lazyFetcher.prefetch(ctx, []string{treeish}, paths); err != nil {
+			s.Logger.Warn("failed to fetch missing blobs of archive", log.String("repo", string(req.Repo)), log.Error(err))
+		}
+	}
+
 	cmdStart = time.Now()
-	cmd := exec.CommandContext(ctx, "git", req.Args...)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	if env := lazyFetcher.env(); env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	dir.Set(cmd)
 	cmd.Stdout = stdoutW
+	if smudgeLFS {
+		cmd.Stdout = lfsStdout
+	}
 	cmd.Stderr = stderrW
 
 	exitStatus, execErr = runCommand(ctx, cmd)
+	if smudgeLFS {
+		if err := lfsStdout.Close(); err != nil && execErr == nil {
+			execErr = errors.Wrap(err, "replace Git LFS pointer files")
+		}
+	}
 
 	status = strconv.Itoa(exitStatus)
 	stdoutN = stdoutW.n
@@ -2051,6 +2105,14 @@ func (s *Server) doClone(ctx context.Context, repo api.RepoName, dir GitDir, syn
 		return errors.Wrap(err, `git config set "sourcegraph.type"`)
 	}
 
+	if err := s.fetchLFSObjects(ctx, tmp, remoteURL, gitLFSOptions(syncer)); err != nil {
+		s.Logger.Warn("Failed to fetch Git LFS objects", log.String("repo", string(repo)), log.Error(err))
+	}
+
+	if err := fetchSparsePaths(ctx, tmp, remoteURL); err != nil {
+		s.Logger.Warn("Failed to fetch blobs of sparse paths", log.String("repo", string(repo)), log.Error(err))
+	}
+
 	// Update the last-changed stamp.
 	if err := setLastChanged(tmp); err != nil {
 		return errors.Wrapf(err, "failed to update last changed time")
@@ -2407,6 +2469,14 @@ func (s *Server) doBackgroundRepoUpdate(repo api.RepoName) error {
 		return errors.Wrap(err, `git config set "sourcegraph.type"`)
 	}
 
+	if err := s.fetchLFSObjects(ctx, dir, remoteURL, gitLFSOptions(syncer)); err != nil {
+		s.Logger.Warn("Failed to fetch Git LFS objects", log.String("repo", string(repo)), log.Error(err))
+	}
+
+	if err := fetchSparsePaths(ctx, dir, remoteURL); err != nil {
+		s.Logger.Warn("Failed to fetch blobs of sparse paths", log.String("repo", string(repo)), log.Error(err))
+	}
+
 	// Update the last-changed stamp.
 	if err := setLastChanged(dir); err != nil {
 		s.Logger.Warn("Failed to update last changed time", log.String("repo", string(repo)), log.Error(err))
@@ -190,7 +190,11 @@ func configureRemoteGitCommand(cmd *exec.Cmd, tlsConf *tlsConfig) {
 		panic(fmt.Sprintf("Only git or p4-fusion commands are supported, got %q", executable))
 	}
 
-	cmd.Env = append(cmd.Env, "GIT_ASKPASS=true") // disable password prompt
+	// Disable password prompts, unless the command answers them with its own askpass
+	// program, like the git svn commands of SubversionRepoSyncer.
+	if !hasEnv(cmd.Env, "GIT_ASKPASS") {
+		cmd.Env = append(cmd.Env, "GIT_ASKPASS=true")
+	}
 
 	// Suppress asking to add SSH host key to known_hosts (which will hang because
 	// the command is non-interactive).
@@ -223,6 +227,16 @@ func configureRemoteGitCommand(cmd *exec.Cmd, tlsConf *tlsConfig) {
 	cmd.Args = append(cmd.Args[:1], append(extraArgs, cmd.Args[1:]...)...)
 }
 
+// hasEnv returns whether env sets the environment variable key.
+func hasEnv(env []string, key string) bool {
+	for _, kv := range env {
+		if strings.HasPrefix(kv, key+"=") {
+			return true
+		}
+	}
+	return false
+}
+
 // writeTempFile writes data to the TempFile with pattern. Returns the path of
 // the tempfile.
 func writeTempFile(pattern string, data []byte) (path string, err error) {
@@ -11,7 +11,11 @@ import (
 )
 
 // GitRepoSyncer is a syncer for Git repositories.
-type GitRepoSyncer struct{}
+type GitRepoSyncer struct {
+	// LFS configures the fetching of Git LFS objects. They are not fetched when it
+	// is nil.
+	LFS *GitLFSOptions
+}
 
 func (s *GitRepoSyncer) Type() string {
 	return "git"
@@ -56,14 +60,28 @@ func (s *GitRepoSyncer) CloneCommand(ctx context.Context, remoteURL *vcs.URL, tm
 		return nil, errors.Wrapf(err, "clone setup failed")
 	}
 
-	cmd, _ = s.fetchCommand(ctx, remoteURL)
+	partial := s.partialCloneOpts(ctx, remoteURL)
+	if partial != nil {
+		if err := configurePartialClone(GitDir(tmpPath), partial); err != nil {
+			return nil, errors.Wrapf(err, "clone setup failed")
+		}
+	}
+
+	cmd, _ = s.fetchCommand(ctx, remoteURL, partial)
 	cmd.Dir = tmpPath
 	return cmd, nil
 }
 
 // Fetch tries to fetch updates of a Git repository.
 func (s *GitRepoSyncer) Fetch(ctx context.Context, remoteURL *vcs.URL, dir GitDir) error {
-	cmd, configRemoteOpts := s.fetchCommand(ctx, remoteURL)
+	partial := s.partialCloneOpts(ctx, remoteURL)
+	if partial != nil && !isPartialClone(dir) {
+		// Repositories cloned in full before being configured to be cloned partially
+		// are only cloned partially once they are re-cloned.
+		partial = nil
+	}
+
+	cmd, configRemoteOpts := s.fetchCommand(ctx, remoteURL, partial)
 	dir.Set(cmd)
 	if output, err := runWith(ctx, cmd, configRemoteOpts, nil); err != nil {
 		return errors.Wrapf(err, "failed to update with output %q", newURLRedactor(remoteURL).redact(string(output)))
@@ -76,7 +94,19 @@ func (s *GitRepoSyncer) RemoteShowCommand(ctx context.Context, remoteURL *vcs.UR
 	return exec.CommandContext(ctx, "git", "remote", "show", remoteURL.String()), nil
 }
 
-func (s *GitRepoSyncer) fetchCommand(ctx context.Context, remoteURL *vcs.URL) (cmd *exec.Cmd, configRemoteOpts bool) {
+// partialCloneOpts returns the partial clone options of the repository, or nil if it
+// is cloned in full. Repositories fetched with custom commands are always cloned in
+// full.
+func (s *GitRepoSyncer) partialCloneOpts(ctx context.Context, remoteURL *vcs.URL) *partialCloneOptions {
+	if customFetchCmd(ctx, remoteURL) != nil || useRefspecOverrides() {
+		return nil
+	}
+	return partialCloneOpts(remoteURL)
+}
+
+// fetchCommand returns the command to fetch the repository. If partial is not nil, the
+// repository is a partial clone and only the blobs matching its filter are fetched.
+func (s *GitRepoSyncer) fetchCommand(ctx context.Context, remoteURL *vcs.URL, partial *partialCloneOptions) (cmd *exec.Cmd, configRemoteOpts bool) {
 	configRemoteOpts = true
 	if customCmd := customFetchCmd(ctx, remoteURL); customCmd != nil {
 		cmd = customCmd
@@ -84,11 +114,19 @@ func (s *GitRepoSyncer) fetchCommand(ctx context.Context, remoteURL *vcs.URL) (c
 	} else if useRefspecOverrides() {
 		cmd = refspecOverridesFetchCmd(ctx, remoteURL)
 	} else {
-		cmd = exec.CommandContext(ctx, "git", "fetch",
+		args := []string{"fetch",
 			// We already have janitor jobs that run git gc. We disable git gc here to avoid
 			// a possible corruption of repositories by competing gc processes.
 			"--no-auto-gc",
-			"--progress", "--prune", remoteURL.String(),
+		}
+		remote := remoteURL.String()
+		if partial != nil {
+			// Filters can only be used when fetching from the promisor remote.
+			args = append(args, "--filter="+partial.filter())
+			remote = promisorRemote
+		}
+		cmd = exec.CommandContext(ctx, "git", append(args,
+			"--progress", "--prune", remote,
 			// Normal git refs
 			"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*",
 			// GitHub pull requests
@@ -100,7 +138,10 @@ func (s *GitRepoSyncer) fetchCommand(ctx context.Context, remoteURL *vcs.URL) (c
 			// Gerrit changesets
 			"+refs/changes/*:refs/changes/*",
 			// Possibly deprecated refs for sourcegraph zap experiment?
-			"+refs/sourcegraph/*:refs/sourcegraph/*")
+			"+refs/sourcegraph/*:refs/sourcegraph/*")...)
+		if partial != nil {
+			cmd.Env = append(os.Environ(), promisorRemoteEnv(remoteURL)...)
+		}
 	}
 	return cmd, configRemoteOpts
 }
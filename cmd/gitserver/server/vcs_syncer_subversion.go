@@ -0,0 +1,206 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const (
+	// svnAuthorsFile is the git svn authors file, written in the Git directory.
+	svnAuthorsFile = "sourcegraph-svn-authors"
+	// svnAuthorsProg maps the authors missing from the authors file, which git svn
+	// would otherwise fail on.
+	svnAuthorsProg = "sourcegraph-svn-authors-prog"
+	// svnAskPass answers the password prompts of git svn with the password in the
+	// SRC_SVN_PASSWORD environment variable, since it cannot be passed on the
+	// command line.
+	svnAskPass = "sourcegraph-svn-askpass"
+)
+
+// SubversionRepoSyncer is a syncer for Subversion repositories, which are converted
+// to Git repositories with git svn. The trunk is imported as the master branch, and
+// the subdirectories of the branches and tags directories as branches and tags.
+type SubversionRepoSyncer struct {
+	// Layout is the directory layout of the repositories. The standard layout is
+	// used when it is nil.
+	Layout *schema.SubversionLayout
+
+	// Authors maps Subversion usernames to Git authors in the form
+	// "Full Name <email@example.com>".
+	Authors map[string]string
+}
+
+func (s *SubversionRepoSyncer) Type() string {
+	return "subversion"
+}
+
+// IsCloneable checks to see if the Subversion repository exists and is accessible
+// with the credentials of the remote URL.
+func (s *SubversionRepoSyncer) IsCloneable(ctx context.Context, remoteURL *vcs.URL) error {
+	repoURL, username, password := decomposeSubversionRemoteURL(remoteURL)
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// Example: svn info --non-interactive --no-auth-cache --username admin --password-from-stdin https://svn.example.com/repos/project
+	args := []string{"info", "--non-interactive", "--no-auth-cache"}
+	if username != "" {
+		args = append(args, "--username", username, "--password-from-stdin")
+	}
+	cmd := exec.CommandContext(ctx, "svn", append(args, repoURL)...)
+	cmd.Stdin = strings.NewReader(password)
+
+	out, err := runWith(ctx, cmd, false, nil)
+	if err != nil {
+		if ctxerr := ctx.Err(); ctxerr != nil {
+			err = ctxerr
+		}
+		if len(out) > 0 {
+			err = errors.Errorf("%s (output follows)\n\n%s", err, newURLRedactor(remoteURL).redact(string(out)))
+		}
+		return err
+	}
+	return nil
+}
+
+// CloneCommand returns the command to be executed for cloning a Subversion repository
+// as a Git repository. The Git repository is configured for git svn before the command
+// is returned, and the command fetches the whole history of the Subversion repository.
+func (s *SubversionRepoSyncer) CloneCommand(ctx context.Context, remoteURL *vcs.URL, tmpPath string) (*exec.Cmd, error) {
+	if err := os.MkdirAll(tmpPath, os.ModePerm); err != nil {
+		return nil, errors.Wrapf(err, "clone failed to create tmp dir")
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "init", "--bare", ".")
+	cmd.Dir = tmpPath
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "clone setup failed")
+	}
+
+	dir := GitDir(tmpPath)
+	repoURL, _, _ := decomposeSubversionRemoteURL(remoteURL)
+	for _, kv := range s.remoteConfig(repoURL) {
+		cmd := exec.CommandContext(ctx, "git", "config", "--add", kv[0], kv[1])
+		dir.Set(cmd)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return nil, errors.Wrapf(err, "failed to configure git svn with output %q", string(output))
+		}
+	}
+
+	return s.fetchCommand(ctx, remoteURL, dir)
+}
+
+// Fetch fetches the Subversion revisions committed since the last fetch.
+func (s *SubversionRepoSyncer) Fetch(ctx context.Context, remoteURL *vcs.URL, dir GitDir) error {
+	cmd, err := s.fetchCommand(ctx, remoteURL, dir)
+	if err != nil {
+		return err
+	}
+	if output, err := runWith(ctx, cmd, true, nil); err != nil {
+		return errors.Wrapf(err, "failed to update with output %q", newURLRedactor(remoteURL).redact(string(output)))
+	}
+	return nil
+}
+
+// RemoteShowCommand returns the command to be executed for showing the Git remote of a
+// Subversion repository.
+func (s *SubversionRepoSyncer) RemoteShowCommand(ctx context.Context, remoteURL *vcs.URL) (cmd *exec.Cmd, err error) {
+	// There is no Git remote, the trunk is always imported as master.
+	return exec.CommandContext(ctx, "git", "remote", "show", "./"), nil
+}
+
+// remoteConfig returns the git svn configuration of the repository, as written by
+// git svn init, except that branches and tags are fetched directly into refs/heads
+// and refs/tags instead of remote tracking refs.
+func (s *SubversionRepoSyncer) remoteConfig(repoURL string) [][2]string {
+	layout := s.Layout
+	if layout == nil {
+		layout = &schema.SubversionLayout{
+			Trunk:    "trunk",
+			Branches: []string{"branches"},
+			Tags:     []string{"tags"},
+		}
+	}
+
+	config := [][2]string{{"svn-remote.svn.url", repoURL}}
+	if layout.Trunk == "" && len(layout.Branches) == 0 && len(layout.Tags) == 0 {
+		// Without a layout the whole repository is a single branch.
+		return append(config, [2]string{"svn-remote.svn.fetch", ":refs/heads/master"})
+	}
+	if layout.Trunk != "" {
+		config = append(config, [2]string{"svn-remote.svn.fetch", strings.Trim(layout.Trunk, "/") + ":refs/heads/master"})
+	}
+	for _, branches := range layout.Branches {
+		config = append(config, [2]string{"svn-remote.svn.branches", strings.Trim(branches, "/") + "/*:refs/heads/*"})
+	}
+	for _, tags := range layout.Tags {
+		config = append(config, [2]string{"svn-remote.svn.tags", strings.Trim(tags, "/") + "/*:refs/tags/*"})
+	}
+	return config
+}
+
+// fetchCommand returns the git svn command fetching new revisions into dir. The files
+// it needs for author mapping and authentication are (re)written in dir, so that
+// changes to the configuration apply to the next fetch.
+func (s *SubversionRepoSyncer) fetchCommand(ctx context.Context, remoteURL *vcs.URL, dir GitDir) (*exec.Cmd, error) {
+	_, username, password := decomposeSubversionRemoteURL(remoteURL)
+
+	// Example: git svn fetch --no-auth-cache --username admin --authors-file sourcegraph-svn-authors --authors-prog sourcegraph-svn-authors-prog
+	args := []string{"svn", "fetch", "--no-auth-cache"}
+	if username != "" {
+		args = append(args, "--username", username)
+	}
+	if len(s.Authors) > 0 {
+		if err := os.WriteFile(dir.Path(svnAuthorsFile), svnAuthors(s.Authors), 0600); err != nil {
+			return nil, errors.Wrap(err, "write authors file")
+		}
+		if err := os.WriteFile(dir.Path(svnAuthorsProg), []byte("#!/bin/sh\nprintf '%s <%s>\\n' \"$1\" \"$1\"\n"), 0700); err != nil {
+			return nil, errors.Wrap(err, "write authors program")
+		}
+		args = append(args, "--authors-file", dir.Path(svnAuthorsFile), "--authors-prog", dir.Path(svnAuthorsProg))
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	dir.Set(cmd)
+	if password != "" {
+		if err := os.WriteFile(dir.Path(svnAskPass), []byte("#!/bin/sh\nprintf '%s\\n' \"$SRC_SVN_PASSWORD\"\n"), 0700); err != nil {
+			return nil, errors.Wrap(err, "write askpass program")
+		}
+		cmd.Env = append(cmd.Env, "GIT_ASKPASS="+dir.Path(svnAskPass), "SRC_SVN_PASSWORD="+password)
+	}
+	return cmd, nil
+}
+
+// svnAuthors returns the content of a git svn authors file for the given mapping.
+func svnAuthors(authors map[string]string) []byte {
+	usernames := make([]string, 0, len(authors))
+	for username := range authors {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+
+	var b bytes.Buffer
+	for _, username := range usernames {
+		fmt.Fprintf(&b, "%s = %s\n", username, authors[username])
+	}
+	return b.Bytes()
+}
+
+// decomposeSubversionRemoteURL returns the URL of the Subversion repository without
+// the credentials, which git svn and svn take separately.
+func decomposeSubversionRemoteURL(remoteURL *vcs.URL) (repoURL, username, password string) {
+	u := remoteURL.URL
+	u.User = nil
+	password, _ = remoteURL.User.Password()
+	return u.String(), remoteURL.User.Username(), password
+}
@@ -18,7 +18,7 @@ export GOOS=linux
 export CGO_ENABLED=0
 
 echo "--- go build"
-pkg="github.com/sourcegraph/sourcegraph/cmd/migrator"
+pkg=${1:-github.com/sourcegraph/sourcegraph/cmd/migrator}
 go build -trimpath -ldflags "-X github.com/sourcegraph/sourcegraph/internal/version.version=$VERSION -X github.com/sourcegraph/sourcegraph/internal/version.timestamp=$(date +%s)" -buildmode exe -tags dist -o "$OUTPUT/$(basename $pkg)" "$pkg"
 
 echo "--- docker build"
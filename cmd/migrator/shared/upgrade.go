@@ -0,0 +1,286 @@
+package shared
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/sourcegraph/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	connections "github.com/sourcegraph/sourcegraph/internal/database/connections/live"
+	"github.com/sourcegraph/sourcegraph/internal/database/migration/cliutil"
+	"github.com/sourcegraph/sourcegraph/internal/database/migration/multiversion"
+	"github.com/sourcegraph/sourcegraph/internal/database/migration/runner"
+	"github.com/sourcegraph/sourcegraph/internal/database/migration/schemas"
+	"github.com/sourcegraph/sourcegraph/internal/database/postgresdsn"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/internal/oobmigration"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+	"github.com/sourcegraph/sourcegraph/internal/version"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/lib/output"
+)
+
+// upgradeCommand upgrades the databases across several versions, running the out-of-band
+// migrations that must complete before a version is reached inline between schema migrations.
+func upgradeCommand(factory cliutil.RunnerFactory, outFactory cliutil.OutputFactory, registerMigrations func(db database.DB, outOfBandMigrationRunner *oobmigration.Runner) error) *cli.Command {
+	fromFlag := &cli.StringFlag{
+		Name:     "from",
+		Usage:    "The `version` of Sourcegraph currently running against the databases, e.g. 3.38.",
+		Required: true,
+	}
+	toFlag := &cli.StringFlag{
+		Name:  "to",
+		Usage: "The `version` of Sourcegraph to upgrade to. Defaults to the version of the migrator.",
+	}
+	schemaNamesFlag := &cli.StringSliceFlag{
+		Name:  "db",
+		Usage: "The target `schema(s)` to modify. Comma-separated values are accepted. Supply \"all\" to migrate all schemas.",
+		Value: cli.NewStringSlice("all"),
+	}
+	dryRunFlag := &cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Print the upgrade plan without modifying the databases.",
+		Value: false,
+	}
+	unprivilegedOnlyFlag := &cli.BoolFlag{
+		Name:  "unprivileged-only",
+		Usage: `Do not apply privileged migrations.`,
+		Value: false,
+	}
+
+	action := func(cmd *cli.Context) error {
+		ctx := cmd.Context
+		out := outFactory()
+
+		if cmd.NArg() != 0 {
+			return upgradeFlagHelp(out, "too many arguments")
+		}
+		from, err := oobmigration.NewVersionFromString(fromFlag.Get(cmd))
+		if err != nil {
+			return upgradeFlagHelp(out, "failed to parse -from: %s", err)
+		}
+		toVersion := toFlag.Get(cmd)
+		if toVersion == "" {
+			if version.IsDev(version.Version()) {
+				return upgradeFlagHelp(out, "supply a target version via -to for development builds")
+			}
+			toVersion = version.Version()
+		}
+		to, err := oobmigration.NewVersionFromString(toVersion)
+		if err != nil {
+			return upgradeFlagHelp(out, "failed to parse -to: %s", err)
+		}
+		schemaNames := schemaNamesFlag.Get(cmd)
+		if len(schemaNames) == 1 && schemaNames[0] == "all" {
+			schemaNames = schemas.SchemaNames
+		}
+
+		observationContext := &observation.Context{
+			Logger:     log.Scoped("upgrade", "multi-version upgrade"),
+			Tracer:     &trace.Tracer{Tracer: opentracing.GlobalTracer()},
+			Registerer: prometheus.DefaultRegisterer,
+		}
+		db, err := newFrontendDB(observationContext)
+		if err != nil {
+			return err
+		}
+
+		store := oobmigration.NewStoreWithDB(db)
+		outOfBandMigrationRunner := oobmigration.NewRunnerWithDB(db, oobmigration.RefreshInterval, observationContext)
+		if err := registerMigrations(db, outOfBandMigrationRunner); err != nil {
+			return err
+		}
+
+		u := &upgrader{
+			out:                      out,
+			store:                    store,
+			outOfBandMigrationRunner: outOfBandMigrationRunner,
+			factory:                  factory,
+			schemaNames:              schemaNames,
+			unprivilegedOnly:         unprivilegedOnlyFlag.Get(cmd),
+		}
+		if dryRunFlag.Get(cmd) {
+			return u.printPlan(ctx, from, to)
+		}
+		return u.upgrade(ctx, from, to)
+	}
+
+	return &cli.Command{
+		Name:        "upgrade",
+		UsageText:   fmt.Sprintf("%s upgrade -from=<version> [-to=<version>] [-db=all] [-dry-run]", appName),
+		Usage:       "Upgrade the databases across several versions, running required out-of-band migrations inline",
+		Description: cliutil.ConstructLongHelp(),
+		Action:      action,
+		Flags: []cli.Flag{
+			fromFlag,
+			toFlag,
+			schemaNamesFlag,
+			dryRunFlag,
+			unprivilegedOnlyFlag,
+		},
+	}
+}
+
+type upgrader struct {
+	out                      *output.Output
+	store                    *oobmigration.Store
+	outOfBandMigrationRunner *oobmigration.Runner
+	factory                  cliutil.RunnerFactory
+	schemaNames              []string
+	unprivilegedOnly         bool
+}
+
+// plan returns the plan to upgrade from the given version, based on the current state of the
+// out-of-band migrations.
+func (u *upgrader) plan(ctx context.Context, from, to oobmigration.Version) (multiversion.Plan, error) {
+	migrations, err := u.store.List(ctx)
+	if err != nil {
+		return multiversion.Plan{}, err
+	}
+
+	return multiversion.NewPlan(from, to, migrations, multiversion.SchemaLeaves)
+}
+
+func (u *upgrader) printPlan(ctx context.Context, from, to oobmigration.Version) error {
+	plan, err := u.plan(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	u.out.WriteLine(output.Line("", output.StyleBold, plan.String()))
+	u.out.WriteLine(output.Line("", output.StyleSuggestion, "Out-of-band migrations introduced after the starting version are planned once the schema migrations introducing them are applied."))
+	return nil
+}
+
+// upgrade applies the first step of the plan, then plans the rest of the upgrade again as
+// the applied schema migrations may have introduced new out-of-band migrations.
+func (u *upgrader) upgrade(ctx context.Context, from, to oobmigration.Version) error {
+	for {
+		plan, err := u.plan(ctx, from, to)
+		if err != nil {
+			return err
+		}
+		if err := u.checkRegistered(plan); err != nil {
+			return err
+		}
+
+		step := plan.Steps[0]
+		if err := u.migrateSchemas(ctx, step); err != nil {
+			return err
+		}
+		if err := u.runOutOfBandMigrations(ctx, step); err != nil {
+			return err
+		}
+
+		if len(plan.Steps) == 1 {
+			u.out.WriteLine(output.Linef(output.EmojiSuccess, output.StyleSuccess, "Upgraded from %s to %s", plan.From, plan.To))
+			return nil
+		}
+		from = step.Version
+	}
+}
+
+// checkRegistered returns an error if the migrator cannot run some of the out-of-band
+// migrations of the plan, before any change is made to the databases.
+func (u *upgrader) checkRegistered(plan multiversion.Plan) error {
+	for _, step := range plan.Steps {
+		var unregistered []int
+		for _, id := range step.OutOfBandMigrationIDs {
+			if !u.outOfBandMigrationRunner.Registered(id) {
+				unregistered = append(unregistered, id)
+			}
+		}
+		if len(unregistered) == 0 {
+			continue
+		}
+
+		return errors.Newf(
+			"the migrator cannot run the out-of-band migrations %v required at %s: upgrade to %s, run Sourcegraph until these migrations complete, then upgrade from %s",
+			unregistered, step.Version, step.Version, step.Version,
+		)
+	}
+
+	return nil
+}
+
+func (u *upgrader) migrateSchemas(ctx context.Context, step multiversion.Step) error {
+	var operations []runner.MigrationOperation
+	for _, schemaName := range u.schemaNames {
+		switch {
+		case step.Latest:
+			operations = append(operations, runner.MigrationOperation{
+				SchemaName: schemaName,
+				Type:       runner.MigrationOperationTypeUpgrade,
+			})
+		case len(step.Leaves[schemaName]) > 0:
+			operations = append(operations, runner.MigrationOperation{
+				SchemaName:     schemaName,
+				Type:           runner.MigrationOperationTypeTargetedUp,
+				TargetVersions: step.Leaves[schemaName],
+			})
+		}
+	}
+	if len(operations) == 0 {
+		return nil
+	}
+
+	u.out.WriteLine(output.Linef("", output.StylePending, "Applying schema migrations of %s...", step.Version))
+
+	r, err := u.factory(ctx, u.schemaNames)
+	if err != nil {
+		return err
+	}
+	if err := r.Run(ctx, runner.Options{
+		Operations:       operations,
+		UnprivilegedOnly: u.unprivilegedOnly,
+	}); err != nil {
+		return errors.Wrapf(err, "applying schema migrations of %s", step.Version)
+	}
+
+	u.out.WriteLine(output.Linef(output.EmojiSuccess, output.StyleSuccess, "Applied schema migrations of %s", step.Version))
+	return nil
+}
+
+func (u *upgrader) runOutOfBandMigrations(ctx context.Context, step multiversion.Step) error {
+	if len(step.OutOfBandMigrationIDs) == 0 {
+		return nil
+	}
+
+	u.out.WriteLine(output.Linef("", output.StylePending, "Running out-of-band migrations %v required after %s...", step.OutOfBandMigrationIDs, step.Version))
+
+	if err := u.outOfBandMigrationRunner.RunToCompletion(ctx, step.OutOfBandMigrationIDs, func(migration oobmigration.Migration) {
+		u.out.WriteLine(output.Linef("", output.StyleReset, "  Migration %d (%s): %.2f%%", migration.ID, migration.Description, migration.Progress*100))
+	}); err != nil {
+		return err
+	}
+
+	u.out.WriteLine(output.Linef(output.EmojiSuccess, output.StyleSuccess, "Completed out-of-band migrations %v", step.OutOfBandMigrationIDs))
+	return nil
+}
+
+// newFrontendDB connects to the frontend database without validating its schema, which
+// changes during the upgrade.
+func newFrontendDB(observationContext *observation.Context) (database.DB, error) {
+	dsns, err := postgresdsn.DSNsBySchema([]string{"frontend"})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := connections.RawNewFrontendDB(dsns["frontend"], appName, observationContext)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to frontend database")
+	}
+
+	return database.NewDB(sqlDB), nil
+}
+
+// upgradeFlagHelp returns an error that prints the specified error message with usage text.
+func upgradeFlagHelp(out *output.Output, message string, args ...any) error {
+	out.WriteLine(output.Linef("", output.StyleWarning, "ERROR: "+message, args...))
+	return flag.ErrHelp
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 
 	"github.com/sourcegraph/sourcegraph/internal/encryption"
+	et "github.com/sourcegraph/sourcegraph/internal/encryption/testing"
 )
 
 // invalidKey is an encryption.Key that just base64 encodes the plaintext,
@@ -23,3 +24,15 @@ func (k invalidKey) Decrypt(ctx context.Context, ciphertext []byte) (*encryption
 func (k invalidKey) Version(ctx context.Context) (encryption.KeyVersion, error) {
 	return encryption.KeyVersion{Type: "invalidkey"}, nil
 }
+
+// rotatedKey is an encryption.Key that encodes secrets like et.TestKey, with a version
+// that can be changed to simulate the rotation of the key. It decrypts the secrets
+// encoded with any version.
+type rotatedKey struct {
+	et.TestKey
+	version string
+}
+
+func (k rotatedKey) Version(ctx context.Context) (encryption.KeyVersion, error) {
+	return encryption.KeyVersion{Type: "testkey", Version: k.version}, nil
+}
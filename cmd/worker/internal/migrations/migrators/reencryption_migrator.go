@@ -0,0 +1,190 @@
+package migrators
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/encryption"
+	"github.com/sourcegraph/sourcegraph/internal/encryption/keyring"
+	"github.com/sourcegraph/sourcegraph/internal/encryption/rotation"
+	"github.com/sourcegraph/sourcegraph/internal/oobmigration"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// ReencryptionMigrator is a background job that re-encrypts the rows of the tables with
+// encrypted columns which are encrypted with a previous version of their key, after it
+// has been rotated. Rows are decrypted with the key of the keyring, so it must still be
+// able to decrypt its previous versions, like the KMS keys do.
+// Unencrypted rows are left to the migrations encrypting each table.
+// Since the progress drops as soon as a key is rotated, the migration resumes on its own
+// after each rotation.
+type ReencryptionMigrator struct {
+	store     *rotation.Store
+	tables    []rotation.Table
+	BatchSize int
+
+	// Counting the rows of the tables by key version reads all of them, so the statuses
+	// of the tables are cached between calls to Progress. Up keeps them up to date.
+	mu       sync.Mutex
+	statuses map[string]*cachedStatus
+}
+
+type cachedStatus struct {
+	status    *rotation.Status
+	countedAt time.Time
+}
+
+// statusRecountInterval is the interval at which the rows of the tables are counted again
+// even though their key did not change, to account for the rows created in the meantime.
+const statusRecountInterval = 15 * time.Minute
+
+var _ oobmigration.Migrator = &ReencryptionMigrator{}
+
+func NewReencryptionMigrator(store *rotation.Store) *ReencryptionMigrator {
+	// not locking too many rows of each table at a time to prevent congestion
+	return &ReencryptionMigrator{store: store, tables: rotation.Tables, BatchSize: 50, statuses: map[string]*cachedStatus{}}
+}
+
+func NewReencryptionMigratorWithDB(db database.DB) *ReencryptionMigrator {
+	return NewReencryptionMigrator(rotation.NewStore(db))
+}
+
+// ID of the migration row in in the out_of_band_migrations table.
+// This ID was defined arbitrarily in this migration file: frontend/1655410000/up.sql.
+func (m *ReencryptionMigrator) ID() int {
+	return 15
+}
+
+// Progress returns a value from 0 to 1 representing the percentage of encrypted rows
+// already encrypted with the current version of their key. The tables without a key
+// are ignored. The rows of a table are only counted again once its key is rotated or
+// after statusRecountInterval.
+func (m *ReencryptionMigrator) Progress(ctx context.Context) (float64, error) {
+	ring := keyring.Default()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := make([]*rotation.Status, 0, len(m.tables))
+	for _, t := range m.tables {
+		if t.Key(ring) == nil {
+			continue
+		}
+		currentKeyID, err := t.CurrentKeyID(ctx, ring)
+		if err != nil {
+			return 0, err
+		}
+
+		cached, ok := m.statuses[t.Name]
+		if !ok || cached.status.CurrentKeyID != currentKeyID || time.Since(cached.countedAt) > statusRecountInterval {
+			status, err := m.store.Status(ctx, t, ring)
+			if err != nil {
+				return 0, err
+			}
+			cached = &cachedStatus{status: status, countedAt: time.Now()}
+			m.statuses[t.Name] = cached
+		}
+		statuses = append(statuses, cached.status)
+	}
+
+	return rotation.Progress(statuses), nil
+}
+
+// Up loads BatchSize rows of each table encrypted with a previous version of their key,
+// locks them, and re-encrypts them with the current version of the key returned by
+// keyring.Default().
+// Up ensures the values can be decrypted with the same key before overwriting them.
+// The key id is stored alongside the encrypted values.
+func (m *ReencryptionMigrator) Up(ctx context.Context) error {
+	ring := keyring.Default()
+
+	for _, t := range m.tables {
+		key := t.Key(ring)
+		if key == nil {
+			continue
+		}
+		if err := m.reencrypt(ctx, t, key); err != nil {
+			return errors.Wrapf(err, "re-encrypting %s", t.Name)
+		}
+	}
+
+	return nil
+}
+
+func (m *ReencryptionMigrator) reencrypt(ctx context.Context, t rotation.Table, key encryption.Key) (err error) {
+	version, err := key.Version(ctx)
+	if err != nil {
+		return err
+	}
+	keyIdent := version.JSON()
+
+	tx, err := m.store.Transact(ctx)
+	if err != nil {
+		return err
+	}
+
+	var rows []*rotation.Row
+	defer func() {
+		if err = tx.Done(err); err == nil {
+			m.reencrypted(t, rows, keyIdent)
+		}
+	}()
+
+	rows, err = tx.ListStale(ctx, t, keyIdent, m.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		for i, value := range row.Values {
+			if value == nil {
+				continue
+			}
+
+			secret, err := key.Decrypt(ctx, value)
+			if err != nil {
+				return errors.Wrapf(err, "decrypting %s of row %d", t.Columns[i].Name, row.ID)
+			}
+
+			encrypted, err := key.Encrypt(ctx, []byte(secret.Secret()))
+			if err != nil {
+				return err
+			}
+
+			// ensure encryption round-trip is valid with keyIdent
+			decrypted, err := key.Decrypt(ctx, encrypted)
+			if err != nil {
+				return err
+			}
+			if decrypted.Secret() != secret.Secret() {
+				return errors.Newf("invalid encryption round-trip of %s of row %d", t.Columns[i].Name, row.ID)
+			}
+
+			row.Values[i] = encrypted
+		}
+
+		if err := tx.Update(ctx, t, row, keyIdent); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reencrypted updates the cached status of the given table after the given rows are
+// re-encrypted with the key version identified by keyIdent.
+func (m *ReencryptionMigrator) reencrypted(t rotation.Table, rows []*rotation.Row, keyIdent string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if cached, ok := m.statuses[t.Name]; ok && cached.status.CurrentKeyID == keyIdent {
+		cached.status.Reencrypted(rows, keyIdent)
+	}
+}
+
+// Down is a no-op: the rows cannot be encrypted with a previous version of a key.
+func (m *ReencryptionMigrator) Down(ctx context.Context) error {
+	return nil
+}
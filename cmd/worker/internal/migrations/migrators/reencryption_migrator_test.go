@@ -0,0 +1,123 @@
+package migrators
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
+	"github.com/sourcegraph/sourcegraph/internal/encryption/keyring"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/internal/types/typestest"
+)
+
+func TestReencryptionMigrator(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := context.Background()
+	db := database.NewDB(dbtest.NewDB(t))
+
+	// ensure no keyring is configured
+	keyring.MockDefault(keyring.Ring{})
+	defer keyring.MockDefault(keyring.Ring{})
+
+	migrator := NewReencryptionMigratorWithDB(db)
+	migrator.BatchSize = 2
+
+	requireProgressEqual := func(want float64) {
+		t.Helper()
+
+		got, err := migrator.Progress(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprintf("%.3f", want) != fmt.Sprintf("%.3f", got) {
+			t.Fatalf("invalid progress: want %f, got %f", want, got)
+		}
+	}
+
+	// progress without keys should be 1
+	requireProgressEqual(1)
+
+	// Create an unencrypted external service, which is left to the encryption migration
+	confGet := func() *conf.Unified {
+		return &conf.Unified{}
+	}
+	svcs := typestest.GenerateExternalServices(4, typestest.MakeExternalServices()...)
+	if err := db.ExternalServices().Create(ctx, confGet, svcs[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create 3 external services and 2 webhook logs encrypted with the first key version
+	v1 := rotatedKey{version: "1"}
+	keyring.MockDefault(keyring.Ring{ExternalServiceKey: v1, WebhookLogKey: v1})
+	for _, svc := range svcs[1:] {
+		if err := db.ExternalServices().Create(ctx, confGet, svc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	logs := make([]*types.WebhookLog, 2)
+	for i := range logs {
+		logs[i] = &types.WebhookLog{
+			ReceivedAt: time.Now().UTC().Truncate(time.Microsecond),
+			StatusCode: http.StatusOK,
+			Request:    types.WebhookLogMessage{Body: []byte(fmt.Sprintf("request %d", i))},
+			Response:   types.WebhookLogMessage{Body: []byte(fmt.Sprintf("response %d", i))},
+		}
+		if err := db.WebhookLogs(v1).Create(ctx, logs[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// everything is encrypted with the current key version
+	requireProgressEqual(1)
+
+	// Rotate the keys
+	v2 := rotatedKey{version: "2"}
+	keyring.MockDefault(keyring.Ring{ExternalServiceKey: v2, WebhookLogKey: v2})
+	requireProgressEqual(0)
+
+	// Up should re-encrypt two rows of each table
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// rows: 5, re-encrypted: 4, progress: 80%
+	requireProgressEqual(0.8)
+
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	requireProgressEqual(1)
+
+	// Up without anything left to do shouldn't do anything
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	requireProgressEqual(1)
+
+	// the values can still be decrypted
+	for _, svc := range svcs {
+		got, err := db.ExternalServices().GetByID(ctx, svc.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Config != svc.Config {
+			t.Fatalf("invalid config of external service %d: want %q, got %q", svc.ID, svc.Config, got.Config)
+		}
+	}
+	for _, log := range logs {
+		// the webhook log store errors on logs encrypted with another key version
+		got, err := db.WebhookLogs(v2).GetByID(ctx, log.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got.Request.Body) != string(log.Request.Body) || string(got.Response.Body) != string(log.Response.Body) {
+			t.Fatalf("invalid webhook log %d: want %+v, got %+v", log.ID, log, got)
+		}
+	}
+}
@@ -30,5 +30,11 @@ func RegisterOSSMigrations(db database.DB, outOfBandMigrationRunner *oobmigratio
 		return errors.Wrap(err, "failed to run external service webhook job")
 	}
 
+	// Run a background job to re-encrypt encrypted columns with the current version of their key.
+	reencryptionMigrator := NewReencryptionMigratorWithDB(db)
+	if err := outOfBandMigrationRunner.Register(reencryptionMigrator.ID(), reencryptionMigrator, oobmigration.MigratorOptions{Interval: 3 * time.Second}); err != nil {
+		return errors.Wrap(err, "failed to run re-encryption job")
+	}
+
 	return nil
 }
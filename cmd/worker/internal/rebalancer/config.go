@@ -0,0 +1,27 @@
+package rebalancer
+
+import (
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+type config struct {
+	env.BaseConfig
+
+	Interval           time.Duration
+	Concurrency        int
+	TransfersPerMinute int
+}
+
+var configInst = &config{}
+
+func (c *config) Load() {
+	c.Interval = c.GetInterval("GITSERVER_REBALANCER_INTERVAL", "30s", "How frequently to check for gitserver topology changes and update the rebalance progress.")
+	c.Concurrency = c.GetInt("GITSERVER_REBALANCER_CONCURRENCY", "2", "The maximum number of repositories copied between gitserver instances at the same time.")
+	c.TransfersPerMinute = c.GetInt("GITSERVER_REBALANCER_TRANSFERS_PER_MINUTE", "60", "The maximum number of repositories copied between gitserver instances per minute.")
+	if c.TransfersPerMinute <= 0 {
+		c.AddError(errors.New("GITSERVER_REBALANCER_TRANSFERS_PER_MINUTE must be greater than 0"))
+	}
+}
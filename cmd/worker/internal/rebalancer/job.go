@@ -0,0 +1,80 @@
+package rebalancer
+
+import (
+	"context"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+
+	"github.com/sourcegraph/log"
+
+	"github.com/sourcegraph/sourcegraph/cmd/worker/job"
+	workerdb "github.com/sourcegraph/sourcegraph/cmd/worker/shared/init/db"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/migration"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil/dbworker"
+)
+
+type rebalancerJob struct{}
+
+// NewRebalancerJob creates a new job which moves repositories between gitserver instances
+// when instances are added or removed.
+func NewRebalancerJob() job.Job {
+	return &rebalancerJob{}
+}
+
+func (j *rebalancerJob) Description() string {
+	return "Moves repositories between gitserver instances when instances are added or removed."
+}
+
+func (j *rebalancerJob) Config() []env.Config {
+	return []env.Config{configInst}
+}
+
+func (j *rebalancerJob) Routines(ctx context.Context, logger log.Logger) ([]goroutine.BackgroundRoutine, error) {
+	sqlDB, err := workerdb.Init()
+	if err != nil {
+		return nil, err
+	}
+	db := database.NewDB(sqlDB)
+
+	observationContext := &observation.Context{
+		Logger:     logger.Scoped("routines", "gitserver rebalancer job routines"),
+		Tracer:     &trace.Tracer{Tracer: opentracing.GlobalTracer()},
+		Registerer: prometheus.DefaultRegisterer,
+	}
+
+	workerStore := newRelocatorStore(db)
+	limiter := rate.NewLimiter(rate.Every(time.Minute/time.Duration(configInst.TransfersPerMinute)), 1)
+
+	return []goroutine.BackgroundRoutine{
+		goroutine.NewPeriodicGoroutine(context.Background(), configInst.Interval, &planner{
+			db:     db,
+			store:  migration.NewRebalanceStore(db),
+			logger: logger.Scoped("planner", "detects gitserver topology changes"),
+		}),
+		dbworker.NewWorker(context.Background(), workerStore, &relocator{
+			client:  gitserver.NewClient(db),
+			limiter: limiter,
+		}, workerutil.WorkerOptions{
+			Name:              "gitserver_relocator_worker",
+			NumHandlers:       configInst.Concurrency,
+			Interval:          5 * time.Second,
+			HeartbeatInterval: 15 * time.Second,
+			Metrics:           workerutil.NewMetrics(observationContext, "gitserver_relocator"),
+		}),
+		dbworker.NewResetter(workerStore, dbworker.ResetterOptions{
+			Name:     "gitserver_relocator_worker_resetter",
+			Interval: time.Minute,
+			Metrics:  *dbworker.NewMetrics(observationContext, "gitserver_relocator"),
+		}),
+	}, nil
+}
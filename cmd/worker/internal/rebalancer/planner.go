@@ -0,0 +1,133 @@
+package rebalancer
+
+import (
+	"context"
+
+	"github.com/sourcegraph/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/migration"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+// planner detects changes of the list of gitserver instances, computes the repositories which
+// have to move to a different instance and keeps track of the progress of moving them.
+type planner struct {
+	db     database.DB
+	store  *migration.RebalanceStore
+	logger log.Logger
+}
+
+var _ goroutine.Handler = &planner{}
+var _ goroutine.ErrorHandler = &planner{}
+
+func (p *planner) Handle(ctx context.Context) error {
+	if !conf.ExperimentalFeatures().GitServerRebalancing {
+		return nil
+	}
+
+	addrs := conf.Get().ServiceConnections().GitServers
+	if len(addrs) == 0 {
+		return nil
+	}
+
+	r, err := p.store.Latest(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case r == nil:
+		// This is the first topology we see, there is nothing to move yet.
+		_, err := p.store.Record(ctx, addrs)
+		return err
+
+	case !r.Finished():
+		// Changes of the topology while a rebalance is in progress are picked up once it
+		// finished. Until then, clients keep routing with the addresses of the rebalance.
+		r, err := p.store.Refresh(ctx, r.ID)
+		if err != nil {
+			return err
+		}
+		if r.Finished() {
+			p.logger.Info("finished moving repositories between gitserver instances",
+				log.Int("moved", r.MovedRepos),
+				log.Int("failed", r.FailedRepos))
+		}
+		return nil
+
+	case r.Settled(addrs):
+		return nil
+	}
+
+	repos, err := p.db.Repos().ListMinimalRepos(ctx, database.ReposListOptions{})
+	if err != nil {
+		return err
+	}
+
+	moves, err := computeMoves(repos, r.TargetAddrs, addrs, conf.ExperimentalFeatures().GitServerPinnedRepos, func(repo api.RepoName, addrs []string) (string, error) {
+		return gitserver.ShardAddrForRepo(ctx, p.db, repo, addrs)
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.store.Start(ctx, r.TargetAddrs, addrs, moves); err != nil {
+		return err
+	}
+	p.logger.Info("gitserver instances changed, moving repositories",
+		log.Strings("previous", r.TargetAddrs),
+		log.Strings("target", addrs),
+		log.Int("repos", len(moves)))
+	return nil
+}
+
+func (p *planner) HandleError(err error) {
+	p.logger.Error("failed to plan gitserver rebalance", log.Error(err))
+}
+
+// computeMoves returns the repositories whose owner in target differs from their owner in
+// previous. Pinned repositories never move. Repositories owned by an instance which is not part
+// of target anymore are skipped as well: there is nothing left to copy them from, so their new
+// owner clones them from the code host on demand.
+func computeMoves(repos []types.MinimalRepo, previous, target []string, pinned map[string]string, owner func(api.RepoName, []string) (string, error)) ([]migration.RebalanceMove, error) {
+	exists := make(map[string]bool, len(target))
+	for _, addr := range target {
+		exists[addr] = true
+	}
+
+	var moves []migration.RebalanceMove
+	for _, repo := range repos {
+		if _, ok := pinned[string(protocol.NormalizeRepo(repo.Name))]; ok {
+			continue
+		}
+
+		from, err := owner(repo.Name, previous)
+		if err != nil {
+			return nil, err
+		}
+		if !exists[from] {
+			continue
+		}
+		to, err := owner(repo.Name, target)
+		if err != nil {
+			return nil, err
+		}
+		if from == to {
+			continue
+		}
+
+		moves = append(moves, migration.RebalanceMove{
+			RepoID: repo.ID,
+			Name:   repo.Name,
+			From:   from,
+			To:     to,
+		})
+	}
+	return moves, nil
+}
@@ -0,0 +1,57 @@
+package rebalancer
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/migration"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestComputeMoves(t *testing.T) {
+	repos := []types.MinimalRepo{
+		{ID: 1, Name: "github.com/foo/stays"},
+		{ID: 2, Name: "github.com/foo/moves"},
+		{ID: 3, Name: "github.com/foo/pinned"},
+		{ID: 4, Name: "github.com/foo/orphaned"},
+	}
+
+	// owner assigns every repo a fixed address per topology, based on its name.
+	owners := map[string]map[api.RepoName]string{
+		"previous": {
+			"github.com/foo/stays":    "gitserver-0",
+			"github.com/foo/moves":    "gitserver-0",
+			"github.com/foo/pinned":   "gitserver-0",
+			"github.com/foo/orphaned": "gitserver-1",
+		},
+		"target": {
+			"github.com/foo/stays":    "gitserver-0",
+			"github.com/foo/moves":    "gitserver-2",
+			"github.com/foo/pinned":   "gitserver-2",
+			"github.com/foo/orphaned": "gitserver-2",
+		},
+	}
+	previous := []string{"gitserver-0", "gitserver-1"}
+	target := []string{"gitserver-0", "gitserver-2"}
+	owner := func(repo api.RepoName, addrs []string) (string, error) {
+		if addrs[1] == "gitserver-1" {
+			return owners["previous"][repo], nil
+		}
+		return owners["target"][repo], nil
+	}
+	pinned := map[string]string{"github.com/foo/pinned": "gitserver-0"}
+
+	moves, err := computeMoves(repos, previous, target, pinned, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []migration.RebalanceMove{
+		{RepoID: 2, Name: "github.com/foo/moves", From: "gitserver-0", To: "gitserver-2"},
+	}
+	if diff := cmp.Diff(want, moves); diff != "" {
+		t.Errorf("unexpected moves (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,147 @@
+package rebalancer
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"golang.org/x/time/rate"
+
+	"github.com/sourcegraph/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil"
+	dbworkerstore "github.com/sourcegraph/sourcegraph/internal/workerutil/dbworker/store"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// relocatorJob is a record of the gitserver_relocator_jobs table: a repository which has to be
+// copied from one gitserver instance to another.
+type relocatorJob struct {
+	ID             int
+	RepoID         api.RepoID
+	RepoName       api.RepoName
+	SourceHostname string
+	DestHostname   string
+	DeleteSource   bool
+}
+
+func (j *relocatorJob) RecordID() int {
+	return j.ID
+}
+
+func scanFirstRelocatorJob(rows *sql.Rows, queryErr error) (_ workerutil.Record, exists bool, err error) {
+	if queryErr != nil {
+		return nil, false, queryErr
+	}
+	defer func() { err = basestore.CloseRows(rows, err) }()
+
+	if !rows.Next() {
+		return nil, false, nil
+	}
+
+	var j relocatorJob
+	if err := rows.Scan(&j.ID, &j.RepoID, &j.RepoName, &j.SourceHostname, &j.DestHostname, &j.DeleteSource); err != nil {
+		return nil, false, err
+	}
+	return &j, true, nil
+}
+
+// newRelocatorStore creates a store that reads and writes to the gitserver_relocator_jobs
+// table. Jobs are dequeued in the order they were enqueued, which is the order of the
+// repository names for jobs of a rebalance.
+func newRelocatorStore(s basestore.ShareableStore) dbworkerstore.Store {
+	return dbworkerstore.New(s.Handle(), dbworkerstore.Options{
+		Name:      "gitserver_relocator_jobs_store",
+		TableName: "gitserver_relocator_jobs",
+		ViewName:  "gitserver_relocator_jobs_with_repo_name j",
+		ColumnExpressions: []*sqlf.Query{
+			sqlf.Sprintf("j.id"),
+			sqlf.Sprintf("j.repo_id"),
+			sqlf.Sprintf("j.repo_name"),
+			sqlf.Sprintf("j.source_hostname"),
+			sqlf.Sprintf("j.dest_hostname"),
+			sqlf.Sprintf("j.delete_source"),
+		},
+		Scan:              scanFirstRelocatorJob,
+		OrderByExpression: sqlf.Sprintf("j.id"),
+		StalledMaxAge:     time.Minute,
+		RetryAfter:        time.Minute,
+		MaxNumRetries:     3,
+	})
+}
+
+// maxVerifyAttempts is how often the refs of a copy are compared to the refs of its source
+// before the copy is considered broken.
+const maxVerifyAttempts = 3
+
+// relocator copies a repository to its new gitserver instance and verifies the copy.
+type relocator struct {
+	client  gitserver.Client
+	limiter *rate.Limiter
+}
+
+var _ workerutil.Handler = &relocator{}
+
+func (h *relocator) Handle(ctx context.Context, logger log.Logger, record workerutil.Record) error {
+	job := record.(*relocatorJob)
+
+	if err := h.limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	// The destination clones the repository from the source instance if it does not have a
+	// clone yet. Otherwise it fetches the repository from the code host.
+	resp, err := h.client.RequestRepoMigrate(ctx, job.RepoName, job.SourceHostname, job.DestHostname)
+	if err != nil {
+		return errors.Wrapf(err, "failed to copy repository from %s to %s", job.SourceHostname, job.DestHostname)
+	}
+	if resp.Error != "" {
+		return errors.Newf("failed to copy repository from %s to %s: %s", job.SourceHostname, job.DestHostname, resp.Error)
+	}
+
+	if err := h.verify(ctx, job); err != nil {
+		return err
+	}
+	logger.Debug("copied repository", log.String("repo", string(job.RepoName)), log.String("from", job.SourceHostname), log.String("to", job.DestHostname))
+
+	if job.DeleteSource {
+		if err := h.client.RemoveFrom(ctx, job.RepoName, job.SourceHostname); err != nil {
+			return errors.Wrapf(err, "failed to remove repository from %s", job.SourceHostname)
+		}
+	}
+	return nil
+}
+
+// verify checks that the copy on the destination has the same refs as the source. The source
+// keeps fetching from the code host while the repository is copied, so if they differ both
+// fetch the repository once more before the refs are compared again.
+func (h *relocator) verify(ctx context.Context, job *relocatorJob) error {
+	for attempt := 1; ; attempt++ {
+		source, err := h.client.RefsChecksumFrom(ctx, job.RepoName, job.SourceHostname)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read refs from %s", job.SourceHostname)
+		}
+		dest, err := h.client.RefsChecksumFrom(ctx, job.RepoName, job.DestHostname)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read refs from %s", job.DestHostname)
+		}
+		if source == dest {
+			return nil
+		}
+		if attempt == maxVerifyAttempts {
+			return errors.Newf("refs on %s do not match refs on %s", job.DestHostname, job.SourceHostname)
+		}
+
+		// Until this job completed, the repository is routed to the source instance.
+		if _, err := h.client.RequestRepoUpdate(ctx, job.RepoName, 0); err != nil {
+			return errors.Wrapf(err, "failed to update repository on %s", job.SourceHostname)
+		}
+		if _, err := h.client.RequestRepoMigrate(ctx, job.RepoName, job.SourceHostname, job.DestHostname); err != nil {
+			return errors.Wrapf(err, "failed to update repository on %s", job.DestHostname)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package rebalancer
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/time/rate"
+
+	"github.com/sourcegraph/log/logtest"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+func TestRelocator(t *testing.T) {
+	job := &relocatorJob{
+		ID:             1,
+		RepoName:       "github.com/foo/bar",
+		SourceHostname: "gitserver-0",
+		DestHostname:   "gitserver-1",
+	}
+
+	newClient := func(checksums map[string][]string) *gitserver.MockClient {
+		client := gitserver.NewMockClient()
+		client.RequestRepoMigrateFunc.SetDefaultReturn(&protocol.RepoUpdateResponse{}, nil)
+		client.RequestRepoUpdateFunc.SetDefaultReturn(&protocol.RepoUpdateResponse{}, nil)
+		client.RefsChecksumFromFunc.SetDefaultHook(func(_ context.Context, _ api.RepoName, from string) (string, error) {
+			sum := checksums[from][0]
+			if len(checksums[from]) > 1 {
+				checksums[from] = checksums[from][1:]
+			}
+			return sum, nil
+		})
+		return client
+	}
+
+	t.Run("verified copy", func(t *testing.T) {
+		client := newClient(map[string][]string{
+			"gitserver-0": {"abc"},
+			"gitserver-1": {"abc"},
+		})
+		h := &relocator{client: client, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+		if err := h.Handle(context.Background(), logtest.Scoped(t), job); err != nil {
+			t.Fatal(err)
+		}
+		if n := len(client.RequestRepoMigrateFunc.History()); n != 1 {
+			t.Errorf("expected the repository to be copied once, got %d", n)
+		}
+		if n := len(client.RemoveFromFunc.History()); n != 0 {
+			t.Errorf("expected the source not to be removed, got %d calls", n)
+		}
+	})
+
+	t.Run("catches up", func(t *testing.T) {
+		client := newClient(map[string][]string{
+			"gitserver-0": {"new"},
+			"gitserver-1": {"old", "new"},
+		})
+		h := &relocator{client: client, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+		if err := h.Handle(context.Background(), logtest.Scoped(t), job); err != nil {
+			t.Fatal(err)
+		}
+		if n := len(client.RequestRepoMigrateFunc.History()); n != 2 {
+			t.Errorf("expected the destination to fetch once more, got %d requests", n)
+		}
+		if n := len(client.RequestRepoUpdateFunc.History()); n != 1 {
+			t.Errorf("expected the source to fetch once more, got %d requests", n)
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		client := newClient(map[string][]string{
+			"gitserver-0": {"abc"},
+			"gitserver-1": {"def"},
+		})
+		h := &relocator{client: client, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+		if err := h.Handle(context.Background(), logtest.Scoped(t), job); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+
+	t.Run("copy failed", func(t *testing.T) {
+		client := newClient(nil)
+		client.RequestRepoMigrateFunc.SetDefaultReturn(&protocol.RepoUpdateResponse{Error: "boom"}, nil)
+		h := &relocator{client: client, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+		if err := h.Handle(context.Background(), logtest.Scoped(t), job); err == nil {
+			t.Fatal("expected an error")
+		}
+		if n := len(client.RefsChecksumFromFunc.History()); n != 0 {
+			t.Errorf("expected no verification, got %d calls", n)
+		}
+	})
+
+	t.Run("delete source", func(t *testing.T) {
+		client := newClient(map[string][]string{
+			"gitserver-0": {"abc"},
+			"gitserver-1": {"abc"},
+		})
+		h := &relocator{client: client, limiter: rate.NewLimiter(rate.Inf, 1)}
+
+		job := *job
+		job.DeleteSource = true
+		if err := h.Handle(context.Background(), logtest.Scoped(t), &job); err != nil {
+			t.Fatal(err)
+		}
+		if history := client.RemoveFromFunc.History(); len(history) != 1 || history[0].Arg2 != "gitserver-0" {
+			t.Errorf("expected the repository to be removed from the source, got %v", history)
+		}
+	})
+}
@@ -0,0 +1,14 @@
+// Package migrators exposes the out-of-band migrators of the worker to other services, such
+// as the migrator, which runs them inline during multi-version upgrades.
+package migrators
+
+import (
+	"github.com/sourcegraph/sourcegraph/cmd/worker/internal/migrations/migrators"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/oobmigration"
+)
+
+// RegisterOSSMigrations registers the OSS out-of-band migrators with the given runner.
+func RegisterOSSMigrations(db database.DB, outOfBandMigrationRunner *oobmigration.Runner) error {
+	return migrators.RegisterOSSMigrations(db, outOfBandMigrationRunner)
+}
@@ -19,6 +19,7 @@ allowed_prefix=(
   github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend
   github.com/sourcegraph/sourcegraph/enterprise/cmd/worker
   github.com/sourcegraph/sourcegraph/enterprise/cmd/repo-updater
+  github.com/sourcegraph/sourcegraph/enterprise/cmd/migrator
   github.com/sourcegraph/sourcegraph/enterprise/cmd/precise-code-intel-
   github.com/sourcegraph/sourcegraph/enterprise/cmd/symbols
   # Doesn't connect but uses db internals for use with sqlite
@@ -0,0 +1,107 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+type LSIFStore interface {
+	Export(ctx context.Context, bundleID int, root string, toolInfo protocol.ToolInfo, w io.Writer) error
+}
+
+type ExportHandler struct {
+	db         database.DB
+	dbStore    DBStore
+	lsifStore  LSIFStore
+	operations *Operations
+}
+
+// NewExportHandler creates a handler that re-serializes the processed data of an upload
+// as an LSIF dump.
+func NewExportHandler(db database.DB, dbStore DBStore, lsifStore LSIFStore, operations *Operations) http.Handler {
+	handler := &ExportHandler{
+		db:         db,
+		dbStore:    dbStore,
+		lsifStore:  lsifStore,
+		operations: operations,
+	}
+
+	return http.HandlerFunc(handler.handleExport)
+}
+
+var errUploadNotProcessed = errors.New("upload has not been processed")
+
+// GET /uploads/{id}/export
+//
+// handleExport writes the processed data of the upload with the given identifier to the response
+// as newline-delimited LSIF. Uploads that are not visible to the current user, or that have not
+// completed processing, cannot be exported.
+func (h *ExportHandler) handleExport(w http.ResponseWriter, r *http.Request) {
+	statusCode, err := func() (statusCode int, err error) {
+		ctx, trace, endObservation := h.operations.handleExport.With(r.Context(), &err, observation.Args{})
+		defer func() {
+			endObservation(1, observation.Args{LogFields: []log.Field{
+				log.Int("statusCode", statusCode),
+			}})
+		}()
+
+		uploadID, err := strconv.Atoi(mux.Vars(r)["UploadID"])
+		if err != nil {
+			return http.StatusBadRequest, errors.Errorf("illegal upload id %q", mux.Vars(r)["UploadID"])
+		}
+		trace.Log(log.Int("uploadID", uploadID))
+
+		// 🚨 SECURITY: GetUploadByID filters uploads by the repository permissions of the
+		// current user; we additionally ensure the repository itself is visible.
+		upload, exists, err := h.dbStore.GetUploadByID(ctx, uploadID)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		if !exists {
+			return http.StatusNotFound, errors.Errorf("upload %d not found", uploadID)
+		}
+		if _, err := h.db.Repos().Get(ctx, api.RepoID(upload.RepositoryID)); err != nil {
+			if errcode.IsNotFound(err) {
+				return http.StatusNotFound, errors.Errorf("upload %d not found", uploadID)
+			}
+			return http.StatusInternalServerError, err
+		}
+		if upload.State != "completed" {
+			return http.StatusConflict, errUploadNotProcessed
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="upload-%d.lsif"`, upload.ID))
+
+		toolInfo := protocol.ToolInfo{Name: upload.Indexer, Version: upload.IndexerVersion}
+		if err := h.lsifStore.Export(ctx, upload.ID, upload.Root, toolInfo, w); err != nil {
+			// The response has already been partially written; the status code is only
+			// reported for observability.
+			log15.Error("codeintel.httpapi: failed to export upload", "uploadID", upload.ID, "error", err)
+			return http.StatusInternalServerError, nil
+		}
+
+		return http.StatusOK, nil
+	}()
+	if err != nil {
+		if statusCode >= 500 {
+			log15.Error("codeintel.httpapi: failed to export upload", "error", err)
+		}
+
+		http.Error(w, fmt.Sprintf("failed to export upload: %s", err.Error()), statusCode)
+	}
+}
@@ -0,0 +1,106 @@
+package httpapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
+
+	store "github.com/sourcegraph/sourcegraph/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+)
+
+func TestHandleExport(t *testing.T) {
+	mockDBStore := NewMockDBStore()
+	mockDBStore.GetUploadByIDFunc.SetDefaultReturn(store.Upload{
+		ID:             42,
+		RepositoryID:   50,
+		Root:           "proj/",
+		Indexer:        "lsif-go",
+		IndexerVersion: "1.2.3",
+		State:          "completed",
+	}, true, nil)
+
+	mockLSIFStore := NewMockLSIFStore()
+	mockLSIFStore.ExportFunc.SetDefaultHook(func(_ context.Context, _ int, _ string, _ protocol.ToolInfo, w io.Writer) error {
+		_, err := io.WriteString(w, "{}\n")
+		return err
+	})
+
+	w := httptest.NewRecorder()
+	r := mux.SetURLVars(httptest.NewRequest("GET", "/uploads/42/export", nil), map[string]string{"UploadID": "42"})
+	NewExportHandler(newRepoVisibleDB(), mockDBStore, mockLSIFStore, NewOperations(&observation.TestContext)).ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code. want=%d have=%d", http.StatusOK, w.Code)
+	}
+	if body := w.Body.String(); body != "{}\n" {
+		t.Errorf("unexpected body %q", body)
+	}
+	if disposition := w.Header().Get("Content-Disposition"); disposition != `attachment; filename="upload-42.lsif"` {
+		t.Errorf("unexpected content disposition %q", disposition)
+	}
+
+	if len(mockLSIFStore.ExportFunc.History()) != 1 {
+		t.Fatalf("unexpected number of Export calls. want=%d have=%d", 1, len(mockLSIFStore.ExportFunc.History()))
+	}
+	call := mockLSIFStore.ExportFunc.History()[0]
+	if call.Arg1 != 42 {
+		t.Errorf("unexpected bundle id. want=%d have=%d", 42, call.Arg1)
+	}
+	if call.Arg2 != "proj/" {
+		t.Errorf("unexpected root. want=%q have=%q", "proj/", call.Arg2)
+	}
+	if diff := cmp.Diff(protocol.ToolInfo{Name: "lsif-go", Version: "1.2.3"}, call.Arg3); diff != "" {
+		t.Errorf("unexpected tool info (-want +got):\n%s", diff)
+	}
+}
+
+func TestHandleExportUnknownUpload(t *testing.T) {
+	mockDBStore := NewMockDBStore()
+	mockLSIFStore := NewMockLSIFStore()
+
+	w := httptest.NewRecorder()
+	r := mux.SetURLVars(httptest.NewRequest("GET", "/uploads/42/export", nil), map[string]string{"UploadID": "42"})
+	NewExportHandler(newRepoVisibleDB(), mockDBStore, mockLSIFStore, NewOperations(&observation.TestContext)).ServeHTTP(w, r)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unexpected status code. want=%d have=%d", http.StatusNotFound, w.Code)
+	}
+	if len(mockLSIFStore.ExportFunc.History()) != 0 {
+		t.Errorf("unexpected Export calls")
+	}
+}
+
+func TestHandleExportUnprocessedUpload(t *testing.T) {
+	mockDBStore := NewMockDBStore()
+	mockDBStore.GetUploadByIDFunc.SetDefaultReturn(store.Upload{ID: 42, RepositoryID: 50, State: "queued"}, true, nil)
+	mockLSIFStore := NewMockLSIFStore()
+
+	w := httptest.NewRecorder()
+	r := mux.SetURLVars(httptest.NewRequest("GET", "/uploads/42/export", nil), map[string]string{"UploadID": "42"})
+	NewExportHandler(newRepoVisibleDB(), mockDBStore, mockLSIFStore, NewOperations(&observation.TestContext)).ServeHTTP(w, r)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("unexpected status code. want=%d have=%d", http.StatusConflict, w.Code)
+	}
+	if len(mockLSIFStore.ExportFunc.History()) != 0 {
+		t.Errorf("unexpected Export calls")
+	}
+}
+
+func newRepoVisibleDB() database.DB {
+	repos := database.NewMockRepoStore()
+	repos.GetFunc.SetDefaultReturn(&types.Repo{ID: 50}, nil)
+
+	db := database.NewMockDB()
+	db.ReposFunc.SetDefaultReturn(repos)
+	return db
+}
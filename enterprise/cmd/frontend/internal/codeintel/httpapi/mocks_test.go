@@ -8,10 +8,12 @@ package httpapi
 
 import (
 	"context"
+	"io"
 	"sync"
 
 	dbstore "github.com/sourcegraph/sourcegraph/internal/codeintel/stores/dbstore"
 	github "github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	protocol "github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
 )
 
 // MockDBStore is a mock implementation of the DBStore interface (from the
@@ -1190,3 +1192,160 @@ func (c GitHubClientListInstallationRepositoriesFuncCall) Args() []interface{} {
 func (c GitHubClientListInstallationRepositoriesFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1, c.Result2, c.Result3}
 }
+
+// MockLSIFStore is a mock implementation of the LSIFStore interface (from
+// the package
+// github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/codeintel/httpapi)
+// used for unit testing.
+type MockLSIFStore struct {
+	// ExportFunc is an instance of a mock function object controlling the
+	// behavior of the method Export.
+	ExportFunc *LSIFStoreExportFunc
+}
+
+// NewMockLSIFStore creates a new mock of the LSIFStore interface. All
+// methods return zero values for all results, unless overwritten.
+func NewMockLSIFStore() *MockLSIFStore {
+	return &MockLSIFStore{
+		ExportFunc: &LSIFStoreExportFunc{
+			defaultHook: func(context.Context, int, string, protocol.ToolInfo, io.Writer) (r0 error) {
+				return
+			},
+		},
+	}
+}
+
+// NewStrictMockLSIFStore creates a new mock of the LSIFStore interface. All
+// methods panic on invocation, unless overwritten.
+func NewStrictMockLSIFStore() *MockLSIFStore {
+	return &MockLSIFStore{
+		ExportFunc: &LSIFStoreExportFunc{
+			defaultHook: func(context.Context, int, string, protocol.ToolInfo, io.Writer) error {
+				panic("unexpected invocation of MockLSIFStore.Export")
+			},
+		},
+	}
+}
+
+// NewMockLSIFStoreFrom creates a new mock of the MockLSIFStore interface.
+// All methods delegate to the given implementation, unless overwritten.
+func NewMockLSIFStoreFrom(i LSIFStore) *MockLSIFStore {
+	return &MockLSIFStore{
+		ExportFunc: &LSIFStoreExportFunc{
+			defaultHook: i.Export,
+		},
+	}
+}
+
+// LSIFStoreExportFunc describes the behavior when the Export method of the
+// parent MockLSIFStore instance is invoked.
+type LSIFStoreExportFunc struct {
+	defaultHook func(context.Context, int, string, protocol.ToolInfo, io.Writer) error
+	hooks       []func(context.Context, int, string, protocol.ToolInfo, io.Writer) error
+	history     []LSIFStoreExportFuncCall
+	mutex       sync.Mutex
+}
+
+// Export delegates to the next hook function in the queue and stores the
+// parameter and result values of this invocation.
+func (m *MockLSIFStore) Export(v0 context.Context, v1 int, v2 string, v3 protocol.ToolInfo, v4 io.Writer) error {
+	r0 := m.ExportFunc.nextHook()(v0, v1, v2, v3, v4)
+	m.ExportFunc.appendCall(LSIFStoreExportFuncCall{v0, v1, v2, v3, v4, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the Export method of the
+// parent MockLSIFStore instance is invoked and the hook queue is empty.
+func (f *LSIFStoreExportFunc) SetDefaultHook(hook func(context.Context, int, string, protocol.ToolInfo, io.Writer) error) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// Export method of the parent MockLSIFStore instance invokes the hook at
+// the front of the queue and discards it. After the queue is empty, the
+// default hook function is invoked for any future action.
+func (f *LSIFStoreExportFunc) PushHook(hook func(context.Context, int, string, protocol.ToolInfo, io.Writer) error) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *LSIFStoreExportFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int, string, protocol.ToolInfo, io.Writer) error {
+		return r0
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *LSIFStoreExportFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int, string, protocol.ToolInfo, io.Writer) error {
+		return r0
+	})
+}
+
+func (f *LSIFStoreExportFunc) nextHook() func(context.Context, int, string, protocol.ToolInfo, io.Writer) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *LSIFStoreExportFunc) appendCall(r0 LSIFStoreExportFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of LSIFStoreExportFuncCall objects describing
+// the invocations of this function.
+func (f *LSIFStoreExportFunc) History() []LSIFStoreExportFuncCall {
+	f.mutex.Lock()
+	history := make([]LSIFStoreExportFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// LSIFStoreExportFuncCall is an object that describes an invocation of
+// method Export on an instance of MockLSIFStore.
+type LSIFStoreExportFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 protocol.ToolInfo
+	// Arg4 is the value of the 5th argument passed to this method
+	// invocation.
+	Arg4 io.Writer
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c LSIFStoreExportFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c LSIFStoreExportFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
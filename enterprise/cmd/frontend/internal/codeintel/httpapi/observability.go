@@ -9,6 +9,7 @@ import (
 
 type Operations struct {
 	authMiddleware                 *observation.Operation
+	handleExport                   *observation.Operation
 	handleEnqueue                  *observation.Operation
 	handleEnqueueSinglePayload     *observation.Operation
 	handleEnqueueMultipartSetup    *observation.Operation
@@ -34,6 +35,7 @@ func NewOperations(observationContext *observation.Context) *Operations {
 
 	return &Operations{
 		authMiddleware:                 op("authMiddleware"),
+		handleExport:                   op("HandleExport"),
 		handleEnqueue:                  op("HandleEnqueue"),
 		handleEnqueueSinglePayload:     op("handleEnqueueSinglePayload"),
 		handleEnqueueMultipartSetup:    op("handleEnqueueMultipartSetup"),
@@ -35,6 +35,7 @@ func Init(ctx context.Context, db database.DB, config *Config, enterpriseService
 
 	enterpriseServices.CodeIntelResolver = resolver
 	enterpriseServices.NewCodeIntelUploadHandler = newUploadHandler(services)
+	enterpriseServices.NewCodeIntelExportHandler = func() http.Handler { return services.ExportHandler }
 	return nil
 }
 
@@ -0,0 +1,297 @@
+package resolvers
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/opentracing/opentracing-go/log"
+
+	store "github.com/sourcegraph/sourcegraph/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/stores/lsifstore"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// APIDiff describes the change in exported symbols between two processed uploads for the
+// same repository, root, and indexer at different commits.
+type APIDiff struct {
+	BaseUpload store.Upload
+	HeadUpload store.Upload
+	Added      []lsifstore.ExportedSymbol
+	Removed    []lsifstore.ExportedSymbol
+	Changed    []ChangedSymbol
+}
+
+// ChangedSymbol pairs the base and head versions of an exported symbol whose signature
+// differs between two uploads.
+type ChangedSymbol struct {
+	Base lsifstore.ExportedSymbol
+	Head lsifstore.ExportedSymbol
+}
+
+// APIDiff compares the exported symbols of the uploads at the base and head commits of the given
+// repository. Uploads are paired by root and indexer; uploads without a counterpart at the other
+// commit are ignored. If root or indexer are non-empty, only uploads matching those values are
+// compared.
+func (r *resolver) APIDiff(ctx context.Context, repositoryID int, baseCommit, headCommit, root, indexer string) (_ []APIDiff, err error) {
+	ctx, _, endObservation := r.operations.apiDiff.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("repositoryID", repositoryID),
+		log.String("baseCommit", baseCommit),
+		log.String("headCommit", headCommit),
+		log.String("root", root),
+		log.String("indexer", indexer),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	baseDumps, err := r.dumpsAtCommit(ctx, repositoryID, baseCommit, root, indexer)
+	if err != nil {
+		return nil, err
+	}
+	headDumps, err := r.dumpsAtCommit(ctx, repositoryID, headCommit, root, indexer)
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs [][2]int
+	for key, baseDump := range baseDumps {
+		if headDump, ok := headDumps[key]; ok {
+			pairs = append(pairs, [2]int{baseDump.ID, headDump.ID})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i][0] < pairs[j][0] })
+
+	ids := make([]int, 0, len(pairs)*2)
+	for _, pair := range pairs {
+		ids = append(ids, pair[0], pair[1])
+	}
+	uploads, err := r.dbStore.GetUploadsByIDs(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+	uploadsByID := make(map[int]store.Upload, len(uploads))
+	for _, upload := range uploads {
+		uploadsByID[upload.ID] = upload
+	}
+
+	diffs := make([]APIDiff, 0, len(pairs))
+	for _, pair := range pairs {
+		baseUpload, ok1 := uploadsByID[pair[0]]
+		headUpload, ok2 := uploadsByID[pair[1]]
+		if !ok1 || !ok2 {
+			// Upload was deleted between queries
+			continue
+		}
+
+		baseSymbols, err := r.lsifStore.ExportedSymbols(ctx, baseUpload.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "lsifStore.ExportedSymbols")
+		}
+		headSymbols, err := r.lsifStore.ExportedSymbols(ctx, headUpload.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "lsifStore.ExportedSymbols")
+		}
+
+		added, removed, changed := diffExportedSymbols(baseSymbols, headSymbols)
+		diffs = append(diffs, APIDiff{
+			BaseUpload: baseUpload,
+			HeadUpload: headUpload,
+			Added:      added,
+			Removed:    removed,
+			Changed:    changed,
+		})
+	}
+
+	return diffs, nil
+}
+
+// apiDiffDependentsPageSize is the number of package references read from the database at
+// once when searching for the dependents of an API diff.
+const apiDiffDependentsPageSize = 100
+
+// APIDiffDependents returns the names of the other repositories whose uploads reference a
+// package providing a symbol that was removed or changed by the given diff. References are
+// read from the uploads visible at the head commit of the diff and from the uploads at the
+// tip of the default branch of other repositories, which the caller is authorized to see.
+func (r *resolver) APIDiffDependents(ctx context.Context, diff APIDiff) (_ []string, err error) {
+	ctx, _, endObservation := r.operations.apiDiffDependents.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("baseUploadID", diff.BaseUpload.ID),
+		log.Int("headUploadID", diff.HeadUpload.ID),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	seen := map[precise.QualifiedMonikerData]struct{}{}
+	var monikers []precise.QualifiedMonikerData
+	addMoniker := func(symbol lsifstore.ExportedSymbol) {
+		if symbol.Package.Name == "" {
+			return
+		}
+
+		moniker := precise.QualifiedMonikerData{
+			MonikerData:            precise.MonikerData{Scheme: symbol.Scheme},
+			PackageInformationData: symbol.Package,
+		}
+		if _, ok := seen[moniker]; !ok {
+			seen[moniker] = struct{}{}
+			monikers = append(monikers, moniker)
+		}
+	}
+	for _, symbol := range diff.Removed {
+		addMoniker(symbol)
+	}
+	for _, symbol := range diff.Changed {
+		addMoniker(symbol.Base)
+	}
+	if len(monikers) == 0 {
+		return nil, nil
+	}
+
+	dumpIDs, err := r.referencingDumpIDs(ctx, diff.HeadUpload.RepositoryID, diff.HeadUpload.Commit, monikers)
+	if err != nil {
+		return nil, err
+	}
+	dumps, err := r.dbStore.GetDumpsByIDs(ctx, dumpIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "dbStore.GetDumpsByIDs")
+	}
+
+	repositoryNames := map[string]struct{}{}
+	for _, dump := range dumps {
+		if dump.RepositoryID != diff.BaseUpload.RepositoryID {
+			repositoryNames[dump.RepositoryName] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(repositoryNames))
+	for name := range repositoryNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// referencingDumpIDs returns the identifiers of the dumps that reference any of the given
+// packages and are visible from the given repository and commit.
+func (r *resolver) referencingDumpIDs(ctx context.Context, repositoryID int, commit string, monikers []precise.QualifiedMonikerData) (_ []int, err error) {
+	seen := map[int]struct{}{}
+	var ids []int
+
+	for offset := 0; ; offset += apiDiffDependentsPageSize {
+		scanner, _, err := r.dbStore.ReferenceIDs(ctx, repositoryID, commit, monikers, apiDiffDependentsPageSize, offset)
+		if err != nil {
+			return nil, errors.Wrap(err, "dbStore.ReferenceIDs")
+		}
+
+		n := 0
+		for {
+			reference, exists, err := scanner.Next()
+			if err != nil {
+				return nil, errors.Append(errors.Wrap(err, "dbStore.ReferenceIDs.Next"), scanner.Close())
+			}
+			if !exists {
+				break
+			}
+			n++
+
+			if _, ok := seen[reference.DumpID]; !ok {
+				seen[reference.DumpID] = struct{}{}
+				ids = append(ids, reference.DumpID)
+			}
+		}
+		if err := scanner.Close(); err != nil {
+			return nil, errors.Wrap(err, "dbStore.ReferenceIDs.Close")
+		}
+
+		if n < apiDiffDependentsPageSize {
+			return ids, nil
+		}
+	}
+}
+
+type dumpKey struct {
+	root    string
+	indexer string
+}
+
+// dumpsAtCommit returns the most recently processed dump for each root and indexer that was
+// uploaded for exactly the given commit.
+func (r *resolver) dumpsAtCommit(ctx context.Context, repositoryID int, commit, root, indexer string) (map[dumpKey]store.Dump, error) {
+	dumps, err := r.dbStore.FindClosestDumps(ctx, repositoryID, commit, root, false, indexer)
+	if err != nil {
+		return nil, errors.Wrap(err, "dbStore.FindClosestDumps")
+	}
+
+	dumpsByKey := make(map[dumpKey]store.Dump, len(dumps))
+	for _, dump := range dumps {
+		if dump.Commit != commit || (root != "" && dump.Root != root) {
+			continue
+		}
+
+		// Dumps are ordered by most-recently-finished
+		key := dumpKey{root: dump.Root, indexer: dump.Indexer}
+		if _, ok := dumpsByKey[key]; !ok {
+			dumpsByKey[key] = dump
+		}
+	}
+
+	return dumpsByKey, nil
+}
+
+// diffExportedSymbols compares two sets of exported symbols by moniker. A symbol present
+// in both sets is considered changed if its hover signature differs.
+func diffExportedSymbols(base, head []lsifstore.ExportedSymbol) (added, removed []lsifstore.ExportedSymbol, changed []ChangedSymbol) {
+	key := func(symbol lsifstore.ExportedSymbol) string {
+		return symbol.Scheme + ":" + symbol.Identifier
+	}
+
+	baseByKey := make(map[string]lsifstore.ExportedSymbol, len(base))
+	for _, symbol := range base {
+		baseByKey[key(symbol)] = symbol
+	}
+	headByKey := make(map[string]lsifstore.ExportedSymbol, len(head))
+	for _, symbol := range head {
+		headByKey[key(symbol)] = symbol
+	}
+
+	for _, symbol := range head {
+		baseSymbol, ok := baseByKey[key(symbol)]
+		if !ok {
+			added = append(added, symbol)
+		} else if HoverSignature(baseSymbol.HoverText) != HoverSignature(symbol.HoverText) {
+			changed = append(changed, ChangedSymbol{Base: baseSymbol, Head: symbol})
+		}
+	}
+	for _, symbol := range base {
+		if _, ok := headByKey[key(symbol)]; !ok {
+			removed = append(removed, symbol)
+		}
+	}
+
+	return added, removed, changed
+}
+
+// HoverSignature returns the signature portion of the given hover text. Indexers conventionally
+// emit the signature of a symbol as the first fenced code block of the hover text, followed by
+// its documentation. If the hover text does not begin with a code block, the entire trimmed text
+// is returned.
+func HoverSignature(hoverText string) string {
+	text := strings.TrimSpace(hoverText)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+
+	// Skip the fence and its language identifier
+	newline := strings.Index(text, "\n")
+	if newline == -1 {
+		return text
+	}
+	body := text[newline+1:]
+
+	if end := strings.Index(body, "```"); end != -1 {
+		body = body[:end]
+	}
+
+	return strings.TrimSpace(body)
+}
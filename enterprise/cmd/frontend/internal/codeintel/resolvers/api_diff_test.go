@@ -0,0 +1,162 @@
+package resolvers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	store "github.com/sourcegraph/sourcegraph/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/stores/lsifstore"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/stores/shared"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+)
+
+func TestAPIDiff(t *testing.T) {
+	baseCommit := "deadbeef01deadbeef02deadbeef03deadbeef04"
+	headCommit := "deadbeef05deadbeef06deadbeef07deadbeef08"
+
+	mockDBStore := NewMockDBStore()
+	mockDBStore.FindClosestDumpsFunc.SetDefaultHook(func(ctx context.Context, repositoryID int, commit, path string, rootMustEnclosePath bool, indexer string) ([]store.Dump, error) {
+		if commit == baseCommit {
+			return []store.Dump{
+				{ID: 1, Commit: baseCommit, Root: "lib/", Indexer: "lsif-go"},
+				{ID: 2, Commit: baseCommit, Root: "cmd/", Indexer: "lsif-go"},
+				{ID: 3, Commit: "cafebabe", Root: "lib/", Indexer: "lsif-go"}, // ancestor
+			}, nil
+		}
+
+		return []store.Dump{
+			{ID: 4, Commit: headCommit, Root: "lib/", Indexer: "lsif-go"},
+			{ID: 5, Commit: headCommit, Root: "lib/", Indexer: "lsif-go"}, // older
+		}, nil
+	})
+	mockDBStore.GetUploadsByIDsFunc.SetDefaultHook(func(ctx context.Context, ids ...int) ([]store.Upload, error) {
+		uploads := make([]store.Upload, 0, len(ids))
+		for _, id := range ids {
+			uploads = append(uploads, store.Upload{ID: id})
+		}
+		return uploads, nil
+	})
+
+	symbol := func(identifier, hoverText string) lsifstore.ExportedSymbol {
+		return lsifstore.ExportedSymbol{Scheme: "gomod", Identifier: identifier, HoverText: hoverText}
+	}
+
+	mockLSIFStore := NewMockLSIFStore()
+	mockLSIFStore.ExportedSymbolsFunc.SetDefaultHook(func(ctx context.Context, bundleID int) ([]lsifstore.ExportedSymbol, error) {
+		switch bundleID {
+		case 1:
+			return []lsifstore.ExportedSymbol{
+				symbol("lib:Bar", "```go\nfunc Bar()\n```\n\n---\n\nBar does bar."),
+				symbol("lib:Baz", "```go\nfunc Baz(x int)\n```"),
+				symbol("lib:Foo", "```go\nfunc Foo()\n```"),
+			}, nil
+		case 4:
+			return []lsifstore.ExportedSymbol{
+				symbol("lib:Bar", "```go\nfunc Bar()\n```\n\n---\n\nBar does bar very well."),
+				symbol("lib:Baz", "```go\nfunc Baz(x, y int)\n```"),
+				symbol("lib:Qux", "```go\nfunc Qux()\n```"),
+			}, nil
+		}
+
+		t.Fatalf("unexpected bundle %d", bundleID)
+		return nil, nil
+	})
+
+	resolver := newResolver(mockDBStore, mockLSIFStore, NewMockGitserverClient(), nil, nil, nil, nil, 50, &observation.TestContext, database.NewMockDB())
+	diffs, err := resolver.APIDiff(context.Background(), 50, baseCommit, headCommit, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []APIDiff{
+		{
+			BaseUpload: store.Upload{ID: 1},
+			HeadUpload: store.Upload{ID: 4},
+			Added:      []lsifstore.ExportedSymbol{symbol("lib:Qux", "```go\nfunc Qux()\n```")},
+			Removed:    []lsifstore.ExportedSymbol{symbol("lib:Foo", "```go\nfunc Foo()\n```")},
+			Changed: []ChangedSymbol{
+				{
+					Base: symbol("lib:Baz", "```go\nfunc Baz(x int)\n```"),
+					Head: symbol("lib:Baz", "```go\nfunc Baz(x, y int)\n```"),
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, diffs); diff != "" {
+		t.Errorf("unexpected diffs (-want +got):\n%s", diff)
+	}
+}
+
+func TestAPIDiffDependents(t *testing.T) {
+	pkg := precise.PackageInformationData{Name: "github.com/sourcegraph/lib", Version: "v1.0.0"}
+	symbol := func(identifier string) lsifstore.ExportedSymbol {
+		return lsifstore.ExportedSymbol{Scheme: "gomod", Identifier: identifier, Package: pkg}
+	}
+
+	mockDBStore := NewMockDBStore()
+	mockDBStore.ReferenceIDsFunc.SetDefaultHook(func(ctx context.Context, repositoryID int, commit string, monikers []precise.QualifiedMonikerData, limit, offset int) (store.PackageReferenceScanner, int, error) {
+		if offset > 0 {
+			return store.PackageReferenceScannerFromSlice(), 3, nil
+		}
+
+		return store.PackageReferenceScannerFromSlice(
+			shared.PackageReference{Package: shared.Package{DumpID: 10, Scheme: "gomod", Name: pkg.Name, Version: pkg.Version}},
+			shared.PackageReference{Package: shared.Package{DumpID: 11, Scheme: "gomod", Name: pkg.Name, Version: pkg.Version}},
+			shared.PackageReference{Package: shared.Package{DumpID: 12, Scheme: "gomod", Name: pkg.Name, Version: pkg.Version}},
+		), 3, nil
+	})
+	mockDBStore.GetDumpsByIDsFunc.SetDefaultHook(func(ctx context.Context, ids []int) ([]store.Dump, error) {
+		return []store.Dump{
+			{ID: 10, RepositoryID: 51, RepositoryName: "github.com/sourcegraph/b"},
+			{ID: 11, RepositoryID: 52, RepositoryName: "github.com/sourcegraph/a"},
+			{ID: 12, RepositoryID: 50, RepositoryName: "github.com/sourcegraph/lib"}, // same repository
+		}, nil
+	})
+
+	resolver := newResolver(mockDBStore, NewMockLSIFStore(), NewMockGitserverClient(), nil, nil, nil, nil, 50, &observation.TestContext, database.NewMockDB())
+	dependents, err := resolver.APIDiffDependents(context.Background(), APIDiff{
+		BaseUpload: store.Upload{ID: 1, RepositoryID: 50},
+		HeadUpload: store.Upload{ID: 4, RepositoryID: 50, Commit: "deadbeef"},
+		Added:      []lsifstore.ExportedSymbol{symbol("lib:Qux")},
+		Removed:    []lsifstore.ExportedSymbol{symbol("lib:Foo")},
+		Changed:    []ChangedSymbol{{Base: symbol("lib:Baz"), Head: symbol("lib:Baz")}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if diff := cmp.Diff([]string{"github.com/sourcegraph/a", "github.com/sourcegraph/b"}, dependents); diff != "" {
+		t.Errorf("unexpected dependents (-want +got):\n%s", diff)
+	}
+
+	history := mockDBStore.ReferenceIDsFunc.History()
+	if len(history) != 1 {
+		t.Fatalf("unexpected number of ReferenceIDs calls. want=%d have=%d", 1, len(history))
+	}
+	expectedMonikers := []precise.QualifiedMonikerData{
+		{MonikerData: precise.MonikerData{Scheme: "gomod"}, PackageInformationData: pkg},
+	}
+	if diff := cmp.Diff(expectedMonikers, history[0].Arg3); diff != "" {
+		t.Errorf("unexpected monikers (-want +got):\n%s", diff)
+	}
+}
+
+func TestHoverSignature(t *testing.T) {
+	testCases := map[string]string{
+		"":                       "",
+		"plain text":             "plain text",
+		"```go\nfunc Foo()\n```": "func Foo()",
+		"```go\ntype T struct{}\n```\n\n---\n\ndocs": "type T struct{}",
+		"```\nunterminated":                          "unterminated",
+	}
+
+	for hoverText, expected := range testCases {
+		if signature := HoverSignature(hoverText); signature != expected {
+			t.Errorf("unexpected signature for %q. want=%q have=%q", hoverText, expected, signature)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/codeintel/resolvers"
+	store "github.com/sourcegraph/sourcegraph/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/stores/lsifstore"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/batches"
+	"github.com/sourcegraph/sourcegraph/lib/batches/overridable"
+)
+
+type apiDiffResolver struct {
+	db               database.DB
+	resolver         resolvers.Resolver
+	gitserver        GitserverClient
+	diff             resolvers.APIDiff
+	prefetcher       *Prefetcher
+	locationResolver *CachedLocationResolver
+	errTracer        *observation.ErrCollector
+}
+
+func NewAPIDiffResolver(
+	db database.DB,
+	resolver resolvers.Resolver,
+	gitserver GitserverClient,
+	diff resolvers.APIDiff,
+	prefetcher *Prefetcher,
+	locationResolver *CachedLocationResolver,
+	errTracer *observation.ErrCollector,
+) gql.CodeIntelAPIDiffResolver {
+	return &apiDiffResolver{
+		db:               db,
+		resolver:         resolver,
+		gitserver:        gitserver,
+		diff:             diff,
+		prefetcher:       prefetcher,
+		locationResolver: locationResolver,
+		errTracer:        errTracer,
+	}
+}
+
+func (r *apiDiffResolver) BaseUpload() gql.LSIFUploadResolver {
+	return NewUploadResolver(r.db, r.gitserver, r.resolver, r.diff.BaseUpload, r.prefetcher, r.locationResolver, r.errTracer)
+}
+
+func (r *apiDiffResolver) HeadUpload() gql.LSIFUploadResolver {
+	return NewUploadResolver(r.db, r.gitserver, r.resolver, r.diff.HeadUpload, r.prefetcher, r.locationResolver, r.errTracer)
+}
+
+func (r *apiDiffResolver) Added() []gql.CodeIntelExportedSymbolResolver {
+	return r.symbolResolvers(r.diff.HeadUpload, r.diff.Added)
+}
+
+func (r *apiDiffResolver) Removed() []gql.CodeIntelExportedSymbolResolver {
+	return r.symbolResolvers(r.diff.BaseUpload, r.diff.Removed)
+}
+
+func (r *apiDiffResolver) Changed() []gql.CodeIntelChangedSymbolResolver {
+	resolvers := make([]gql.CodeIntelChangedSymbolResolver, 0, len(r.diff.Changed))
+	for _, symbol := range r.diff.Changed {
+		resolvers = append(resolvers, &changedSymbolResolver{
+			base: r.symbolResolver(r.diff.BaseUpload, symbol.Base),
+			head: r.symbolResolver(r.diff.HeadUpload, symbol.Head),
+		})
+	}
+
+	return resolvers
+}
+
+// BatchSpec returns a batch spec that targets the repositories depending on the symbols that
+// were removed or changed by this diff, or nil if the diff does not remove or change any symbol
+// or no other repository depends on them.
+func (r *apiDiffResolver) BatchSpec(ctx context.Context, args *gql.CodeIntelAPIDiffBatchSpecArgs) (*string, error) {
+	if len(r.diff.Removed) == 0 && len(r.diff.Changed) == 0 {
+		return nil, nil
+	}
+
+	dependents, err := r.resolver.APIDiffDependents(ctx, r.diff)
+	if err != nil {
+		return nil, err
+	}
+	if len(dependents) == 0 {
+		return nil, nil
+	}
+
+	spec, err := newAPIDiffBatchSpec(args.Name, r.diff, dependents)
+	if err != nil {
+		return nil, err
+	}
+
+	return &spec, nil
+}
+
+func (r *apiDiffResolver) symbolResolvers(upload store.Upload, symbols []lsifstore.ExportedSymbol) []gql.CodeIntelExportedSymbolResolver {
+	resolvers := make([]gql.CodeIntelExportedSymbolResolver, 0, len(symbols))
+	for _, symbol := range symbols {
+		resolvers = append(resolvers, r.symbolResolver(upload, symbol))
+	}
+
+	return resolvers
+}
+
+func (r *apiDiffResolver) symbolResolver(upload store.Upload, symbol lsifstore.ExportedSymbol) gql.CodeIntelExportedSymbolResolver {
+	return &exportedSymbolResolver{
+		upload:           upload,
+		symbol:           symbol,
+		locationResolver: r.locationResolver,
+	}
+}
+
+type exportedSymbolResolver struct {
+	upload           store.Upload
+	symbol           lsifstore.ExportedSymbol
+	locationResolver *CachedLocationResolver
+}
+
+func (r *exportedSymbolResolver) Scheme() string     { return r.symbol.Scheme }
+func (r *exportedSymbolResolver) Identifier() string { return r.symbol.Identifier }
+
+func (r *exportedSymbolResolver) Signature() string {
+	return resolvers.HoverSignature(r.symbol.HoverText)
+}
+
+func (r *exportedSymbolResolver) Hover() gql.HoverResolver {
+	if r.symbol.HoverText == "" {
+		return nil
+	}
+
+	return NewHoverResolver(r.symbol.HoverText, convertRange(r.symbol.Location.Range))
+}
+
+func (r *exportedSymbolResolver) Location(ctx context.Context) (gql.LocationResolver, error) {
+	return resolveLocation(ctx, r.locationResolver, resolvers.AdjustedLocation{
+		Dump: store.Dump{
+			ID:           r.upload.ID,
+			Commit:       r.upload.Commit,
+			Root:         r.upload.Root,
+			RepositoryID: r.upload.RepositoryID,
+		},
+		Path:           r.upload.Root + r.symbol.Location.Path,
+		AdjustedCommit: r.upload.Commit,
+		AdjustedRange:  r.symbol.Location.Range,
+	})
+}
+
+type changedSymbolResolver struct {
+	base gql.CodeIntelExportedSymbolResolver
+	head gql.CodeIntelExportedSymbolResolver
+}
+
+func (r *changedSymbolResolver) Base() gql.CodeIntelExportedSymbolResolver { return r.base }
+func (r *changedSymbolResolver) Head() gql.CodeIntelExportedSymbolResolver { return r.head }
+
+// newAPIDiffBatchSpec returns a batch spec, serialized as JSON, on the given repositories. The
+// description and changeset template body list the symbols removed or changed by the diff; the
+// steps that update the repositories are left to the author of the batch change.
+func newAPIDiffBatchSpec(name string, diff resolvers.APIDiff, repositories []string) (string, error) {
+	var symbols strings.Builder
+	for _, symbol := range diff.Removed {
+		fmt.Fprintf(&symbols, "- removed: `%s:%s`\n", symbol.Scheme, symbol.Identifier)
+	}
+	for _, symbol := range diff.Changed {
+		fmt.Fprintf(&symbols, "- changed: `%s:%s`\n", symbol.Base.Scheme, symbol.Base.Identifier)
+	}
+
+	title := fmt.Sprintf("Update usages of the API of %s", diff.HeadUpload.RepositoryName)
+	body := fmt.Sprintf(
+		"The API of %s changed between %s and %s:\n\n%s",
+		diff.HeadUpload.RepositoryName,
+		diff.BaseUpload.Commit,
+		diff.HeadUpload.Commit,
+		symbols.String(),
+	)
+	published := overridable.FromBoolOrString(false)
+
+	spec := batches.BatchSpec{
+		Name:        name,
+		Description: body,
+		On:          make([]batches.OnQueryOrRepository, 0, len(repositories)),
+		ChangesetTemplate: &batches.ChangesetTemplate{
+			Title:     title,
+			Body:      body,
+			Branch:    "api-diff/" + name,
+			Commit:    batches.ExpandedGitCommitDescription{Message: title},
+			Published: &published,
+		},
+	}
+	for _, repository := range repositories {
+		spec.On = append(spec.On, batches.OnQueryOrRepository{Repository: repository})
+	}
+
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+
+	// Validate the spec so that an invalid name is reported here rather than when the spec
+	// is applied.
+	if _, err := batches.ParseBatchSpec(raw, batches.ParseBatchSpecOptions{}); err != nil {
+		return "", err
+	}
+
+	return string(raw), nil
+}
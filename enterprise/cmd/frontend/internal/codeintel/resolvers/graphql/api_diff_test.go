@@ -0,0 +1,54 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/codeintel/resolvers"
+	store "github.com/sourcegraph/sourcegraph/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/stores/lsifstore"
+	"github.com/sourcegraph/sourcegraph/lib/batches"
+)
+
+func TestNewAPIDiffBatchSpec(t *testing.T) {
+	symbol := func(identifier string) lsifstore.ExportedSymbol {
+		return lsifstore.ExportedSymbol{Scheme: "gomod", Identifier: identifier}
+	}
+	diff := resolvers.APIDiff{
+		BaseUpload: store.Upload{RepositoryName: "github.com/sourcegraph/lib", Commit: "deadbeef"},
+		HeadUpload: store.Upload{RepositoryName: "github.com/sourcegraph/lib", Commit: "cafebabe"},
+		Removed:    []lsifstore.ExportedSymbol{symbol("lib:Foo")},
+		Changed:    []resolvers.ChangedSymbol{{Base: symbol("lib:Baz"), Head: symbol("lib:Baz")}},
+	}
+
+	raw, err := newAPIDiffBatchSpec("update-lib", diff, []string{"github.com/sourcegraph/a", "github.com/sourcegraph/b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	spec, err := batches.ParseBatchSpec([]byte(raw), batches.ParseBatchSpecOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error parsing batch spec: %s", err)
+	}
+
+	expectedOn := []batches.OnQueryOrRepository{
+		{Repository: "github.com/sourcegraph/a"},
+		{Repository: "github.com/sourcegraph/b"},
+	}
+	if diff := cmp.Diff(expectedOn, spec.On); diff != "" {
+		t.Errorf("unexpected repositories (-want +got):\n%s", diff)
+	}
+
+	expectedBody := "The API of github.com/sourcegraph/lib changed between deadbeef and cafebabe:\n\n- removed: `gomod:lib:Foo`\n- changed: `gomod:lib:Baz`\n"
+	if spec.ChangesetTemplate.Body != expectedBody {
+		t.Errorf("unexpected body. want=%q have=%q", expectedBody, spec.ChangesetTemplate.Body)
+	}
+	if spec.ChangesetTemplate.Branch != "api-diff/update-lib" {
+		t.Errorf("unexpected branch. want=%q have=%q", "api-diff/update-lib", spec.ChangesetTemplate.Branch)
+	}
+
+	if _, err := newAPIDiffBatchSpec("invalid name", diff, []string{"github.com/sourcegraph/a"}); err == nil {
+		t.Fatalf("expected error for invalid batch change name")
+	}
+}
@@ -92,6 +92,14 @@ var (
 		name: "lsif-dotnet",
 		urn:  "github.com/tcz717/LsifDotnet",
 	}
+	scipDotnet = codeIntelIndexerResolver{
+		name: "scip-dotnet",
+		urn:  "github.com/sourcegraph/scip-dotnet",
+	}
+	scipRuby = codeIntelIndexerResolver{
+		name: "scip-ruby",
+		urn:  "github.com/sourcegraph/scip-ruby",
+	}
 )
 
 var allIndexers = []gql.CodeIntelIndexerResolver{
@@ -113,6 +121,8 @@ var allIndexers = []gql.CodeIntelIndexerResolver{
 	&lsifPHP,
 	&lsifTerraform,
 	&lsifDotnet,
+	&scipDotnet,
+	&scipRuby,
 }
 
 // A map of file extension to a list of indexers in order of recommendation
@@ -140,7 +150,8 @@ var languageToIndexer = map[string][]gql.CodeIntelIndexerResolver{
 	".rs":      {&rustAnalyzer},
 	".php":     {&lsifPHP},
 	".tf":      {&lsifTerraform},
-	".cs":      {&lsifDotnet},
+	".cs":      {&scipDotnet, &lsifDotnet},
+	".rb":      {&scipRuby},
 }
 
 var imageToIndexer = map[string]gql.CodeIntelIndexerResolver{
@@ -151,4 +162,7 @@ var imageToIndexer = map[string]gql.CodeIntelIndexerResolver{
 	"sourcegraph/lsif-clang":      &lsifClang,
 	"davidrjenni/lsif-php":        &lsifPHP,
 	"sourcegraph/lsif-rust":       &rustAnalyzer,
+	"sourcegraph/scip-python":     &scipPython,
+	"sourcegraph/scip-ruby":       &scipRuby,
+	"sourcegraph/scip-dotnet":     &scipDotnet,
 }
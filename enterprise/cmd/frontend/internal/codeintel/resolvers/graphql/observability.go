@@ -7,6 +7,7 @@ import (
 )
 
 type operations struct {
+	apiDiff                   *observation.Operation
 	commitGraph               *observation.Operation
 	configurationPolicies     *observation.Operation
 	configurationPolicyByID   *observation.Operation
@@ -30,6 +31,7 @@ type operations struct {
 	repositorySummary         *observation.Operation
 	requestedLanguageSupport  *observation.Operation
 	requestLanguageSupport    *observation.Operation
+	sendAPIDiffAlert          *observation.Operation
 	updateConfigurationPolicy *observation.Operation
 	updateIndexConfiguration  *observation.Operation
 }
@@ -48,6 +50,7 @@ func newOperations(observationContext *observation.Context) *operations {
 	}
 
 	return &operations{
+		apiDiff:                   op("APIDiff"),
 		commitGraph:               op("CommitGraph"),
 		configurationPolicies:     op("ConfigurationPolicies"),
 		configurationPolicyByID:   op("ConfigurationPolicyByID"),
@@ -71,6 +74,7 @@ func newOperations(observationContext *observation.Context) *operations {
 		repositorySummary:         op("RepositorySummary"),
 		requestedLanguageSupport:  op("RequestedLanguageSupport"),
 		requestLanguageSupport:    op("RequestLanguageSupport"),
+		sendAPIDiffAlert:          op("SendAPIDiffAlert"),
 		updateConfigurationPolicy: op("UpdateConfigurationPolicy"),
 		updateIndexConfiguration:  op("UpdateIndexConfiguration"),
 	}
@@ -8,12 +8,16 @@ import (
 
 	"github.com/grafana/regexp"
 	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
 	"github.com/opentracing/opentracing-go/log"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/codeintel/resolvers"
+	cmbackground "github.com/sourcegraph/sourcegraph/enterprise/internal/codemonitors/background"
+	edb "github.com/sourcegraph/sourcegraph/enterprise/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/codeintel/autoindexing"
 	autoindexinggraphql "github.com/sourcegraph/sourcegraph/internal/codeintel/autoindexing/transport/graphql"
@@ -560,6 +564,142 @@ func (r *Resolver) RepositorySummary(ctx context.Context, id graphql.ID) (_ gql.
 	), nil
 }
 
+// 🚨 SECURITY: Only entrypoint is within the repository resolver so the user is already authenticated
+func (r *Resolver) APIDiff(ctx context.Context, id graphql.ID, args *gql.CodeIntelAPIDiffArgs) (_ []gql.CodeIntelAPIDiffResolver, err error) {
+	ctx, errTracer, endObservation := r.observationContext.apiDiff.WithErrors(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("repoID", string(id)),
+		log.String("base", args.Base),
+		log.String("head", args.Head),
+	}})
+	endObservation.OnCancel(ctx, 1, observation.Args{})
+
+	repositoryID, err := gql.UnmarshalRepositoryID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs, err := r.apiDiffs(ctx, int(repositoryID), args)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.apiDiffResolvers(diffs, errTracer), nil
+}
+
+func (r *Resolver) SendCodeIntelAPIDiffAlert(ctx context.Context, args *gql.SendCodeIntelAPIDiffAlertArgs) (_ []gql.CodeIntelAPIDiffResolver, err error) {
+	ctx, errTracer, endObservation := r.observationContext.sendAPIDiffAlert.WithErrors(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("repoID", string(args.Repository)),
+		log.String("codeMonitorID", string(args.CodeMonitor)),
+		log.String("base", args.Base),
+		log.String("head", args.Head),
+	}})
+	endObservation.OnCancel(ctx, 1, observation.Args{})
+
+	var monitorID int64
+	if err := unmarshalCodeMonitorID(args.CodeMonitor, &monitorID); err != nil {
+		return nil, err
+	}
+
+	// 🚨 SECURITY: Only the owner of the code monitor and site admins can run its actions
+	if err := r.checkCodeMonitorAccess(ctx, monitorID); err != nil {
+		return nil, err
+	}
+
+	repositoryID, err := gql.UnmarshalRepositoryID(args.Repository)
+	if err != nil {
+		return nil, err
+	}
+
+	// 🚨 SECURITY: Repos().Get returns an error if the user cannot see the repository
+	repo, err := r.db.Repos().Get(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs, err := r.apiDiffs(ctx, int(repositoryID), &args.CodeIntelAPIDiffArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	alert := cmbackground.APIDiffAlert{
+		Repository: string(repo.Name),
+		Base:       args.Base,
+		Head:       args.Head,
+	}
+	for _, diff := range diffs {
+		for _, symbol := range diff.Removed {
+			alert.Removed = append(alert.Removed, symbol.Scheme+":"+symbol.Identifier)
+		}
+		for _, symbol := range diff.Changed {
+			alert.Changed = append(alert.Changed, symbol.Base.Scheme+":"+symbol.Base.Identifier)
+		}
+	}
+
+	if len(alert.Removed) > 0 || len(alert.Changed) > 0 {
+		if err := cmbackground.SendAPIDiffAlert(ctx, edb.NewEnterpriseDB(r.db).CodeMonitors(), monitorID, alert); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.apiDiffResolvers(diffs, errTracer), nil
+}
+
+// apiDiffs resolves the base and head revisions of the given arguments and compares the
+// uploads of the repository at the resulting commits.
+func (r *Resolver) apiDiffs(ctx context.Context, repositoryID int, args *gql.CodeIntelAPIDiffArgs) ([]resolvers.APIDiff, error) {
+	baseCommit, err := r.gitserver.ResolveRevision(ctx, repositoryID, args.Base)
+	if err != nil {
+		return nil, err
+	}
+	headCommit, err := r.gitserver.ResolveRevision(ctx, repositoryID, args.Head)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.resolver.APIDiff(ctx, repositoryID, string(baseCommit), string(headCommit), derefString(args.Root, ""), derefString(args.Indexer, ""))
+}
+
+func (r *Resolver) apiDiffResolvers(diffs []resolvers.APIDiff, errTracer *observation.ErrCollector) []gql.CodeIntelAPIDiffResolver {
+	// Create a new prefetcher here as we only want to cache upload and index records in
+	// the same graphQL request, not across different request.
+	prefetcher := NewPrefetcher(r.resolver)
+
+	resolvers := make([]gql.CodeIntelAPIDiffResolver, 0, len(diffs))
+	for _, diff := range diffs {
+		resolvers = append(resolvers, NewAPIDiffResolver(r.db, r.resolver, r.gitserver, diff, prefetcher, r.locationResolver, errTracer))
+	}
+
+	return resolvers
+}
+
+// checkCodeMonitorAccess returns an error if the current user is neither a site admin nor the
+// owner of the given code monitor.
+func (r *Resolver) checkCodeMonitorAccess(ctx context.Context, monitorID int64) error {
+	actr := actor.FromContext(ctx)
+	if !actr.IsAuthenticated() {
+		return errors.New("not authenticated")
+	}
+	if backend.CheckUserIsSiteAdmin(ctx, r.db, actr.UID) == nil {
+		return nil
+	}
+
+	monitor, err := edb.NewEnterpriseDB(r.db).CodeMonitors().GetMonitor(ctx, monitorID)
+	if err != nil {
+		return errors.Wrap(err, "GetMonitor")
+	}
+	if monitor.UserID != actr.UID {
+		return errors.New("code monitor is not owned by the current user")
+	}
+	return nil
+}
+
+func unmarshalCodeMonitorID(id graphql.ID, monitorID *int64) error {
+	if kind := relay.UnmarshalKind(id); kind != cmbackground.MonitorKind {
+		return errors.Errorf("expected graphql ID kind %s, got %s", cmbackground.MonitorKind, kind)
+	}
+	return relay.UnmarshalSpec(id, monitorID)
+}
+
 // 🚨 SECURITY: Only entrypoint is within the repository resolver so the user is already authenticated
 func (r *Resolver) IndexConfiguration(ctx context.Context, id graphql.ID) (_ gql.IndexConfigurationResolver, err error) {
 	_, traceErrs, endObservation := r.observationContext.indexConfiguration.WithErrors(ctx, &err, observation.Args{LogFields: []log.Field{
@@ -78,6 +78,7 @@ type LSIFStore interface {
 	MonikersByPosition(ctx context.Context, bundleID int, path string, line, character int) ([][]precise.MonikerData, error)
 	BulkMonikerResults(ctx context.Context, tableName string, ids []int, args []precise.MonikerData, limit, offset int) (_ []lsifstore.Location, _ int, err error)
 	PackageInformation(ctx context.Context, bundleID int, path string, packageInformationID string) (precise.PackageInformationData, bool, error)
+	ExportedSymbols(ctx context.Context, bundleID int) ([]lsifstore.ExportedSymbol, error)
 }
 
 type IndexEnqueuer interface {
@@ -1081,6 +1081,12 @@ func (c QueryResolverStencilFuncCall) Results() []interface{} {
 // github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/codeintel/resolvers)
 // used for unit testing.
 type MockResolver struct {
+	// APIDiffFunc is an instance of a mock function object controlling the
+	// behavior of the method APIDiff.
+	APIDiffFunc *ResolverAPIDiffFunc
+	// APIDiffDependentsFunc is an instance of a mock function object
+	// controlling the behavior of the method APIDiffDependents.
+	APIDiffDependentsFunc *ResolverAPIDiffDependentsFunc
 	// AuditLogsForUploadFunc is an instance of a mock function object
 	// controlling the behavior of the method AuditLogsForUpload.
 	AuditLogsForUploadFunc *ResolverAuditLogsForUploadFunc
@@ -1186,6 +1192,16 @@ type MockResolver struct {
 // return zero values for all results, unless overwritten.
 func NewMockResolver() *MockResolver {
 	return &MockResolver{
+		APIDiffFunc: &ResolverAPIDiffFunc{
+			defaultHook: func(context.Context, int, string, string, string, string) (r0 []resolvers.APIDiff, r1 error) {
+				return
+			},
+		},
+		APIDiffDependentsFunc: &ResolverAPIDiffDependentsFunc{
+			defaultHook: func(context.Context, resolvers.APIDiff) (r0 []string, r1 error) {
+				return
+			},
+		},
 		AuditLogsForUploadFunc: &ResolverAuditLogsForUploadFunc{
 			defaultHook: func(context.Context, int) (r0 []dbstore.UploadLog, r1 error) {
 				return
@@ -1343,6 +1359,16 @@ func NewMockResolver() *MockResolver {
 // methods panic on invocation, unless overwritten.
 func NewStrictMockResolver() *MockResolver {
 	return &MockResolver{
+		APIDiffFunc: &ResolverAPIDiffFunc{
+			defaultHook: func(context.Context, int, string, string, string, string) ([]resolvers.APIDiff, error) {
+				panic("unexpected invocation of MockResolver.APIDiff")
+			},
+		},
+		APIDiffDependentsFunc: &ResolverAPIDiffDependentsFunc{
+			defaultHook: func(context.Context, resolvers.APIDiff) ([]string, error) {
+				panic("unexpected invocation of MockResolver.APIDiffDependents")
+			},
+		},
 		AuditLogsForUploadFunc: &ResolverAuditLogsForUploadFunc{
 			defaultHook: func(context.Context, int) ([]dbstore.UploadLog, error) {
 				panic("unexpected invocation of MockResolver.AuditLogsForUpload")
@@ -1500,6 +1526,12 @@ func NewStrictMockResolver() *MockResolver {
 // methods delegate to the given implementation, unless overwritten.
 func NewMockResolverFrom(i resolvers.Resolver) *MockResolver {
 	return &MockResolver{
+		APIDiffFunc: &ResolverAPIDiffFunc{
+			defaultHook: i.APIDiff,
+		},
+		APIDiffDependentsFunc: &ResolverAPIDiffDependentsFunc{
+			defaultHook: i.APIDiffDependents,
+		},
 		AuditLogsForUploadFunc: &ResolverAuditLogsForUploadFunc{
 			defaultHook: i.AuditLogsForUpload,
 		},
@@ -1593,6 +1625,233 @@ func NewMockResolverFrom(i resolvers.Resolver) *MockResolver {
 	}
 }
 
+// ResolverAPIDiffFunc describes the behavior when the APIDiff method of the
+// parent MockResolver instance is invoked.
+type ResolverAPIDiffFunc struct {
+	defaultHook func(context.Context, int, string, string, string, string) ([]resolvers.APIDiff, error)
+	hooks       []func(context.Context, int, string, string, string, string) ([]resolvers.APIDiff, error)
+	history     []ResolverAPIDiffFuncCall
+	mutex       sync.Mutex
+}
+
+// APIDiff delegates to the next hook function in the queue and stores the
+// parameter and result values of this invocation.
+func (m *MockResolver) APIDiff(v0 context.Context, v1 int, v2 string, v3 string, v4 string, v5 string) ([]resolvers.APIDiff, error) {
+	r0, r1 := m.APIDiffFunc.nextHook()(v0, v1, v2, v3, v4, v5)
+	m.APIDiffFunc.appendCall(ResolverAPIDiffFuncCall{v0, v1, v2, v3, v4, v5, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the APIDiff method of
+// the parent MockResolver instance is invoked and the hook queue is empty.
+func (f *ResolverAPIDiffFunc) SetDefaultHook(hook func(context.Context, int, string, string, string, string) ([]resolvers.APIDiff, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// APIDiff method of the parent MockResolver instance invokes the hook at
+// the front of the queue and discards it. After the queue is empty, the
+// default hook function is invoked for any future action.
+func (f *ResolverAPIDiffFunc) PushHook(hook func(context.Context, int, string, string, string, string) ([]resolvers.APIDiff, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *ResolverAPIDiffFunc) SetDefaultReturn(r0 []resolvers.APIDiff, r1 error) {
+	f.SetDefaultHook(func(context.Context, int, string, string, string, string) ([]resolvers.APIDiff, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *ResolverAPIDiffFunc) PushReturn(r0 []resolvers.APIDiff, r1 error) {
+	f.PushHook(func(context.Context, int, string, string, string, string) ([]resolvers.APIDiff, error) {
+		return r0, r1
+	})
+}
+
+func (f *ResolverAPIDiffFunc) nextHook() func(context.Context, int, string, string, string, string) ([]resolvers.APIDiff, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *ResolverAPIDiffFunc) appendCall(r0 ResolverAPIDiffFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of ResolverAPIDiffFuncCall objects describing
+// the invocations of this function.
+func (f *ResolverAPIDiffFunc) History() []ResolverAPIDiffFuncCall {
+	f.mutex.Lock()
+	history := make([]ResolverAPIDiffFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// ResolverAPIDiffFuncCall is an object that describes an invocation of
+// method APIDiff on an instance of MockResolver.
+type ResolverAPIDiffFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 string
+	// Arg4 is the value of the 5th argument passed to this method
+	// invocation.
+	Arg4 string
+	// Arg5 is the value of the 6th argument passed to this method
+	// invocation.
+	Arg5 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []resolvers.APIDiff
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c ResolverAPIDiffFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4, c.Arg5}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c ResolverAPIDiffFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
+// ResolverAPIDiffDependentsFunc describes the behavior when the
+// APIDiffDependents method of the parent MockResolver instance is invoked.
+type ResolverAPIDiffDependentsFunc struct {
+	defaultHook func(context.Context, resolvers.APIDiff) ([]string, error)
+	hooks       []func(context.Context, resolvers.APIDiff) ([]string, error)
+	history     []ResolverAPIDiffDependentsFuncCall
+	mutex       sync.Mutex
+}
+
+// APIDiffDependents delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockResolver) APIDiffDependents(v0 context.Context, v1 resolvers.APIDiff) ([]string, error) {
+	r0, r1 := m.APIDiffDependentsFunc.nextHook()(v0, v1)
+	m.APIDiffDependentsFunc.appendCall(ResolverAPIDiffDependentsFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the APIDiffDependents
+// method of the parent MockResolver instance is invoked and the hook queue
+// is empty.
+func (f *ResolverAPIDiffDependentsFunc) SetDefaultHook(hook func(context.Context, resolvers.APIDiff) ([]string, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// APIDiffDependents method of the parent MockResolver instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *ResolverAPIDiffDependentsFunc) PushHook(hook func(context.Context, resolvers.APIDiff) ([]string, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *ResolverAPIDiffDependentsFunc) SetDefaultReturn(r0 []string, r1 error) {
+	f.SetDefaultHook(func(context.Context, resolvers.APIDiff) ([]string, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *ResolverAPIDiffDependentsFunc) PushReturn(r0 []string, r1 error) {
+	f.PushHook(func(context.Context, resolvers.APIDiff) ([]string, error) {
+		return r0, r1
+	})
+}
+
+func (f *ResolverAPIDiffDependentsFunc) nextHook() func(context.Context, resolvers.APIDiff) ([]string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *ResolverAPIDiffDependentsFunc) appendCall(r0 ResolverAPIDiffDependentsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of ResolverAPIDiffDependentsFuncCall objects
+// describing the invocations of this function.
+func (f *ResolverAPIDiffDependentsFunc) History() []ResolverAPIDiffDependentsFuncCall {
+	f.mutex.Lock()
+	history := make([]ResolverAPIDiffDependentsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// ResolverAPIDiffDependentsFuncCall is an object that describes an
+// invocation of method APIDiffDependents on an instance of MockResolver.
+type ResolverAPIDiffDependentsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 resolvers.APIDiff
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []string
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c ResolverAPIDiffDependentsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c ResolverAPIDiffDependentsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // ResolverAuditLogsForUploadFunc describes the behavior when the
 // AuditLogsForUpload method of the parent MockResolver instance is invoked.
 type ResolverAuditLogsForUploadFunc struct {
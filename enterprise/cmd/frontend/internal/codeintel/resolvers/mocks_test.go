@@ -7776,6 +7776,9 @@ type MockLSIFStore struct {
 	// ExistsFunc is an instance of a mock function object controlling the
 	// behavior of the method Exists.
 	ExistsFunc *LSIFStoreExistsFunc
+	// ExportedSymbolsFunc is an instance of a mock function object
+	// controlling the behavior of the method ExportedSymbols.
+	ExportedSymbolsFunc *LSIFStoreExportedSymbolsFunc
 	// HoverFunc is an instance of a mock function object controlling the
 	// behavior of the method Hover.
 	HoverFunc *LSIFStoreHoverFunc
@@ -7828,6 +7831,11 @@ func NewMockLSIFStore() *MockLSIFStore {
 				return
 			},
 		},
+		ExportedSymbolsFunc: &LSIFStoreExportedSymbolsFunc{
+			defaultHook: func(context.Context, int) (r0 []lsifstore.ExportedSymbol, r1 error) {
+				return
+			},
+		},
 		HoverFunc: &LSIFStoreHoverFunc{
 			defaultHook: func(context.Context, int, string, int, int) (r0 string, r1 lsifstore.Range, r2 bool, r3 error) {
 				return
@@ -7895,6 +7903,11 @@ func NewStrictMockLSIFStore() *MockLSIFStore {
 				panic("unexpected invocation of MockLSIFStore.Exists")
 			},
 		},
+		ExportedSymbolsFunc: &LSIFStoreExportedSymbolsFunc{
+			defaultHook: func(context.Context, int) ([]lsifstore.ExportedSymbol, error) {
+				panic("unexpected invocation of MockLSIFStore.ExportedSymbols")
+			},
+		},
 		HoverFunc: &LSIFStoreHoverFunc{
 			defaultHook: func(context.Context, int, string, int, int) (string, lsifstore.Range, bool, error) {
 				panic("unexpected invocation of MockLSIFStore.Hover")
@@ -7952,6 +7965,9 @@ func NewMockLSIFStoreFrom(i LSIFStore) *MockLSIFStore {
 		ExistsFunc: &LSIFStoreExistsFunc{
 			defaultHook: i.Exists,
 		},
+		ExportedSymbolsFunc: &LSIFStoreExportedSymbolsFunc{
+			defaultHook: i.ExportedSymbols,
+		},
 		HoverFunc: &LSIFStoreHoverFunc{
 			defaultHook: i.Hover,
 		},
@@ -8570,6 +8586,114 @@ func (c LSIFStoreExistsFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// LSIFStoreExportedSymbolsFunc describes the behavior when the
+// ExportedSymbols method of the parent MockLSIFStore instance is invoked.
+type LSIFStoreExportedSymbolsFunc struct {
+	defaultHook func(context.Context, int) ([]lsifstore.ExportedSymbol, error)
+	hooks       []func(context.Context, int) ([]lsifstore.ExportedSymbol, error)
+	history     []LSIFStoreExportedSymbolsFuncCall
+	mutex       sync.Mutex
+}
+
+// ExportedSymbols delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockLSIFStore) ExportedSymbols(v0 context.Context, v1 int) ([]lsifstore.ExportedSymbol, error) {
+	r0, r1 := m.ExportedSymbolsFunc.nextHook()(v0, v1)
+	m.ExportedSymbolsFunc.appendCall(LSIFStoreExportedSymbolsFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the ExportedSymbols
+// method of the parent MockLSIFStore instance is invoked and the hook queue
+// is empty.
+func (f *LSIFStoreExportedSymbolsFunc) SetDefaultHook(hook func(context.Context, int) ([]lsifstore.ExportedSymbol, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// ExportedSymbols method of the parent MockLSIFStore instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *LSIFStoreExportedSymbolsFunc) PushHook(hook func(context.Context, int) ([]lsifstore.ExportedSymbol, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *LSIFStoreExportedSymbolsFunc) SetDefaultReturn(r0 []lsifstore.ExportedSymbol, r1 error) {
+	f.SetDefaultHook(func(context.Context, int) ([]lsifstore.ExportedSymbol, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *LSIFStoreExportedSymbolsFunc) PushReturn(r0 []lsifstore.ExportedSymbol, r1 error) {
+	f.PushHook(func(context.Context, int) ([]lsifstore.ExportedSymbol, error) {
+		return r0, r1
+	})
+}
+
+func (f *LSIFStoreExportedSymbolsFunc) nextHook() func(context.Context, int) ([]lsifstore.ExportedSymbol, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *LSIFStoreExportedSymbolsFunc) appendCall(r0 LSIFStoreExportedSymbolsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of LSIFStoreExportedSymbolsFuncCall objects
+// describing the invocations of this function.
+func (f *LSIFStoreExportedSymbolsFunc) History() []LSIFStoreExportedSymbolsFuncCall {
+	f.mutex.Lock()
+	history := make([]LSIFStoreExportedSymbolsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// LSIFStoreExportedSymbolsFuncCall is an object that describes an
+// invocation of method ExportedSymbols on an instance of MockLSIFStore.
+type LSIFStoreExportedSymbolsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []lsifstore.ExportedSymbol
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c LSIFStoreExportedSymbolsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c LSIFStoreExportedSymbolsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // LSIFStoreHoverFunc describes the behavior when the Hover method of the
 // parent MockLSIFStore instance is invoked.
 type LSIFStoreHoverFunc struct {
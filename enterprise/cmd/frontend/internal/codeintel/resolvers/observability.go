@@ -14,14 +14,16 @@ import (
 )
 
 type operations struct {
-	definitions     *observation.Operation
-	diagnostics     *observation.Operation
-	hover           *observation.Operation
-	queryResolver   *observation.Operation
-	ranges          *observation.Operation
-	references      *observation.Operation
-	implementations *observation.Operation
-	stencil         *observation.Operation
+	apiDiff           *observation.Operation
+	apiDiffDependents *observation.Operation
+	definitions       *observation.Operation
+	diagnostics       *observation.Operation
+	hover             *observation.Operation
+	queryResolver     *observation.Operation
+	ranges            *observation.Operation
+	references        *observation.Operation
+	implementations   *observation.Operation
+	stencil           *observation.Operation
 
 	findClosestDumps *observation.Operation
 }
@@ -55,14 +57,16 @@ func newOperations(observationContext *observation.Context) *operations {
 	}
 
 	return &operations{
-		definitions:     op("Definitions"),
-		diagnostics:     op("Diagnostics"),
-		hover:           op("Hover"),
-		implementations: op("Implementations"),
-		ranges:          op("Ranges"),
-		references:      op("References"),
-		stencil:         op("Stencil"),
-		queryResolver:   op("QueryResolver"),
+		apiDiff:           op("APIDiff"),
+		apiDiffDependents: op("APIDiffDependents"),
+		definitions:       op("Definitions"),
+		diagnostics:       op("Diagnostics"),
+		hover:             op("Hover"),
+		implementations:   op("Implementations"),
+		ranges:            op("Ranges"),
+		references:        op("References"),
+		stencil:           op("Stencil"),
+		queryResolver:     op("QueryResolver"),
 
 		findClosestDumps: subOp("findClosestDumps"),
 	}
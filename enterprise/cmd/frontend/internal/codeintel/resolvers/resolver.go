@@ -61,6 +61,8 @@ type Resolver interface {
 	IndexConnectionResolver(opts store.GetIndexesOptions) *IndexesResolver
 	QueryResolver(ctx context.Context, args *gql.GitBlobLSIFDataArgs) (QueryResolver, error)
 	RepositorySummary(ctx context.Context, repositoryID int) (RepositorySummary, error)
+	APIDiff(ctx context.Context, repositoryID int, baseCommit, headCommit, root, indexer string) ([]APIDiff, error)
+	APIDiffDependents(ctx context.Context, diff APIDiff) ([]string, error)
 
 	RequestLanguageSupport(ctx context.Context, userID int, language string) error
 	RequestedLanguageSupport(ctx context.Context, userID int) ([]string, error)
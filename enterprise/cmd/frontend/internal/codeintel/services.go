@@ -40,6 +40,8 @@ type Services struct {
 	InternalUploadHandler http.Handler
 	ExternalUploadHandler http.Handler
 
+	ExportHandler http.Handler
+
 	locker          *locker.Locker
 	gitserverClient *gitserver.Client
 	indexEnqueuer   *autoindexing.Service
@@ -94,6 +96,7 @@ func NewServices(ctx context.Context, config *Config, siteConfig conftypes.Watch
 	}
 	internalUploadHandler := newUploadHandler(true)
 	externalUploadHandler := newUploadHandler(false)
+	exportHandler := httpapi.NewExportHandler(db, &httpapi.DBStoreShim{Store: dbStore}, lsifStore, operations)
 
 	// Initialize gitserver client
 	gitserverClient := gitserver.New(db, dbStore, observationContext)
@@ -110,6 +113,7 @@ func NewServices(ctx context.Context, config *Config, siteConfig conftypes.Watch
 
 		InternalUploadHandler: internalUploadHandler,
 		ExternalUploadHandler: externalUploadHandler,
+		ExportHandler:         exportHandler,
 
 		locker:          locker,
 		gitserverClient: gitserverClient,
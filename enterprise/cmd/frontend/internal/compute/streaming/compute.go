@@ -10,30 +10,41 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/search"
 	"github.com/sourcegraph/sourcegraph/internal/search/client"
 	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/search/run"
 	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
 	"github.com/sourcegraph/sourcegraph/lib/group"
 )
 
-func toComputeResult(ctx context.Context, db database.DB, cmd compute.Command, match result.Match) (out []compute.Result, _ error) {
+func toComputeResult(ctx context.Context, db database.DB, cmd compute.Command, match result.Match, groupBy compute.GroupBy) (out []compute.Result, groupValues []string, _ error) {
+	run := func(match result.Match) error {
+		result, err := cmd.Run(ctx, db, match)
+		if err != nil {
+			return err
+		}
+		out = append(out, result)
+		if groupBy != "" {
+			groupValues = append(groupValues, compute.GroupValues(groupBy, match, result)...)
+		}
+		return nil
+	}
+
 	if v, ok := match.(*result.CommitMatch); ok && v.DiffPreview != nil {
 		for _, diffMatch := range v.CommitToDiffMatches() {
-			result, err := cmd.Run(ctx, db, diffMatch)
-			if err != nil {
-				return nil, err
+			if err := run(diffMatch); err != nil {
+				return nil, nil, err
 			}
-			out = append(out, result)
 		}
 	} else {
-		result, err := cmd.Run(ctx, db, match)
-		if err != nil {
-			return nil, err
+		if err := run(match); err != nil {
+			return nil, nil, err
 		}
-		out = append(out, result)
 	}
-	return out, nil
+	return out, groupValues, nil
 }
 
-func NewComputeStream(ctx context.Context, db database.DB, query string) (<-chan Event, func() (*search.Alert, error)) {
+// NewComputeStream runs the compute query and streams back its results. If
+// groupBy is set, the events also hold the group values of the results.
+func NewComputeStream(ctx context.Context, db database.DB, query string, groupBy compute.GroupBy) (<-chan Event, func() (*search.Alert, error)) {
 	computeQuery, err := compute.Parse(query)
 	if err != nil {
 		return nil, func() (*search.Alert, error) { return nil, err }
@@ -47,8 +58,9 @@ func NewComputeStream(ctx context.Context, db database.DB, query string) (<-chan
 	eventsC := make(chan Event, 8)
 	errorC := make(chan error, 1)
 	type groupEvent struct {
-		results []compute.Result
-		err     error
+		results     []compute.Result
+		groupValues []string
+		err         error
 	}
 	g := group.NewParallelOrdered(8, func(e groupEvent) {
 		if e.err != nil {
@@ -57,29 +69,20 @@ func NewComputeStream(ctx context.Context, db database.DB, query string) (<-chan
 			default:
 			}
 		} else {
-			eventsC <- Event{Results: e.results}
+			eventsC <- Event{Results: e.results, GroupValues: e.groupValues}
 		}
 	})
 	stream := streaming.StreamFunc(func(event streaming.SearchEvent) {
 		for _, match := range event.Results {
 			match := match
 			g.Submit(func() groupEvent {
-				results, err := toComputeResult(ctx, db, computeQuery.Command, match)
-				return groupEvent{results, err}
+				results, groupValues, err := toComputeResult(ctx, db, computeQuery.Command, match, groupBy)
+				return groupEvent{results, groupValues, err}
 			})
 		}
 	})
 
-	settings, err := graphqlbackend.DecodedViewerFinalSettings(ctx, db)
-	if err != nil {
-		close(eventsC)
-		close(errorC)
-		return eventsC, func() (*search.Alert, error) { return nil, err }
-	}
-
-	patternType := "regexp"
-	searchClient := client.NewSearchClient(db, search.Indexed(), search.SearcherURLs())
-	inputs, err := searchClient.Plan(ctx, "", &patternType, searchQuery, search.Streaming, settings, envvar.SourcegraphDotComMode())
+	searchClient, inputs, err := planSearch(ctx, db, searchQuery)
 	if err != nil {
 		close(eventsC)
 		close(errorC)
@@ -111,3 +114,19 @@ func NewComputeStream(ctx context.Context, db database.DB, query string) (<-chan
 		return f.alert, f.err
 	}
 }
+
+// planSearch plans the search query of a compute query for the current user.
+func planSearch(ctx context.Context, db database.DB, searchQuery string) (client.SearchClient, *run.SearchInputs, error) {
+	settings, err := graphqlbackend.DecodedViewerFinalSettings(ctx, db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	patternType := "regexp"
+	searchClient := client.NewSearchClient(db, search.Indexed(), search.SearcherURLs())
+	inputs, err := searchClient.Plan(ctx, "", &patternType, searchQuery, search.Streaming, settings, envvar.SourcegraphDotComMode())
+	if err != nil {
+		return nil, nil, err
+	}
+	return searchClient, inputs, nil
+}
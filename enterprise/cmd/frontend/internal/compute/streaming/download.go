@@ -0,0 +1,226 @@
+package streaming
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/compute"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/search/run"
+	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
+	"github.com/sourcegraph/sourcegraph/lib/batches"
+	"github.com/sourcegraph/sourcegraph/lib/batches/overridable"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/lib/group"
+)
+
+const (
+	// formatPatch downloads the changes of a replace command as a zip
+	// archive holding a patch per repository.
+	formatPatch = "patch"
+
+	// formatBatchSpec downloads the changes of a replace command as a batch
+	// spec and the changeset specs that apply them, without any steps.
+	formatBatchSpec = "batchspec"
+)
+
+// serveDownload runs the replace command of the compute query to completion
+// and writes its changes in the requested format.
+func (h *streamHandler) serveDownload(ctx context.Context, w http.ResponseWriter, args *args) error {
+	diffs, err := computeDiffs(ctx, h.db, args.Query)
+	if err != nil {
+		http.Error(w, err.Error(), errcode.HTTP(err))
+		return err
+	}
+	patches := compute.NewRepositoryPatches(diffs)
+
+	switch args.Format {
+	case formatPatch:
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="compute-patches.zip"`)
+		return writePatchArchive(w, patches)
+
+	case formatBatchSpec:
+		spec, err := newComputeBatchSpec(ctx, h.db, args.Query, args.Name, patches)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return err
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", args.Name+".batch.json"))
+		return json.NewEncoder(w).Encode(spec)
+	}
+
+	return errors.Errorf("unknown format %q", args.Format)
+}
+
+// computeDiffs runs the replace command of the compute query and returns the
+// changes it makes to the matched files. It fails if the search returns an
+// alert or hits a limit, since the changes would then be incomplete.
+func computeDiffs(ctx context.Context, db database.DB, query string) ([]*compute.FileDiff, error) {
+	computeQuery, err := compute.Parse(query)
+	if err != nil {
+		return nil, badRequestError{err}
+	}
+
+	replace, ok := computeQuery.Command.(*compute.Replace)
+	if !ok {
+		return nil, badRequestError{errors.Errorf("patches can only be created for replace commands, got %s", computeQuery.Command.String())}
+	}
+
+	searchQuery, err := computeQuery.ToSearchQuery()
+	if err != nil {
+		return nil, badRequestError{err}
+	}
+
+	searchClient, inputs, err := planSearch(ctx, db, searchQuery)
+	if err != nil {
+		var queryErr *run.QueryError
+		if errors.As(err, &queryErr) {
+			return nil, badRequestError{err}
+		}
+		return nil, err
+	}
+
+	type groupEvent struct {
+		diff *compute.FileDiff
+		err  error
+	}
+	var (
+		diffs    []*compute.FileDiff
+		diffErr  error
+		limitHit bool
+	)
+	g := group.NewParallelOrdered(8, func(e groupEvent) {
+		if e.err != nil {
+			if diffErr == nil {
+				diffErr = e.err
+			}
+		} else if e.diff != nil {
+			diffs = append(diffs, e.diff)
+		}
+	})
+	stream := streaming.StreamFunc(func(event streaming.SearchEvent) {
+		limitHit = limitHit || event.Stats.IsLimitHit
+		for _, match := range event.Results {
+			match := match
+			g.Submit(func() groupEvent {
+				diff, err := replace.Diff(ctx, db, match)
+				return groupEvent{diff, err}
+			})
+		}
+	})
+
+	alert, err := searchClient.Execute(ctx, stream, inputs)
+	g.Done()
+	if err != nil {
+		return nil, err
+	}
+	if diffErr != nil {
+		return nil, diffErr
+	}
+	if alert != nil {
+		return nil, badRequestError{errors.Errorf("%s: %s", alert.Title, alert.Description)}
+	}
+	if limitHit {
+		return nil, badRequestError{errors.New("the search hit a limit, so the changes would be incomplete: add count:all to the query or narrow it down")}
+	}
+	return diffs, nil
+}
+
+// badRequestError is an error caused by the compute query rather than by the
+// server.
+type badRequestError struct{ error }
+
+func (e badRequestError) BadRequest() bool { return true }
+
+// writePatchArchive writes a zip archive holding a patch per repository, which
+// can be applied with git apply. Patches of another revision than the default
+// branch are suffixed with the revision.
+func writePatchArchive(w io.Writer, patches []*compute.RepositoryPatch) error {
+	zw := zip.NewWriter(w)
+	for _, p := range patches {
+		name := string(p.Repository)
+		if p.Revision != "" {
+			name += "@" + strings.ReplaceAll(p.Revision, "/", "-")
+		}
+
+		f, err := zw.Create(name + ".patch")
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, p.Diff); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// computeBatchSpec is a batch spec without steps, together with the changeset
+// specs that apply the changes of a replace command. It can be applied without
+// executing the batch spec.
+type computeBatchSpec struct {
+	BatchSpec      *batches.BatchSpec       `json:"batchSpec"`
+	ChangesetSpecs []*batches.ChangesetSpec `json:"changesetSpecs"`
+}
+
+func newComputeBatchSpec(ctx context.Context, db database.DB, query, name string, patches []*compute.RepositoryPatch) (*computeBatchSpec, error) {
+	title := "Apply compute replacement"
+	body := fmt.Sprintf("This change was generated by the compute query:\n\n```\n%s\n```", query)
+	branch := "compute/" + name
+	published := overridable.FromBoolOrString(false)
+
+	spec := &computeBatchSpec{
+		BatchSpec: &batches.BatchSpec{
+			Name:        name,
+			Description: body,
+			ChangesetTemplate: &batches.ChangesetTemplate{
+				Title:     title,
+				Body:      body,
+				Branch:    branch,
+				Commit:    batches.ExpandedGitCommitDescription{Message: title},
+				Published: &published,
+			},
+		},
+		ChangesetSpecs: make([]*batches.ChangesetSpec, 0, len(patches)),
+	}
+
+	for _, p := range patches {
+		baseRef := p.Revision
+		if baseRef == "" {
+			ref, _, err := gitserver.NewClient(db).GetDefaultBranch(ctx, p.Repository)
+			if err != nil {
+				return nil, errors.Wrapf(err, "resolving default branch of %s", p.Repository)
+			}
+			baseRef = ref
+		} else if !strings.HasPrefix(baseRef, "refs/") {
+			baseRef = "refs/heads/" + baseRef
+		}
+
+		repoID := string(graphqlbackend.MarshalRepositoryID(p.RepositoryID))
+		spec.ChangesetSpecs = append(spec.ChangesetSpecs, &batches.ChangesetSpec{
+			BaseRepository: repoID,
+			BaseRev:        string(p.Commit),
+			BaseRef:        baseRef,
+			HeadRepository: repoID,
+			HeadRef:        "refs/heads/" + branch,
+			Title:          title,
+			Body:           body,
+			Commits: []batches.GitCommitDescription{{
+				Message: title,
+				Diff:    p.Diff,
+			}},
+			Published: batches.PublishedValue{Val: false},
+		})
+	}
+
+	return spec, nil
+}
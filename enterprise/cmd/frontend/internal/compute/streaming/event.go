@@ -4,4 +4,8 @@ import "github.com/sourcegraph/sourcegraph/enterprise/internal/compute"
 
 type Event struct {
 	Results []compute.Result // TODO(rvantonder): hydrate repo information in this Event type.
+
+	// GroupValues are the group values of the outputs of Results, set when
+	// the outputs are aggregated.
+	GroupValues []string
 }
@@ -9,7 +9,10 @@ import (
 
 	otlog "github.com/opentracing/opentracing-go/log"
 
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/compute"
 	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+	"github.com/sourcegraph/sourcegraph/internal/search"
 	streamhttp "github.com/sourcegraph/sourcegraph/internal/search/streaming/http"
 	"github.com/sourcegraph/sourcegraph/internal/trace"
 	"github.com/sourcegraph/sourcegraph/lib/errors"
@@ -49,6 +52,11 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		tr.Finish()
 	}()
 
+	if args.Format != "" {
+		err = h.serveDownload(ctx, w, args)
+		return
+	}
+
 	eventWriter, err := streamhttp.NewWriter(w)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -62,9 +70,14 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Log events to trace
 	eventWriter.StatHook = eventStreamOTHook(tr.LogFields)
 
-	events, getResults := NewComputeStream(ctx, h.db, args.Query)
+	events, getResults := NewComputeStream(ctx, h.db, args.Query, args.GroupBy)
 	events = batchEvents(events, 50*time.Millisecond)
 
+	if args.GroupBy != "" {
+		err = h.serveAggregation(ctx, eventWriter, events, getResults, args)
+		return
+	}
+
 	// Store marshalled matches and flush periodically or when we go over
 	// 32kb. 32kb chosen to be smaller than bufio.MaxTokenSize. Note: we can
 	// still write more than that.
@@ -109,10 +122,56 @@ LOOP:
 
 	matchesFlush()
 
+	err = writeFinalEvents(ctx, eventWriter, getResults)
+}
+
+// serveAggregation tallies the outputs of the compute query and periodically
+// streams the running counts of the top groups as aggregation events, instead
+// of streaming the results themselves.
+func (h *streamHandler) serveAggregation(ctx context.Context, eventWriter *streamhttp.Writer, events <-chan Event, getResults func() (*search.Alert, error), args *args) error {
+	aggregator := compute.NewAggregator(args.GroupBy)
+	dirty := false
+	aggregationFlush := func() {
+		if !dirty {
+			return
+		}
+		dirty = false
+		_ = eventWriter.Event("aggregation", aggregator.Aggregation(args.Top))
+	}
+
+	flushTicker := time.NewTicker(h.flushTickerInternal)
+	defer flushTicker.Stop()
+
+LOOP:
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				break LOOP
+			}
+			if len(event.GroupValues) > 0 {
+				aggregator.Add(event.GroupValues...)
+				dirty = true
+			}
+		case <-flushTicker.C:
+			aggregationFlush()
+		}
+	}
+
+	// Always send the final counts, even if there are no results.
+	dirty = true
+	aggregationFlush()
+
+	return writeFinalEvents(ctx, eventWriter, getResults)
+}
+
+// writeFinalEvents waits for the compute query to finish and sends the error or
+// alerts it resulted in.
+func writeFinalEvents(ctx context.Context, eventWriter *streamhttp.Writer, getResults func() (*search.Alert, error)) error {
 	alert, err := getResults()
 	if err != nil {
 		_ = eventWriter.Event("error", streamhttp.EventError{Message: err.Error()})
-		return
+		return err
 	}
 
 	if err := ctx.Err(); errors.Is(err, context.DeadlineExceeded) {
@@ -135,13 +194,31 @@ LOOP:
 			ProposedQueries: pqs,
 		})
 	}
+	return nil
 }
 
 type args struct {
 	Query   string
 	Display int
+
+	// GroupBy is set when the outputs of the query are aggregated instead of
+	// streamed back.
+	GroupBy compute.GroupBy
+
+	// Top is the number of groups with the highest counts that are streamed
+	// back when the outputs are aggregated.
+	Top int
+
+	// Format is set when the changes of a replace command are downloaded as
+	// patches or a batch spec instead of streamed back.
+	Format string
+
+	// Name is the name of the downloaded batch spec.
+	Name string
 }
 
+var validBatchSpecName = lazyregexp.New(`^[\w.-]+$`)
+
 func parseURLQuery(q url.Values) (*args, error) {
 	get := func(k, def string) string {
 		v := q.Get(k)
@@ -165,6 +242,27 @@ func parseURLQuery(q url.Values) (*args, error) {
 		return nil, errors.Errorf("display must be an integer, got %q: %w", display, err)
 	}
 
+	if groupBy := get("groupBy", ""); groupBy != "" {
+		if a.GroupBy, err = compute.ParseGroupBy(groupBy); err != nil {
+			return nil, err
+		}
+	}
+
+	top := get("top", "10")
+	if a.Top, err = strconv.Atoi(top); err != nil || a.Top < 1 {
+		return nil, errors.Errorf("top must be a positive integer, got %q", top)
+	}
+
+	switch a.Format = get("format", ""); a.Format {
+	case "", formatPatch, formatBatchSpec:
+	default:
+		return nil, errors.Errorf("format must be one of %s or %s, got %q", formatPatch, formatBatchSpec, a.Format)
+	}
+
+	if a.Name = get("name", "compute-replace"); !validBatchSpecName.MatchString(a.Name) {
+		return nil, errors.Errorf("name must only contain letters, digits, '.', '_' and '-', got %q", a.Name)
+	}
+
 	return &a, nil
 }
 
@@ -202,6 +300,7 @@ func batchEvents(source <-chan Event, delay time.Duration) <-chan Event {
 						return
 					}
 					event.Results = append(event.Results, newEvent.Results...)
+					event.GroupValues = append(event.GroupValues, newEvent.GroupValues...)
 				case <-timer:
 					results <- event
 					continue OUTER
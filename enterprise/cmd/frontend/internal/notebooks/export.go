@@ -0,0 +1,51 @@
+package notebooks
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/notebooks/resolvers"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/notebooks"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// GET /notebooks/{ID}/markdown
+//
+// newMarkdownExportHandler returns a handler that downloads the blocks of the
+// notebook with the given GraphQL ID in the Markdown format of notebooks.
+func newMarkdownExportHandler(db database.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := resolvers.UnmarshalNotebookID(graphql.ID(mux.Vars(r)["ID"]))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// 🚨 SECURITY: GetNotebook only returns notebooks that are visible to the current user.
+		notebook, err := notebooks.Notebooks(db).GetNotebook(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, notebooks.ErrNotebookNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			log15.Error("notebooks: failed to get notebook", "id", id, "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		markdown, err := notebooks.NotebookToMarkdown(notebook.Blocks, notebook.Variables)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="notebook.snb.md"`)
+		_, _ = io.WriteString(w, markdown)
+	})
+}
@@ -2,6 +2,7 @@ package notebooks
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/enterprise"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/notebooks/resolvers"
@@ -13,5 +14,6 @@ import (
 
 func Init(ctx context.Context, db database.DB, _ conftypes.UnifiedWatchable, enterpriseServices *enterprise.Services, observationContext *observation.Context) error {
 	enterpriseServices.NotebooksResolver = resolvers.NewResolver(db)
+	enterpriseServices.NewNotebookExportHandler = func() http.Handler { return newMarkdownExportHandler(db) }
 	return nil
 }
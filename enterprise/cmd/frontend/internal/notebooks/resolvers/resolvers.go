@@ -36,7 +36,7 @@ func marshalNotebookID(notebookID int64) graphql.ID {
 	return relay.MarshalID(notebookIDKind, notebookID)
 }
 
-func unmarshalNotebookID(id graphql.ID) (notebookID int64, err error) {
+func UnmarshalNotebookID(id graphql.ID) (notebookID int64, err error) {
 	if kind := relay.UnmarshalKind(id); kind != notebookIDKind {
 		err = errors.Errorf("expected graphql ID to have kind %q; got %q", notebookIDKind, kind)
 		return
@@ -46,7 +46,7 @@ func unmarshalNotebookID(id graphql.ID) (notebookID int64, err error) {
 }
 
 func (r *Resolver) NotebookByID(ctx context.Context, id graphql.ID) (graphqlbackend.NotebookResolver, error) {
-	notebookID, err := unmarshalNotebookID(id)
+	notebookID, err := UnmarshalNotebookID(id)
 	if err != nil {
 		return nil, err
 	}
@@ -118,6 +118,14 @@ func convertNotebookBlockInput(inputBlock graphqlbackend.CreateNotebookBlockInpu
 	return block, nil
 }
 
+func convertNotebookVariablesInput(inputVariables []graphqlbackend.NotebookVariableInput) notebooks.NotebookVariables {
+	variables := make(notebooks.NotebookVariables, 0, len(inputVariables))
+	for _, v := range inputVariables {
+		variables = append(variables, notebooks.NotebookVariable{Name: v.Name, DefaultValue: v.DefaultValue})
+	}
+	return variables
+}
+
 func (r *Resolver) CreateNotebook(ctx context.Context, args graphqlbackend.CreateNotebookInputArgs) (graphqlbackend.NotebookResolver, error) {
 	user, err := r.db.Users().GetByCurrentAuthUser(ctx)
 	if err != nil {
@@ -141,6 +149,9 @@ func (r *Resolver) CreateNotebook(ctx context.Context, args graphqlbackend.Creat
 		UpdaterUserID: user.ID,
 		Blocks:        blocks,
 	}
+	if notebookInput.Variables != nil {
+		notebook.Variables = convertNotebookVariablesInput(*notebookInput.Variables)
+	}
 	err = graphqlbackend.UnmarshalNamespaceID(args.Notebook.Namespace, &notebook.NamespaceUserID, &notebook.NamespaceOrgID)
 	if err != nil {
 		return nil, err
@@ -163,7 +174,7 @@ func (r *Resolver) UpdateNotebook(ctx context.Context, args graphqlbackend.Updat
 		return nil, err
 	}
 
-	id, err := unmarshalNotebookID(args.ID)
+	id, err := UnmarshalNotebookID(args.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -192,6 +203,9 @@ func (r *Resolver) UpdateNotebook(ctx context.Context, args graphqlbackend.Updat
 	notebook.Title = notebookInput.Title
 	notebook.Public = notebookInput.Public
 	notebook.Blocks = blocks
+	if notebookInput.Variables != nil {
+		notebook.Variables = convertNotebookVariablesInput(*notebookInput.Variables)
+	}
 	notebook.UpdaterUserID = user.ID
 	var namespaceUserID, namespaceOrgID int32
 	err = graphqlbackend.UnmarshalNamespaceID(args.Notebook.Namespace, &namespaceUserID, &namespaceOrgID)
@@ -219,7 +233,7 @@ func (r *Resolver) DeleteNotebook(ctx context.Context, args graphqlbackend.Delet
 		return nil, err
 	}
 
-	id, err := unmarshalNotebookID(args.ID)
+	id, err := UnmarshalNotebookID(args.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -243,6 +257,78 @@ func (r *Resolver) DeleteNotebook(ctx context.Context, args graphqlbackend.Delet
 	return &graphqlbackend.EmptyResponse{}, nil
 }
 
+func (r *Resolver) CreateNotebookFromMarkdown(ctx context.Context, args graphqlbackend.CreateNotebookFromMarkdownArgs) (graphqlbackend.NotebookResolver, error) {
+	user, err := r.db.Users().GetByCurrentAuthUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, variables, err := notebooks.NotebookFromMarkdown(args.Notebook.Markdown)
+	if err != nil {
+		return nil, err
+	}
+
+	notebook := &notebooks.Notebook{
+		Title:         args.Notebook.Title,
+		Public:        args.Notebook.Public,
+		CreatorUserID: user.ID,
+		UpdaterUserID: user.ID,
+		Blocks:        blocks,
+		Variables:     variables,
+	}
+	err = graphqlbackend.UnmarshalNamespaceID(args.Notebook.Namespace, &notebook.NamespaceUserID, &notebook.NamespaceOrgID)
+	if err != nil {
+		return nil, err
+	}
+	err = validateNotebookWritePermissionsForUser(ctx, r.db, notebook, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	createdNotebook, err := notebooks.Notebooks(r.db).CreateNotebook(ctx, notebook)
+	if err != nil {
+		return nil, err
+	}
+	return &notebookResolver{createdNotebook, r.db}, nil
+}
+
+func (r *Resolver) UpdateNotebookFromMarkdown(ctx context.Context, args graphqlbackend.UpdateNotebookFromMarkdownArgs) (graphqlbackend.NotebookResolver, error) {
+	user, err := r.db.Users().GetByCurrentAuthUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := UnmarshalNotebookID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	store := notebooks.Notebooks(r.db)
+	notebook, err := store.GetNotebook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateNotebookWritePermissionsForUser(ctx, r.db, notebook, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, variables, err := notebooks.NotebookFromMarkdown(args.Markdown)
+	if err != nil {
+		return nil, err
+	}
+	notebook.Blocks = blocks
+	notebook.Variables = variables
+	notebook.UpdaterUserID = user.ID
+
+	updatedNotebook, err := store.UpdateNotebook(ctx, notebook)
+	if err != nil {
+		return nil, err
+	}
+	return &notebookResolver{updatedNotebook, r.db}, nil
+}
+
 func marshalNotebookCursor(cursor int64) string {
 	return string(relay.MarshalID("NotebookCursor", cursor))
 }
@@ -380,13 +466,44 @@ func (r *notebookResolver) Title(ctx context.Context) string {
 }
 
 func (r *notebookResolver) Blocks(ctx context.Context) []graphqlbackend.NotebookBlockResolver {
-	blockResolvers := make([]graphqlbackend.NotebookBlockResolver, 0, len(r.notebook.Blocks))
-	for _, block := range r.notebook.Blocks {
+	return blocksToResolvers(r.notebook.Blocks)
+}
+
+func blocksToResolvers(blocks notebooks.NotebookBlocks) []graphqlbackend.NotebookBlockResolver {
+	blockResolvers := make([]graphqlbackend.NotebookBlockResolver, 0, len(blocks))
+	for _, block := range blocks {
 		blockResolvers = append(blockResolvers, &notebookBlockResolver{block})
 	}
 	return blockResolvers
 }
 
+func (r *notebookResolver) Variables(ctx context.Context) []graphqlbackend.NotebookVariableResolver {
+	variableResolvers := make([]graphqlbackend.NotebookVariableResolver, 0, len(r.notebook.Variables))
+	for _, variable := range r.notebook.Variables {
+		variableResolvers = append(variableResolvers, &notebookVariableResolver{variable})
+	}
+	return variableResolvers
+}
+
+func (r *notebookResolver) RenderedBlocks(ctx context.Context, args graphqlbackend.RenderedNotebookBlocksArgs) ([]graphqlbackend.NotebookBlockResolver, error) {
+	values := map[string]string{}
+	if args.Variables != nil {
+		for _, v := range *args.Variables {
+			values[v.Name] = v.Value
+		}
+	}
+
+	resolvedValues, err := r.notebook.VariableValues(values)
+	if err != nil {
+		return nil, err
+	}
+	return blocksToResolvers(r.notebook.Blocks.SubstituteVariables(resolvedValues)), nil
+}
+
+func (r *notebookResolver) Markdown(ctx context.Context) (string, error) {
+	return notebooks.NotebookToMarkdown(r.notebook.Blocks, r.notebook.Variables)
+}
+
 func (r *notebookResolver) Creator(ctx context.Context) (*graphqlbackend.UserResolver, error) {
 	if r.notebook.CreatorUserID == 0 {
 		return nil, nil
@@ -465,6 +582,18 @@ func (r *notebookResolver) ViewerHasStarred(ctx context.Context) (bool, error) {
 	return star != nil, nil
 }
 
+type notebookVariableResolver struct {
+	variable notebooks.NotebookVariable
+}
+
+func (r *notebookVariableResolver) Name() string {
+	return r.variable.Name
+}
+
+func (r *notebookVariableResolver) DefaultValue() string {
+	return r.variable.DefaultValue
+}
+
 type notebookBlockResolver struct {
 	block notebooks.NotebookBlock
 }
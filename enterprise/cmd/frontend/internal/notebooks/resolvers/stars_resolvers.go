@@ -114,7 +114,7 @@ func (r *Resolver) CreateNotebookStar(ctx context.Context, args graphqlbackend.C
 		return nil, err
 	}
 
-	notebookID, err := unmarshalNotebookID(args.NotebookID)
+	notebookID, err := UnmarshalNotebookID(args.NotebookID)
 	if err != nil {
 		return nil, err
 	}
@@ -139,7 +139,7 @@ func (r *Resolver) DeleteNotebookStar(ctx context.Context, args graphqlbackend.D
 		return nil, err
 	}
 
-	notebookID, err := unmarshalNotebookID(args.NotebookID)
+	notebookID, err := UnmarshalNotebookID(args.NotebookID)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,8 @@
+#!/usr/bin/env bash
+
+# This script builds the enterprise migrator docker image.
+
+set -ex
+cd "$(dirname "${BASH_SOURCE[0]}")"/../../..
+
+./cmd/migrator/build.sh github.com/sourcegraph/sourcegraph/enterprise/cmd/migrator
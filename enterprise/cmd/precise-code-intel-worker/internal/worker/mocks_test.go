@@ -3266,6 +3266,9 @@ type MockWorkerStore struct {
 	// QueuedCountFunc is an instance of a mock function object controlling
 	// the behavior of the method QueuedCount.
 	QueuedCountFunc *WorkerStoreQueuedCountFunc
+	// QueuedCountByFairnessKeyFunc is an instance of a mock function object
+	// controlling the behavior of the method QueuedCountByFairnessKey.
+	QueuedCountByFairnessKeyFunc *WorkerStoreQueuedCountByFairnessKeyFunc
 	// RequeueFunc is an instance of a mock function object controlling the
 	// behavior of the method Requeue.
 	RequeueFunc *WorkerStoreRequeueFunc
@@ -3329,6 +3332,11 @@ func NewMockWorkerStore() *MockWorkerStore {
 				return
 			},
 		},
+		QueuedCountByFairnessKeyFunc: &WorkerStoreQueuedCountByFairnessKeyFunc{
+			defaultHook: func(context.Context, int) (r0 map[string]int, r1 error) {
+				return
+			},
+		},
 		RequeueFunc: &WorkerStoreRequeueFunc{
 			defaultHook: func(context.Context, int, time.Time) (r0 error) {
 				return
@@ -3401,6 +3409,11 @@ func NewStrictMockWorkerStore() *MockWorkerStore {
 				panic("unexpected invocation of MockWorkerStore.QueuedCount")
 			},
 		},
+		QueuedCountByFairnessKeyFunc: &WorkerStoreQueuedCountByFairnessKeyFunc{
+			defaultHook: func(context.Context, int) (map[string]int, error) {
+				panic("unexpected invocation of MockWorkerStore.QueuedCountByFairnessKey")
+			},
+		},
 		RequeueFunc: &WorkerStoreRequeueFunc{
 			defaultHook: func(context.Context, int, time.Time) error {
 				panic("unexpected invocation of MockWorkerStore.Requeue")
@@ -3456,6 +3469,9 @@ func NewMockWorkerStoreFrom(i store.Store) *MockWorkerStore {
 		QueuedCountFunc: &WorkerStoreQueuedCountFunc{
 			defaultHook: i.QueuedCount,
 		},
+		QueuedCountByFairnessKeyFunc: &WorkerStoreQueuedCountByFairnessKeyFunc{
+			defaultHook: i.QueuedCountByFairnessKey,
+		},
 		RequeueFunc: &WorkerStoreRequeueFunc{
 			defaultHook: i.Requeue,
 		},
@@ -4468,6 +4484,117 @@ func (c WorkerStoreQueuedCountFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// WorkerStoreQueuedCountByFairnessKeyFunc describes the behavior when the
+// QueuedCountByFairnessKey method of the parent MockWorkerStore instance is
+// invoked.
+type WorkerStoreQueuedCountByFairnessKeyFunc struct {
+	defaultHook func(context.Context, int) (map[string]int, error)
+	hooks       []func(context.Context, int) (map[string]int, error)
+	history     []WorkerStoreQueuedCountByFairnessKeyFuncCall
+	mutex       sync.Mutex
+}
+
+// QueuedCountByFairnessKey delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockWorkerStore) QueuedCountByFairnessKey(v0 context.Context, v1 int) (map[string]int, error) {
+	r0, r1 := m.QueuedCountByFairnessKeyFunc.nextHook()(v0, v1)
+	m.QueuedCountByFairnessKeyFunc.appendCall(WorkerStoreQueuedCountByFairnessKeyFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// QueuedCountByFairnessKey method of the parent MockWorkerStore instance is
+// invoked and the hook queue is empty.
+func (f *WorkerStoreQueuedCountByFairnessKeyFunc) SetDefaultHook(hook func(context.Context, int) (map[string]int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// QueuedCountByFairnessKey method of the parent MockWorkerStore instance
+// invokes the hook at the front of the queue and discards it. After the
+// queue is empty, the default hook function is invoked for any future
+// action.
+func (f *WorkerStoreQueuedCountByFairnessKeyFunc) PushHook(hook func(context.Context, int) (map[string]int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *WorkerStoreQueuedCountByFairnessKeyFunc) SetDefaultReturn(r0 map[string]int, r1 error) {
+	f.SetDefaultHook(func(context.Context, int) (map[string]int, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *WorkerStoreQueuedCountByFairnessKeyFunc) PushReturn(r0 map[string]int, r1 error) {
+	f.PushHook(func(context.Context, int) (map[string]int, error) {
+		return r0, r1
+	})
+}
+
+func (f *WorkerStoreQueuedCountByFairnessKeyFunc) nextHook() func(context.Context, int) (map[string]int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *WorkerStoreQueuedCountByFairnessKeyFunc) appendCall(r0 WorkerStoreQueuedCountByFairnessKeyFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of WorkerStoreQueuedCountByFairnessKeyFuncCall
+// objects describing the invocations of this function.
+func (f *WorkerStoreQueuedCountByFairnessKeyFunc) History() []WorkerStoreQueuedCountByFairnessKeyFuncCall {
+	f.mutex.Lock()
+	history := make([]WorkerStoreQueuedCountByFairnessKeyFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// WorkerStoreQueuedCountByFairnessKeyFuncCall is an object that describes
+// an invocation of method QueuedCountByFairnessKey on an instance of
+// MockWorkerStore.
+type WorkerStoreQueuedCountByFairnessKeyFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 map[string]int
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c WorkerStoreQueuedCountByFairnessKeyFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c WorkerStoreQueuedCountByFairnessKeyFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // WorkerStoreRequeueFunc describes the behavior when the Requeue method of
 // the parent MockWorkerStore instance is invoked.
 type WorkerStoreRequeueFunc struct {
@@ -142,8 +142,13 @@ func main() {
 		Handler:      httpserver.NewHandler(nil),
 	})
 
+	routines := []goroutine.BackgroundRoutine{worker, server}
+	if config.LSIFUploadStoreConfig.RequiresExpirer() {
+		routines = append(routines, lsifuploadstore.NewExpirer(uploadStore, config.LSIFUploadStoreConfig))
+	}
+
 	// Go!
-	goroutine.MonitorBackgroundRoutines(context.Background(), worker, server)
+	goroutine.MonitorBackgroundRoutines(context.Background(), routines...)
 }
 
 func mustInitializeDB() *sql.DB {
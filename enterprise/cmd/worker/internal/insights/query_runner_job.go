@@ -6,6 +6,7 @@ import (
 	"github.com/sourcegraph/log"
 
 	"github.com/sourcegraph/sourcegraph/cmd/worker/job"
+	"github.com/sourcegraph/sourcegraph/cmd/worker/shared/init/codeintel"
 	workerdb "github.com/sourcegraph/sourcegraph/cmd/worker/shared/init/db"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/background"
@@ -62,7 +63,17 @@ func (s *insightsQueryRunnerJob) Routines(ctx context.Context, logger log.Logger
 		return nil, err
 	}
 
-	return background.GetBackgroundQueryRunnerJob(context.Background(), logger, database.NewDB(mainAppDb), insightsDB), nil
+	// Series with the precise references generation method read processed LSIF uploads.
+	dumpStore, err := codeintel.InitDBStore()
+	if err != nil {
+		return nil, err
+	}
+	lsifStore, err := codeintel.InitLSIFStore()
+	if err != nil {
+		return nil, err
+	}
+
+	return background.GetBackgroundQueryRunnerJob(context.Background(), logger, database.NewDB(mainAppDb), insightsDB, dumpStore, lsifStore), nil
 }
 
 func NewInsightsQueryRunnerJob() job.Job {
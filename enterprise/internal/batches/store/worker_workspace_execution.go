@@ -44,15 +44,16 @@ var batchSpecWorkspaceExecutionWorkerStoreOptions = dbworkerstore.Options{
 	Scan: func(rows *sql.Rows, err error) (workerutil.Record, bool, error) {
 		return scanFirstBatchSpecWorkspaceExecutionJob(rows, err)
 	},
-	OrderByExpression: sqlf.Sprintf("batch_spec_workspace_execution_jobs.place_in_global_queue"),
+	OrderByExpression: sqlf.Sprintf("batch_spec_workspace_execution_jobs.created_at, batch_spec_workspace_execution_jobs.id"),
 	StalledMaxAge:     batchSpecWorkspaceExecutionJobStalledJobMaximumAge,
 	MaxNumResets:      batchSpecWorkspaceExecutionJobMaximumNumResets,
 	// Explicitly disable retries.
 	MaxNumRetries: 0,
 
-	// This view ranks jobs from different users in a round-robin fashion
-	// so that no single user can clog the queue.
-	ViewName: "batch_spec_workspace_execution_queue batch_spec_workspace_execution_jobs",
+	// Jobs from different users are dequeued in a round-robin fashion so that
+	// no single user can clog the queue. The batch_spec_workspace_execution_queue
+	// view ranks jobs in the same order to report their place in the queue.
+	FairnessKeyExpression: sqlf.Sprintf("batch_spec_workspace_execution_jobs.user_id"),
 }
 
 type BatchSpecWorkspaceExecutionWorkerStore interface {
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"encoding/json"
+	"sort"
 	"testing"
 	"time"
 
@@ -616,8 +617,8 @@ func TestBatchSpecWorkspaceExecutionWorkerStore_Dequeue_RoundRobin(t *testing.T)
 	workerStore := dbworkerstore.NewWithMetrics(s.Handle(), batchSpecWorkspaceExecutionWorkerStoreOptions, &observation.TestContext)
 
 	// We create multiple jobs for each user because this test ensures jobs are
-	// dequeued in a round-robin fashion, starting with the user who dequeued
-	// the longest ago.
+	// dequeued in a round-robin fashion, starting with the user with the fewest
+	// jobs processing.
 	job1 := setupBatchSpecAssociation(ctx, s, t, user, repo)  // User_ID: 1
 	job2 := setupBatchSpecAssociation(ctx, s, t, user, repo)  // User_ID: 1
 	job3 := setupBatchSpecAssociation(ctx, s, t, user2, repo) // User_ID: 2
@@ -626,6 +627,21 @@ func TestBatchSpecWorkspaceExecutionWorkerStore_Dequeue_RoundRobin(t *testing.T)
 	job6 := setupBatchSpecAssociation(ctx, s, t, user3, repo) // User_ID: 3
 
 	want := []int64{job1, job3, job5, job2, job4, job6}
+
+	// The place of the jobs in the global queue matches the dequeue order.
+	jobs, err := s.ListBatchSpecWorkspaceExecutionJobs(ctx, ListBatchSpecWorkspaceExecutionJobsOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].PlaceInGlobalQueue < jobs[j].PlaceInGlobalQueue })
+	placed := make([]int64, 0, len(jobs))
+	for _, job := range jobs {
+		placed = append(placed, job.ID)
+	}
+	if diff := cmp.Diff(want, placed); diff != "" {
+		t.Fatalf("invalid place in global queue: %s", diff)
+	}
+
 	have := []int64{}
 
 	// We dequeue records until there are no more left. Then, we check in which
@@ -0,0 +1,95 @@
+package background
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/slack-go/slack"
+
+	edb "github.com/sourcegraph/sourcegraph/enterprise/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/txemail/txtypes"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// alertMessages are the messages sent by the actions of a code monitor for an
+// alert that is raised outside of its query trigger. The links of the messages
+// are tagged with the given UTM source.
+type alertMessages struct {
+	emailTemplates txtypes.Templates
+	emailData      func(utmSource string, email *edb.EmailAction) any
+	slackPayload   func(utmSource string) *slack.WebhookMessage
+	webhookPayload func(utmSource string) any
+}
+
+// sendAlert runs the enabled actions of the code monitor with the given ID with
+// the messages of an alert. Actions of a disabled code monitor are not run.
+func sendAlert(ctx context.Context, s edb.CodeMonitorStore, monitorID int64, newMessages func(m *edb.Monitor, externalURL *url.URL) alertMessages) (err error) {
+	m, err := s.GetMonitor(ctx, monitorID)
+	if err != nil {
+		return errors.Wrap(err, "GetMonitor")
+	}
+	if !m.Enabled {
+		return nil
+	}
+
+	externalURL, err := getExternalURL(ctx)
+	if err != nil {
+		return err
+	}
+	messages := newMessages(m, externalURL)
+	opts := edb.ListActionsOpts{MonitorID: &m.ID}
+
+	emails, err := s.ListEmailActions(ctx, opts)
+	if err != nil {
+		return errors.Wrap(err, "ListEmailActions")
+	}
+	for _, e := range emails {
+		if !e.Enabled {
+			continue
+		}
+		recs, listErr := s.ListRecipients(ctx, edb.ListRecipientsOpts{EmailID: &e.ID})
+		if listErr != nil {
+			err = errors.Append(err, errors.Wrap(listErr, "ListRecipients"))
+			continue
+		}
+		data := messages.emailData(utmSourceEmail, e)
+		for _, rec := range recs {
+			if rec.NamespaceUserID == nil {
+				// Like for search results, emails are not yet sent to org members.
+				continue
+			}
+			if sendErr := sendEmail(ctx, *rec.NamespaceUserID, messages.emailTemplates, data); sendErr != nil {
+				err = errors.Append(err, sendErr)
+			}
+		}
+	}
+
+	slackWebhooks, listErr := s.ListSlackWebhookActions(ctx, opts)
+	if listErr != nil {
+		return errors.Append(err, errors.Wrap(listErr, "ListSlackWebhookActions"))
+	}
+	for _, w := range slackWebhooks {
+		if !w.Enabled {
+			continue
+		}
+		if sendErr := postSlackWebhook(ctx, httpcli.ExternalDoer, w.URL, messages.slackPayload("code-monitor-slack-webhook")); sendErr != nil {
+			err = errors.Append(err, sendErr)
+		}
+	}
+
+	webhooks, listErr := s.ListWebhookActions(ctx, opts)
+	if listErr != nil {
+		return errors.Append(err, errors.Wrap(listErr, "ListWebhookActions"))
+	}
+	for _, w := range webhooks {
+		if !w.Enabled {
+			continue
+		}
+		if sendErr := postWebhook(ctx, httpcli.ExternalDoer, w.URL, messages.webhookPayload("code-monitor-webhook")); sendErr != nil {
+			err = errors.Append(err, sendErr)
+		}
+	}
+
+	return err
+}
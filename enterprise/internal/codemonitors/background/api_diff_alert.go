@@ -0,0 +1,221 @@
+package background
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	edb "github.com/sourcegraph/sourcegraph/enterprise/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/txemail"
+	"github.com/sourcegraph/sourcegraph/internal/txemail/txtypes"
+)
+
+// APIDiffAlert reports the symbols exported by the precise code intelligence
+// uploads of a repository that were removed or changed between two revisions.
+// It is delivered through the email, Slack and webhook actions of a code
+// monitor.
+type APIDiffAlert struct {
+	Repository string
+	Base       string
+	Head       string
+
+	// Removed and Changed are the monikers of the symbols, in the form
+	// "<scheme>:<identifier>".
+	Removed []string
+	Changed []string
+}
+
+// maxAPIDiffAlertSymbols bounds the number of symbols listed in the email and
+// Slack messages of an API diff alert.
+const maxAPIDiffAlertSymbols = 20
+
+type apiDiffAlertArgs struct {
+	MonitorDescription string
+	MonitorID          int64
+	ExternalURL        *url.URL
+	UTMSource          string
+	Alert              APIDiffAlert
+}
+
+// SendAPIDiffAlert runs the enabled actions of the code monitor with the given
+// ID for the API diff alert. Actions of a disabled code monitor are not run.
+func SendAPIDiffAlert(ctx context.Context, s edb.CodeMonitorStore, monitorID int64, alert APIDiffAlert) error {
+	return sendAlert(ctx, s, monitorID, func(m *edb.Monitor, externalURL *url.URL) alertMessages {
+		newArgs := func(utmSource string) apiDiffAlertArgs {
+			return apiDiffAlertArgs{
+				MonitorDescription: m.Description,
+				MonitorID:          m.ID,
+				ExternalURL:        externalURL,
+				UTMSource:          utmSource,
+				Alert:              alert,
+			}
+		}
+		return alertMessages{
+			emailTemplates: apiDiffAlertEmailTemplates,
+			emailData: func(utmSource string, email *edb.EmailAction) any {
+				return newTemplateDataForAPIDiffAlert(newArgs(utmSource), email)
+			},
+			slackPayload: func(utmSource string) *slack.WebhookMessage {
+				return apiDiffAlertSlackPayload(newArgs(utmSource))
+			},
+			webhookPayload: func(utmSource string) any {
+				return generateAPIDiffAlertWebhookPayload(newArgs(utmSource))
+			},
+		}
+	})
+}
+
+var apiDiffAlertEmailTemplates = txemail.MustValidate(txtypes.Templates{
+	Subject: `{{.Priority}}Sourcegraph code monitor {{.Description}}: breaking API changes in {{.Repository}}`,
+	Text: `
+The API of {{.Repository}} watched by your Sourcegraph code monitor, {{.Description}}, has breaking changes between {{.Base}} and {{.Head}}: {{.Summary}}.
+{{range .Symbols}}
+- {{.}}{{end}}{{if .TruncatedCount}}
+- and {{.TruncatedCount}} more{{end}}
+
+View the changes on Sourcegraph: {{.CompareURL}}
+
+__
+You are receiving this notification because you are a recipient on a code monitor.
+
+View code monitor: {{.CodeMonitorURL}}
+`,
+	HTML: `
+<p>The API of <b>{{.Repository}}</b> watched by your Sourcegraph code monitor, <b>{{.Description}}</b>, has breaking changes between <code>{{.Base}}</code> and <code>{{.Head}}</code>: {{.Summary}}.</p>
+
+<ul>{{range .Symbols}}
+<li><code>{{.}}</code></li>{{end}}{{if .TruncatedCount}}
+<li>and {{.TruncatedCount}} more</li>{{end}}
+</ul>
+
+<p><a href="{{.CompareURL}}">View the changes on Sourcegraph</a></p>
+
+<p>You are receiving this notification because you are a recipient on a code monitor. <a href="{{.CodeMonitorURL}}">View code monitor</a></p>
+`,
+})
+
+type TemplateDataAPIDiffAlert struct {
+	Priority       string
+	Description    string
+	Repository     string
+	Base           string
+	Head           string
+	Summary        string
+	Symbols        []string
+	TruncatedCount int
+	CompareURL     string
+	CodeMonitorURL string
+}
+
+func newTemplateDataForAPIDiffAlert(args apiDiffAlertArgs, email *edb.EmailAction) *TemplateDataAPIDiffAlert {
+	var priority string
+	if email.Priority == priorityCritical {
+		priority = "[Critical] "
+	}
+	symbols, truncatedCount := apiDiffAlertSymbols(args.Alert)
+	return &TemplateDataAPIDiffAlert{
+		Priority:       priority,
+		Description:    args.MonitorDescription,
+		Repository:     args.Alert.Repository,
+		Base:           args.Alert.Base,
+		Head:           args.Alert.Head,
+		Summary:        apiDiffAlertSummary(args.Alert),
+		Symbols:        symbols,
+		TruncatedCount: truncatedCount,
+		CompareURL:     getCompareURL(args.ExternalURL, args.Alert, args.UTMSource),
+		CodeMonitorURL: getCodeMonitorURL(args.ExternalURL, args.MonitorID, args.UTMSource),
+	}
+}
+
+func apiDiffAlertSlackPayload(args apiDiffAlertArgs) *slack.WebhookMessage {
+	newMarkdownSection := func(s string) slack.Block {
+		return slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", s, false, false), nil, nil)
+	}
+
+	symbols, truncatedCount := apiDiffAlertSymbols(args.Alert)
+	if truncatedCount > 0 {
+		symbols = append(symbols, fmt.Sprintf("and %d more", truncatedCount))
+	}
+
+	blocks := []slack.Block{
+		newMarkdownSection(fmt.Sprintf(
+			"The API of *%s* watched by the Sourcegraph code monitor *%s* has breaking changes between `%s` and `%s`: %s.",
+			args.Alert.Repository,
+			args.MonitorDescription,
+			args.Alert.Base,
+			args.Alert.Head,
+			apiDiffAlertSummary(args.Alert),
+		)),
+		newMarkdownSection(fmt.Sprintf("```%s```", strings.Join(symbols, "\n"))),
+		newMarkdownSection(fmt.Sprintf(
+			"<%s|View the changes> or <%s|edit the code monitor>",
+			getCompareURL(args.ExternalURL, args.Alert, args.UTMSource),
+			getCodeMonitorURL(args.ExternalURL, args.MonitorID, args.UTMSource),
+		)),
+	}
+	return &slack.WebhookMessage{Blocks: &slack.Blocks{BlockSet: blocks}}
+}
+
+type apiDiffAlertWebhookPayload struct {
+	MonitorDescription string   `json:"monitorDescription"`
+	MonitorURL         string   `json:"monitorURL"`
+	Repository         string   `json:"repository"`
+	Base               string   `json:"base"`
+	Head               string   `json:"head"`
+	Removed            []string `json:"removed"`
+	Changed            []string `json:"changed"`
+}
+
+func generateAPIDiffAlertWebhookPayload(args apiDiffAlertArgs) apiDiffAlertWebhookPayload {
+	return apiDiffAlertWebhookPayload{
+		MonitorDescription: args.MonitorDescription,
+		MonitorURL:         getCodeMonitorURL(args.ExternalURL, args.MonitorID, args.UTMSource),
+		Repository:         args.Alert.Repository,
+		Base:               args.Alert.Base,
+		Head:               args.Alert.Head,
+		Removed:            nonNilStrings(args.Alert.Removed),
+		Changed:            nonNilStrings(args.Alert.Changed),
+	}
+}
+
+// apiDiffAlertSummary describes the number of removed and changed symbols of
+// the alert, such as "2 symbols removed and 1 symbol changed".
+func apiDiffAlertSummary(alert APIDiffAlert) string {
+	var parts []string
+	if n := len(alert.Removed); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d %s removed", n, pluralize("symbol", n)))
+	}
+	if n := len(alert.Changed); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d %s changed", n, pluralize("symbol", n)))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// apiDiffAlertSymbols returns the removed and changed symbols of the alert to
+// list in a message, along with the number of symbols left out.
+func apiDiffAlertSymbols(alert APIDiffAlert) (symbols []string, truncatedCount int) {
+	for _, symbol := range alert.Removed {
+		symbols = append(symbols, "removed: "+symbol)
+	}
+	for _, symbol := range alert.Changed {
+		symbols = append(symbols, "changed: "+symbol)
+	}
+	if len(symbols) > maxAPIDiffAlertSymbols {
+		return symbols[:maxAPIDiffAlertSymbols], len(symbols) - maxAPIDiffAlertSymbols
+	}
+	return symbols, 0
+}
+
+func getCompareURL(externalURL *url.URL, alert APIDiffAlert, utmSource string) string {
+	return sourcegraphURL(externalURL, fmt.Sprintf("%s/-/compare/%s...%s", alert.Repository, alert.Base, alert.Head), "", utmSource)
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,92 @@
+package background
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	edb "github.com/sourcegraph/sourcegraph/enterprise/internal/database"
+)
+
+func TestAPIDiffAlert(t *testing.T) {
+	eu, err := url.Parse("https://sourcegraph.com")
+	require.NoError(t, err)
+
+	args := apiDiffAlertArgs{
+		MonitorDescription: "Breaking changes in the SDK",
+		MonitorID:          42,
+		ExternalURL:        eu,
+		UTMSource:          "code-monitor-webhook",
+		Alert: APIDiffAlert{
+			Repository: "github.com/sourcegraph/sdk",
+			Base:       "v1.0.0",
+			Head:       "main",
+			Removed:    []string{"gomod:github.com/sourcegraph/sdk:Client.Close"},
+			Changed:    []string{"gomod:github.com/sourcegraph/sdk:NewClient", "gomod:github.com/sourcegraph/sdk:Client.Do"},
+		},
+	}
+
+	t.Run("webhook", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{
+				"monitorDescription": "Breaking changes in the SDK",
+				"monitorURL": "https://sourcegraph.com/code-monitoring/Q29kZU1vbml0b3I6NDI=?utm_source=code-monitor-webhook",
+				"repository": "github.com/sourcegraph/sdk",
+				"base": "v1.0.0",
+				"head": "main",
+				"removed": ["gomod:github.com/sourcegraph/sdk:Client.Close"],
+				"changed": ["gomod:github.com/sourcegraph/sdk:NewClient", "gomod:github.com/sourcegraph/sdk:Client.Do"]
+			}`, string(b))
+			w.WriteHeader(200)
+		}))
+		defer s.Close()
+
+		err := postWebhook(context.Background(), s.Client(), s.URL, generateAPIDiffAlertWebhookPayload(args))
+		require.NoError(t, err)
+	})
+
+	t.Run("slack", func(t *testing.T) {
+		j, err := json.Marshal(apiDiffAlertSlackPayload(args))
+		require.NoError(t, err)
+		require.Contains(t, string(j), "*github.com/sourcegraph/sdk* watched by the Sourcegraph code monitor *Breaking changes in the SDK* has breaking changes between `v1.0.0` and `main`: 1 symbol removed and 2 symbols changed.")
+		require.Contains(t, string(j), "removed: gomod:github.com/sourcegraph/sdk:Client.Close")
+		require.Contains(t, string(j), "https://sourcegraph.com/github.com/sourcegraph/sdk/-/compare/v1.0.0...main")
+	})
+
+	t.Run("email", func(t *testing.T) {
+		data := newTemplateDataForAPIDiffAlert(args, &edb.EmailAction{Priority: priorityCritical})
+		require.Equal(t, &TemplateDataAPIDiffAlert{
+			Priority:    "[Critical] ",
+			Description: "Breaking changes in the SDK",
+			Repository:  "github.com/sourcegraph/sdk",
+			Base:        "v1.0.0",
+			Head:        "main",
+			Summary:     "1 symbol removed and 2 symbols changed",
+			Symbols: []string{
+				"removed: gomod:github.com/sourcegraph/sdk:Client.Close",
+				"changed: gomod:github.com/sourcegraph/sdk:NewClient",
+				"changed: gomod:github.com/sourcegraph/sdk:Client.Do",
+			},
+			CompareURL:     "https://sourcegraph.com/github.com/sourcegraph/sdk/-/compare/v1.0.0...main?utm_source=code-monitor-webhook",
+			CodeMonitorURL: "https://sourcegraph.com/code-monitoring/Q29kZU1vbml0b3I6NDI=?utm_source=code-monitor-webhook",
+		}, data)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		alert := APIDiffAlert{}
+		for i := 0; i < maxAPIDiffAlertSymbols+3; i++ {
+			alert.Removed = append(alert.Removed, "npm:sdk:f")
+		}
+		symbols, truncatedCount := apiDiffAlertSymbols(alert)
+		require.Len(t, symbols, maxAPIDiffAlertSymbols)
+		require.Equal(t, 3, truncatedCount)
+	})
+}
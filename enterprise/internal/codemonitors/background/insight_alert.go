@@ -0,0 +1,153 @@
+package background
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/slack-go/slack"
+
+	edb "github.com/sourcegraph/sourcegraph/enterprise/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/txemail"
+	"github.com/sourcegraph/sourcegraph/internal/txemail/txtypes"
+)
+
+const utmSourceInsightAlert = "code-insights-alert"
+
+// InsightAlert is a fired alert rule of a code insight series. It is delivered
+// through the email, Slack and webhook actions of a code monitor.
+type InsightAlert struct {
+	SeriesID string
+	Query    string
+
+	// Reason describes why the alert fired, such as "rose to 120, reaching
+	// the threshold of 100".
+	Reason string
+	Value  float64
+}
+
+type insightAlertArgs struct {
+	MonitorDescription string
+	MonitorID          int64
+	ExternalURL        *url.URL
+	UTMSource          string
+	Alert              InsightAlert
+}
+
+// SendInsightAlert runs the enabled actions of the code monitor with the given
+// ID for the insight alert. Actions of a disabled code monitor are not run.
+func SendInsightAlert(ctx context.Context, s edb.CodeMonitorStore, monitorID int64, alert InsightAlert) error {
+	return sendAlert(ctx, s, monitorID, func(m *edb.Monitor, externalURL *url.URL) alertMessages {
+		newArgs := func(utmSource string) insightAlertArgs {
+			return insightAlertArgs{
+				MonitorDescription: m.Description,
+				MonitorID:          m.ID,
+				ExternalURL:        externalURL,
+				UTMSource:          utmSource,
+				Alert:              alert,
+			}
+		}
+		return alertMessages{
+			emailTemplates: insightAlertEmailTemplates,
+			emailData: func(utmSource string, email *edb.EmailAction) any {
+				return newTemplateDataForInsightAlert(newArgs(utmSource), email)
+			},
+			slackPayload: func(utmSource string) *slack.WebhookMessage {
+				return insightAlertSlackPayload(newArgs(utmSource))
+			},
+			webhookPayload: func(utmSource string) any {
+				return generateInsightAlertWebhookPayload(newArgs(utmSource))
+			},
+		}
+	})
+}
+
+var insightAlertEmailTemplates = txemail.MustValidate(txtypes.Templates{
+	Subject: `{{.Priority}}Sourcegraph code monitor {{.Description}}: insight series {{.Reason}}`,
+	Text: `
+The code insight series of your Sourcegraph code monitor, {{.Description}}, {{.Reason}}.
+
+Query: {{.Query}}
+
+View search on Sourcegraph: {{.SearchURL}}
+
+__
+You are receiving this notification because you are a recipient on a code monitor.
+
+View code monitor: {{.CodeMonitorURL}}
+`,
+	HTML: `
+<p>The code insight series of your Sourcegraph code monitor, <b>{{.Description}}</b>, {{.Reason}}.</p>
+
+<p>Query: <code>{{.Query}}</code></p>
+
+<p><a href="{{.SearchURL}}">View search on Sourcegraph</a></p>
+
+<p>You are receiving this notification because you are a recipient on a code monitor. <a href="{{.CodeMonitorURL}}">View code monitor</a></p>
+`,
+})
+
+type TemplateDataInsightAlert struct {
+	Priority       string
+	Description    string
+	Reason         string
+	Query          string
+	SearchURL      string
+	CodeMonitorURL string
+}
+
+func newTemplateDataForInsightAlert(args insightAlertArgs, email *edb.EmailAction) *TemplateDataInsightAlert {
+	var priority string
+	if email.Priority == priorityCritical {
+		priority = "[Critical] "
+	}
+	return &TemplateDataInsightAlert{
+		Priority:       priority,
+		Description:    args.MonitorDescription,
+		Reason:         args.Alert.Reason,
+		Query:          args.Alert.Query,
+		SearchURL:      getSearchURL(args.ExternalURL, args.Alert.Query, args.UTMSource),
+		CodeMonitorURL: getCodeMonitorURL(args.ExternalURL, args.MonitorID, args.UTMSource),
+	}
+}
+
+func insightAlertSlackPayload(args insightAlertArgs) *slack.WebhookMessage {
+	newMarkdownSection := func(s string) slack.Block {
+		return slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", s, false, false), nil, nil)
+	}
+
+	blocks := []slack.Block{
+		newMarkdownSection(fmt.Sprintf(
+			"The code insight series of the Sourcegraph code monitor *%s* %s.",
+			args.MonitorDescription,
+			args.Alert.Reason,
+		)),
+		newMarkdownSection(fmt.Sprintf("```%s```", args.Alert.Query)),
+		newMarkdownSection(fmt.Sprintf(
+			"<%s|View results> or <%s|edit the code monitor>",
+			getSearchURL(args.ExternalURL, args.Alert.Query, args.UTMSource),
+			getCodeMonitorURL(args.ExternalURL, args.MonitorID, args.UTMSource),
+		)),
+	}
+	return &slack.WebhookMessage{Blocks: &slack.Blocks{BlockSet: blocks}}
+}
+
+type insightAlertWebhookPayload struct {
+	MonitorDescription string  `json:"monitorDescription"`
+	MonitorURL         string  `json:"monitorURL"`
+	Query              string  `json:"query"`
+	SeriesID           string  `json:"seriesId"`
+	Reason             string  `json:"reason"`
+	Value              float64 `json:"value"`
+}
+
+func generateInsightAlertWebhookPayload(args insightAlertArgs) insightAlertWebhookPayload {
+	return insightAlertWebhookPayload{
+		MonitorDescription: args.MonitorDescription,
+		MonitorURL:         getCodeMonitorURL(args.ExternalURL, args.MonitorID, args.UTMSource),
+		Query:              args.Alert.Query,
+		SeriesID:           args.Alert.SeriesID,
+		Reason:             args.Alert.Reason,
+		Value:              args.Alert.Value,
+	}
+}
@@ -0,0 +1,72 @@
+package background
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	edb "github.com/sourcegraph/sourcegraph/enterprise/internal/database"
+)
+
+func TestInsightAlert(t *testing.T) {
+	eu, err := url.Parse("https://sourcegraph.com")
+	require.NoError(t, err)
+
+	args := insightAlertArgs{
+		MonitorDescription: "Deprecated API usage",
+		MonitorID:          42,
+		ExternalURL:        eu,
+		UTMSource:          "code-monitor-webhook",
+		Alert: InsightAlert{
+			SeriesID: "s:123",
+			Query:    "oldpkg.Foo",
+			Reason:   "rose to 120, reaching the threshold of 100",
+			Value:    120,
+		},
+	}
+
+	t.Run("webhook", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{
+				"monitorDescription": "Deprecated API usage",
+				"monitorURL": "https://sourcegraph.com/code-monitoring/Q29kZU1vbml0b3I6NDI=?utm_source=code-monitor-webhook",
+				"query": "oldpkg.Foo",
+				"seriesId": "s:123",
+				"reason": "rose to 120, reaching the threshold of 100",
+				"value": 120
+			}`, string(b))
+			w.WriteHeader(200)
+		}))
+		defer s.Close()
+
+		err := postWebhook(context.Background(), s.Client(), s.URL, generateInsightAlertWebhookPayload(args))
+		require.NoError(t, err)
+	})
+
+	t.Run("slack", func(t *testing.T) {
+		j, err := json.Marshal(insightAlertSlackPayload(args))
+		require.NoError(t, err)
+		require.Contains(t, string(j), "*Deprecated API usage* rose to 120, reaching the threshold of 100.")
+		require.Contains(t, string(j), "https://sourcegraph.com/search?q=oldpkg.Foo")
+	})
+
+	t.Run("email", func(t *testing.T) {
+		data := newTemplateDataForInsightAlert(args, &edb.EmailAction{Priority: priorityCritical})
+		require.Equal(t, &TemplateDataInsightAlert{
+			Priority:       "[Critical] ",
+			Description:    "Deprecated API usage",
+			Reason:         "rose to 120, reaching the threshold of 100",
+			Query:          "oldpkg.Foo",
+			SearchURL:      "https://sourcegraph.com/search?q=oldpkg.Foo&utm_source=code-monitor-webhook",
+			CodeMonitorURL: "https://sourcegraph.com/code-monitoring/Q29kZU1vbml0b3I6NDI=?utm_source=code-monitor-webhook",
+		}, data)
+	})
+}
@@ -0,0 +1,156 @@
+package compute
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// GroupBy is the property by which the outputs of a compute command are
+// grouped when they are aggregated.
+type GroupBy string
+
+const (
+	GroupByValue  GroupBy = "value"
+	GroupByRepo   GroupBy = "repo"
+	GroupByPath   GroupBy = "path"
+	GroupByAuthor GroupBy = "author"
+)
+
+func ParseGroupBy(s string) (GroupBy, error) {
+	switch g := GroupBy(s); g {
+	case GroupByValue, GroupByRepo, GroupByPath, GroupByAuthor:
+		return g, nil
+	}
+	return "", errors.Errorf("invalid group by %q, expected one of value, repo, path, author", s)
+}
+
+// Bucket is the number of outputs that have the same group value.
+type Bucket struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Aggregation is a summary of the outputs of a compute command, grouped by a
+// property. Buckets holds the groups with the highest counts, in decreasing
+// order of count. The outputs of all other groups are tallied in OtherCount.
+type Aggregation struct {
+	GroupBy    GroupBy  `json:"groupBy"`
+	Buckets    []Bucket `json:"buckets"`
+	OtherCount int      `json:"otherCount"`
+	TotalCount int      `json:"totalCount"`
+}
+
+// Aggregator tallies the group values of the outputs of a compute command. It
+// is not safe for concurrent use.
+type Aggregator struct {
+	groupBy GroupBy
+	counts  map[string]int
+	total   int
+}
+
+func NewAggregator(groupBy GroupBy) *Aggregator {
+	return &Aggregator{
+		groupBy: groupBy,
+		counts:  make(map[string]int),
+	}
+}
+
+// Add tallies the given group values, as returned by GroupValues.
+func (a *Aggregator) Add(values ...string) {
+	for _, v := range values {
+		a.counts[v]++
+	}
+	a.total += len(values)
+}
+
+// Aggregation returns the running counts of the top n groups. Ties are
+// broken by value so that the result is stable.
+func (a *Aggregator) Aggregation(n int) *Aggregation {
+	buckets := make([]Bucket, 0, len(a.counts))
+	for v, c := range a.counts {
+		buckets = append(buckets, Bucket{Value: v, Count: c})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Count != buckets[j].Count {
+			return buckets[i].Count > buckets[j].Count
+		}
+		return buckets[i].Value < buckets[j].Value
+	})
+
+	var other int
+	if n >= 0 && len(buckets) > n {
+		for _, b := range buckets[n:] {
+			other += b.Count
+		}
+		buckets = buckets[:n]
+	}
+
+	return &Aggregation{
+		GroupBy:    a.groupBy,
+		Buckets:    buckets,
+		OtherCount: other,
+		TotalCount: a.total,
+	}
+}
+
+// GroupValues returns the group value of every output of the result r, which
+// was computed from the search match m. Outputs are the lines of output text,
+// or the matches of a match context. Outputs without a value for the group,
+// such as file matches grouped by author, are dropped.
+func GroupValues(groupBy GroupBy, m result.Match, r Result) []string {
+	values := outputValues(r)
+	if groupBy == GroupByValue || len(values) == 0 {
+		return values
+	}
+
+	env := NewMetaEnvironment(m, "")
+	var value string
+	switch groupBy {
+	case GroupByRepo:
+		value = env.Repo
+	case GroupByPath:
+		value = env.Path
+	case GroupByAuthor:
+		value = env.Author
+	}
+	if value == "" {
+		return nil
+	}
+
+	for i := range values {
+		values[i] = value
+	}
+	return values
+}
+
+func outputValues(r Result) []string {
+	switch v := r.(type) {
+	case *Text:
+		if v == nil {
+			return nil
+		}
+		if v.Kind != "output" {
+			return []string{v.Value}
+		}
+		var values []string
+		for _, line := range strings.Split(v.Value, "\n") {
+			if line != "" {
+				values = append(values, line)
+			}
+		}
+		return values
+	case *MatchContext:
+		if v == nil {
+			return nil
+		}
+		values := make([]string, 0, len(v.Matches))
+		for _, match := range v.Matches {
+			values = append(values, match.Value)
+		}
+		return values
+	}
+	return nil
+}
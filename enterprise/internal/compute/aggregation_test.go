@@ -0,0 +1,91 @@
+package compute
+
+import (
+	"testing"
+
+	"github.com/hexops/autogold"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestAggregator(t *testing.T) {
+	test := func(groupBy GroupBy, top int, inputs map[result.Match][]Result) *Aggregation {
+		a := NewAggregator(groupBy)
+		for m, rs := range inputs {
+			for _, r := range rs {
+				a.Add(GroupValues(groupBy, m, r)...)
+			}
+		}
+		return a.Aggregation(top)
+	}
+
+	file := func(repo, path string) result.Match {
+		return &result.FileMatch{File: result.File{Repo: types.MinimalRepo{Name: api.RepoName("github.com/" + repo)}, Path: path}}
+	}
+	output := func(value string) Result {
+		return &Text{Value: value, Kind: "output"}
+	}
+
+	autogold.Want("group by value", &Aggregation{
+		GroupBy: GroupBy("value"),
+		Buckets: []Bucket{
+			{
+				Value: "2.14.1",
+				Count: 3,
+			},
+			{
+				Value: "2.17.0",
+				Count: 2,
+			},
+		},
+		OtherCount: 1,
+		TotalCount: 6,
+	}).Equal(t, test(GroupByValue, 2, map[result.Match][]Result{
+		file("a", "pom.xml"):       {output("2.14.1\n2.17.0\n")},
+		file("b", "pom.xml"):       {output("2.14.1\n2.14.1\n")},
+		file("c", "build.gradle"):  {output("2.17.0\n1.2.17\n")},
+		file("d", "settings.yaml"): {output("")},
+	}))
+
+	autogold.Want("group by repo", &Aggregation{
+		GroupBy: GroupBy("repo"),
+		Buckets: []Bucket{
+			{
+				Value: "github.com/a",
+				Count: 3,
+			},
+			{
+				Value: "github.com/b",
+				Count: 1,
+			},
+		},
+		TotalCount: 4,
+	}).Equal(t, test(GroupByRepo, 10, map[result.Match][]Result{
+		file("a", "pom.xml"):      {output("2.14.1\n2.17.0\n")},
+		file("a", "build.gradle"): {&MatchContext{Matches: []Match{{Value: "log4j"}}}},
+		file("b", "pom.xml"):      {output("2.14.1\n")},
+	}))
+
+	autogold.Want("group by author drops file matches", &Aggregation{
+		GroupBy:    GroupBy("author"),
+		Buckets:    []Bucket{},
+		TotalCount: 0,
+	}).Equal(t, test(GroupByAuthor, 10, map[result.Match][]Result{
+		file("a", "pom.xml"): {output("2.14.1\n")},
+	}))
+}
+
+func TestParseGroupBy(t *testing.T) {
+	test := func(input string) string {
+		groupBy, err := ParseGroupBy(input)
+		if err != nil {
+			return err.Error()
+		}
+		return string(groupBy)
+	}
+
+	autogold.Want("valid", "path").Equal(t, test("path"))
+	autogold.Want("invalid", `invalid group by "lang", expected one of value, repo, path, author`).Equal(t, test("lang"))
+}
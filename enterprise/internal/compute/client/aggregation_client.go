@@ -0,0 +1,87 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	stdhttp "net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/compute"
+	"github.com/sourcegraph/sourcegraph/internal/search/streaming/http"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// NewComputeAggregationStreamRequest returns an http.Request against the
+// streaming API for query, which aggregates the outputs of query by groupBy
+// and streams back the running counts of the top groups.
+func NewComputeAggregationStreamRequest(baseURL string, query string, groupBy compute.GroupBy, top int) (*stdhttp.Request, error) {
+	q := url.Values{}
+	q.Set("q", query)
+	q.Set("groupBy", string(groupBy))
+	q.Set("top", strconv.Itoa(top))
+
+	req, err := stdhttp.NewRequest("GET", baseURL+"/compute/stream?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	return req, nil
+}
+
+type ComputeAggregationStreamDecoder struct {
+	// OnAggregation is called with the running counts of the aggregation.
+	// The last call holds the final counts.
+	OnAggregation func(aggregation *compute.Aggregation)
+	OnAlert       func(*http.EventAlert)
+	OnError       func(*http.EventError)
+	OnUnknown     func(event, data []byte)
+}
+
+func (rr ComputeAggregationStreamDecoder) ReadAll(r io.Reader) error {
+	dec := http.NewDecoder(r)
+
+	for dec.Scan() {
+		event := dec.Event()
+		data := dec.Data()
+
+		if bytes.Equal(event, []byte("aggregation")) {
+			if rr.OnAggregation == nil {
+				continue
+			}
+			var d compute.Aggregation
+			if err := json.Unmarshal(data, &d); err != nil {
+				return errors.Errorf("failed to decode compute aggregation payload: %w", err)
+			}
+			rr.OnAggregation(&d)
+		} else if bytes.Equal(event, []byte("alert")) {
+			if rr.OnAlert == nil {
+				continue
+			}
+			var d http.EventAlert
+			if err := json.Unmarshal(data, &d); err != nil {
+				return errors.Errorf("failed to decode alert payload: %w", err)
+			}
+			rr.OnAlert(&d)
+		} else if bytes.Equal(event, []byte("error")) {
+			if rr.OnError == nil {
+				continue
+			}
+			var d http.EventError
+			if err := json.Unmarshal(data, &d); err != nil {
+				return errors.Errorf("failed to decode error payload: %w", err)
+			}
+			rr.OnError(&d)
+		} else if bytes.Equal(event, []byte("done")) {
+			// Always the last event
+			break
+		} else {
+			if rr.OnUnknown == nil {
+				continue
+			}
+			rr.OnUnknown(event, data)
+		}
+	}
+	return dec.Err()
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hexops/autogold"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/compute"
+	"github.com/sourcegraph/sourcegraph/internal/search/streaming/http"
+)
+
+func TestComputeAggregationStreamDecoder_ReadAll(t *testing.T) {
+	raw := `event: aggregation
+data: {"groupBy":"value","buckets":[{"value":"2.14.1","count":2}],"otherCount":0,"totalCount":2}
+
+event: aggregation
+data: {"groupBy":"value","buckets":[{"value":"2.14.1","count":3},{"value":"2.17.0","count":1}],"otherCount":1,"totalCount":5}
+
+event: alert
+data: {"title": "alert"}
+
+event: done
+data: {}`
+
+	var last *compute.Aggregation
+	aggregationCount := 0
+	alertCount := 0
+	decoder := ComputeAggregationStreamDecoder{
+		OnAggregation: func(aggregation *compute.Aggregation) {
+			aggregationCount++
+			last = aggregation
+		},
+		OnAlert: func(event *http.EventAlert) {
+			alertCount++
+		},
+	}
+
+	err := decoder.ReadAll(strings.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	autogold.Want("aggregationCount", int(2)).Equal(t, aggregationCount)
+	autogold.Want("alertCount", int(1)).Equal(t, alertCount)
+	autogold.Want("last aggregation", &compute.Aggregation{
+		GroupBy: compute.GroupBy("value"),
+		Buckets: []compute.Bucket{
+			{
+				Value: "2.14.1",
+				Count: 3,
+			},
+			{
+				Value: "2.17.0",
+				Count: 1,
+			},
+		},
+		OtherCount: 1,
+		TotalCount: 5,
+	}).Equal(t, last)
+}
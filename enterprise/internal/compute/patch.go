@@ -0,0 +1,129 @@
+package compute
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/authz"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+// FileDiff is the change that a Replace command makes to the content of a
+// file, as a unified diff that can be applied with git apply.
+type FileDiff struct {
+	Repository   api.RepoName
+	RepositoryID api.RepoID
+
+	// Revision is the revision that was searched, as given in the query. It
+	// is empty for the default branch.
+	Revision string
+	Commit   api.CommitID
+
+	Path string
+	Diff string
+}
+
+// Diff runs the replacement on the file of r and returns the change as a
+// unified diff. It returns nil if r is not a file match or the replacement
+// does not change the file.
+func (c *Replace) Diff(ctx context.Context, db database.DB, r result.Match) (*FileDiff, error) {
+	m, ok := r.(*result.FileMatch)
+	if !ok {
+		return nil, nil
+	}
+
+	content, err := git.ReadFile(ctx, db, m.Repo.Name, m.CommitID, m.Path, authz.DefaultSubRepoPermsChecker)
+	if err != nil {
+		return nil, err
+	}
+	replaced, err := replace(ctx, content, c.SearchPattern, c.ReplacePattern)
+	if err != nil {
+		return nil, err
+	}
+	if replaced.Value == string(content) {
+		return nil, nil
+	}
+
+	var revision string
+	if m.InputRev != nil {
+		revision = *m.InputRev
+	}
+
+	return &FileDiff{
+		Repository:   m.Repo.Name,
+		RepositoryID: m.Repo.ID,
+		Revision:     revision,
+		Commit:       m.CommitID,
+		Path:         m.Path,
+		Diff:         unifiedDiff(m.Path, string(content), replaced.Value),
+	}, nil
+}
+
+// unifiedDiff returns the diff from before to after of the file at path, in
+// the format of git diff. Like git, the last line of a file without a trailing
+// newline is followed by a "\ No newline at end of file" marker, so that the
+// diff applies to the file as is.
+func unifiedDiff(path, before, after string) string {
+	edits := myers.ComputeEdits(span.URIFromPath(path), before, after)
+	unified := gotextdiff.ToUnified("a/"+path, "b/"+path, before, edits)
+	return fmt.Sprintf("diff --git a/%s b/%s\n%s", path, path, unified)
+}
+
+// RepositoryPatch is the combined diff of all the changed files of a
+// repository at a revision.
+type RepositoryPatch struct {
+	Repository   api.RepoName
+	RepositoryID api.RepoID
+	Revision     string
+	Commit       api.CommitID
+	Diff         string
+}
+
+// NewRepositoryPatches groups the given file diffs by repository and revision.
+// The patches are ordered by repository and revision, and the files of a patch
+// by path.
+func NewRepositoryPatches(diffs []*FileDiff) []*RepositoryPatch {
+	type key struct {
+		repo     api.RepoName
+		revision string
+	}
+	byRepo := make(map[key][]*FileDiff)
+	for _, d := range diffs {
+		k := key{d.Repository, d.Revision}
+		byRepo[k] = append(byRepo[k], d)
+	}
+
+	patches := make([]*RepositoryPatch, 0, len(byRepo))
+	for _, files := range byRepo {
+		sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+		var b strings.Builder
+		for _, f := range files {
+			b.WriteString(f.Diff)
+		}
+
+		patches = append(patches, &RepositoryPatch{
+			Repository:   files[0].Repository,
+			RepositoryID: files[0].RepositoryID,
+			Revision:     files[0].Revision,
+			Commit:       files[0].Commit,
+			Diff:         b.String(),
+		})
+	}
+	sort.Slice(patches, func(i, j int) bool {
+		if patches[i].Repository != patches[j].Repository {
+			return patches[i].Repository < patches[j].Repository
+		}
+		return patches[i].Revision < patches[j].Revision
+	})
+	return patches
+}
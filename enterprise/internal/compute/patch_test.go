@@ -0,0 +1,91 @@
+package compute
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/regexp"
+	"github.com/hexops/autogold"
+
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+func TestReplaceDiff(t *testing.T) {
+	test := func(content string) string {
+		defer git.ResetMocks()
+		cmd := &Replace{
+			SearchPattern:  &Regexp{Value: regexp.MustCompile(`log4j-(\d+)\.(\d+)`)},
+			ReplacePattern: "log4j-2.17",
+		}
+		diff, err := cmd.Diff(context.Background(), database.NewMockDB(), fileMatch(content))
+		if err != nil {
+			return err.Error()
+		}
+		if diff == nil {
+			return "<nil>"
+		}
+		return diff.Diff
+	}
+
+	autogold.Want("replacement is a unified diff", `diff --git a/my/awesome/path.ml b/my/awesome/path.ml
+--- a/my/awesome/path.ml
++++ b/my/awesome/path.ml
+@@ -1,3 +1,3 @@
+ deps:
+-  - log4j-2.14
++  - log4j-2.17
+   - junit-4.13
+`).Equal(t, test("deps:\n  - log4j-2.14\n  - junit-4.13\n"))
+
+	autogold.Want("unchanged file has no diff", "<nil>").Equal(t, test("deps:\n  - junit-4.13\n"))
+
+	autogold.Want("missing newline at end of file is marked", `diff --git a/my/awesome/path.ml b/my/awesome/path.ml
+--- a/my/awesome/path.ml
++++ b/my/awesome/path.ml
+@@ -1,2 +1,2 @@
+ deps:
+-  - log4j-2.14
+\ No newline at end of file
++  - log4j-2.17
+\ No newline at end of file
+`).Equal(t, test("deps:\n  - log4j-2.14"))
+
+	autogold.Want("unchanged last line without newline is marked", `diff --git a/my/awesome/path.ml b/my/awesome/path.ml
+--- a/my/awesome/path.ml
++++ b/my/awesome/path.ml
+@@ -1,2 +1,2 @@
+-  - log4j-2.14
++  - log4j-2.17
+   - junit-4.13
+\ No newline at end of file
+`).Equal(t, test("  - log4j-2.14\n  - junit-4.13"))
+}
+
+func TestNewRepositoryPatches(t *testing.T) {
+	patches := NewRepositoryPatches([]*FileDiff{
+		{Repository: "b", Commit: "c2", Path: "z.go", Diff: "diff z.go\n"},
+		{Repository: "a", Commit: "c1", Path: "y.go", Diff: "diff y.go\n"},
+		{Repository: "b", Commit: "c2", Path: "x.go", Diff: "diff x.go\n"},
+		{Repository: "b", Revision: "release", Commit: "c3", Path: "x.go", Diff: "diff x.go\n"},
+	})
+
+	autogold.Want("patches are grouped by repository and revision", []*RepositoryPatch{
+		{
+			Repository: "a",
+			Commit:     "c1",
+			Diff:       "diff y.go\n",
+		},
+		{
+			Repository: "b",
+			Commit:     "c2",
+			Diff:       "diff x.go\ndiff z.go\n",
+		},
+		{
+			Repository: "b",
+			Revision:   "release",
+			Commit:     "c3",
+			Diff:       "diff x.go\n",
+		},
+	}).Equal(t, patches)
+}
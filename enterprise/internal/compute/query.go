@@ -2,8 +2,10 @@ package compute
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/grafana/regexp"
+	"github.com/yuin/gopher-lua/parse"
 
 	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
 	"github.com/sourcegraph/sourcegraph/internal/search/query"
@@ -103,6 +105,8 @@ var ComputePredicateRegistry = query.PredicateRegistry{
 		"output":             func() query.Predicate { return query.EmptyPredicate{} },
 		"output.regexp":      func() query.Predicate { return query.EmptyPredicate{} },
 		"output.structural":  func() query.Predicate { return query.EmptyPredicate{} },
+		"script":             func() query.Predicate { return query.EmptyPredicate{} },
+		"script.regexp":      func() query.Predicate { return query.EmptyPredicate{} },
 	},
 }
 
@@ -214,6 +218,43 @@ func parseOutput(q *query.Basic) (Command, bool, error) {
 	}, true, nil
 }
 
+func parseScript(q *query.Basic) (Command, bool, error) {
+	pattern, err := extractPattern(q)
+	if err != nil {
+		return nil, false, err
+	}
+
+	name, args, ok := parseContentPredicate(pattern)
+	if !ok {
+		return nil, false, nil
+	}
+	if name != "script" && name != "script.regexp" {
+		// unrecognized name
+		return nil, false, nil
+	}
+	left, right, err := parseArrowSyntax(args)
+	if err != nil {
+		return nil, false, err
+	}
+
+	matchPattern, err := toRegexpPattern(left)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "script command")
+	}
+
+	// Only check the syntax here; the script is evaluated in a sandbox when run.
+	if _, err := parse.Parse(strings.NewReader("return "+right), "script"); err != nil {
+		return nil, false, errors.Wrap(err, "script command")
+	}
+
+	// The default separator is newline and cannot be changed currently.
+	return &Script{
+		SearchPattern: matchPattern,
+		Script:        right,
+		Separator:     "\n",
+	}, true, nil
+}
+
 func parseMatchOnly(q *query.Basic) (Command, bool, error) {
 	pattern, err := extractPattern(q)
 	if err != nil {
@@ -257,6 +298,7 @@ func first(parsers ...commandParser) commandParser {
 var parseCommand = first(
 	parseReplace,
 	parseOutput,
+	parseScript,
 	parseMatchOnly,
 )
 
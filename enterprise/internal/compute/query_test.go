@@ -50,6 +50,14 @@ func TestParse(t *testing.T) {
 	autogold.Want("replace no left hand side",
 		"Command: `Replace in place: () -> (b)`").
 		Equal(t, test("content:replace(->b)"))
+
+	autogold.Want("script",
+		"Command: `Script: (v(\\d+)) -> (function(m) return m.groups[1] end)`").
+		Equal(t, test(`content:script(v(\d+) -> function(m) return m.groups[1] end)`))
+
+	autogold.Want("script syntax error",
+		"script command: script line:1(column:26) near 'end':   syntax error\n").
+		Equal(t, test(`content:script(a -> function(m) end end)`))
 }
 
 func TestToSearchQuery(t *testing.T) {
@@ -0,0 +1,203 @@
+package compute
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/regexp"
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/luasandbox"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// Script runs a user-supplied Lua function over each match of SearchPattern in
+// the content of a result. The function receives the match and the file
+// metadata as tables, and returns the string to output for the match, or nil to
+// skip it.
+type Script struct {
+	SearchPattern MatchPattern
+	Script        string
+	Separator     string
+}
+
+func (c *Script) ToSearchPattern() string {
+	return c.SearchPattern.String()
+}
+
+func (c *Script) String() string {
+	return fmt.Sprintf("Script: (%s) -> (%s)", c.SearchPattern.String(), c.Script)
+}
+
+const (
+	// scriptTimeout bounds the wall-clock time of the script over all the matches
+	// of a single result.
+	scriptTimeout = time.Second
+
+	// scriptMemoryLimit bounds the memory allocated by the script over all the
+	// matches of a single result.
+	scriptMemoryLimit = 64 * 1024 * 1024
+
+	// maxScriptOutputBytes bounds the size of the output of the script for a
+	// single result.
+	maxScriptOutputBytes = 1024 * 1024
+)
+
+func runScript(ctx context.Context, content string, matchPattern MatchPattern, script, separator string, env *MetaEnvironment) (*Text, error) {
+	match, ok := matchPattern.(*Regexp)
+	if !ok {
+		return nil, errors.Errorf("unsupported script operation for match pattern %T", matchPattern)
+	}
+
+	sandbox, err := luasandbox.GetService().CreateSandbox(ctx, luasandbox.CreateOptions{
+		Modules: luasandbox.DefaultModules,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer sandbox.Close()
+
+	opts := luasandbox.RunOptions{
+		Timeout:     scriptTimeout,
+		MemoryLimit: scriptMemoryLimit,
+	}
+
+	var b strings.Builder
+	err = sandbox.RunGoCallback(ctx, opts, func(ctx context.Context, state *lua.LState) error {
+		f, err := loadScriptFunction(state, script)
+		if err != nil {
+			return err
+		}
+
+		file := newFileTable(state, env)
+		for _, submatches := range match.Value.FindAllStringSubmatchIndex(content, -1) {
+			state.Push(f)
+			state.Push(newMatchTable(state, match.Value, content, submatches))
+			state.Push(file)
+			if err := state.PCall(2, 1, nil); err != nil {
+				return err
+			}
+			value := state.Get(-1)
+			state.Pop(1)
+
+			switch value.Type() {
+			case lua.LTNil:
+				continue
+			case lua.LTString, lua.LTNumber:
+				b.WriteString(value.String())
+				b.WriteString(separator)
+			default:
+				return errors.Errorf("script must return a string or nil, got %s", value.Type())
+			}
+
+			if b.Len() > maxScriptOutputBytes {
+				return errors.Errorf("script output exceeds %d bytes", maxScriptOutputBytes)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "script command")
+	}
+
+	return &Text{Value: b.String(), Kind: "output"}, nil
+}
+
+// loadScriptFunction evaluates the given script, which must be an expression
+// evaluating to a function.
+func loadScriptFunction(state *lua.LState, script string) (*lua.LFunction, error) {
+	chunk, err := luasandbox.LoadString(state, "return "+script)
+	if err != nil {
+		return nil, err
+	}
+
+	state.Push(chunk)
+	if err := state.PCall(0, 1, nil); err != nil {
+		return nil, err
+	}
+	value := state.Get(-1)
+	state.Pop(1)
+
+	f, ok := value.(*lua.LFunction)
+	if !ok {
+		return nil, errors.Errorf("script must be a function, got %s", value.Type())
+	}
+	return f, nil
+}
+
+// newMatchTable creates the table describing a single match that is passed to
+// the script:
+//
+//	{
+//	  value = "v1.2.3",                        -- the text of the whole match
+//	  groups = { "1", "2", "3" },              -- the capture groups, in order
+//	  named = { major = "1", ... },            -- the named capture groups
+//	  offset = 42,                             -- the byte offset of the match
+//	  line = 3,                                -- the 1-based line of the match
+//	}
+//
+// Capture groups that did not participate in the match are empty strings.
+func newMatchTable(state *lua.LState, match *regexp.Regexp, content string, submatches []int) *lua.LTable {
+	t := state.NewTable()
+	t.RawSetString("value", lua.LString(content[submatches[0]:submatches[1]]))
+	t.RawSetString("offset", lua.LNumber(submatches[0]))
+	t.RawSetString("line", lua.LNumber(strings.Count(content[:submatches[0]], "\n")+1))
+
+	groups := state.NewTable()
+	named := state.NewTable()
+	for i, name := range match.SubexpNames() {
+		if i == 0 {
+			continue
+		}
+
+		var value string
+		if start, end := submatches[2*i], submatches[2*i+1]; start >= 0 {
+			value = content[start:end]
+		}
+
+		groups.Append(lua.LString(value))
+		if name != "" {
+			named.RawSetString(name, lua.LString(value))
+		}
+	}
+	t.RawSetString("groups", groups)
+	t.RawSetString("named", named)
+
+	return t
+}
+
+// newFileTable creates the table describing the result the matches belong to
+// that is passed to the script. It holds the same metadata as the variables of
+// output templates, except for the content.
+func newFileTable(state *lua.LState, env *MetaEnvironment) *lua.LTable {
+	t := state.NewTable()
+	for name, value := range map[string]string{
+		"repo":   env.Repo,
+		"path":   env.Path,
+		"commit": env.Commit,
+		"author": env.Author,
+		"date":   env.Date,
+		"email":  env.Email,
+		"lang":   env.Lang,
+	} {
+		t.RawSetString(name, lua.LString(value))
+	}
+	return t
+}
+
+func (c *Script) Run(ctx context.Context, db database.DB, r result.Match) (Result, error) {
+	content, ok, err := resultContent(ctx, db, r, false)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	return runScript(ctx, content, c.SearchPattern, c.Script, c.Separator, NewMetaEnvironment(r, content))
+}
@@ -0,0 +1,77 @@
+package compute
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/regexp"
+	"github.com/hexops/autogold"
+)
+
+func Test_runScript(t *testing.T) {
+	test := func(input string, cmd *Script) string {
+		env := &MetaEnvironment{Repo: "my/awesome/repo", Path: "go.mod", Lang: "Go"}
+		result, err := runScript(context.Background(), input, cmd.SearchPattern, cmd.Script, cmd.Separator, env)
+		if err != nil {
+			return err.Error()
+		}
+		return result.Value
+	}
+
+	autogold.Want(
+		"normalize version strings",
+		"v1.2.0~v2.0.0~").
+		Equal(t, test("foo 1.2 bar v2.0.0", &Script{
+			SearchPattern: &Regexp{Value: regexp.MustCompile(`v?(\d+)\.(\d+)(?:\.(\d+))?`)},
+			Script: `function(m)
+				local patch = m.groups[3]
+				if patch == "" then patch = "0" end
+				return "v" .. m.groups[1] .. "." .. m.groups[2] .. "." .. patch
+			end`,
+			Separator: "~",
+		}))
+
+	autogold.Want(
+		"named groups, file metadata and skipped matches",
+		"my/awesome/repo:go.mod:2 (Go) sourcegraph/log\n").
+		Equal(t, test("require (\n\tgithub.com/sourcegraph/log v1\n\tgithub.com/other/log v1\n)", &Script{
+			SearchPattern: &Regexp{Value: regexp.MustCompile(`github\.com/(?P<org>\w+)/(?P<name>\w+)`)},
+			Script: `function(m, file)
+				if m.named.org ~= "sourcegraph" then return nil end
+				return file.repo .. ":" .. file.path .. ":" .. m.line .. " (" .. file.lang .. ") " .. m.named.org .. "/" .. m.named.name
+			end`,
+			Separator: "\n",
+		}))
+
+	autogold.Want(
+		"non-string return value",
+		"script command: script must return a string or nil, got table").
+		Equal(t, test("a", &Script{
+			SearchPattern: &Regexp{Value: regexp.MustCompile(`a`)},
+			Script:        `function(m) return {} end`,
+		}))
+
+	autogold.Want(
+		"script is not a function",
+		"script command: script must be a function, got string").
+		Equal(t, test("a", &Script{
+			SearchPattern: &Regexp{Value: regexp.MustCompile(`a`)},
+			Script:        `"a"`,
+		}))
+
+	autogold.Want(
+		"no access to io",
+		"script command: <string>:1: attempt to index a non-table object(nil) with key 'open'\nstack traceback:\n\t<string>:1: in main chunk\n\t[G]: ?").
+		Equal(t, test("a", &Script{
+			SearchPattern: &Regexp{Value: regexp.MustCompile(`a`)},
+			Script:        `io.open("/etc/passwd")`,
+		}))
+
+	autogold.Want(
+		"memory limit",
+		"script command: memory limit exceeded").
+		Equal(t, test("a", &Script{
+			SearchPattern: &Regexp{Value: regexp.MustCompile(`a`)},
+			Script:        `function(m) local t = {} for i=1,1e9 do t[i] = i end return #t end`,
+		}))
+}
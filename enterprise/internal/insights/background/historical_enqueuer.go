@@ -154,6 +154,16 @@ func NewScopedBackfiller(workerBaseStore *basestore.Store, insightsStore *store.
 }
 
 func (s *ScopedBackfiller) ScopedBackfill(ctx context.Context, definitions []itypes.InsightSeries) error {
+	definitions, preciseReferencesSeries := splitPreciseReferencesSeries(definitions)
+	for _, series := range preciseReferencesSeries {
+		for _, job := range preciseReferencesJobs(series) {
+			job.Priority = int(priority.High)
+			if err := s.enqueueQueryRunnerJob(ctx, job); err != nil {
+				return err
+			}
+		}
+	}
+
 	var repositories []string
 	uniques := make(map[string]any)
 	stats := make(statistics)
@@ -370,9 +380,17 @@ func (h *historicalEnqueuer) Handler(ctx context.Context) error {
 	for _, series := range foundInsights {
 		log15.Info("Loaded insight data series for historical processing", "series_id", series.SeriesID)
 	}
-	if err := h.buildFrames(ctx, foundInsights); err != nil {
+	searchSeries, preciseReferencesSeries := splitPreciseReferencesSeries(foundInsights)
+	if err := h.buildFrames(ctx, searchSeries); err != nil {
 		multi = errors.Append(multi, err)
 	}
+	for _, series := range preciseReferencesSeries {
+		for _, job := range preciseReferencesJobs(series) {
+			if err := h.enqueueQueryRunnerJob(ctx, job); err != nil {
+				multi = errors.Append(multi, err)
+			}
+		}
+	}
 	if err == nil {
 		// we successfully performed a full repo iteration without any "hard" errors, so we will update the metadata
 		// of each insight series to reflect they have seen a full iteration. This does not mean they were necessarily successful,
@@ -639,6 +657,37 @@ func (a *backfillAnalyzer) analyzeSeries(ctx context.Context, bctx *buildSeriesC
 	return err, job, preempted
 }
 
+// splitPreciseReferencesSeries splits the series with the precise references generation method from the
+// series that are backfilled by searching each repository.
+func splitPreciseReferencesSeries(definitions []itypes.InsightSeries) (search, preciseReferences []itypes.InsightSeries) {
+	for _, series := range definitions {
+		if series.GenerationMethod == itypes.PreciseReferences {
+			preciseReferences = append(preciseReferences, series)
+		} else {
+			search = append(search, series)
+		}
+	}
+	return search, preciseReferences
+}
+
+// preciseReferencesJobs returns the jobs that backfill a series with the precise references generation
+// method. Unlike search series, which are backfilled one repository at a time, each job counts the
+// references in all the repositories of the series at one point in time, using the uploads of the most
+// recent commit at that time.
+func preciseReferencesJobs(series itypes.InsightSeries) []*queryrunner.Job {
+	frames := query.BuildFrames(12, timeseries.TimeInterval{
+		Unit:  itypes.IntervalUnit(series.SampleIntervalUnit),
+		Value: series.SampleIntervalValue,
+	}, series.CreatedAt.Truncate(time.Hour*24))
+
+	jobs := make([]*queryrunner.Job, 0, len(frames))
+	for _, frame := range frames {
+		execution := &compression.QueryExecution{RecordingTime: frame.From}
+		jobs = append(jobs, queryrunner.ToQueueJob(execution, series.SeriesID, series.Query, priority.Indexed, priority.FromTimeInterval(frame.From, series.CreatedAt)))
+	}
+	return jobs
+}
+
 // cachedGitFirstEverCommit is a simple in-memory cache for gitFirstEverCommit calls. It does so
 // using a map, and entries are never evicted because they are expected to be small and in general
 // unchanging.
@@ -108,13 +108,18 @@ func enqueue(ctx context.Context, dataSeries []types.InsightSeries, mode store.P
 		uniqueSeries[seriesID] = series
 
 		// Construct the search query that will generate data for this repository and time (revision) tuple.
-		modifiedQuery, err := querybuilder.GlobalQuery(series.Query)
-		if err != nil {
-			multi = errors.Append(multi, errors.Wrapf(err, "GlobalQuery series_id:%s", seriesID))
-			continue
+		// The query of a precise references series is a moniker rather than a search query.
+		modifiedQuery := series.Query
+		if series.GenerationMethod != types.PreciseReferences {
+			var err error
+			modifiedQuery, err = querybuilder.GlobalQuery(series.Query)
+			if err != nil {
+				multi = errors.Append(multi, errors.Wrapf(err, "GlobalQuery series_id:%s", seriesID))
+				continue
+			}
 		}
 
-		err = enqueueQueryRunnerJob(ctx, &queryrunner.Job{
+		err := enqueueQueryRunnerJob(ctx, &queryrunner.Job{
 			SeriesID:    seriesID,
 			SearchQuery: modifiedQuery,
 			State:       "queued",
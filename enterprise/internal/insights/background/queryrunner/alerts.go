@@ -0,0 +1,200 @@
+package queryrunner
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/inconshreveable/log15"
+
+	cmbackground "github.com/sourcegraph/sourcegraph/enterprise/internal/codemonitors/background"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/store"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// evaluateAlerts evaluates the alert rules of the series after a new value was recorded at
+// recordTime, and runs the code monitor actions of the rules that are met.
+func (r *workHandler) evaluateAlerts(ctx context.Context, series *types.InsightSeries, recordTime time.Time) error {
+	if r.sendAlert == nil || r.alertOwner == nil {
+		return nil
+	}
+
+	alerts, err := r.metadadataStore.GetSeriesAlerts(ctx, series.SeriesID)
+	if err != nil || len(alerts) == 0 {
+		return err
+	}
+
+	type ownerTotals struct {
+		times   []time.Time
+		values  []float64
+		visible bool
+	}
+	totalsByOwner := make(map[int32]ownerTotals)
+
+	var errs error
+	for _, alert := range alerts {
+		userID, orgIDs, err := r.alertOwner(ctx, alert.CodeMonitorID)
+		if err != nil {
+			errs = errors.Append(errs, errors.Wrapf(err, "owner of alert %d", alert.ID))
+			continue
+		}
+
+		// 🚨 SECURITY: The actions of the code monitor notify its owner, so the series is
+		// evaluated with the permissions of the owner rather than those of the internal actor.
+		// Otherwise the alert would reveal values recorded for repositories the owner cannot see.
+		totals, ok := totalsByOwner[userID]
+		if !ok {
+			times, values, visible, err := r.userSeriesTotals(ctx, series, recordTime, userID, orgIDs)
+			if err != nil {
+				errs = errors.Append(errs, err)
+				continue
+			}
+			totals = ownerTotals{times: times, values: values, visible: visible}
+			totalsByOwner[userID] = totals
+		}
+		if !totals.visible {
+			log15.Warn("insights alert owner cannot see the series", "seriesID", series.SeriesID, "alertID", alert.ID, "userID", userID)
+			continue
+		}
+
+		reason, fired := evaluateAlert(alert, totals.times, totals.values)
+		if !fired {
+			continue
+		}
+		log15.Info("insights alert fired", "seriesID", series.SeriesID, "alertID", alert.ID, "reason", reason)
+
+		err = r.sendAlert(ctx, alert.CodeMonitorID, cmbackground.InsightAlert{
+			SeriesID: series.SeriesID,
+			Query:    series.Query,
+			Reason:   reason,
+			Value:    totals.values[len(totals.values)-1],
+		})
+		if err != nil {
+			errs = errors.Append(errs, errors.Wrapf(err, "sending alert %d", alert.ID))
+			continue
+		}
+		if err := r.metadadataStore.MarkSeriesAlertFired(ctx, alert.ID, recordTime); err != nil {
+			errs = errors.Append(errs, err)
+		}
+	}
+	return errs
+}
+
+// userSeriesTotals returns the totals of the series up to recordTime as seen by the given user,
+// who is a member of the given organizations. It reports whether the user can still see the
+// series through an insight view shared with them.
+func (r *workHandler) userSeriesTotals(ctx context.Context, series *types.InsightSeries, recordTime time.Time, userID int32, orgIDs []int) ([]time.Time, []float64, bool, error) {
+	ctx = actor.WithActor(ctx, actor.FromUser(userID))
+
+	views, err := r.metadadataStore.GetAll(ctx, store.InsightQueryArgs{SeriesID: series.SeriesID, UserID: []int{int(userID)}, OrgID: orgIDs, Limit: 1})
+	if err != nil {
+		return nil, nil, false, errors.Wrap(err, "GetAll")
+	}
+	if len(views) == 0 {
+		return nil, nil, false, nil
+	}
+
+	// The points of repositories the user cannot see are excluded based on the actor.
+	points, err := r.insightsStore.SeriesPoints(ctx, store.SeriesPointsOpts{
+		SeriesID:         &series.SeriesID,
+		To:               &recordTime,
+		ExcludeSnapshots: true,
+	})
+	if err != nil {
+		return nil, nil, false, errors.Wrap(err, "SeriesPoints")
+	}
+	times, values := seriesTotals(points)
+	return times, values, true, nil
+}
+
+// seriesTotals returns the points in time of the series in ascending order along with their
+// values, where the values of all the capture groups at a point in time are summed.
+func seriesTotals(points []store.SeriesPoint) ([]time.Time, []float64) {
+	totals := make(map[time.Time]float64, len(points))
+	for _, p := range points {
+		totals[p.Time] += p.Value
+	}
+	times := make([]time.Time, 0, len(totals))
+	for t := range totals {
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	values := make([]float64, 0, len(times))
+	for _, t := range times {
+		values = append(values, totals[t])
+	}
+	return times, values
+}
+
+// evaluateAlert evaluates the alert rule against the values of a series at the given points
+// in time, where the last value is the one that was just recorded. It returns why the alert
+// fired.
+func evaluateAlert(alert *types.InsightSeriesAlert, times []time.Time, values []float64) (reason string, fired bool) {
+	n := len(values)
+	if n == 0 {
+		return "", false
+	}
+	latest := values[n-1]
+
+	// Never fire twice for the same recording, e.g. when the job is retried.
+	if alert.LastFiredAt != nil && !alert.LastFiredAt.Before(times[n-1]) {
+		return "", false
+	}
+
+	switch alert.Kind {
+	case types.ThresholdAlert:
+		// Only fire when the threshold is crossed, not on every recording above it.
+		if latest < alert.Threshold || (n > 1 && values[n-2] >= alert.Threshold) {
+			return "", false
+		}
+		return fmt.Sprintf("rose to %s, reaching the threshold of %s", formatValue(latest), formatValue(alert.Threshold)), true
+
+	case types.PercentChangeAlert:
+		if n <= alert.Intervals {
+			return "", false
+		}
+		// Once fired, the change must be measured over a window that starts after the
+		// recording that fired the alert. Otherwise a single jump would fire again on each of
+		// the following recordings for as long as it stays within the window.
+		if alert.LastFiredAt != nil && !times[n-1-alert.Intervals].After(*alert.LastFiredAt) {
+			return "", false
+		}
+		base := values[n-1-alert.Intervals]
+		if base == latest {
+			return "", false
+		}
+
+		change := math.Inf(1)
+		if base == 0 {
+			if latest < 0 {
+				change = math.Inf(-1)
+			}
+		} else {
+			change = (latest - base) / math.Abs(base) * 100
+		}
+		if (alert.Threshold >= 0 && change < alert.Threshold) || (alert.Threshold < 0 && change > alert.Threshold) {
+			return "", false
+		}
+
+		intervals := "interval"
+		if alert.Intervals != 1 {
+			intervals = fmt.Sprintf("%d intervals", alert.Intervals)
+		}
+		if math.IsInf(change, 0) {
+			return fmt.Sprintf("changed from %s to %s over the last %s", formatValue(base), formatValue(latest), intervals), true
+		}
+		return fmt.Sprintf("changed by %+.1f%% from %s to %s over the last %s", change, formatValue(base), formatValue(latest), intervals), true
+	}
+
+	return "", false
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,145 @@
+package queryrunner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/store"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/types"
+)
+
+func TestSeriesTotals(t *testing.T) {
+	t1 := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.AddDate(0, 1, 0)
+	points := []store.SeriesPoint{
+		{Time: t2, Value: 3},
+		{Time: t1, Value: 1},
+		{Time: t2, Value: 4},
+		{Time: t1, Value: 2},
+	}
+	times, values := seriesTotals(points)
+	if diff := cmp.Diff([]time.Time{t1, t2}, times); diff != "" {
+		t.Fatalf("unexpected times (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]float64{3, 7}, values); diff != "" {
+		t.Fatalf("unexpected totals (-want +got):\n%s", diff)
+	}
+}
+
+func TestEvaluateAlert(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	// recording returns the time of the i-th recording of the series.
+	recording := func(i int) *time.Time {
+		t := start.AddDate(0, i, 0)
+		return &t
+	}
+
+	tests := []struct {
+		name   string
+		alert  types.InsightSeriesAlert
+		values []float64
+		want   string
+		fired  bool
+	}{
+		{
+			name:   "no values",
+			alert:  types.InsightSeriesAlert{Kind: types.ThresholdAlert, Threshold: 10, Intervals: 1},
+			values: nil,
+		},
+		{
+			name:   "threshold crossed",
+			alert:  types.InsightSeriesAlert{Kind: types.ThresholdAlert, Threshold: 100, Intervals: 1},
+			values: []float64{80, 120},
+			want:   "rose to 120, reaching the threshold of 100",
+			fired:  true,
+		},
+		{
+			name:   "threshold reached by first value",
+			alert:  types.InsightSeriesAlert{Kind: types.ThresholdAlert, Threshold: 100, Intervals: 1},
+			values: []float64{100},
+			want:   "rose to 100, reaching the threshold of 100",
+			fired:  true,
+		},
+		{
+			name:   "threshold already exceeded",
+			alert:  types.InsightSeriesAlert{Kind: types.ThresholdAlert, Threshold: 100, Intervals: 1},
+			values: []float64{110, 120},
+		},
+		{
+			name:   "threshold not reached",
+			alert:  types.InsightSeriesAlert{Kind: types.ThresholdAlert, Threshold: 100, Intervals: 1},
+			values: []float64{80, 99.5},
+		},
+		{
+			name:   "percent increase",
+			alert:  types.InsightSeriesAlert{Kind: types.PercentChangeAlert, Threshold: 20, Intervals: 1},
+			values: []float64{100, 125},
+			want:   "changed by +25.0% from 100 to 125 over the last interval",
+			fired:  true,
+		},
+		{
+			name:   "percent increase below threshold",
+			alert:  types.InsightSeriesAlert{Kind: types.PercentChangeAlert, Threshold: 30, Intervals: 1},
+			values: []float64{100, 125},
+		},
+		{
+			name:   "percent decrease",
+			alert:  types.InsightSeriesAlert{Kind: types.PercentChangeAlert, Threshold: -10, Intervals: 2},
+			values: []float64{100, 95, 80},
+			want:   "changed by -20.0% from 100 to 80 over the last 2 intervals",
+			fired:  true,
+		},
+		{
+			name:   "increase does not fire decrease alert",
+			alert:  types.InsightSeriesAlert{Kind: types.PercentChangeAlert, Threshold: -10, Intervals: 1},
+			values: []float64{100, 150},
+		},
+		{
+			name:   "not enough values",
+			alert:  types.InsightSeriesAlert{Kind: types.PercentChangeAlert, Threshold: 10, Intervals: 3},
+			values: []float64{1, 2, 3},
+		},
+		{
+			name:   "change from zero",
+			alert:  types.InsightSeriesAlert{Kind: types.PercentChangeAlert, Threshold: 50, Intervals: 1},
+			values: []float64{0, 5},
+			want:   "changed from 0 to 5 over the last interval",
+			fired:  true,
+		},
+		{
+			name:   "percent increase already fired",
+			alert:  types.InsightSeriesAlert{Kind: types.PercentChangeAlert, Threshold: 20, Intervals: 2, LastFiredAt: recording(1)},
+			values: []float64{100, 150, 150},
+		},
+		{
+			name:   "percent increase after window moved past last firing",
+			alert:  types.InsightSeriesAlert{Kind: types.PercentChangeAlert, Threshold: 20, Intervals: 2, LastFiredAt: recording(1)},
+			values: []float64{100, 150, 150, 160, 200},
+			want:   "changed by +33.3% from 150 to 200 over the last 2 intervals",
+			fired:  true,
+		},
+		{
+			name:   "same recording evaluated again",
+			alert:  types.InsightSeriesAlert{Kind: types.ThresholdAlert, Threshold: 100, Intervals: 1, LastFiredAt: recording(1)},
+			values: []float64{80, 120},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			times := make([]time.Time, 0, len(test.values))
+			for i := range test.values {
+				times = append(times, *recording(i))
+			}
+			got, fired := evaluateAlert(&test.alert, times, test.values)
+			if fired != test.fired {
+				t.Fatalf("unexpected fired: want %v, got %v", test.fired, fired)
+			}
+			if got != test.want {
+				t.Errorf("unexpected reason: want %q, got %q", test.want, got)
+			}
+		})
+	}
+}
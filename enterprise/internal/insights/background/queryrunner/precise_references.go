@@ -0,0 +1,127 @@
+package queryrunner
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/store"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/authz"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// DumpStore is the subset of the codeintel dbstore used to find the processed LSIF uploads of
+// repositories at a point in time.
+type DumpStore interface {
+	GetDumpsAtTime(ctx context.Context, repositoryIDs []int, before time.Time) ([]dbstore.Dump, error)
+}
+
+// LSIFStore is the subset of the codeintel lsifstore used to count precise references.
+type LSIFStore interface {
+	ReferenceCounts(ctx context.Context, uploadIDs []int, monikers []precise.MonikerData) (map[int]int, error)
+}
+
+// ParseMoniker parses the query of a series with the precise references generation method, which
+// is a moniker in the form scheme:identifier such as gomod:github.com/sourcegraph/oldpkg:Foo.
+func ParseMoniker(query string) (precise.MonikerData, error) {
+	scheme, identifier, ok := strings.Cut(strings.TrimSpace(query), ":")
+	if !ok || scheme == "" || identifier == "" {
+		return precise.MonikerData{}, errors.Newf("invalid moniker %q: expected scheme:identifier", query)
+	}
+	return precise.MonikerData{Scheme: scheme, Identifier: identifier}, nil
+}
+
+func (r *workHandler) preciseReferencesHandler(ctx context.Context, job *Job, series *types.InsightSeries, recordTime time.Time) (err error) {
+	if series.JustInTime {
+		return errors.Newf("just in time series are not eligible for background processing, series_id: %s", series.ID)
+	}
+
+	recordings, err := r.generatePreciseReferencesRecordings(ctx, job, series, recordTime)
+	if err != nil {
+		return err
+	}
+
+	err = r.persistRecordings(ctx, job, series, recordings)
+	return err
+}
+
+// generatePreciseReferencesRecordings records the number of precise references to the moniker of the
+// series in each repository, as of the most recent commit at recordTime that has processed uploads.
+func (r *workHandler) generatePreciseReferencesRecordings(ctx context.Context, job *Job, series *types.InsightSeries, recordTime time.Time) (_ []store.RecordSeriesPointArgs, err error) {
+	if r.dumpStore == nil || r.lsifStore == nil {
+		return nil, errors.New("precise references are not available")
+	}
+
+	moniker, err := ParseMoniker(job.SearchQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	// Counting the references in the uploads of every repository would not be bounded, so
+	// precise references series must be scoped to a set of repositories.
+	if len(series.Repositories) == 0 {
+		return nil, errors.Newf("precise references series must be scoped to repositories, series_id: %s", series.SeriesID)
+	}
+	repos, err := r.repoStore.List(ctx, database.ReposListOptions{Names: series.Repositories})
+	if err != nil {
+		return nil, errors.Wrap(err, "repoStore.List")
+	}
+	if len(repos) == 0 {
+		return nil, nil
+	}
+	repositoryIDs := make([]int, 0, len(repos))
+	for _, repo := range repos {
+		repositoryIDs = append(repositoryIDs, int(repo.ID))
+	}
+
+	dumps, err := r.dumpStore.GetDumpsAtTime(ctx, repositoryIDs, recordTime)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetDumpsAtTime")
+	}
+	uploadIDs := make([]int, 0, len(dumps))
+	for _, dump := range dumps {
+		uploadIDs = append(uploadIDs, dump.ID)
+	}
+
+	countsByUpload, err := r.lsifStore.ReferenceCounts(ctx, uploadIDs, []precise.MonikerData{moniker})
+	if err != nil {
+		return nil, errors.Wrap(err, "ReferenceCounts")
+	}
+
+	// A repository may have multiple uploads at the sampled commit, e.g. for different roots.
+	countsByRepo := make(map[api.RepoID]int, len(dumps))
+	repoNames := make(map[api.RepoID]string, len(dumps))
+	for _, dump := range dumps {
+		count, ok := countsByUpload[dump.ID]
+		if !ok {
+			continue
+		}
+		repoID := api.RepoID(dump.RepositoryID)
+		countsByRepo[repoID] += count
+		repoNames[repoID] = dump.RepositoryName
+	}
+
+	checker := authz.DefaultSubRepoPermsChecker
+	var recordings []store.RecordSeriesPointArgs
+
+	for repoID, count := range countsByRepo {
+		// sub-repo permissions filtering. If the repo supports it, then it should be excluded from search results
+		var subRepoEnabled bool
+		subRepoEnabled, err = checkSubRepoPermissions(ctx, checker, repoID, err)
+		if subRepoEnabled {
+			continue
+		}
+		recordings = append(recordings, ToRecording(job, float64(count), recordTime, repoNames[repoID], repoID, nil)...)
+	}
+	if err != nil {
+		// Fail the job so that it is retried, rather than recording the series without the
+		// repositories whose permissions could not be checked.
+		return nil, errors.Wrap(err, "checkSubRepoPermissions")
+	}
+	return recordings, nil
+}
@@ -0,0 +1,128 @@
+package queryrunner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/store"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/types"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	dbtypes "github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+)
+
+func TestParseMoniker(t *testing.T) {
+	got, err := ParseMoniker(" gomod:github.com/sourcegraph/oldpkg:Foo ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := precise.MonikerData{Scheme: "gomod", Identifier: "github.com/sourcegraph/oldpkg:Foo"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected moniker (-want +got):\n%s", diff)
+	}
+
+	for _, query := range []string{"", "oldpkg.Foo", "gomod:", ":Foo"} {
+		if _, err := ParseMoniker(query); err == nil {
+			t.Errorf("expected error parsing %q", query)
+		}
+	}
+}
+
+type fakeDumpStore func(ctx context.Context, repositoryIDs []int, before time.Time) ([]dbstore.Dump, error)
+
+func (f fakeDumpStore) GetDumpsAtTime(ctx context.Context, repositoryIDs []int, before time.Time) ([]dbstore.Dump, error) {
+	return f(ctx, repositoryIDs, before)
+}
+
+type fakeLSIFStore func(ctx context.Context, uploadIDs []int, monikers []precise.MonikerData) (map[int]int, error)
+
+func (f fakeLSIFStore) ReferenceCounts(ctx context.Context, uploadIDs []int, monikers []precise.MonikerData) (map[int]int, error) {
+	return f(ctx, uploadIDs, monikers)
+}
+
+func TestGeneratePreciseReferencesRecordings(t *testing.T) {
+	recordTime := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	repoStore := database.NewMockRepoStore()
+	repoStore.ListFunc.SetDefaultReturn([]*dbtypes.Repo{{ID: 10}, {ID: 11}, {ID: 12}}, nil)
+
+	handler := workHandler{
+		repoStore: repoStore,
+		dumpStore: fakeDumpStore(func(ctx context.Context, repositoryIDs []int, before time.Time) ([]dbstore.Dump, error) {
+			if diff := cmp.Diff([]int{10, 11, 12}, repositoryIDs); diff != "" {
+				t.Errorf("unexpected repository IDs (-want +got):\n%s", diff)
+			}
+			if !before.Equal(recordTime) {
+				t.Errorf("unexpected time: %s", before)
+			}
+			return []dbstore.Dump{
+				{ID: 1, RepositoryID: 10, RepositoryName: "github.com/a/a"},
+				{ID: 2, RepositoryID: 10, RepositoryName: "github.com/a/a"},
+				{ID: 3, RepositoryID: 11, RepositoryName: "github.com/b/b"},
+				{ID: 4, RepositoryID: 12, RepositoryName: "github.com/c/c"},
+			}, nil
+		}),
+		lsifStore: fakeLSIFStore(func(ctx context.Context, uploadIDs []int, monikers []precise.MonikerData) (map[int]int, error) {
+			if diff := cmp.Diff([]int{1, 2, 3, 4}, uploadIDs); diff != "" {
+				t.Errorf("unexpected upload IDs (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff([]precise.MonikerData{{Scheme: "gomod", Identifier: "github.com/a/a/oldpkg:Foo"}}, monikers); diff != "" {
+				t.Errorf("unexpected monikers (-want +got):\n%s", diff)
+			}
+			// Upload 4 has no references to the moniker.
+			return map[int]int{1: 3, 2: 4, 3: 1}, nil
+		}),
+	}
+
+	job := &Job{SeriesID: "series1", SearchQuery: "gomod:github.com/a/a/oldpkg:Foo", RecordTime: &recordTime, PersistMode: string(store.RecordMode)}
+	series := &types.InsightSeries{SeriesID: "series1", GenerationMethod: types.PreciseReferences, Repositories: []string{"github.com/a/a", "github.com/b/b", "github.com/c/c"}}
+
+	recordings, err := handler.generatePreciseReferencesRecordings(context.Background(), job, series, recordTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]float64{}
+	for _, recording := range recordings {
+		if !recording.Point.Time.Equal(recordTime) {
+			t.Errorf("unexpected recording time: %s", recording.Point.Time)
+		}
+		got[*recording.RepoName] = recording.Point.Value
+	}
+	want := map[string]float64{
+		"github.com/a/a": 7,
+		"github.com/b/b": 1,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected recordings (-want +got):\n%s", diff)
+	}
+}
+
+func TestGeneratePreciseReferencesRecordingsUnavailable(t *testing.T) {
+	handler := workHandler{}
+	job := &Job{SeriesID: "series1", SearchQuery: "gomod:github.com/a/a/oldpkg:Foo"}
+	if _, err := handler.generatePreciseReferencesRecordings(context.Background(), job, &types.InsightSeries{}, time.Now()); err == nil {
+		t.Fatal("expected error without code intelligence stores")
+	}
+}
+
+func TestGeneratePreciseReferencesRecordingsUnscoped(t *testing.T) {
+	handler := workHandler{
+		dumpStore: fakeDumpStore(func(ctx context.Context, repositoryIDs []int, before time.Time) ([]dbstore.Dump, error) {
+			t.Fatal("unexpected call to GetDumpsAtTime")
+			return nil, nil
+		}),
+		lsifStore: fakeLSIFStore(func(ctx context.Context, uploadIDs []int, monikers []precise.MonikerData) (map[int]int, error) {
+			t.Fatal("unexpected call to ReferenceCounts")
+			return nil, nil
+		}),
+	}
+	job := &Job{SeriesID: "series1", SearchQuery: "gomod:github.com/a/a/oldpkg:Foo"}
+	if _, err := handler.generatePreciseReferencesRecordings(context.Background(), job, &types.InsightSeries{SeriesID: "series1"}, time.Now()); err == nil {
+		t.Fatal("expected error for a series without repositories")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/sourcegraph/log"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	cmbackground "github.com/sourcegraph/sourcegraph/enterprise/internal/codemonitors/background"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/discovery"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/query"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/query/streaming"
@@ -47,6 +48,16 @@ type workHandler struct {
 
 	computeSearch       func(context.Context, string) ([]query.ComputeResult, error)
 	computeSearchStream func(context.Context, string) (*streaming.ComputeTabulationResult, error)
+
+	dumpStore DumpStore
+	lsifStore LSIFStore
+
+	// sendAlert runs the actions of the code monitor with the given ID for a fired alert
+	// rule. Alert rules are not evaluated if nil.
+	sendAlert func(ctx context.Context, monitorID int64, alert cmbackground.InsightAlert) error
+	// alertOwner returns the ID of the user who owns the code monitor with the given ID along
+	// with the IDs of the organizations they are a member of.
+	alertOwner func(ctx context.Context, monitorID int64) (userID int32, orgIDs []int, err error)
 }
 
 type insightsHandler func(ctx context.Context, job *Job, series *types.InsightSeries, recordTime time.Time) error
@@ -389,7 +400,12 @@ func (r *workHandler) persistRecordings(ctx context.Context, job *Job, series *t
 		return errors.Wrap(err, "filterRecordingsBySeriesRepos")
 	}
 
-	if recordErr := tx.RecordSeriesPoints(ctx, filteredRecordings); recordErr != nil {
+	ownedRecordings, err := withRepoOwners(ctx, r.repoStore, filteredRecordings)
+	if err != nil {
+		return errors.Wrap(err, "withRepoOwners")
+	}
+
+	if recordErr := tx.RecordSeriesPoints(ctx, ownedRecordings); recordErr != nil {
 		err = errors.Append(err, errors.Wrap(recordErr, "RecordSeriesPointsCapture"))
 	}
 	return err
@@ -423,6 +439,41 @@ func filterRecordingsBySeriesRepos(ctx context.Context, repoStore discovery.Repo
 
 }
 
+// withRepoOwners records the owner of the repository of each recording in its metadata, so that
+// the data points of a series can be broken down by owner.
+func withRepoOwners(ctx context.Context, repoStore discovery.RepoStore, recordings []store.RecordSeriesPointArgs) ([]store.RecordSeriesPointArgs, error) {
+	var repoIDs []api.RepoID
+	seen := map[api.RepoID]bool{}
+	for _, record := range recordings {
+		if record.RepoID != nil && !seen[*record.RepoID] {
+			seen[*record.RepoID] = true
+			repoIDs = append(repoIDs, *record.RepoID)
+		}
+	}
+	if len(repoIDs) == 0 {
+		return recordings, nil
+	}
+
+	repos, err := repoStore.List(ctx, database.ReposListOptions{IDs: repoIDs})
+	if err != nil {
+		return nil, errors.Wrap(err, "repoStore.List")
+	}
+	owners := make(map[api.RepoID]string, len(repos))
+	for _, repo := range repos {
+		owners[repo.ID] = store.RepoOwner(repo)
+	}
+
+	for i, record := range recordings {
+		if record.RepoID == nil {
+			continue
+		}
+		if owner, ok := owners[*record.RepoID]; ok {
+			recordings[i].Metadata = store.RepoSeriesPointMetadata{Owner: owner}
+		}
+	}
+	return recordings, nil
+}
+
 func (r *workHandler) Handle(ctx context.Context, logger log.Logger, record workerutil.Record) (err error) {
 	// 🚨 SECURITY: The request is performed without authentication, we get back results from every
 	// repository on Sourcegraph - results will be filtered when users query for insight data based on the
@@ -453,13 +504,25 @@ func (r *workHandler) Handle(ctx context.Context, logger log.Logger, record work
 	}
 
 	handlersByType := map[types.GenerationMethod]insightsHandler{
-		types.SearchCompute: r.computeHandler,
-		types.Search:        r.searchHandler,
+		types.SearchCompute:     r.computeHandler,
+		types.Search:            r.searchHandler,
+		types.PreciseReferences: r.preciseReferencesHandler,
 	}
 
 	executableHandler, ok := handlersByType[series.GenerationMethod]
 	if !ok {
 		return errors.Newf("unable to handle record for series_id: %s and generation_method: %s", series.SeriesID, series.GenerationMethod)
 	}
-	return executableHandler(ctx, job, series, recordTime)
+	if err := executableHandler(ctx, job, series, recordTime); err != nil {
+		return err
+	}
+
+	// Alert rules are only evaluated for new recordings of the series, not when backfilling
+	// historical data or taking snapshots.
+	if job.RecordTime == nil && store.PersistMode(job.PersistMode) == store.RecordMode {
+		if err := r.evaluateAlerts(ctx, series, recordTime); err != nil {
+			log15.Error("insights.queryrunner.workHandler: failed to evaluate alerts", "seriesID", series.SeriesID, "error", err)
+		}
+	}
+	return nil
 }
@@ -21,6 +21,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/authz"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
 	dbtypes "github.com/sourcegraph/sourcegraph/internal/types"
 	"github.com/sourcegraph/sourcegraph/lib/errors"
 )
@@ -1400,3 +1401,38 @@ func TestGetSeries(t *testing.T) {
 	})
 
 }
+
+func TestWithRepoOwners(t *testing.T) {
+	repoStore := database.NewMockRepoStore()
+	repoStore.ListFunc.SetDefaultReturn([]*dbtypes.Repo{
+		{ID: 1, Name: "github.com/a/x", ExternalRepo: api.ExternalRepoSpec{ServiceID: "https://github.com/"}, Metadata: &github.Repository{NameWithOwner: "a/x"}},
+		{ID: 2, Name: "example.com/b/y"},
+	}, nil)
+
+	repoID := func(id api.RepoID) *api.RepoID { return &id }
+	recordings := []store.RecordSeriesPointArgs{
+		{RepoID: repoID(1)},
+		{RepoID: repoID(2)},
+		{RepoID: repoID(3)},
+		{},
+	}
+	got, err := withRepoOwners(context.Background(), repoStore, recordings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []any{
+		store.RepoSeriesPointMetadata{Owner: "github.com/a"},
+		store.RepoSeriesPointMetadata{Owner: "example.com/b"},
+		nil,
+		nil,
+	}
+	for i, recording := range got {
+		if recording.Metadata != want[i] {
+			t.Errorf("recording %d: want metadata %v, got %v", i, want[i], recording.Metadata)
+		}
+	}
+	if ids := repoStore.ListFunc.History()[0].Arg1.IDs; len(ids) != 3 {
+		t.Errorf("want the repositories of 3 recordings listed, got %v", ids)
+	}
+}
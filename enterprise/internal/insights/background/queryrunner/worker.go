@@ -12,6 +12,8 @@ import (
 
 	"github.com/sourcegraph/log"
 
+	cmbackground "github.com/sourcegraph/sourcegraph/enterprise/internal/codemonitors/background"
+	edb "github.com/sourcegraph/sourcegraph/enterprise/internal/database"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/compression"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/discovery"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/query"
@@ -38,7 +40,7 @@ import (
 
 // NewWorker returns a worker that will execute search queries and insert information about the
 // results into the code insights database.
-func NewWorker(ctx context.Context, logger log.Logger, workerStore dbworkerstore.Store, insightsStore *store.Store, repoStore discovery.RepoStore, metrics workerutil.WorkerMetrics) *workerutil.Worker {
+func NewWorker(ctx context.Context, logger log.Logger, workerStore dbworkerstore.Store, insightsStore *store.Store, repoStore discovery.RepoStore, dumpStore DumpStore, lsifStore LSIFStore, db edb.EnterpriseDB, metrics workerutil.WorkerMetrics) *workerutil.Worker {
 	numHandlers := conf.Get().InsightsQueryWorkerConcurrency
 	if numHandlers <= 0 {
 		numHandlers = 1
@@ -77,6 +79,13 @@ func NewWorker(ctx context.Context, logger log.Logger, workerStore dbworkerstore
 		return float64(count)
 	}))
 
+	prometheus.DefaultRegisterer.MustRegister(dbworker.NewQueuedCountByFairnessKeyCollector(
+		workerStore,
+		"src_insights_search_queue_fairness_key_total",
+		"Total number of jobs in the queued state per series.",
+		nil,
+	))
+
 	return dbworker.NewWorker(ctx, workerStore, &workHandler{
 		baseWorkerStore: basestore.NewWithHandle(workerStore.Handle()),
 		insightsStore:   insightsStore,
@@ -102,6 +111,26 @@ func NewWorker(ctx context.Context, logger log.Logger, workerStore dbworkerstore
 			}
 			return streamResults, nil
 		},
+		dumpStore: dumpStore,
+		lsifStore: lsifStore,
+		sendAlert: func(ctx context.Context, monitorID int64, alert cmbackground.InsightAlert) error {
+			return cmbackground.SendInsightAlert(ctx, db.CodeMonitors(), monitorID, alert)
+		},
+		alertOwner: func(ctx context.Context, monitorID int64) (int32, []int, error) {
+			monitor, err := db.CodeMonitors().GetMonitor(ctx, monitorID)
+			if err != nil {
+				return 0, nil, errors.Wrap(err, "GetMonitor")
+			}
+			orgs, err := db.Orgs().GetByUserID(ctx, monitor.UserID)
+			if err != nil {
+				return 0, nil, errors.Wrap(err, "GetByUserID")
+			}
+			orgIDs := make([]int, 0, len(orgs))
+			for _, org := range orgs {
+				orgIDs = append(orgIDs, int(org.ID))
+			}
+			return monitor.UserID, orgIDs, nil
+		},
 	}, options)
 }
 
@@ -147,7 +176,12 @@ func CreateDBWorkerStore(s *basestore.Store, observationContext *observation.Con
 		RetryAfter:        30 * time.Minute,
 		MaxNumRetries:     10,
 		MaxNumResets:      10,
-		OrderByExpression: sqlf.Sprintf("priority, id"),
+		OrderByExpression: sqlf.Sprintf("id"),
+
+		// Jobs of the same priority are dequeued round-robin between series so that
+		// backfilling a single series cannot delay all the others.
+		PriorityExpression:    sqlf.Sprintf("priority"),
+		FairnessKeyExpression: sqlf.Sprintf("series_id"),
 	}, observationContext)
 }
 
@@ -2,6 +2,7 @@ package insights
 
 import (
 	"context"
+	"net/http"
 	"os"
 	"strconv"
 	"time"
@@ -53,6 +54,7 @@ func Init(ctx context.Context, postgres database.DB, _ conftypes.UnifiedWatchabl
 		return err
 	}
 	enterpriseServices.InsightsResolver = resolvers.New(db, postgres)
+	enterpriseServices.NewInsightsExportHandler = func() http.Handler { return resolvers.NewExportHandler(db, postgres) }
 
 	return nil
 }
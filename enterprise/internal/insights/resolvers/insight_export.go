@@ -0,0 +1,226 @@
+package resolvers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/inconshreveable/log15"
+
+	edb "github.com/sourcegraph/sourcegraph/enterprise/internal/database"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/store"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/timeutil"
+)
+
+// GET /insights/{ID}/export?format=csv|json
+//
+// NewExportHandler returns a handler that downloads all the recorded data points of the series of
+// the insight view with the given GraphQL ID, broken down by repository and capture group. The
+// filters saved on the insight view are applied. The format defaults to CSV.
+func NewExportHandler(db edb.InsightsDB, postgres database.DB) http.Handler {
+	base := WithBase(db, postgres, timeutil.Now)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if !actor.FromContext(ctx).IsAuthenticated() {
+			http.Error(w, "not authenticated", http.StatusUnauthorized)
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "csv"
+		}
+		if format != "csv" && format != "json" {
+			http.Error(w, "format must be one of csv or json", http.StatusBadRequest)
+			return
+		}
+
+		var uniqueID string
+		if err := unmarshalID(graphql.ID(mux.Vars(r)["ID"]), insightKind, &uniqueID); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// 🚨 SECURITY: only return the insight view if the user has been granted access to it.
+		userIDs, orgIDs, err := getUserPermissions(ctx, postgres.Orgs())
+		if err != nil {
+			log15.Error("insights: failed to get user permissions", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		viewSeries, err := base.insightStore.GetAll(ctx, store.InsightQueryArgs{UniqueID: uniqueID, UserID: userIDs, OrgID: orgIDs})
+		if err != nil {
+			log15.Error("insights: failed to get insight view", "id", uniqueID, "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		views := base.insightStore.GroupByView(ctx, viewSeries)
+		if len(views) == 0 {
+			http.Error(w, "insight not found", http.StatusNotFound)
+			return
+		}
+		view := views[0]
+
+		// Resolve the filters of every series before anything is written, so that errors can
+		// still be reported with a status code.
+		opts := make([]store.SeriesPointsOpts, 0, len(view.Series))
+		for _, series := range view.Series {
+			seriesOpts, err := getRecordedSeriesPointOpts(ctx, base.workerBaseStore.Handle().DB(), series, view.Filters)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			// Export every point that was recorded, not only the ones displayed on the chart.
+			seriesOpts.From = nil
+			opts = append(opts, *seriesOpts)
+		}
+
+		var ew exportWriter
+		if format == "json" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Disposition", `attachment; filename="insight.json"`)
+			ew = newJSONExportWriter(w)
+		} else {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="insight.csv"`)
+			ew = newCSVExportWriter(w)
+		}
+
+		for i, series := range view.Series {
+			err := base.timeSeriesStore.RepoSeriesPoints(ctx, opts[i], func(point store.RepoSeriesPoint) error {
+				return ew.Write(series, point)
+			})
+			if err != nil {
+				// The response has already started, so the best we can do is to truncate it.
+				log15.Error("insights: failed to export series points", "id", uniqueID, "seriesID", series.SeriesID, "error", err)
+				return
+			}
+		}
+		if err := ew.Close(); err != nil {
+			log15.Error("insights: failed to export series points", "id", uniqueID, "error", err)
+		}
+	})
+}
+
+// exportWriter writes the data points of insight series in an export format.
+type exportWriter interface {
+	Write(series types.InsightViewSeries, point store.RepoSeriesPoint) error
+	Close() error
+}
+
+type exportedPoint struct {
+	SeriesID     string    `json:"seriesId"`
+	SeriesLabel  string    `json:"seriesLabel"`
+	Query        string    `json:"query"`
+	RepositoryID int32     `json:"repositoryId"`
+	Repository   string    `json:"repository"`
+	Capture      *string   `json:"capture"`
+	Time         time.Time `json:"time"`
+	Value        float64   `json:"value"`
+}
+
+func newExportedPoint(series types.InsightViewSeries, point store.RepoSeriesPoint) exportedPoint {
+	return exportedPoint{
+		SeriesID:     series.SeriesID,
+		SeriesLabel:  series.Label,
+		Query:        series.Query,
+		RepositoryID: int32(point.RepoID),
+		Repository:   point.RepoName,
+		Capture:      point.Capture,
+		Time:         point.Time.UTC(),
+		Value:        point.Value,
+	}
+}
+
+var csvExportHeader = []string{"series_id", "series_label", "query", "repository_id", "repository", "capture", "time", "value"}
+
+type csvExportWriter struct {
+	w         *csv.Writer
+	wroteHead bool
+}
+
+func newCSVExportWriter(w io.Writer) *csvExportWriter {
+	return &csvExportWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvExportWriter) writeHeader() error {
+	if c.wroteHead {
+		return nil
+	}
+	c.wroteHead = true
+	return c.w.Write(csvExportHeader)
+}
+
+func (c *csvExportWriter) Write(series types.InsightViewSeries, point store.RepoSeriesPoint) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	p := newExportedPoint(series, point)
+	var capture string
+	if p.Capture != nil {
+		capture = *p.Capture
+	}
+	return c.w.Write([]string{
+		p.SeriesID,
+		p.SeriesLabel,
+		p.Query,
+		strconv.Itoa(int(p.RepositoryID)),
+		p.Repository,
+		capture,
+		p.Time.Format(time.RFC3339),
+		strconv.FormatFloat(p.Value, 'f', -1, 64),
+	})
+}
+
+func (c *csvExportWriter) Close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonExportWriter writes the data points as a JSON array, one element per line.
+type jsonExportWriter struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONExportWriter(w io.Writer) *jsonExportWriter {
+	return &jsonExportWriter{w: w}
+}
+
+func (j *jsonExportWriter) Write(series types.InsightViewSeries, point store.RepoSeriesPoint) error {
+	b, err := json.Marshal(newExportedPoint(series, point))
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if j.count == 0 {
+		sep = "[\n"
+	}
+	j.count++
+	if _, err := io.WriteString(j.w, sep); err != nil {
+		return err
+	}
+	_, err = j.w.Write(b)
+	return err
+}
+
+func (j *jsonExportWriter) Close() error {
+	end := "\n]\n"
+	if j.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(j.w, end)
+	return err
+}
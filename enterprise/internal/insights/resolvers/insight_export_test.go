@@ -0,0 +1,80 @@
+package resolvers
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/store"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/types"
+)
+
+func TestExportWriters(t *testing.T) {
+	current := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	capture := "1.18"
+
+	series := []types.InsightViewSeries{
+		{SeriesID: "s1", Label: "TODOs", Query: "TODO"},
+		{SeriesID: "s2", Query: `go\s(\d\.\d+)`},
+	}
+	points := []store.RepoSeriesPoint{
+		{SeriesID: "s1", RepoID: 1, RepoName: "github.com/a/a", Time: current, Value: 3},
+		{SeriesID: "s2", RepoID: 2, RepoName: "github.com/b/b", Time: current, Value: 1.5, Capture: &capture},
+	}
+
+	write := func(ew exportWriter) {
+		for i := range points {
+			if err := ew.Write(series[i], points[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := ew.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		write(newCSVExportWriter(&buf))
+
+		want := `series_id,series_label,query,repository_id,repository,capture,time,value
+s1,TODOs,TODO,1,github.com/a/a,,2022-06-01T00:00:00Z,3
+s2,,go\s(\d\.\d+),2,github.com/b/b,1.18,2022-06-01T00:00:00Z,1.5
+`
+		if diff := cmp.Diff(want, buf.String()); diff != "" {
+			t.Errorf("unexpected CSV (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		write(newJSONExportWriter(&buf))
+
+		want := `[
+{"seriesId":"s1","seriesLabel":"TODOs","query":"TODO","repositoryId":1,"repository":"github.com/a/a","capture":null,"time":"2022-06-01T00:00:00Z","value":3},
+{"seriesId":"s2","seriesLabel":"","query":"go\\s(\\d\\.\\d+)","repositoryId":2,"repository":"github.com/b/b","capture":"1.18","time":"2022-06-01T00:00:00Z","value":1.5}
+]
+`
+		if diff := cmp.Diff(want, buf.String()); diff != "" {
+			t.Errorf("unexpected JSON (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		var csvBuf, jsonBuf bytes.Buffer
+		if err := newCSVExportWriter(&csvBuf).Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := newJSONExportWriter(&jsonBuf).Close(); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff("series_id,series_label,query,repository_id,repository,capture,time,value\n", csvBuf.String()); diff != "" {
+			t.Errorf("unexpected CSV (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff("[]\n", jsonBuf.String()); diff != "" {
+			t.Errorf("unexpected JSON (-want +got):\n%s", diff)
+		}
+	})
+}
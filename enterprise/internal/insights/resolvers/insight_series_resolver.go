@@ -215,6 +215,8 @@ type precalculatedInsightSeriesResolver struct {
 	points   []store.SeriesPoint
 	label    string
 	filters  types.InsightViewFilters
+	// capture is the captured value of the series, if it is expanded from a capture group series.
+	capture *string
 }
 
 func (p *precalculatedInsightSeriesResolver) SeriesId() string {
@@ -400,6 +402,7 @@ func expandCaptureGroupSeriesRecorded(ctx context.Context, definition types.Insi
 	sortedCaptureGroups, limit := getSortedCaptureGroups(seriesOptions, definition, groupedByCapture)
 	var resolvers []graphqlbackend.InsightSeriesResolver
 	for _, capturedValue := range sortedCaptureGroups[0:limit] {
+		capturedValue := capturedValue
 		points := groupedByCapture[capturedValue]
 		sort.Slice(points, func(i, j int) bool {
 			return points[i].Time.Before(points[j].Time)
@@ -414,6 +417,7 @@ func expandCaptureGroupSeriesRecorded(ctx context.Context, definition types.Insi
 			filters:         filters,
 			seriesId:        fmt.Sprintf("%s-%s", definition.SeriesID, capturedValue),
 			statusResolver:  statusResolver,
+			capture:         &capturedValue,
 		})
 	}
 	if len(resolvers) == 0 {
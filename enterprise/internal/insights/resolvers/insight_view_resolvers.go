@@ -10,6 +10,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/featureflag"
 
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/background"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/background/queryrunner"
 
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/licensing"
 
@@ -366,6 +367,10 @@ func (s *searchInsightDataSeriesDefinitionResolver) GeneratedFromCaptureGroups()
 	return s.series.GeneratedFromCaptureGroups, nil
 }
 
+func (s *searchInsightDataSeriesDefinitionResolver) GeneratedFromPreciseReferences() (bool, error) {
+	return s.series.GenerationMethod == types.PreciseReferences, nil
+}
+
 type insightIntervalTimeScopeResolver struct {
 	unit  string
 	value int32
@@ -569,7 +574,7 @@ func (r *Resolver) UpdateLineChartSearchInsight(ctx context.Context, args *graph
 		} else {
 			// If it's a frontend series, we can just update it.
 			existingRepos := getExistingSeriesRepositories(*series.SeriesId, views[0].Series)
-			if len(series.RepositoryScope.Repositories) > 0 && len(existingRepos) > 0 {
+			if len(series.RepositoryScope.Repositories) > 0 && len(existingRepos) > 0 && searchGenerationMethod(series) != types.PreciseReferences {
 				err = tx.UpdateFrontendSeries(ctx, store.UpdateFrontendSeriesArgs{
 					SeriesID:          *series.SeriesId,
 					Query:             series.Query,
@@ -977,6 +982,19 @@ func createAndAttachSeries(ctx context.Context, tx *store.InsightStore, scopedBa
 	if series.GeneratedFromCaptureGroups != nil {
 		dynamic = *series.GeneratedFromCaptureGroups
 	}
+	generationMethod := searchGenerationMethod(series)
+	preciseReferences := generationMethod == types.PreciseReferences
+	if preciseReferences {
+		if dynamic {
+			return nil, errors.New("a series can not be generated from both precise references and capture groups")
+		}
+		if _, err := queryrunner.ParseMoniker(series.Query); err != nil {
+			return nil, err
+		}
+		if len(series.RepositoryScope.Repositories) == 0 {
+			return nil, errors.New("a series generated from precise references must be scoped to repositories")
+		}
+	}
 
 	// Don't try to match on non-global series, since they are always replaced
 	if len(series.RepositoryScope.Repositories) == 0 {
@@ -985,6 +1003,7 @@ func createAndAttachSeries(ctx context.Context, tx *store.InsightStore, scopedBa
 			StepIntervalUnit:          series.TimeScope.StepInterval.Unit,
 			StepIntervalValue:         int(series.TimeScope.StepInterval.Value),
 			GenerateFromCaptureGroups: dynamic,
+			GenerationMethod:          generationMethod,
 		})
 		if err != nil {
 			return nil, errors.Wrap(err, "FindMatchingSeries")
@@ -1004,14 +1023,15 @@ func createAndAttachSeries(ctx context.Context, tx *store.InsightStore, scopedBa
 			SampleIntervalUnit:         series.TimeScope.StepInterval.Unit,
 			SampleIntervalValue:        int(series.TimeScope.StepInterval.Value),
 			GeneratedFromCaptureGroups: dynamic,
-			JustInTime:                 len(repos) > 0 && !deprecateJustInTime,
+			// Precise references can not be counted just in time, so those series are always recorded.
+			JustInTime: len(repos) > 0 && !deprecateJustInTime && !preciseReferences,
 			// JustInTime:       false,
-			GenerationMethod: searchGenerationMethod(series),
+			GenerationMethod: generationMethod,
 		})
 		if err != nil {
 			return nil, errors.Wrap(err, "CreateSeries")
 		}
-		if len(seriesToAdd.Repositories) > 0 && deprecateJustInTime {
+		if len(seriesToAdd.Repositories) > 0 && !seriesToAdd.JustInTime {
 			err := scopedBackfiller.ScopedBackfill(ctx, []types.InsightSeries{seriesToAdd})
 			if err != nil {
 				return nil, errors.Wrap(err, "ScopedBackfill")
@@ -1040,6 +1060,9 @@ func createAndAttachSeries(ctx context.Context, tx *store.InsightStore, scopedBa
 }
 
 func searchGenerationMethod(series graphqlbackend.LineChartSearchInsightDataSeriesInput) types.GenerationMethod {
+	if series.GeneratedFromPreciseReferences != nil && *series.GeneratedFromPreciseReferences {
+		return types.PreciseReferences
+	}
 	if series.GeneratedFromCaptureGroups != nil && *series.GeneratedFromCaptureGroups {
 		return types.SearchCompute
 	}
@@ -0,0 +1,165 @@
+package resolvers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	cmbackground "github.com/sourcegraph/sourcegraph/enterprise/internal/codemonitors/background"
+	edb "github.com/sourcegraph/sourcegraph/enterprise/internal/database"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/store"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+const insightSeriesAlertKind = "InsightSeriesAlert"
+
+var _ graphqlbackend.InsightSeriesAlertResolver = &insightSeriesAlertResolver{}
+
+func (r *Resolver) InsightSeriesAlerts(ctx context.Context, args *graphqlbackend.InsightSeriesAlertsArgs) ([]graphqlbackend.InsightSeriesAlertResolver, error) {
+	permissionsValidator := PermissionsValidatorFromBase(&r.baseInsightResolver)
+	if err := permissionsValidator.validateUserAccessForSeries(ctx, args.SeriesId); err != nil {
+		return nil, err
+	}
+
+	alerts, err := r.insightStore.GetSeriesAlerts(ctx, args.SeriesId)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]graphqlbackend.InsightSeriesAlertResolver, 0, len(alerts))
+	for _, alert := range alerts {
+		// Only return the alert rules whose code monitor the user has access to.
+		if err := r.checkCodeMonitorAccess(ctx, alert.CodeMonitorID); err != nil {
+			continue
+		}
+		resolvers = append(resolvers, &insightSeriesAlertResolver{alert: alert})
+	}
+	return resolvers, nil
+}
+
+func (r *Resolver) CreateInsightSeriesAlert(ctx context.Context, args *graphqlbackend.CreateInsightSeriesAlertArgs) (graphqlbackend.InsightSeriesAlertResolver, error) {
+	var monitorID int64
+	if err := unmarshalID(args.Input.CodeMonitor, cmbackground.MonitorKind, &monitorID); err != nil {
+		return nil, err
+	}
+	if err := r.checkCodeMonitorAccess(ctx, monitorID); err != nil {
+		return nil, err
+	}
+	permissionsValidator := PermissionsValidatorFromBase(&r.baseInsightResolver)
+	if err := permissionsValidator.validateUserAccessForSeries(ctx, args.Input.SeriesId); err != nil {
+		return nil, err
+	}
+
+	kind := types.AlertKind(strings.ToLower(args.Input.Kind))
+	if kind != types.ThresholdAlert && kind != types.PercentChangeAlert {
+		return nil, errors.Newf("invalid alert kind: %s", args.Input.Kind)
+	}
+	intervals := 1
+	if args.Input.Intervals != nil {
+		intervals = int(*args.Input.Intervals)
+	}
+	if intervals < 1 {
+		return nil, errors.New("intervals must be at least 1")
+	}
+
+	alert, err := r.insightStore.CreateSeriesAlert(ctx, store.CreateSeriesAlertArgs{
+		SeriesID:      args.Input.SeriesId,
+		Kind:          kind,
+		Threshold:     args.Input.Threshold,
+		Intervals:     intervals,
+		CodeMonitorID: monitorID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "CreateSeriesAlert")
+	}
+	return &insightSeriesAlertResolver{alert: alert}, nil
+}
+
+func (r *Resolver) DeleteInsightSeriesAlert(ctx context.Context, args *graphqlbackend.DeleteInsightSeriesAlertArgs) (*graphqlbackend.EmptyResponse, error) {
+	var id int
+	if err := unmarshalID(args.Id, insightSeriesAlertKind, &id); err != nil {
+		return nil, err
+	}
+	alert, err := r.insightStore.GetSeriesAlert(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.checkCodeMonitorAccess(ctx, alert.CodeMonitorID); err != nil {
+		return nil, err
+	}
+	permissionsValidator := PermissionsValidatorFromBase(&r.baseInsightResolver)
+	if err := permissionsValidator.validateUserAccessForSeries(ctx, alert.SeriesID); err != nil {
+		return nil, err
+	}
+
+	if err := r.insightStore.DeleteSeriesAlert(ctx, id); err != nil {
+		return nil, errors.Wrap(err, "DeleteSeriesAlert")
+	}
+	return &graphqlbackend.EmptyResponse{}, nil
+}
+
+// checkCodeMonitorAccess returns an error if the current user is neither a site admin nor
+// the owner of the code monitor with the given ID.
+func (r *Resolver) checkCodeMonitorAccess(ctx context.Context, monitorID int64) error {
+	actr := actor.FromContext(ctx)
+	if !actr.IsAuthenticated() {
+		return errors.New("not authenticated")
+	}
+	if backend.CheckUserIsSiteAdmin(ctx, r.postgresDB, actr.UID) == nil {
+		return nil
+	}
+
+	monitor, err := edb.NewEnterpriseDB(r.postgresDB).CodeMonitors().GetMonitor(ctx, monitorID)
+	if err != nil {
+		return errors.Wrap(err, "GetMonitor")
+	}
+	if monitor.UserID != actr.UID {
+		return errors.New("code monitor is not owned by the current user")
+	}
+	return nil
+}
+
+func unmarshalID(id graphql.ID, kind string, v any) error {
+	if got := relay.UnmarshalKind(id); got != kind {
+		return errors.Errorf("expected graphql ID kind %s, got %s", kind, got)
+	}
+	return relay.UnmarshalSpec(id, v)
+}
+
+type insightSeriesAlertResolver struct {
+	alert *types.InsightSeriesAlert
+}
+
+func (i *insightSeriesAlertResolver) ID() graphql.ID {
+	return relay.MarshalID(insightSeriesAlertKind, i.alert.ID)
+}
+
+func (i *insightSeriesAlertResolver) SeriesId() string {
+	return i.alert.SeriesID
+}
+
+func (i *insightSeriesAlertResolver) Kind() string {
+	return strings.ToUpper(string(i.alert.Kind))
+}
+
+func (i *insightSeriesAlertResolver) Threshold() float64 {
+	return i.alert.Threshold
+}
+
+func (i *insightSeriesAlertResolver) Intervals() int32 {
+	return int32(i.alert.Intervals)
+}
+
+func (i *insightSeriesAlertResolver) CodeMonitor() graphql.ID {
+	return relay.MarshalID(cmbackground.MonitorKind, i.alert.CodeMonitorID)
+}
+
+func (i *insightSeriesAlertResolver) LastFiredAt() *graphqlbackend.DateTime {
+	return graphqlbackend.DateTimeOrNil(i.alert.LastFiredAt)
+}
@@ -0,0 +1,164 @@
+package resolvers
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/store"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	internalTypes "github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+var _ graphqlbackend.InsightSeriesBreakdownResolver = &insightSeriesBreakdownResolver{}
+
+func (p *precalculatedInsightSeriesResolver) Breakdown(ctx context.Context, args *graphqlbackend.InsightSeriesBreakdownArgs) ([]graphqlbackend.InsightSeriesBreakdownResolver, error) {
+	if args.GroupBy != "REPOSITORY" && args.GroupBy != "OWNER" {
+		return nil, errors.Newf("invalid breakdown group: %s", args.GroupBy)
+	}
+
+	opts, err := getRecordedSeriesPointOpts(ctx, p.workerBaseStore.Handle().DB(), p.series, p.filters)
+	if err != nil {
+		return nil, errors.Wrap(err, "getRecordedSeriesPointOpts")
+	}
+
+	var points []store.RepoSeriesPoint
+	err = p.insightsStore.RepoSeriesPoints(ctx, *opts, func(point store.RepoSeriesPoint) error {
+		// Capture group series are expanded into one resolver per captured value.
+		if p.capture != nil && (point.Capture == nil || *point.Capture != *p.capture) {
+			return nil
+		}
+		points = append(points, point)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	groupBy := func(point store.RepoSeriesPoint) string { return point.RepoName }
+	if args.GroupBy == "OWNER" {
+		owners, err := p.repoOwners(ctx, points)
+		if err != nil {
+			return nil, err
+		}
+		groupBy = func(point store.RepoSeriesPoint) string {
+			if point.Owner != nil {
+				return *point.Owner
+			}
+			return owners[point.RepoID]
+		}
+	}
+
+	breakdowns := breakdownSeriesPoints(p.seriesId, points, groupBy)
+	if args.First != nil && int(*args.First) >= 0 && int(*args.First) < len(breakdowns) {
+		breakdowns = breakdowns[:*args.First]
+	}
+
+	resolvers := make([]graphqlbackend.InsightSeriesBreakdownResolver, 0, len(breakdowns))
+	for _, breakdown := range breakdowns {
+		resolvers = append(resolvers, &insightSeriesBreakdownResolver{breakdown: breakdown})
+	}
+	return resolvers, nil
+}
+
+// Breakdown is not available for series that do not record data points per repository.
+func (r *insightSeriesResolver) Breakdown(ctx context.Context, args *graphqlbackend.InsightSeriesBreakdownArgs) ([]graphqlbackend.InsightSeriesBreakdownResolver, error) {
+	return nil, nil
+}
+
+// Breakdown is not available for series that are calculated just in time.
+func (d *dynamicInsightSeriesResolver) Breakdown(ctx context.Context, args *graphqlbackend.InsightSeriesBreakdownArgs) ([]graphqlbackend.InsightSeriesBreakdownResolver, error) {
+	return nil, nil
+}
+
+// seriesBreakdown is the sum over time of the data points of the repositories in a group.
+type seriesBreakdown struct {
+	name        string
+	latestValue float64
+	points      []store.SeriesPoint
+}
+
+// breakdownSeriesPoints sums the data points of the repositories in each group returned by groupBy.
+// Groups are sorted by their value at the latest time of all points in descending order.
+func breakdownSeriesPoints(seriesID string, points []store.RepoSeriesPoint, groupBy func(point store.RepoSeriesPoint) string) []seriesBreakdown {
+	var latest time.Time
+	values := map[string]map[time.Time]float64{}
+	for _, point := range points {
+		name := groupBy(point)
+		if _, ok := values[name]; !ok {
+			values[name] = map[time.Time]float64{}
+		}
+		values[name][point.Time] += point.Value
+		if point.Time.After(latest) {
+			latest = point.Time
+		}
+	}
+
+	breakdowns := make([]seriesBreakdown, 0, len(values))
+	for name, valuesByTime := range values {
+		breakdown := seriesBreakdown{name: name, latestValue: valuesByTime[latest]}
+		for t, value := range valuesByTime {
+			breakdown.points = append(breakdown.points, store.SeriesPoint{SeriesID: seriesID, Time: t, Value: value})
+		}
+		sort.Slice(breakdown.points, func(i, j int) bool {
+			return breakdown.points[i].Time.Before(breakdown.points[j].Time)
+		})
+		breakdowns = append(breakdowns, breakdown)
+	}
+	sort.Slice(breakdowns, func(i, j int) bool {
+		if breakdowns[i].latestValue != breakdowns[j].latestValue {
+			return breakdowns[i].latestValue > breakdowns[j].latestValue
+		}
+		return breakdowns[i].name < breakdowns[j].name
+	})
+	return breakdowns
+}
+
+// repoOwners returns the owners of the repositories of the points recorded without an owner,
+// determined like the owners recorded with the points by the work handler.
+func (p *precalculatedInsightSeriesResolver) repoOwners(ctx context.Context, points []store.RepoSeriesPoint) (map[api.RepoID]string, error) {
+	owners := map[api.RepoID]string{}
+	var repoIDs []api.RepoID
+	for _, point := range points {
+		if _, ok := owners[point.RepoID]; !ok && point.Owner == nil {
+			// Repositories that no longer exist fall back to their recorded name.
+			owners[point.RepoID] = store.RepoOwner(&internalTypes.Repo{Name: api.RepoName(point.RepoName)})
+			repoIDs = append(repoIDs, point.RepoID)
+		}
+	}
+	if len(repoIDs) == 0 {
+		return owners, nil
+	}
+
+	repos, err := database.NewDBWith(p.workerBaseStore).Repos().List(ctx, database.ReposListOptions{IDs: repoIDs})
+	if err != nil {
+		return nil, errors.Wrap(err, "Repos.List")
+	}
+	for _, repo := range repos {
+		owners[repo.ID] = store.RepoOwner(repo)
+	}
+	return owners, nil
+}
+
+type insightSeriesBreakdownResolver struct {
+	breakdown seriesBreakdown
+}
+
+func (i *insightSeriesBreakdownResolver) Name() string {
+	return i.breakdown.name
+}
+
+func (i *insightSeriesBreakdownResolver) LatestValue() float64 {
+	return i.breakdown.latestValue
+}
+
+func (i *insightSeriesBreakdownResolver) Points() []graphqlbackend.InsightsDataPointResolver {
+	resolvers := make([]graphqlbackend.InsightsDataPointResolver, 0, len(i.breakdown.points))
+	for _, point := range i.breakdown.points {
+		resolvers = append(resolvers, insightsDataPointResolver{point})
+	}
+	return resolvers
+}
@@ -0,0 +1,52 @@
+package resolvers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/store"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	internalTypes "github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestBreakdownSeriesPoints(t *testing.T) {
+	t1 := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.AddDate(0, 1, 0)
+
+	points := []store.RepoSeriesPoint{
+		{RepoName: "github.com/a/x", Time: t1, Value: 5},
+		{RepoName: "github.com/a/x", Time: t2, Value: 1},
+		{RepoName: "github.com/a/y", Time: t2, Value: 2},
+		{RepoName: "github.com/b/z", Time: t1, Value: 4},
+		{RepoName: "github.com/b/z", Time: t2, Value: 4},
+		{RepoName: "github.com/c/w", Time: t1, Value: 7},
+	}
+	groupByOwner := func(point store.RepoSeriesPoint) string {
+		return store.RepoOwner(&internalTypes.Repo{Name: api.RepoName(point.RepoName)})
+	}
+
+	want := []seriesBreakdown{
+		{
+			name:        "github.com/b",
+			latestValue: 4,
+			points:      []store.SeriesPoint{{SeriesID: "s", Time: t1, Value: 4}, {SeriesID: "s", Time: t2, Value: 4}},
+		},
+		{
+			name:        "github.com/a",
+			latestValue: 3,
+			points:      []store.SeriesPoint{{SeriesID: "s", Time: t1, Value: 5}, {SeriesID: "s", Time: t2, Value: 3}},
+		},
+		{
+			// No longer recorded at the latest time.
+			name:        "github.com/c",
+			latestValue: 0,
+			points:      []store.SeriesPoint{{SeriesID: "s", Time: t1, Value: 7}},
+		},
+	}
+	got := breakdownSeriesPoints("s", points, groupByOwner)
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(seriesBreakdown{})); diff != "" {
+		t.Errorf("unexpected breakdown (-want +got):\n%s", diff)
+	}
+}
@@ -91,6 +91,24 @@ func (v *InsightPermissionsValidator) validateUserAccessForView(ctx context.Cont
 	return nil
 }
 
+func (v *InsightPermissionsValidator) validateUserAccessForSeries(ctx context.Context, seriesId string) error {
+	err := v.loadUserContext(ctx)
+	if err != nil {
+		return err
+	}
+	results, err := v.insightStore.GetAll(ctx, store.InsightQueryArgs{SeriesID: seriesId, UserID: v.userIds, OrgID: v.orgIds, Limit: 1})
+	if err != nil {
+		return errors.Wrap(err, "GetAll")
+	}
+	// 🚨 SECURITY: a series is visible only through an insight view the user can see. As with insights, we return a
+	// generic not found error to prevent leaking series existence.
+	if len(results) == 0 {
+		return errors.New("insight series not found")
+	}
+
+	return nil
+}
+
 // WithBaseStore sets the base store for any insight related stores. Used to propagate a transaction into this validator
 // for permission checks against code insights tables.
 func (v *InsightPermissionsValidator) WithBaseStore(base basestore.ShareableStore) *InsightPermissionsValidator {
@@ -50,6 +50,7 @@ type InsightQueryArgs struct {
 	UserID      []int
 	OrgID       []int
 	DashboardID int
+	SeriesID    string
 
 	After    string
 	Limit    int
@@ -110,6 +111,9 @@ func (s *InsightStore) GetAll(ctx context.Context, args InsightQueryArgs) ([]typ
 	if args.DashboardID > 0 {
 		preds = append(preds, sqlf.Sprintf("iv.id in (select insight_view_id from dashboard_insight_view where dashboard_id = %s)", args.DashboardID))
 	}
+	if args.SeriesID != "" {
+		preds = append(preds, sqlf.Sprintf("i.series_id = %s", args.SeriesID))
+	}
 	if args.After != "" {
 		preds = append(preds, sqlf.Sprintf("iv.unique_id > %s", args.After))
 	}
@@ -787,6 +791,8 @@ type MatchSeriesArgs struct {
 	StepIntervalUnit          string
 	StepIntervalValue         int
 	GenerateFromCaptureGroups bool
+	// GenerationMethod is matched if set.
+	GenerationMethod types.GenerationMethod
 }
 
 func (s *InsightStore) FindMatchingSeries(ctx context.Context, args MatchSeriesArgs) (_ types.InsightSeries, found bool, _ error) {
@@ -794,6 +800,9 @@ func (s *InsightStore) FindMatchingSeries(ctx context.Context, args MatchSeriesA
 		"(repositories = '{}' OR repositories is NULL) AND query = %s AND sample_interval_unit = %s AND sample_interval_value = %s AND generated_from_capture_groups = %s",
 		args.Query, args.StepIntervalUnit, args.StepIntervalValue, args.GenerateFromCaptureGroups,
 	)
+	if args.GenerationMethod != "" {
+		where = sqlf.Sprintf("%s AND generation_method = %s", where, args.GenerationMethod)
+	}
 
 	q := sqlf.Sprintf(getInsightDataSeriesSql, where)
 	rows, err := scanDataSeries(s.Query(ctx, q))
@@ -0,0 +1,46 @@
+package store
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+// RepoOwner returns the owner of a repository prefixed with its code host, such as
+// github.com/sourcegraph, from the code host metadata of the repository. It falls back to the
+// name of the repository without its last path element if the metadata does not name an owner.
+//
+// It is used both to record the owner with the data points of a repository and to break down
+// the data points recorded without one, so that both agree.
+func RepoOwner(repo *types.Repo) string {
+	var owner string
+	switch m := repo.Metadata.(type) {
+	case *github.Repository:
+		owner, _ = m.Owner()
+	case *gitlab.Project:
+		owner, _ = m.Namespace()
+	case *bitbucketserver.Repo:
+		if m.Project != nil {
+			owner = m.Project.Key
+		}
+	case *bitbucketcloud.Repo:
+		if i := strings.LastIndexByte(m.FullName, '/'); i > 0 {
+			owner = m.FullName[:i]
+		}
+	}
+	if u, err := url.Parse(repo.ExternalRepo.ServiceID); err == nil && u.Host != "" && owner != "" {
+		return path.Join(u.Host, owner)
+	}
+
+	name := string(repo.Name)
+	if i := strings.LastIndexByte(name, '/'); i > 0 {
+		return name[:i]
+	}
+	return name
+}
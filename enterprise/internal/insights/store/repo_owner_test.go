@@ -0,0 +1,63 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestRepoOwner(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		repo *types.Repo
+		want string
+	}{
+		{
+			name: "GitHub",
+			repo: &types.Repo{
+				Name:         "ghe.example.com/mirrors/sourcegraph",
+				ExternalRepo: api.ExternalRepoSpec{ServiceID: "https://ghe.example.com/"},
+				Metadata:     &github.Repository{NameWithOwner: "sourcegraph/sourcegraph"},
+			},
+			want: "ghe.example.com/sourcegraph",
+		},
+		{
+			name: "GitLab",
+			repo: &types.Repo{
+				Name:         "gitlab.com/a/b/c",
+				ExternalRepo: api.ExternalRepoSpec{ServiceID: "https://gitlab.com/"},
+				Metadata:     &gitlab.Project{ProjectCommon: gitlab.ProjectCommon{PathWithNamespace: "a/b/c"}},
+			},
+			want: "gitlab.com/a/b",
+		},
+		{
+			name: "Bitbucket Server",
+			repo: &types.Repo{
+				Name:         "bitbucket.example.com/proj/repo",
+				ExternalRepo: api.ExternalRepoSpec{ServiceID: "https://bitbucket.example.com/"},
+				Metadata:     &bitbucketserver.Repo{Slug: "repo", Project: &bitbucketserver.Project{Key: "PROJ"}},
+			},
+			want: "bitbucket.example.com/PROJ",
+		},
+		{
+			name: "no metadata",
+			repo: &types.Repo{Name: "example.com/a/b"},
+			want: "example.com/a",
+		},
+		{
+			name: "no owner",
+			repo: &types.Repo{Name: "myrepo"},
+			want: "myrepo",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := RepoOwner(tc.repo); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
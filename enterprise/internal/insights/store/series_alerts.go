@@ -0,0 +1,123 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/types"
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+var ErrSeriesAlertNotFound = errors.New("insight series alert not found")
+
+type CreateSeriesAlertArgs struct {
+	SeriesID      string
+	Kind          types.AlertKind
+	Threshold     float64
+	Intervals     int
+	CodeMonitorID int64
+}
+
+// CreateSeriesAlert creates an alert rule on the series with the given series ID.
+func (s *InsightStore) CreateSeriesAlert(ctx context.Context, args CreateSeriesAlertArgs) (*types.InsightSeriesAlert, error) {
+	q := sqlf.Sprintf(createSeriesAlertSql, args.Kind, args.Threshold, args.Intervals, args.CodeMonitorID, s.Now(), args.SeriesID)
+	alerts, err := scanSeriesAlerts(s.Query(ctx, q))
+	if err != nil {
+		return nil, err
+	}
+	if len(alerts) == 0 {
+		return nil, errors.Newf("unable to find series with series_id: %s", args.SeriesID)
+	}
+	return alerts[0], nil
+}
+
+// GetSeriesAlert returns the alert rule with the given ID.
+func (s *InsightStore) GetSeriesAlert(ctx context.Context, id int) (*types.InsightSeriesAlert, error) {
+	alerts, err := scanSeriesAlerts(s.Query(ctx, sqlf.Sprintf(getSeriesAlertsSql, sqlf.Sprintf("a.id = %s", id))))
+	if err != nil {
+		return nil, err
+	}
+	if len(alerts) == 0 {
+		return nil, ErrSeriesAlertNotFound
+	}
+	return alerts[0], nil
+}
+
+// GetSeriesAlerts returns the alert rules on the series with the given series ID.
+func (s *InsightStore) GetSeriesAlerts(ctx context.Context, seriesID string) ([]*types.InsightSeriesAlert, error) {
+	return scanSeriesAlerts(s.Query(ctx, sqlf.Sprintf(getSeriesAlertsSql, sqlf.Sprintf("i.series_id = %s", seriesID))))
+}
+
+// DeleteSeriesAlert deletes the alert rule with the given ID.
+func (s *InsightStore) DeleteSeriesAlert(ctx context.Context, id int) error {
+	return s.Exec(ctx, sqlf.Sprintf(deleteSeriesAlertSql, id))
+}
+
+// MarkSeriesAlertFired records that the alert rule with the given ID fired at the given time.
+func (s *InsightStore) MarkSeriesAlertFired(ctx context.Context, id int, firedAt time.Time) error {
+	return s.Exec(ctx, sqlf.Sprintf(markSeriesAlertFiredSql, firedAt, id))
+}
+
+func scanSeriesAlerts(rows *sql.Rows, queryErr error) (_ []*types.InsightSeriesAlert, err error) {
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer func() { err = basestore.CloseRows(rows, err) }()
+
+	results := make([]*types.InsightSeriesAlert, 0)
+	for rows.Next() {
+		var temp types.InsightSeriesAlert
+		if err := rows.Scan(
+			&temp.ID,
+			&temp.InsightSeriesID,
+			&temp.SeriesID,
+			&temp.Kind,
+			&temp.Threshold,
+			&temp.Intervals,
+			&temp.CodeMonitorID,
+			&temp.CreatedAt,
+			&temp.LastFiredAt,
+		); err != nil {
+			return nil, err
+		}
+		results = append(results, &temp)
+	}
+	return results, nil
+}
+
+const createSeriesAlertSql = `
+-- source: enterprise/internal/insights/store/series_alerts.go:CreateSeriesAlert
+WITH inserted AS (
+	INSERT INTO insight_series_alerts (insight_series_id, kind, threshold, intervals, code_monitor_id, created_at)
+	SELECT i.id, %s, %s, %s, %s, %s
+	FROM insight_series i
+	WHERE i.series_id = %s AND i.deleted_at IS NULL
+	RETURNING id, insight_series_id, kind, threshold, intervals, code_monitor_id, created_at, last_fired_at
+)
+SELECT a.id, a.insight_series_id, i.series_id, a.kind, a.threshold, a.intervals, a.code_monitor_id, a.created_at, a.last_fired_at
+FROM inserted a
+JOIN insight_series i ON i.id = a.insight_series_id;
+`
+
+const getSeriesAlertsSql = `
+-- source: enterprise/internal/insights/store/series_alerts.go:GetSeriesAlerts
+SELECT a.id, a.insight_series_id, i.series_id, a.kind, a.threshold, a.intervals, a.code_monitor_id, a.created_at, a.last_fired_at
+FROM insight_series_alerts a
+JOIN insight_series i ON i.id = a.insight_series_id
+WHERE %s
+ORDER BY a.id;
+`
+
+const deleteSeriesAlertSql = `
+-- source: enterprise/internal/insights/store/series_alerts.go:DeleteSeriesAlert
+DELETE FROM insight_series_alerts WHERE id = %s;
+`
+
+const markSeriesAlertFiredSql = `
+-- source: enterprise/internal/insights/store/series_alerts.go:MarkSeriesAlertFired
+UPDATE insight_series_alerts SET last_fired_at = %s WHERE id = %s;
+`
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	edb "github.com/sourcegraph/sourcegraph/enterprise/internal/database"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/insights/types"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+func TestSeriesAlerts(t *testing.T) {
+	insightsDB := edb.NewInsightsDB(dbtest.NewInsightsDB(t))
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	store := NewInsightStore(insightsDB)
+	store.Now = func() time.Time {
+		return now
+	}
+
+	created, err := store.CreateSeries(ctx, types.InsightSeries{
+		SeriesID:           "series1",
+		Query:              "deprecatedFunc",
+		SampleIntervalUnit: string(types.Month),
+		GenerationMethod:   types.Search,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.CreateSeriesAlert(ctx, CreateSeriesAlertArgs{SeriesID: "unknown", Kind: types.ThresholdAlert, Intervals: 1}); err == nil {
+		t.Fatal("expected error creating an alert on an unknown series")
+	}
+
+	alert, err := store.CreateSeriesAlert(ctx, CreateSeriesAlertArgs{
+		SeriesID:      created.SeriesID,
+		Kind:          types.PercentChangeAlert,
+		Threshold:     10,
+		Intervals:     3,
+		CodeMonitorID: 42,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &types.InsightSeriesAlert{
+		ID:              alert.ID,
+		InsightSeriesID: created.ID,
+		SeriesID:        created.SeriesID,
+		Kind:            types.PercentChangeAlert,
+		Threshold:       10,
+		Intervals:       3,
+		CodeMonitorID:   42,
+		CreatedAt:       now,
+	}
+	if diff := cmp.Diff(want, alert); diff != "" {
+		t.Fatalf("unexpected alert (-want +got):\n%s", diff)
+	}
+
+	firedAt := now.Add(time.Hour)
+	if err := store.MarkSeriesAlertFired(ctx, alert.ID, firedAt); err != nil {
+		t.Fatal(err)
+	}
+	alerts, err := store.GetSeriesAlerts(ctx, created.SeriesID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want.LastFiredAt = &firedAt
+	if diff := cmp.Diff([]*types.InsightSeriesAlert{want}, alerts); diff != "" {
+		t.Fatalf("unexpected alerts (-want +got):\n%s", diff)
+	}
+
+	if err := store.DeleteSeriesAlert(ctx, alert.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetSeriesAlert(ctx, alert.ID); !errors.Is(err, ErrSeriesAlertNotFound) {
+		t.Fatalf("expected alert to be deleted, got %v", err)
+	}
+}
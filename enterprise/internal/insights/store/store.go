@@ -25,6 +25,7 @@ type Interface interface {
 	RecordSeriesPoint(ctx context.Context, v RecordSeriesPointArgs) error
 	RecordSeriesPoints(ctx context.Context, pts []RecordSeriesPointArgs) error
 	CountData(ctx context.Context, opts CountDataOpts) (int, error)
+	RepoSeriesPoints(ctx context.Context, opts SeriesPointsOpts, each func(RepoSeriesPoint) error) error
 }
 
 var _ Interface = &Store{}
@@ -111,6 +112,10 @@ type SeriesPointsOpts struct {
 
 	// Limit is the number of data points to query, if non-zero.
 	Limit int
+
+	// ExcludeSnapshots, if true, only queries recorded data points and ignores the snapshot
+	// of the most recent data.
+	ExcludeSnapshots bool
 }
 
 // SeriesPoints queries data points over time for a specific insights' series.
@@ -153,6 +158,68 @@ func (s *Store) SeriesPoints(ctx context.Context, opts SeriesPointsOpts) ([]Seri
 	return points, err
 }
 
+// RepoSeriesPoint describes a single insights' series data point recorded for a repository.
+type RepoSeriesPoint struct {
+	SeriesID string
+	RepoID   api.RepoID
+	RepoName string
+	// Time (always UTC).
+	Time    time.Time
+	Value   float64
+	Capture *string
+	// Owner is the owner of the repository recorded with the data point, if any.
+	Owner *string
+}
+
+// RepoSeriesPointMetadata is the metadata recorded with the data points of a repository.
+type RepoSeriesPointMetadata struct {
+	// Owner is the owner of the repository prefixed with its code host, such as
+	// github.com/sourcegraph.
+	Owner string `json:"owner,omitempty"`
+}
+
+// RepoSeriesPoints calls each for every data point matching opts, without aggregating the points
+// of different repositories. Points are ordered by series, time, repository name and capture.
+// The Limit option is ignored.
+func (s *Store) RepoSeriesPoints(ctx context.Context, opts SeriesPointsOpts, each func(RepoSeriesPoint) error) error {
+	// 🚨 SECURITY: Exclude the repositories the current user cannot see, see SeriesPoints. 🚨
+	denylist, err := s.permStore.GetUnauthorizedRepoIDs(ctx)
+	if err != nil {
+		return err
+	}
+	opts.Excluded = append(opts.Excluded, denylist...)
+
+	q := sqlf.Sprintf(repoSeriesPointsSql, seriesPointsSource(opts), sqlf.Join(seriesPointsPredicates(opts), "\n AND "))
+	return s.query(ctx, q, func(sc scanner) error {
+		var point RepoSeriesPoint
+		if err := sc.Scan(
+			&point.SeriesID,
+			&point.RepoID,
+			&point.RepoName,
+			&point.Time,
+			&point.Value,
+			&point.Capture,
+			&point.Owner,
+		); err != nil {
+			return err
+		}
+		return each(point)
+	})
+}
+
+// Like fullVectorSeriesAggregation, the per-repository maximum eliminates duplicate points that
+// might have been recorded in a given interval for a given repository.
+const repoSeriesPointsSql = `
+-- source: enterprise/internal/insights/store/store.go:RepoSeriesPoints
+SELECT sp.series_id, sp.repo_id, rn.name, sp.time, MAX(sp.value), sp.capture, MAX(m.metadata->>'owner')
+FROM %s AS sp
+JOIN repo_names rn ON sp.repo_name_id = rn.id
+LEFT JOIN metadata m ON sp.metadata_id = m.id
+WHERE %s
+GROUP BY sp.series_id, sp.repo_id, rn.name, sp.time, sp.capture
+ORDER BY sp.series_id, sp.time, rn.name, sp.capture
+`
+
 // Delete will delete the time series data for a particular series_id. This will hard (permanently) delete the data.
 func (s *Store) Delete(ctx context.Context, seriesId string) (err error) {
 	tx, err := s.Transact(ctx)
@@ -191,10 +258,7 @@ const fullVectorSeriesAggregation = `
 -- source: enterprise/internal/insights/store/store.go:SeriesPoints
 SELECT sub.series_id, sub.interval_time, SUM(sub.value) as value, sub.metadata, sub.capture FROM (
 	SELECT sp.repo_name_id, sp.series_id, sp.time AS interval_time, MAX(value) as value, null as metadata, capture
-	FROM (  select * from series_points
-			union
-			select * from series_points_snapshots
-	) AS sp
+	FROM %s AS sp
 	JOIN repo_names rn ON sp.repo_name_id = rn.id
 	WHERE %s
 	GROUP BY sp.series_id, interval_time, sp.repo_name_id, capture
@@ -215,6 +279,21 @@ ORDER BY sub.series_id, sub.interval_time ASC
 // 3. Searches may not complete at the same exact time, so even in a perfect world if the interval
 //    should be 12h it may be off by a minute or so.
 func seriesPointsQuery(opts SeriesPointsOpts) *sqlf.Query {
+	preds := seriesPointsPredicates(opts)
+	limitClause := ""
+	if opts.Limit > 0 {
+		limitClause = fmt.Sprintf("LIMIT %d", opts.Limit)
+	}
+	return sqlf.Sprintf(
+		fullVectorSeriesAggregation+limitClause,
+		seriesPointsSource(opts),
+		sqlf.Join(preds, "\n AND "),
+	)
+}
+
+// seriesPointsPredicates returns the conditions on the series_points (aliased sp) and repo_names
+// (aliased rn) tables that select the points matching opts.
+func seriesPointsPredicates(opts SeriesPointsOpts) []*sqlf.Query {
 	preds := []*sqlf.Query{}
 
 	if opts.SeriesID != nil {
@@ -229,10 +308,6 @@ func seriesPointsQuery(opts SeriesPointsOpts) *sqlf.Query {
 	if opts.To != nil {
 		preds = append(preds, sqlf.Sprintf("time <= %s", *opts.To))
 	}
-	limitClause := ""
-	if opts.Limit > 0 {
-		limitClause = fmt.Sprintf("LIMIT %d", opts.Limit)
-	}
 	if len(opts.Included) > 0 {
 		s := fmt.Sprintf("repo_id = any(%v)", values(opts.Included))
 		preds = append(preds, sqlf.Sprintf(s))
@@ -261,10 +336,18 @@ func seriesPointsQuery(opts SeriesPointsOpts) *sqlf.Query {
 	if len(preds) == 0 {
 		preds = append(preds, sqlf.Sprintf("TRUE"))
 	}
-	return sqlf.Sprintf(
-		fullVectorSeriesAggregation+limitClause,
-		sqlf.Join(preds, "\n AND "),
-	)
+	return preds
+}
+
+// seriesPointsSource returns the table expression the points matching opts are selected from.
+func seriesPointsSource(opts SeriesPointsOpts) *sqlf.Query {
+	if opts.ExcludeSnapshots {
+		return sqlf.Sprintf("series_points")
+	}
+	return sqlf.Sprintf(`(  select * from series_points
+			union
+			select * from series_points_snapshots
+	)`)
 }
 
 //values constructs a SQL values statement out of an array of repository ids
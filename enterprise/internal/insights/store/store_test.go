@@ -326,6 +326,71 @@ func TestRecordSeriesPoints(t *testing.T) {
 	}
 }
 
+func TestRepoSeriesPoints(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	clock := timeutil.Now
+	insightsDB := edb.NewInsightsDB(dbtest.NewInsightsDB(t))
+	postgres := database.NewDB(dbtest.NewDB(t))
+	permStore := NewInsightPermissionStore(postgres)
+	store := NewWithClock(insightsDB, permStore, clock)
+
+	optionalString := func(v string) *string { return &v }
+	optionalRepoID := func(v api.RepoID) *api.RepoID { return &v }
+
+	current := time.Date(2021, time.September, 10, 10, 0, 0, 0, time.UTC)
+
+	for _, record := range []RecordSeriesPointArgs{
+		{SeriesID: "one", Point: SeriesPoint{Time: current, Value: 1}, RepoName: optionalString("repo2"), RepoID: optionalRepoID(4), PersistMode: RecordMode},
+		{SeriesID: "one", Point: SeriesPoint{Time: current, Value: 2}, RepoName: optionalString("repo1"), RepoID: optionalRepoID(3), PersistMode: RecordMode},
+		// A duplicate point only counts once.
+		{SeriesID: "one", Point: SeriesPoint{Time: current, Value: 2}, RepoName: optionalString("repo1"), RepoID: optionalRepoID(3), PersistMode: RecordMode},
+		{SeriesID: "one", Point: SeriesPoint{Time: current.Add(-time.Hour * 24 * 14), Value: 3}, RepoName: optionalString("repo1"), RepoID: optionalRepoID(3), PersistMode: RecordMode},
+		{SeriesID: "two", Point: SeriesPoint{Time: current, Value: 4, Capture: optionalString("a")}, RepoName: optionalString("repo1"), RepoID: optionalRepoID(3), PersistMode: RecordMode},
+	} {
+		if err := store.RecordSeriesPoint(ctx, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	collect := func(opts SeriesPointsOpts) []RepoSeriesPoint {
+		var points []RepoSeriesPoint
+		err := store.RepoSeriesPoints(ctx, opts, func(point RepoSeriesPoint) error {
+			points = append(points, point)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return points
+	}
+
+	t.Run("all", func(t *testing.T) {
+		want := []RepoSeriesPoint{
+			{SeriesID: "one", RepoID: 3, RepoName: "repo1", Time: current.Add(-time.Hour * 24 * 14), Value: 3},
+			{SeriesID: "one", RepoID: 3, RepoName: "repo1", Time: current, Value: 2},
+			{SeriesID: "one", RepoID: 4, RepoName: "repo2", Time: current, Value: 1},
+			{SeriesID: "two", RepoID: 3, RepoName: "repo1", Time: current, Value: 4, Capture: optionalString("a")},
+		}
+		if diff := cmp.Diff(want, collect(SeriesPointsOpts{})); diff != "" {
+			t.Errorf("unexpected points (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("filtered", func(t *testing.T) {
+		seriesID := "one"
+		want := []RepoSeriesPoint{
+			{SeriesID: "one", RepoID: 4, RepoName: "repo2", Time: current, Value: 1},
+		}
+		if diff := cmp.Diff(want, collect(SeriesPointsOpts{SeriesID: &seriesID, ExcludeRepoRegex: []string{"repo1"}})); diff != "" {
+			t.Errorf("unexpected points (-want +got):\n%s", diff)
+		}
+	})
+}
+
 func TestRecordSeriesPointsSnapshotOnly(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
@@ -119,6 +119,10 @@ const (
 	Search        GenerationMethod = "search"
 	SearchCompute GenerationMethod = "search-compute"
 	LanguageStats GenerationMethod = "language-stats"
+
+	// PreciseReferences series count the precise references to a moniker in the processed LSIF
+	// uploads of each repository. The series query is the moniker as scheme:identifier.
+	PreciseReferences GenerationMethod = "precise-references"
 )
 
 type DirtyQuery struct {
@@ -129,6 +133,34 @@ type DirtyQuery struct {
 	Reason  string
 }
 
+type AlertKind string
+
+const (
+	// ThresholdAlert fires when the value of a series rises to or above the
+	// threshold of the alert.
+	ThresholdAlert AlertKind = "threshold"
+
+	// PercentChangeAlert fires when the value of a series changed by at least
+	// the threshold percentage over the intervals of the alert. A negative
+	// threshold fires on a decrease.
+	PercentChangeAlert AlertKind = "percent_change"
+)
+
+// InsightSeriesAlert is an alert rule on an insight series that is evaluated
+// after each recording of the series. When the rule is met, the actions of the
+// code monitor are run.
+type InsightSeriesAlert struct {
+	ID              int
+	InsightSeriesID int
+	SeriesID        string
+	Kind            AlertKind
+	Threshold       float64
+	Intervals       int
+	CodeMonitorID   int64
+	CreatedAt       time.Time
+	LastFiredAt     *time.Time
+}
+
 type DirtyQueryAggregate struct {
 	Count   int
 	ForTime time.Time
@@ -0,0 +1,385 @@
+package notebooks
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/google/uuid"
+
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// The Markdown format of a notebook is plain Markdown where every block is
+// delimited by a directive that holds the block type and ID:
+//
+//	<!-- sourcegraph:md id="1" -->
+//	# Runbook
+//	<!-- /sourcegraph:md -->
+//
+//	```sourcegraph:query id="2"
+//	repo:^github\.com/sourcegraph/sourcegraph$ file:\.go$ func main
+//	```
+//
+//	```sourcegraph:file id="3"
+//	repository: github.com/sourcegraph/sourcegraph
+//	path: cmd/frontend/main.go
+//	revision: main
+//	lines: 1-10
+//	```
+//
+// Query and compute blocks hold their input as the content of the fenced code
+// block, file and symbol blocks hold their input as "key: value" lines. Text
+// outside of any directive is imported as Markdown blocks, and blocks without
+// an ID are assigned a new one, so that hand-written files can be imported.
+//
+// The variables of a notebook, if any, are held in a YAML front matter before
+// the first block:
+//
+//	---
+//	variables:
+//	- defaultValue: main
+//	  name: branch
+//	---
+
+const (
+	markdownDirectivePrefix      = "sourcegraph"
+	markdownBlockEnd             = "<!-- /sourcegraph:md -->"
+	markdownFrontMatterDelimiter = "---"
+)
+
+// markdownFrontMatter holds the fields of a notebook other than its blocks in
+// the Markdown format of the notebook.
+type markdownFrontMatter struct {
+	Variables NotebookVariables `json:"variables,omitempty"`
+}
+
+var (
+	markdownBlockStartPattern = lazyregexp.New(`^<!-- sourcegraph:md(\s.*)? -->$`)
+	fencedBlockStartPattern   = lazyregexp.New("^(`{3,})sourcegraph(?::(\\w+))?(\\s.*)?$")
+	directiveAttributePattern = lazyregexp.New(`^(\w+)=`)
+	backtickRunPattern        = lazyregexp.New("`+")
+)
+
+// NotebookToMarkdown converts the blocks and variables to the Markdown format
+// of a notebook, which can be converted back to the same blocks and variables
+// with NotebookFromMarkdown.
+func NotebookToMarkdown(blocks NotebookBlocks, variables NotebookVariables) (string, error) {
+	var b strings.Builder
+	if len(variables) > 0 {
+		frontMatter, err := yaml.Marshal(markdownFrontMatter{Variables: variables})
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s\n%s%s\n", markdownFrontMatterDelimiter, frontMatter, markdownFrontMatterDelimiter)
+		if len(blocks) > 0 {
+			b.WriteString("\n")
+		}
+	}
+
+	for i, block := range blocks {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		attributes := fmt.Sprintf("id=%s", strconv.Quote(block.ID))
+		switch {
+		case block.MarkdownInput != nil:
+			for _, line := range strings.Split(block.MarkdownInput.Text, "\n") {
+				if markdownBlockStartPattern.MatchString(line) || line == markdownBlockEnd {
+					return "", errors.Errorf("markdown block with id %s contains a block directive", block.ID)
+				}
+			}
+			fmt.Fprintf(&b, "<!-- %s:%s %s -->\n%s\n%s\n", markdownDirectivePrefix, NotebookMarkdownBlockType, attributes, block.MarkdownInput.Text, markdownBlockEnd)
+		case block.QueryInput != nil:
+			writeFencedBlock(&b, NotebookQueryBlockType, attributes, block.QueryInput.Text)
+		case block.ComputeInput != nil:
+			writeFencedBlock(&b, NotebookComputeBlockType, attributes, block.ComputeInput.Value)
+		case block.FileInput != nil:
+			input := block.FileInput
+			fields := []string{"repository: " + input.RepositoryName, "path: " + input.FilePath}
+			if input.Revision != nil {
+				fields = append(fields, "revision: "+*input.Revision)
+			}
+			if input.LineRange != nil {
+				fields = append(fields, fmt.Sprintf("lines: %d-%d", input.LineRange.StartLine, input.LineRange.EndLine))
+			}
+			writeFencedBlock(&b, NotebookFileBlockType, attributes, strings.Join(fields, "\n"))
+		case block.SymbolInput != nil:
+			input := block.SymbolInput
+			fields := []string{"repository: " + input.RepositoryName, "path: " + input.FilePath}
+			if input.Revision != nil {
+				fields = append(fields, "revision: "+*input.Revision)
+			}
+			fields = append(fields,
+				"symbolName: "+input.SymbolName,
+				"symbolContainerName: "+input.SymbolContainerName,
+				"symbolKind: "+input.SymbolKind,
+				fmt.Sprintf("lineContext: %d", input.LineContext),
+			)
+			writeFencedBlock(&b, NotebookSymbolBlockType, attributes, strings.Join(fields, "\n"))
+		default:
+			return "", errors.Errorf("invalid block with id: %s", block.ID)
+		}
+	}
+	return b.String(), nil
+}
+
+// writeFencedBlock writes content as a fenced code block whose fence is longer
+// than any run of backticks in the content.
+func writeFencedBlock(b *strings.Builder, blockType NotebookBlockType, attributes, content string) {
+	fenceLength := 3
+	for _, run := range backtickRunPattern.FindAllString(content, -1) {
+		if len(run) >= fenceLength {
+			fenceLength = len(run) + 1
+		}
+	}
+	fence := strings.Repeat("`", fenceLength)
+	fmt.Fprintf(b, "%s%s:%s %s\n%s\n%s\n", fence, markdownDirectivePrefix, blockType, attributes, content, fence)
+}
+
+// NotebookFromMarkdown parses blocks and variables from the Markdown format of
+// a notebook. A fenced code block with the plain "sourcegraph" info string is
+// parsed as a query block.
+func NotebookFromMarkdown(markdown string) (NotebookBlocks, NotebookVariables, error) {
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+
+	frontMatter, err := parseMarkdownFrontMatter(lines)
+	if err != nil {
+		return nil, nil, err
+	}
+	blocks, err := parseMarkdownBlocks(lines)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := validateNotebookBlocks(blocks); err != nil {
+		return nil, nil, err
+	}
+	if err := validateNotebookVariables(frontMatter.Variables, blocks); err != nil {
+		return nil, nil, err
+	}
+	return blocks, frontMatter.Variables, nil
+}
+
+// parseMarkdownFrontMatter parses the front matter at the start of the given
+// lines, if any, and blanks its lines so that they are not parsed as blocks.
+func parseMarkdownFrontMatter(lines []string) (*markdownFrontMatter, error) {
+	frontMatter := &markdownFrontMatter{}
+	if len(lines) == 0 || strings.TrimRight(lines[0], " ") != markdownFrontMatterDelimiter {
+		return frontMatter, nil
+	}
+
+	end := 1
+	for end < len(lines) && strings.TrimRight(lines[end], " ") != markdownFrontMatterDelimiter {
+		end++
+	}
+	if end == len(lines) {
+		return nil, errors.New("line 1: unterminated front matter")
+	}
+
+	if err := yaml.Unmarshal([]byte(strings.Join(lines[1:end], "\n")), frontMatter); err != nil {
+		return nil, errors.Wrap(err, "invalid front matter")
+	}
+	for i := 0; i <= end; i++ {
+		lines[i] = ""
+	}
+	return frontMatter, nil
+}
+
+func parseMarkdownBlocks(lines []string) (NotebookBlocks, error) {
+	var (
+		blocks NotebookBlocks
+		text   []string
+	)
+	flushText := func() {
+		content := strings.Trim(strings.Join(text, "\n"), "\n")
+		text = nil
+		if strings.TrimSpace(content) == "" {
+			return
+		}
+		blocks = append(blocks, NotebookBlock{
+			ID:            uuid.NewString(),
+			Type:          NotebookMarkdownBlockType,
+			MarkdownInput: &NotebookMarkdownBlockInput{Text: content},
+		})
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		var (
+			blockType   NotebookBlockType
+			rawAttrs    string
+			isBlockLine func(string) bool
+		)
+		if m := markdownBlockStartPattern.FindStringSubmatch(line); m != nil {
+			blockType, rawAttrs = NotebookMarkdownBlockType, m[1]
+			isBlockLine = func(l string) bool { return l == markdownBlockEnd }
+		} else if m := fencedBlockStartPattern.FindStringSubmatch(line); m != nil {
+			blockType, rawAttrs = NotebookBlockType(m[2]), m[3]
+			if blockType == "" {
+				blockType = NotebookQueryBlockType
+			}
+			fence := m[1]
+			isBlockLine = func(l string) bool { return strings.TrimRight(l, " ") == fence }
+		} else {
+			text = append(text, line)
+			continue
+		}
+		flushText()
+
+		end := i + 1
+		for end < len(lines) && !isBlockLine(lines[end]) {
+			end++
+		}
+		if end == len(lines) {
+			return nil, errors.Errorf("line %d: unterminated %s block", i+1, blockType)
+		}
+
+		attributes, err := parseDirectiveAttributes(rawAttrs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", i+1)
+		}
+		block, err := parseMarkdownBlock(blockType, attributes, strings.Join(lines[i+1:end], "\n"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", i+1)
+		}
+		blocks = append(blocks, *block)
+		i = end
+	}
+	flushText()
+
+	return blocks, nil
+}
+
+func parseMarkdownBlock(blockType NotebookBlockType, attributes map[string]string, content string) (*NotebookBlock, error) {
+	id := attributes["id"]
+	if id == "" {
+		id = uuid.NewString()
+	}
+	block := &NotebookBlock{ID: id, Type: blockType}
+
+	switch blockType {
+	case NotebookMarkdownBlockType:
+		block.MarkdownInput = &NotebookMarkdownBlockInput{Text: content}
+	case NotebookQueryBlockType:
+		block.QueryInput = &NotebookQueryBlockInput{Text: content}
+	case NotebookComputeBlockType:
+		block.ComputeInput = &NotebookComputeBlockInput{Value: content}
+	case NotebookFileBlockType:
+		fields, err := parseBlockFields(content, "repository", "path", "revision", "lines")
+		if err != nil {
+			return nil, err
+		}
+		input := &NotebookFileBlockInput{RepositoryName: fields["repository"], FilePath: fields["path"]}
+		if revision, ok := fields["revision"]; ok {
+			input.Revision = &revision
+		}
+		if lines, ok := fields["lines"]; ok {
+			if input.LineRange, err = parseLineRange(lines); err != nil {
+				return nil, err
+			}
+		}
+		block.FileInput = input
+	case NotebookSymbolBlockType:
+		fields, err := parseBlockFields(content, "repository", "path", "revision", "symbolName", "symbolContainerName", "symbolKind", "lineContext")
+		if err != nil {
+			return nil, err
+		}
+		input := &NotebookSymbolBlockInput{
+			RepositoryName:      fields["repository"],
+			FilePath:            fields["path"],
+			SymbolName:          fields["symbolName"],
+			SymbolContainerName: fields["symbolContainerName"],
+			SymbolKind:          fields["symbolKind"],
+		}
+		if revision, ok := fields["revision"]; ok {
+			input.Revision = &revision
+		}
+		if lineContext, ok := fields["lineContext"]; ok {
+			value, err := strconv.ParseInt(lineContext, 10, 32)
+			if err != nil {
+				return nil, errors.Errorf("invalid line context: %q", lineContext)
+			}
+			input.LineContext = int32(value)
+		}
+		block.SymbolInput = input
+	default:
+		return nil, errors.Errorf("invalid block type: %s", blockType)
+	}
+	return block, nil
+}
+
+// parseDirectiveAttributes parses the space-separated key="value" attributes
+// of a block directive, where values are Go-quoted strings.
+func parseDirectiveAttributes(s string) (map[string]string, error) {
+	attributes := map[string]string{}
+	s = strings.TrimSpace(s)
+	for s != "" {
+		m := directiveAttributePattern.FindStringSubmatch(s)
+		if m == nil {
+			return nil, errors.Errorf("invalid block attributes: %q", s)
+		}
+		quoted, err := strconv.QuotedPrefix(s[len(m[0]):])
+		if err != nil {
+			return nil, errors.Errorf("invalid value of block attribute %q", m[1])
+		}
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, errors.Errorf("invalid value of block attribute %q", m[1])
+		}
+		attributes[m[1]] = value
+		s = strings.TrimSpace(s[len(m[0])+len(quoted):])
+	}
+	return attributes, nil
+}
+
+// parseBlockFields parses the "key: value" lines of a file or symbol block,
+// where keys must be one of the given keys.
+func parseBlockFields(content string, keys ...string) (map[string]string, error) {
+	valid := map[string]struct{}{}
+	for _, key := range keys {
+		valid[key] = struct{}{}
+	}
+
+	fields := map[string]string{}
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, errors.Errorf("invalid block field: %q", line)
+		}
+		key = strings.TrimSpace(key)
+		if _, ok := valid[key]; !ok {
+			sort.Strings(keys)
+			return nil, errors.Errorf("unknown block field %q, expected one of %s", key, strings.Join(keys, ", "))
+		}
+		if _, ok := fields[key]; ok {
+			return nil, errors.Errorf("duplicate block field: %s", key)
+		}
+		fields[key] = strings.TrimPrefix(value, " ")
+	}
+	return fields, nil
+}
+
+func parseLineRange(s string) (*LineRange, error) {
+	start, end, ok := strings.Cut(s, "-")
+	if !ok {
+		return nil, errors.Errorf("invalid line range: %q", s)
+	}
+	startLine, err := strconv.ParseInt(strings.TrimSpace(start), 10, 32)
+	if err != nil {
+		return nil, errors.Errorf("invalid line range: %q", s)
+	}
+	endLine, err := strconv.ParseInt(strings.TrimSpace(end), 10, 32)
+	if err != nil {
+		return nil, errors.Errorf("invalid line range: %q", s)
+	}
+	return &LineRange{StartLine: int32(startLine), EndLine: int32(endLine)}, nil
+}
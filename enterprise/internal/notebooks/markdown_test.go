@@ -0,0 +1,146 @@
+package notebooks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNotebookMarkdownRoundTrip(t *testing.T) {
+	revision := "main"
+	emptyRevision := ""
+	blocks := NotebookBlocks{
+		{ID: "1", Type: NotebookMarkdownBlockType, MarkdownInput: &NotebookMarkdownBlockInput{"# Runbook\n\n```go\nfunc main() {}\n```\n"}},
+		{ID: "2", Type: NotebookQueryBlockType, QueryInput: &NotebookQueryBlockInput{"repo:a b ```"}},
+		{ID: "3", Type: NotebookFileBlockType, FileInput: &NotebookFileBlockInput{RepositoryName: "github.com/a/b", FilePath: "dir/main.go", Revision: &revision, LineRange: &LineRange{StartLine: 1, EndLine: 10}}},
+		{ID: "4", Type: NotebookFileBlockType, FileInput: &NotebookFileBlockInput{RepositoryName: "github.com/a/b", FilePath: "main.go", Revision: &emptyRevision}},
+		{ID: "5", Type: NotebookSymbolBlockType, SymbolInput: &NotebookSymbolBlockInput{RepositoryName: "github.com/a/b", FilePath: "main.go", LineContext: 3, SymbolName: "main", SymbolKind: "FUNCTION"}},
+		{ID: "6", Type: NotebookComputeBlockType, ComputeInput: &NotebookComputeBlockInput{`{"query":"repo:a content:output((.*) -> $1)"}`}},
+		{ID: "id with \"quotes\"", Type: NotebookQueryBlockType, QueryInput: &NotebookQueryBlockInput{""}},
+	}
+
+	markdown, err := NotebookToMarkdown(blocks, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, variables, err := NotebookFromMarkdown(markdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, blocks) {
+		t.Fatalf("wanted %+v blocks, got %+v\nmarkdown:\n%s", blocks, got, markdown)
+	}
+	if variables != nil {
+		t.Fatalf("expected no variables, got %+v", variables)
+	}
+}
+
+func TestNotebookMarkdownRoundTripVariables(t *testing.T) {
+	blocks := NotebookBlocks{
+		{ID: "1", Type: NotebookMarkdownBlockType, MarkdownInput: &NotebookMarkdownBlockInput{"---\nNot front matter\n---"}},
+		{ID: "2", Type: NotebookQueryBlockType, QueryInput: &NotebookQueryBlockInput{"repo:${repo} rev:${branch} ${symbol}"}},
+	}
+	variables := NotebookVariables{
+		{Name: "repo", DefaultValue: "github.com/a/b"},
+		{Name: "branch", DefaultValue: "main"},
+		{Name: "symbol", DefaultValue: ""},
+	}
+
+	for _, blocks := range []NotebookBlocks{blocks, nil} {
+		markdown, err := NotebookToMarkdown(blocks, variables)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotBlocks, gotVariables, err := NotebookFromMarkdown(markdown)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(gotBlocks, blocks) {
+			t.Fatalf("wanted %+v blocks, got %+v\nmarkdown:\n%s", blocks, gotBlocks, markdown)
+		}
+		if !reflect.DeepEqual(gotVariables, variables) {
+			t.Fatalf("wanted %+v variables, got %+v\nmarkdown:\n%s", variables, gotVariables, markdown)
+		}
+	}
+}
+
+func TestNotebookFromMarkdown(t *testing.T) {
+	markdown := strings.Join([]string{
+		"# Runbook",
+		"",
+		"Find the callers first.",
+		"",
+		"```sourcegraph",
+		"repo:a NewServer",
+		"```",
+		"",
+		"```sourcegraph:file id=\"f\"",
+		"repository: github.com/a/b",
+		"path: main.go",
+		"lines: 2-4",
+		"```",
+		"",
+		"Done.",
+	}, "\n")
+
+	got, _, err := NotebookFromMarkdown(markdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("expected 4 blocks, got %d", len(got))
+	}
+	for _, block := range got {
+		if block.ID == "" {
+			t.Fatal("expected blocks without an id to be assigned one")
+		}
+	}
+	if got[0].MarkdownInput == nil || got[0].MarkdownInput.Text != "# Runbook\n\nFind the callers first." {
+		t.Fatalf("unexpected first block %+v", got[0])
+	}
+	if got[1].QueryInput == nil || got[1].QueryInput.Text != "repo:a NewServer" {
+		t.Fatalf("unexpected second block %+v", got[1])
+	}
+	wantFile := NotebookBlock{ID: "f", Type: NotebookFileBlockType, FileInput: &NotebookFileBlockInput{RepositoryName: "github.com/a/b", FilePath: "main.go", LineRange: &LineRange{StartLine: 2, EndLine: 4}}}
+	if !reflect.DeepEqual(got[2], wantFile) {
+		t.Fatalf("wanted %+v file block, got %+v", wantFile, got[2])
+	}
+	if got[3].MarkdownInput == nil || got[3].MarkdownInput.Text != "Done." {
+		t.Fatalf("unexpected last block %+v", got[3])
+	}
+}
+
+func TestNotebookFromMarkdownErrors(t *testing.T) {
+	tests := []struct {
+		markdown string
+		wantErr  string
+	}{
+		{markdown: "```sourcegraph:query\nrepo:a", wantErr: "line 1: unterminated query block"},
+		{markdown: "<!-- sourcegraph:md id=\"1\" -->\n# Title", wantErr: "line 1: unterminated md block"},
+		{markdown: "```sourcegraph:chart\nx\n```", wantErr: "line 1: invalid block type: chart"},
+		{markdown: "```sourcegraph:query id=1\nrepo:a\n```", wantErr: `line 1: invalid value of block attribute "id"`},
+		{markdown: "```sourcegraph:file\nrepo: a\n```", wantErr: `line 1: unknown block field "repo", expected one of lines, path, repository, revision`},
+		{markdown: "```sourcegraph:file\nlines: 1\n```", wantErr: `line 1: invalid line range: "1"`},
+		{markdown: "```sourcegraph:symbol\nlineContext: -1\n```", wantErr: "symbol block line context cannot be negative"},
+		{markdown: "```sourcegraph:query id=\"1\"\na\n```\n```sourcegraph:query id=\"1\"\nb\n```", wantErr: "duplicate block id found: 1"},
+		{markdown: "---\nvariables: []\n", wantErr: "line 1: unterminated front matter"},
+		{markdown: "---\nvariables: repo\n---\n", wantErr: "invalid front matter"},
+		{markdown: "---\nvariables:\n- name: repo name\n---\n", wantErr: `invalid variable name: "repo name"`},
+		{markdown: "---\nvariables:\n- name: repo\n---\n```sourcegraph:query id=\"1\"\nrepo:${repo} ${symbol}\n```", wantErr: "undeclared variable symbol referenced in block with id: 1"},
+		{markdown: "---\nvariables: []\n---\n```sourcegraph:query\nrepo:a", wantErr: "line 4: unterminated query block"},
+	}
+
+	for _, tt := range tests {
+		_, _, err := NotebookFromMarkdown(tt.markdown)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("expected error containing %q for %q, got %v", tt.wantErr, tt.markdown, err)
+		}
+	}
+}
+
+func TestNotebookToMarkdownDirectiveInMarkdownBlock(t *testing.T) {
+	blocks := NotebookBlocks{{ID: "1", Type: NotebookMarkdownBlockType, MarkdownInput: &NotebookMarkdownBlockInput{"a\n<!-- /sourcegraph:md -->"}}}
+	if _, err := NotebookToMarkdown(blocks, nil); err == nil {
+		t.Fatal("expected error")
+	}
+}
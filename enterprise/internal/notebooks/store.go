@@ -62,6 +62,21 @@ func (blocks *NotebookBlocks) Scan(value any) error {
 	return json.Unmarshal(b, &blocks)
 }
 
+func (variables NotebookVariables) Value() (driver.Value, error) {
+	if variables == nil {
+		variables = NotebookVariables{}
+	}
+	return json.Marshal(variables)
+}
+
+func (variables *NotebookVariables) Scan(value any) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, &variables)
+}
+
 func Notebooks(db database.DB) NotebooksStore {
 	store := basestore.NewWithHandle(db.Handle())
 	return &notebooksStore{store}
@@ -110,6 +125,7 @@ var notebookColumns = []*sqlf.Query{
 	sqlf.Sprintf("notebooks.id"),
 	sqlf.Sprintf("notebooks.title"),
 	sqlf.Sprintf("notebooks.blocks"),
+	sqlf.Sprintf("notebooks.variables"),
 	sqlf.Sprintf("notebooks.public"),
 	sqlf.Sprintf("notebooks.creator_user_id"),
 	sqlf.Sprintf("notebooks.updater_user_id"),
@@ -189,6 +205,7 @@ func scanNotebook(scanner dbutil.Scanner) (*Notebook, error) {
 		&n.ID,
 		&n.Title,
 		&n.Blocks,
+		&n.Variables,
 		&n.Public,
 		&dbutil.NullInt32{N: &n.CreatorUserID},
 		&dbutil.NullInt32{N: &n.UpdaterUserID},
@@ -333,12 +350,12 @@ func (s *notebooksStore) GetNotebook(ctx context.Context, id int64) (*Notebook,
 }
 
 const insertNotebookFmtStr = `
-INSERT INTO notebooks (title, blocks, public, creator_user_id, updater_user_id, namespace_user_id, namespace_org_id) VALUES (%s, %s, %s, %s, %s, %s, %s)
+INSERT INTO notebooks (title, blocks, variables, public, creator_user_id, updater_user_id, namespace_user_id, namespace_org_id) VALUES (%s, %s, %s, %s, %s, %s, %s, %s)
 RETURNING %s
 `
 
 func (s *notebooksStore) CreateNotebook(ctx context.Context, n *Notebook) (*Notebook, error) {
-	err := validateNotebookBlocks(n.Blocks)
+	err := validateNotebook(n)
 	if err != nil {
 		return nil, err
 	}
@@ -348,6 +365,7 @@ func (s *notebooksStore) CreateNotebook(ctx context.Context, n *Notebook) (*Note
 			insertNotebookFmtStr,
 			n.Title,
 			n.Blocks,
+			n.Variables,
 			n.Public,
 			nullInt32Column(n.CreatorUserID),
 			nullInt32Column(n.UpdaterUserID),
@@ -371,6 +389,7 @@ UPDATE notebooks
 SET
 	title = %s,
 	blocks = %s,
+	variables = %s,
 	public = %s,
 	updater_user_id = %d,
 	namespace_user_id = %d,
@@ -382,7 +401,7 @@ RETURNING %s
 
 // 🚨 SECURITY: The caller must ensure that the actor has permission to update the notebook.
 func (s *notebooksStore) UpdateNotebook(ctx context.Context, n *Notebook) (*Notebook, error) {
-	err := validateNotebookBlocks(n.Blocks)
+	err := validateNotebook(n)
 	if err != nil {
 		return nil, err
 	}
@@ -392,6 +411,7 @@ func (s *notebooksStore) UpdateNotebook(ctx context.Context, n *Notebook) (*Note
 			updateNotebookFmtStr,
 			n.Title,
 			n.Blocks,
+			n.Variables,
 			n.Public,
 			nullInt32Column(n.UpdaterUserID),
 			nullInt32Column(n.NamespaceUserID),
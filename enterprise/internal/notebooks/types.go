@@ -63,10 +63,21 @@ type NotebookBlock struct {
 
 type NotebookBlocks []NotebookBlock
 
+// NotebookVariable is a notebook-level input variable. References to the
+// variable in the form ${name} in the inputs of query, file, symbol and compute
+// blocks are substituted with its value when the notebook is rendered.
+type NotebookVariable struct {
+	Name         string `json:"name"`
+	DefaultValue string `json:"defaultValue"`
+}
+
+type NotebookVariables []NotebookVariable
+
 type Notebook struct {
 	ID              int64
 	Title           string
 	Blocks          NotebookBlocks
+	Variables       NotebookVariables
 	Public          bool
 	CreatorUserID   int32
 	UpdaterUserID   int32
@@ -1,6 +1,9 @@
 package notebooks
 
-import "github.com/sourcegraph/sourcegraph/lib/errors"
+import (
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
 
 func validateNotebookBlock(block NotebookBlock) error {
 	if block.Type != NotebookQueryBlockType &&
@@ -46,3 +49,42 @@ func validateNotebookBlocks(blocks NotebookBlocks) error {
 	}
 	return nil
 }
+
+var validVariableName = lazyregexp.New(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func validateNotebookVariables(variables NotebookVariables, blocks NotebookBlocks) error {
+	names := map[string]struct{}{}
+	for _, variable := range variables {
+		if !validVariableName.MatchString(variable.Name) {
+			return errors.Errorf("invalid variable name: %q", variable.Name)
+		}
+
+		_, ok := names[variable.Name]
+		if ok {
+			return errors.Errorf("duplicate variable name found: %s", variable.Name)
+		}
+		names[variable.Name] = struct{}{}
+	}
+
+	// Notebooks without variables are not parameterized, so text that looks
+	// like a reference (such as a search for ${HOME}) is left alone.
+	if len(variables) == 0 {
+		return nil
+	}
+
+	for _, block := range blocks {
+		for _, name := range variableReferences(block) {
+			if _, ok := names[name]; !ok {
+				return errors.Errorf("undeclared variable %s referenced in block with id: %s", name, block.ID)
+			}
+		}
+	}
+	return nil
+}
+
+func validateNotebook(n *Notebook) error {
+	if err := validateNotebookBlocks(n.Blocks); err != nil {
+		return err
+	}
+	return validateNotebookVariables(n.Variables, n.Blocks)
+}
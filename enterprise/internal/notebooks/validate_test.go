@@ -34,3 +34,38 @@ func TestNotebookBlocksValidation(t *testing.T) {
 		}
 	}
 }
+
+func TestNotebookVariablesValidation(t *testing.T) {
+	tests := []struct {
+		variables NotebookVariables
+		blocks    NotebookBlocks
+		wantErr   string
+	}{
+		{variables: NotebookVariables{{Name: "repo name"}}, wantErr: `invalid variable name: "repo name"`},
+		{variables: NotebookVariables{{Name: "repo"}, {Name: "repo"}}, wantErr: "duplicate variable name found: repo"},
+		{
+			variables: NotebookVariables{{Name: "repo"}},
+			blocks: NotebookBlocks{
+				{ID: "id1", Type: NotebookQueryBlockType, QueryInput: &NotebookQueryBlockInput{"repo:${repo} ${symbol}"}},
+			},
+			wantErr: "undeclared variable symbol referenced in block with id: id1",
+		},
+	}
+
+	for _, tt := range tests {
+		err := validateNotebook(&Notebook{Variables: tt.variables, Blocks: tt.blocks})
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		} else if err.Error() != tt.wantErr {
+			t.Fatalf("wanted '%s' error, got '%s'", tt.wantErr, err.Error())
+		}
+	}
+
+	// References are not checked in notebooks without variables.
+	err := validateNotebook(&Notebook{Blocks: NotebookBlocks{
+		{ID: "id1", Type: NotebookQueryBlockType, QueryInput: &NotebookQueryBlockInput{"content:${HOME}"}},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
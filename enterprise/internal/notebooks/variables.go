@@ -0,0 +1,106 @@
+package notebooks
+
+import (
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// variableReferencePattern matches a reference to a notebook variable, such as
+// ${repo}. The first submatch is the name of the variable.
+var variableReferencePattern = lazyregexp.New(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// VariableValues returns the values of the variables of the notebook, where
+// values holds the values that override the default values. It returns an
+// error if values holds a value for a variable the notebook does not declare.
+func (n *Notebook) VariableValues(values map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(n.Variables))
+	for _, v := range n.Variables {
+		resolved[v.Name] = v.DefaultValue
+	}
+	for name, value := range values {
+		if _, ok := resolved[name]; !ok {
+			return nil, errors.Errorf("notebook has no variable named %q", name)
+		}
+		resolved[name] = value
+	}
+	return resolved, nil
+}
+
+// SubstituteVariables returns a copy of the blocks where the references to
+// variables in the inputs of query, file, symbol and compute blocks are
+// replaced with their values. References to unknown variables are left as is.
+// Markdown blocks are returned unchanged.
+func (blocks NotebookBlocks) SubstituteVariables(values map[string]string) NotebookBlocks {
+	substitute := func(s string) string {
+		return variableReferencePattern.ReplaceAllStringFunc(s, func(ref string) string {
+			name := variableReferencePattern.FindStringSubmatch(ref)[1]
+			if value, ok := values[name]; ok {
+				return value
+			}
+			return ref
+		})
+	}
+	substitutePtr := func(s *string) *string {
+		if s == nil {
+			return nil
+		}
+		v := substitute(*s)
+		return &v
+	}
+
+	substituted := make(NotebookBlocks, 0, len(blocks))
+	for _, block := range blocks {
+		switch {
+		case block.QueryInput != nil:
+			block.QueryInput = &NotebookQueryBlockInput{Text: substitute(block.QueryInput.Text)}
+		case block.FileInput != nil:
+			input := *block.FileInput
+			input.RepositoryName = substitute(input.RepositoryName)
+			input.FilePath = substitute(input.FilePath)
+			input.Revision = substitutePtr(input.Revision)
+			block.FileInput = &input
+		case block.SymbolInput != nil:
+			input := *block.SymbolInput
+			input.RepositoryName = substitute(input.RepositoryName)
+			input.FilePath = substitute(input.FilePath)
+			input.Revision = substitutePtr(input.Revision)
+			input.SymbolName = substitute(input.SymbolName)
+			input.SymbolContainerName = substitute(input.SymbolContainerName)
+			block.SymbolInput = &input
+		case block.ComputeInput != nil:
+			block.ComputeInput = &NotebookComputeBlockInput{Value: substitute(block.ComputeInput.Value)}
+		}
+		substituted = append(substituted, block)
+	}
+	return substituted
+}
+
+// variableReferences returns the names of the variables referenced in the
+// inputs of the block that are substituted when the notebook is rendered.
+func variableReferences(block NotebookBlock) []string {
+	var inputs []string
+	switch {
+	case block.QueryInput != nil:
+		inputs = append(inputs, block.QueryInput.Text)
+	case block.FileInput != nil:
+		inputs = append(inputs, block.FileInput.RepositoryName, block.FileInput.FilePath)
+		if block.FileInput.Revision != nil {
+			inputs = append(inputs, *block.FileInput.Revision)
+		}
+	case block.SymbolInput != nil:
+		inputs = append(inputs, block.SymbolInput.RepositoryName, block.SymbolInput.FilePath, block.SymbolInput.SymbolName, block.SymbolInput.SymbolContainerName)
+		if block.SymbolInput.Revision != nil {
+			inputs = append(inputs, *block.SymbolInput.Revision)
+		}
+	case block.ComputeInput != nil:
+		inputs = append(inputs, block.ComputeInput.Value)
+	}
+
+	var names []string
+	for _, input := range inputs {
+		for _, match := range variableReferencePattern.FindAllStringSubmatch(input, -1) {
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
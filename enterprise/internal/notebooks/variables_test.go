@@ -0,0 +1,57 @@
+package notebooks
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNotebookVariableValues(t *testing.T) {
+	notebook := &Notebook{Variables: NotebookVariables{
+		{Name: "repo", DefaultValue: "github.com/sourcegraph/sourcegraph"},
+		{Name: "symbol", DefaultValue: "NewServer"},
+	}}
+
+	values, err := notebook.VariableValues(map[string]string{"symbol": "NewHandler"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"repo": "github.com/sourcegraph/sourcegraph", "symbol": "NewHandler"}
+	if !reflect.DeepEqual(values, want) {
+		t.Fatalf("wanted %v values, got %v", want, values)
+	}
+
+	_, err = notebook.VariableValues(map[string]string{"service": "frontend"})
+	if err == nil || err.Error() != `notebook has no variable named "service"` {
+		t.Fatalf("expected unknown variable error, got %v", err)
+	}
+}
+
+func TestNotebookBlocksSubstituteVariables(t *testing.T) {
+	revision := "${branch}"
+	blocks := NotebookBlocks{
+		{ID: "1", Type: NotebookMarkdownBlockType, MarkdownInput: &NotebookMarkdownBlockInput{"# Runbook for ${repo}"}},
+		{ID: "2", Type: NotebookQueryBlockType, QueryInput: &NotebookQueryBlockInput{"repo:${repo} ${symbol} ${unknown}"}},
+		{ID: "3", Type: NotebookFileBlockType, FileInput: &NotebookFileBlockInput{RepositoryName: "${repo}", FilePath: "main.go", Revision: &revision}},
+		{ID: "4", Type: NotebookSymbolBlockType, SymbolInput: &NotebookSymbolBlockInput{RepositoryName: "${repo}", FilePath: "main.go", SymbolName: "${symbol}"}},
+		{ID: "5", Type: NotebookComputeBlockType, ComputeInput: &NotebookComputeBlockInput{Value: `{"query":"repo:${repo}"}`}},
+	}
+
+	got := blocks.SubstituteVariables(map[string]string{"repo": "a/b", "symbol": "Serve", "branch": "main"})
+
+	wantRevision := "main"
+	want := NotebookBlocks{
+		{ID: "1", Type: NotebookMarkdownBlockType, MarkdownInput: &NotebookMarkdownBlockInput{"# Runbook for ${repo}"}},
+		{ID: "2", Type: NotebookQueryBlockType, QueryInput: &NotebookQueryBlockInput{"repo:a/b Serve ${unknown}"}},
+		{ID: "3", Type: NotebookFileBlockType, FileInput: &NotebookFileBlockInput{RepositoryName: "a/b", FilePath: "main.go", Revision: &wantRevision}},
+		{ID: "4", Type: NotebookSymbolBlockType, SymbolInput: &NotebookSymbolBlockInput{RepositoryName: "a/b", FilePath: "main.go", SymbolName: "Serve"}},
+		{ID: "5", Type: NotebookComputeBlockType, ComputeInput: &NotebookComputeBlockInput{Value: `{"query":"repo:a/b"}`}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("wanted %+v blocks, got %+v", want, got)
+	}
+
+	// The original blocks are left untouched.
+	if blocks[1].QueryInput.Text != "repo:${repo} ${symbol} ${unknown}" || *blocks[2].FileInput.Revision != "${branch}" {
+		t.Fatal("expected original blocks to be unchanged")
+	}
+}
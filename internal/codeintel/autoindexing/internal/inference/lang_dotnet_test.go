@@ -0,0 +1,88 @@
+package inference
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/autoindex/config"
+)
+
+func TestDotnetGenerator(t *testing.T) {
+	testGenerators(t,
+		generatorTestCase{
+			description: "solution files",
+			repositoryContents: map[string]string{
+				"App.sln":            "",
+				"src/App/App.csproj": "",
+				"src/Lib/Lib.csproj": "",
+				"tools/Tools.sln":    "",
+			},
+			expected: []config.IndexJob{
+				{
+					Steps: []config.DockerStep{
+						{
+							Root:     "",
+							Image:    "sourcegraph/scip-dotnet:autoindex",
+							Commands: []string{"dotnet restore App.sln"},
+						},
+					},
+					LocalSteps:  nil,
+					Root:        "",
+					Indexer:     "sourcegraph/scip-dotnet:autoindex",
+					IndexerArgs: []string{"scip-dotnet", "index", "App.sln"},
+					Outfile:     "index.scip",
+				},
+				{
+					Steps: []config.DockerStep{
+						{
+							Root:     "tools",
+							Image:    "sourcegraph/scip-dotnet:autoindex",
+							Commands: []string{"dotnet restore Tools.sln"},
+						},
+					},
+					LocalSteps:  nil,
+					Root:        "tools",
+					Indexer:     "sourcegraph/scip-dotnet:autoindex",
+					IndexerArgs: []string{"scip-dotnet", "index", "Tools.sln"},
+					Outfile:     "index.scip",
+				},
+			},
+		},
+		generatorTestCase{
+			description: "project files",
+			repositoryContents: map[string]string{
+				"src/App/App.csproj": "",
+				"src/Lib/Lib.csproj": "",
+			},
+			expected: []config.IndexJob{
+				{
+					Steps: []config.DockerStep{
+						{
+							Root:     "src/App",
+							Image:    "sourcegraph/scip-dotnet:autoindex",
+							Commands: []string{"dotnet restore App.csproj"},
+						},
+					},
+					LocalSteps:  nil,
+					Root:        "src/App",
+					Indexer:     "sourcegraph/scip-dotnet:autoindex",
+					IndexerArgs: []string{"scip-dotnet", "index", "App.csproj"},
+					Outfile:     "index.scip",
+				},
+				{
+					Steps: []config.DockerStep{
+						{
+							Root:     "src/Lib",
+							Image:    "sourcegraph/scip-dotnet:autoindex",
+							Commands: []string{"dotnet restore Lib.csproj"},
+						},
+					},
+					LocalSteps:  nil,
+					Root:        "src/Lib",
+					Indexer:     "sourcegraph/scip-dotnet:autoindex",
+					IndexerArgs: []string{"scip-dotnet", "index", "Lib.csproj"},
+					Outfile:     "index.scip",
+				},
+			},
+		},
+	)
+}
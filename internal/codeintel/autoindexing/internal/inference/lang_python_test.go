@@ -0,0 +1,72 @@
+package inference
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/autoindex/config"
+)
+
+func TestPythonGenerator(t *testing.T) {
+	testGenerators(t,
+		generatorTestCase{
+			description: "python projects",
+			repositoryContents: map[string]string{
+				"requirements.txt":     "",
+				"setup.py":             "",
+				"foo/pyproject.toml":   "",
+				"bar/requirements.txt": "",
+			},
+			expected: []config.IndexJob{
+				{
+					Steps: []config.DockerStep{
+						{
+							Root:     "",
+							Image:    "sourcegraph/scip-python:autoindex",
+							Commands: []string{"pip install -r requirements.txt", "pip install ."},
+						},
+					},
+					LocalSteps:  nil,
+					Root:        "",
+					Indexer:     "sourcegraph/scip-python:autoindex",
+					IndexerArgs: []string{"scip-python", "index", "."},
+					Outfile:     "index.scip",
+				},
+				{
+					Steps: []config.DockerStep{
+						{
+							Root:     "bar",
+							Image:    "sourcegraph/scip-python:autoindex",
+							Commands: []string{"pip install -r requirements.txt"},
+						},
+					},
+					LocalSteps:  nil,
+					Root:        "bar",
+					Indexer:     "sourcegraph/scip-python:autoindex",
+					IndexerArgs: []string{"scip-python", "index", "."},
+					Outfile:     "index.scip",
+				},
+				{
+					Steps: []config.DockerStep{
+						{
+							Root:     "foo",
+							Image:    "sourcegraph/scip-python:autoindex",
+							Commands: []string{"pip install ."},
+						},
+					},
+					LocalSteps:  nil,
+					Root:        "foo",
+					Indexer:     "sourcegraph/scip-python:autoindex",
+					IndexerArgs: []string{"scip-python", "index", "."},
+					Outfile:     "index.scip",
+				},
+			},
+		},
+		generatorTestCase{
+			description: "python files only (no match)",
+			repositoryContents: map[string]string{
+				"main.py": "",
+			},
+			expected: []config.IndexJob{},
+		},
+	)
+}
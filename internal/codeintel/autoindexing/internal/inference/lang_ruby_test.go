@@ -0,0 +1,49 @@
+package inference
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/autoindex/config"
+)
+
+func TestRubyGenerator(t *testing.T) {
+	testGenerators(t,
+		generatorTestCase{
+			description: "bundler projects",
+			repositoryContents: map[string]string{
+				"Gemfile":             "",
+				"engines/foo/Gemfile": "",
+			},
+			expected: []config.IndexJob{
+				{
+					Steps: []config.DockerStep{
+						{
+							Root:     "",
+							Image:    "sourcegraph/scip-ruby:autoindex",
+							Commands: []string{"bundle install"},
+						},
+					},
+					LocalSteps:  nil,
+					Root:        "",
+					Indexer:     "sourcegraph/scip-ruby:autoindex",
+					IndexerArgs: []string{"scip-ruby", "--index-file", "index.scip"},
+					Outfile:     "index.scip",
+				},
+				{
+					Steps: []config.DockerStep{
+						{
+							Root:     "engines/foo",
+							Image:    "sourcegraph/scip-ruby:autoindex",
+							Commands: []string{"bundle install"},
+						},
+					},
+					LocalSteps:  nil,
+					Root:        "engines/foo",
+					Indexer:     "sourcegraph/scip-ruby:autoindex",
+					IndexerArgs: []string{"scip-ruby", "--index-file", "index.scip"},
+					Outfile:     "index.scip",
+				},
+			},
+		},
+	)
+}
@@ -0,0 +1,66 @@
+local path = require("path")
+local patterns = require("sg.patterns")
+local recognizers = require("sg.recognizers")
+
+local shared = loadfile("shared.lua")()
+
+local indexer = "sourcegraph/scip-dotnet:autoindex"
+local outfile = "index.scip"
+
+local exclude_paths = patterns.path_combine(shared.exclude_paths, {
+    patterns.path_segment("bin"),
+    patterns.path_segment("obj"),
+})
+
+local new_job = function(project_path)
+    local root = path.dirname(project_path)
+    local base = path.basename(project_path)
+
+    return {
+        steps = {
+            {
+                root = root,
+                image = indexer,
+                commands = { "dotnet restore " .. base },
+            },
+        },
+        root = root,
+        indexer = indexer,
+        indexer_args = { "scip-dotnet", "index", base },
+        outfile = outfile,
+    }
+end
+
+local generate = function(_, paths)
+    local jobs = {}
+    for i = 1, #paths do
+        table.insert(jobs, new_job(paths[i]))
+    end
+
+    return jobs
+end
+
+local sln_recognizer = recognizers.path_recognizer {
+    patterns = {
+        patterns.path_extension("sln"),
+        patterns.path_exclude(exclude_paths),
+    },
+
+    -- Invoked when solution files exist
+    generate = generate,
+}
+
+local csproj_recognizer = recognizers.path_recognizer {
+    patterns = {
+        patterns.path_extension("csproj"),
+        patterns.path_exclude(exclude_paths),
+    },
+
+    -- Invoked when no solution files exist but C# project files exist
+    generate = generate,
+}
+
+return recognizers.fallback_recognizer {
+    sln_recognizer,
+    csproj_recognizer,
+}
@@ -0,0 +1,78 @@
+local path = require("path")
+local patterns = require("sg.patterns")
+local recognizers = require("sg.recognizers")
+
+local shared = loadfile("shared.lua")()
+local util = loadfile("util.lua")()
+
+local indexer = "sourcegraph/scip-python:autoindex"
+local outfile = "index.scip"
+
+local exclude_paths = patterns.path_combine(shared.exclude_paths, {
+    patterns.path_segment("venv"),
+    patterns.path_segment(".venv"),
+    patterns.path_segment("site-packages"),
+})
+
+local is_project_file = function(base)
+    return base == "setup.py" or base == "pyproject.toml" or base == "requirements.txt"
+end
+
+return recognizers.path_recognizer {
+    patterns = {
+        patterns.path_basename("setup.py"),
+        patterns.path_basename("pyproject.toml"),
+        patterns.path_basename("requirements.txt"),
+        patterns.path_exclude(exclude_paths),
+    },
+
+    -- Invoked when setup.py, pyproject.toml, or requirements.txt files exist
+    generate = function(_, paths)
+        local roots = {}
+        local files_by_root = {}
+
+        for i = 1, #paths do
+            local root = path.dirname(paths[i])
+            local base = path.basename(paths[i])
+
+            if is_project_file(base) then
+                if files_by_root[root] == nil then
+                    files_by_root[root] = {}
+                    table.insert(roots, root)
+                end
+
+                table.insert(files_by_root[root], base)
+            end
+        end
+
+        local jobs = {}
+        for i = 1, #roots do
+            local root = roots[i]
+            local files = files_by_root[root]
+
+            local commands = {}
+            if util.contains(files, "requirements.txt") then
+                table.insert(commands, "pip install -r requirements.txt")
+            end
+            if util.contains_any(files, { "setup.py", "pyproject.toml" }) then
+                table.insert(commands, "pip install .")
+            end
+
+            table.insert(jobs, {
+                steps = {
+                    {
+                        root = root,
+                        image = indexer,
+                        commands = commands,
+                    },
+                },
+                root = root,
+                indexer = indexer,
+                indexer_args = { "scip-python", "index", "." },
+                outfile = outfile,
+            })
+        end
+
+        return jobs
+    end,
+}
@@ -1,7 +1,10 @@
 local languages = {
     "clang",
+    "dotnet",
     "go",
     "java",
+    "python",
+    "ruby",
     "rust",
     "test",
     "typescript",
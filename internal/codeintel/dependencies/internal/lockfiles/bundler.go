@@ -0,0 +1,90 @@
+package lockfiles
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+//
+// Gemfile.lock
+//
+
+// parseGemfileLockFile extracts all gems from the GEM section of a Gemfile.lock file.
+//
+// The GEM section lists every resolved gem (including transitive ones) under "specs:"
+// at an indentation of four spaces, in the form "name (version)". Their own
+// dependencies are listed beneath them at an indentation of six spaces with version
+// constraints instead of versions, and are ignored. Gems sourced from GIT or PATH
+// sections are not published to a gem server and are skipped.
+//
+// Platform-specific gems carry a platform suffix on their version, e.g.
+// "nokogiri (1.13.6-x86_64-linux)", which is stripped.
+func parseGemfileLockFile(r io.Reader) ([]reposource.PackageDependency, error) {
+	var (
+		libs    []reposource.PackageDependency
+		inGem   bool
+		inSpecs bool
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			inGem, inSpecs = false, false
+			continue
+		}
+
+		// Section headers are not indented
+		if !strings.HasPrefix(line, " ") {
+			inGem, inSpecs = line == "GEM", false
+			continue
+		}
+
+		if !inGem {
+			continue
+		}
+
+		if strings.TrimSpace(line) == "specs:" {
+			inSpecs = true
+			continue
+		}
+
+		if !inSpecs || !strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "     ") {
+			continue
+		}
+
+		name, version, ok := parseGemSpec(strings.TrimSpace(line))
+		if !ok {
+			return nil, errors.Newf("malformed gem spec %q", line)
+		}
+
+		libs = append(libs, reposource.NewRubyDependency(name, version))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Errorf("error reading Gemfile.lock: %w", err)
+	}
+
+	return libs, nil
+}
+
+// parseGemSpec parses a string of the form "name (version[-platform])".
+func parseGemSpec(spec string) (name, version string, ok bool) {
+	i := strings.Index(spec, " (")
+	if i == -1 || !strings.HasSuffix(spec, ")") {
+		return "", "", false
+	}
+
+	name = spec[:i]
+	version = spec[i+2 : len(spec)-1]
+	if j := strings.Index(version, "-"); j != -1 {
+		version = version[:j]
+	}
+
+	return name, version, true
+}
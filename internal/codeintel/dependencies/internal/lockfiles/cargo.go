@@ -0,0 +1,41 @@
+package lockfiles
+
+import (
+	"io"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+//
+// Cargo.lock
+//
+
+// parseCargoLockFile extracts all packages from a Cargo.lock file. Packages without a
+// source are members of the local workspace (or path dependencies) and are skipped.
+func parseCargoLockFile(r io.Reader) ([]reposource.PackageDependency, error) {
+	var lockfile struct {
+		Packages []struct {
+			Name    string `toml:"name"`
+			Version string `toml:"version"`
+			Source  string `toml:"source"`
+		} `toml:"package"`
+	}
+
+	if _, err := toml.DecodeReader(r, &lockfile); err != nil {
+		return nil, errors.Errorf("error decoding Cargo.lock: %w", err)
+	}
+
+	libs := make([]reposource.PackageDependency, 0, len(lockfile.Packages))
+	for _, pkg := range lockfile.Packages {
+		if pkg.Source == "" {
+			continue
+		}
+
+		libs = append(libs, reposource.NewRustDependency(pkg.Name, pkg.Version))
+	}
+
+	return libs, nil
+}
@@ -0,0 +1,41 @@
+package lockfiles
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+//
+// composer.lock
+//
+
+// parseComposerLockFile extracts all packages, both "packages" and "packages-dev",
+// from a composer.lock file.
+func parseComposerLockFile(r io.Reader) ([]reposource.PackageDependency, error) {
+	type packageInfo struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	var lockfile struct {
+		Packages    []packageInfo `json:"packages"`
+		PackagesDev []packageInfo `json:"packages-dev"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&lockfile); err != nil {
+		return nil, errors.Errorf("error decoding composer.lock: %w", err)
+	}
+
+	libs := make([]reposource.PackageDependency, 0, len(lockfile.Packages)+len(lockfile.PackagesDev))
+	for _, pkg := range lockfile.Packages {
+		libs = append(libs, reposource.NewPhpDependency(pkg.Name, pkg.Version))
+	}
+	for _, pkg := range lockfile.PackagesDev {
+		libs = append(libs, reposource.NewPhpDependency(pkg.Name, pkg.Version))
+	}
+
+	return libs, nil
+}
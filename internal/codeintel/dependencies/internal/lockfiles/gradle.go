@@ -0,0 +1,118 @@
+package lockfiles
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+//
+// gradle.lockfile
+//
+
+// parseGradleLockFile extracts all dependencies from a gradle.lockfile, as generated by
+// Gradle's dependency locking (https://docs.gradle.org/current/userguide/dependency_locking.html).
+//
+// Each dependency is listed on its own line in the form "group:artifact:version=configurations".
+// Comment lines start with "#" and the trailing "empty=" line lists configurations without
+// any dependencies.
+func parseGradleLockFile(r io.Reader) ([]reposource.PackageDependency, error) {
+	var libs []reposource.PackageDependency
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "empty=") {
+			continue
+		}
+
+		if i := strings.Index(line, "="); i != -1 {
+			line = line[:i]
+		}
+
+		dep, err := reposource.ParseMavenDependency(line)
+		if err != nil {
+			return nil, err
+		}
+		if dep.Version == "" {
+			return nil, errors.Newf("dependency %q is missing a version", line)
+		}
+
+		libs = append(libs, dep)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Errorf("error reading gradle.lockfile: %w", err)
+	}
+
+	return libs, nil
+}
+
+//
+// maven-dependency-tree.txt
+//
+
+// parseMavenDependencyTreeFile extracts all dependencies from the output of the
+// maven-dependency-plugin, written to a file by running:
+//
+//	mvn dependency:tree -DoutputFile=maven-dependency-tree.txt
+//
+// The first line is the project itself and is skipped. Every following line is a
+// dependency in the form "groupId:artifactId:type[:classifier]:version:scope", prefixed
+// by tree-drawing characters ("+- ", "\- ", "|  ") indicating its depth.
+func parseMavenDependencyTreeFile(r io.Reader) ([]reposource.PackageDependency, error) {
+	var (
+		libs  []reposource.PackageDependency
+		first = true
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimLeft(scanner.Text(), "|+\\- ")
+		if line == "" {
+			continue
+		}
+
+		if first {
+			first = false
+			continue
+		}
+
+		// Dependencies omitted by the verbose output are wrapped in parentheses
+		line = strings.TrimSuffix(strings.TrimPrefix(line, "("), ")")
+		if i := strings.Index(line, " "); i != -1 {
+			line = line[:i]
+		}
+
+		parts := strings.Split(line, ":")
+
+		var version string
+		switch len(parts) {
+		case 5:
+			// groupId:artifactId:type:version:scope
+			version = parts[3]
+		case 6:
+			// groupId:artifactId:type:classifier:version:scope
+			version = parts[4]
+		default:
+			return nil, errors.Newf("malformed dependency %q", line)
+		}
+
+		libs = append(libs, &reposource.MavenDependency{
+			MavenModule: &reposource.MavenModule{
+				GroupID:    parts[0],
+				ArtifactID: parts[1],
+			},
+			Version: version,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Errorf("error reading maven dependency tree: %w", err)
+	}
+
+	return libs, nil
+}
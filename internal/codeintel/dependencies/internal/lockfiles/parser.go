@@ -15,6 +15,12 @@ var parsers = map[string]parser{
 	"go.mod":            parseGoModFile,
 	"poetry.lock":       parsePoetryLockFile,
 	"Pipfile.lock":      parsePipfileLockFile,
+	"Cargo.lock":        parseCargoLockFile,
+	"Gemfile.lock":      parseGemfileLockFile,
+	"gradle.lockfile":   parseGradleLockFile,
+	"composer.lock":     parseComposerLockFile,
+
+	"maven-dependency-tree.txt": parseMavenDependencyTreeFile,
 }
 
 // lockfilePathspecs is the list of git pathspecs that match lockfiles.
@@ -110,6 +110,74 @@ SELECT
 FROM lsif_dumps_with_repository_name u WHERE u.id IN (%s)
 `
 
+// GetDumpsAtTime returns, for each of the given repositories, the dumps of the most recent commit that was committed
+// at or before the given time and has at least one completed upload. If no repositories are given, dumps of every
+// repository with a completed upload are returned. This is used to sample code intelligence data over time, so unlike
+// FindClosestDumps it does not rely on the commit graph.
+func (s *Store) GetDumpsAtTime(ctx context.Context, repositoryIDs []int, before time.Time) (_ []Dump, err error) {
+	ctx, trace, endObservation := s.operations.getDumpsAtTime.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("numRepositoryIDs", len(repositoryIDs)),
+		log.String("repositoryIDs", intsToString(repositoryIDs)),
+		log.String("before", before.String()),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	conds := []*sqlf.Query{
+		sqlf.Sprintf("u.state = 'completed'"),
+		sqlf.Sprintf("u.committed_at IS NOT NULL"),
+		sqlf.Sprintf("u.committed_at != '-infinity'"),
+		sqlf.Sprintf("u.committed_at <= %s", before),
+	}
+	if len(repositoryIDs) > 0 {
+		var ids []*sqlf.Query
+		for _, id := range repositoryIDs {
+			ids = append(ids, sqlf.Sprintf("%s", id))
+		}
+		conds = append(conds, sqlf.Sprintf("u.repository_id IN (%s)", sqlf.Join(ids, ", ")))
+	}
+
+	dumps, err := scanDumps(s.Store.Query(ctx, sqlf.Sprintf(getDumpsAtTimeQuery, sqlf.Join(conds, " AND "))))
+	if err != nil {
+		return nil, err
+	}
+	trace.Log(log.Int("numDumps", len(dumps)))
+
+	return dumps, nil
+}
+
+const getDumpsAtTimeQuery = `
+-- source: internal/codeintel/stores/dbstore/dumps.go:GetDumpsAtTime
+WITH
+sampled_commits AS (
+	SELECT DISTINCT ON (u.repository_id) u.repository_id, u.commit
+	FROM lsif_uploads u
+	WHERE %s
+	ORDER BY u.repository_id, u.committed_at DESC
+)
+SELECT
+	u.id,
+	u.commit,
+	u.root,
+	EXISTS (` + visibleAtTipSubselectQuery + `) AS visible_at_tip,
+	u.uploaded_at,
+	u.state,
+	u.failure_message,
+	u.started_at,
+	u.finished_at,
+	u.process_after,
+	u.num_resets,
+	u.num_failures,
+	u.repository_id,
+	u.repository_name,
+	u.indexer,
+	u.indexer_version,
+	u.associated_index_id
+FROM lsif_dumps_with_repository_name u
+JOIN sampled_commits sc ON sc.repository_id = u.repository_id AND sc.commit = u.commit
+WHERE u.state = 'completed'
+ORDER BY u.repository_id, u.id
+`
+
 // FindClosestDumps returns the set of dumps that can most accurately answer queries for the given repository, commit, path, and
 // optional indexer. If rootMustEnclosePath is true, then only dumps with a root which is a prefix of path are returned. Otherwise,
 // any dump with a root intersecting the given path is returned.
@@ -85,6 +85,68 @@ func TestGetDumpsByIDs(t *testing.T) {
 	}
 }
 
+func TestGetDumpsAtTime(t *testing.T) {
+	db := database.NewDB(dbtest.NewDB(t))
+	store := testStore(db)
+
+	t1 := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.AddDate(0, 1, 0)
+	t3 := t2.AddDate(0, 1, 0)
+
+	insertUploads(t, db,
+		Upload{ID: 1, RepositoryID: 50, Commit: makeCommit(1), Root: "a/"},
+		Upload{ID: 2, RepositoryID: 50, Commit: makeCommit(1), Root: "b/"},
+		Upload{ID: 3, RepositoryID: 50, Commit: makeCommit(2), Root: "a/"},
+		Upload{ID: 4, RepositoryID: 50, Commit: makeCommit(3), Root: "a/", State: "errored"},
+		Upload{ID: 5, RepositoryID: 51, Commit: makeCommit(4)},
+		Upload{ID: 6, RepositoryID: 52, Commit: makeCommit(5)},
+	)
+
+	for uploadID, commitDate := range map[int]time.Time{
+		1: t1,
+		2: t1,
+		3: t2,
+		4: t2.Add(time.Hour),
+		5: t3,
+	} {
+		if _, err := db.ExecContext(context.Background(), "UPDATE lsif_uploads SET committed_at = $1 WHERE id = $2", commitDate, uploadID); err != nil {
+			t.Fatalf("unexpected error updating commit date %s", err)
+		}
+	}
+
+	testCases := []struct {
+		repositoryIDs []int
+		before        time.Time
+		expectedIDs   []int
+	}{
+		{nil, t1.Add(-time.Hour), nil},
+		{nil, t1, []int{1, 2}},
+		{nil, t2.Add(2 * time.Hour), []int{3}},
+		{nil, t3, []int{3, 5}},
+		{[]int{51}, t3, []int{5}},
+		{[]int{50, 52}, t1.AddDate(0, 0, 1), []int{1, 2}},
+	}
+
+	for _, testCase := range testCases {
+		name := fmt.Sprintf("repositoryIDs=%v before=%s", testCase.repositoryIDs, testCase.before)
+
+		t.Run(name, func(t *testing.T) {
+			dumps, err := store.GetDumpsAtTime(context.Background(), testCase.repositoryIDs, testCase.before)
+			if err != nil {
+				t.Fatalf("unexpected error getting dumps: %s", err)
+			}
+
+			var ids []int
+			for _, dump := range dumps {
+				ids = append(ids, dump.ID)
+			}
+			if diff := cmp.Diff(testCase.expectedIDs, ids); diff != "" {
+				t.Errorf("unexpected dump ids (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestFindClosestDumps(t *testing.T) {
 	db := database.NewDB(dbtest.NewDB(t))
 	store := testStore(db)
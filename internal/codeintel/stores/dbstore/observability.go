@@ -30,6 +30,7 @@ type operations struct {
 	findClosestDumpsFromGraphFragment           *observation.Operation
 	getConfigurationPolicies                    *observation.Operation
 	getConfigurationPolicyByID                  *observation.Operation
+	getDumpsAtTime                              *observation.Operation
 	getDumpsByIDs                               *observation.Operation
 	getIndexByID                                *observation.Operation
 	getIndexConfigurationByRepositoryID         *observation.Operation
@@ -136,6 +137,7 @@ func newOperations(observationContext *observation.Context, metrics *metrics.RED
 		findClosestDumpsFromGraphFragment:    op("FindClosestDumpsFromGraphFragment"),
 		getConfigurationPolicies:             op("GetConfigurationPolicies"),
 		getConfigurationPolicyByID:           op("GetConfigurationPolicyByID"),
+		getDumpsAtTime:                       op("GetDumpsAtTime"),
 		getDumpsByIDs:                        op("GetDumpsByIDs"),
 		getIndexByID:                         op("GetIndexByID"),
 		getIndexConfigurationByRepositoryID:  op("GetIndexConfigurationByRepositoryID"),
@@ -56,6 +56,10 @@ var indexWorkerStoreOptions = dbworkerstore.Options{
 	OrderByExpression: sqlf.Sprintf("u.queued_at, u.id"),
 	StalledMaxAge:     StalledIndexMaxAge,
 	MaxNumResets:      IndexMaxNumResets,
+
+	// Schedule indexes of different repositories round-robin so that a repository
+	// with a large number of queued indexes cannot clog the queue.
+	FairnessKeyExpression: sqlf.Sprintf("u.repository_id"),
 }
 
 func WorkerutilIndexStore(s basestore.ShareableStore, observationContext *observation.Context) dbworkerstore.Store {
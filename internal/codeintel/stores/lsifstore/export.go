@@ -0,0 +1,272 @@
+package lsifstore
+
+import (
+	"context"
+	"io"
+	"sort"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/writer"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// Export re-serializes the processed data of the given bundle as an LSIF dump and writes it
+// to the given writer. Document paths are emitted relative to the given root, which should
+// match the root of the upload so that the output can be re-uploaded and processed into an
+// equivalent bundle.
+//
+// Ranges, hover results, monikers, package information, and definition, reference, and
+// implementation results are emitted. Diagnostics and documentation data are not exported.
+// Result sets were collapsed into ranges during conversion and are not reconstructed.
+func (s *Store) Export(ctx context.Context, bundleID int, root string, toolInfo protocol.ToolInfo, w io.Writer) (err error) {
+	ctx, trace, endObservation := s.operations.export.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("bundleID", bundleID),
+		log.String("root", root),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	emitter := writer.NewEmitter(writer.NewJSONWriter(w))
+	defer func() {
+		if flushErr := emitter.Flush(); flushErr != nil {
+			err = errors.Append(err, flushErr)
+		}
+	}()
+
+	e := newBundleExporter(emitter, root, toolInfo)
+
+	visitDocuments := s.makeDocumentVisitor(e.emitDocument)
+	if err := visitDocuments(s.Store.Query(ctx, sqlf.Sprintf(exportDocumentsQuery, bundleID))); err != nil {
+		return err
+	}
+	trace.Log(log.Int("numDocuments", len(e.documentIDs)))
+
+	visitResultChunks := s.makeResultChunkVisitor(s.Store.Query(ctx, sqlf.Sprintf(exportResultChunksQuery, bundleID)))
+	if err := visitResultChunks(func(_ int, resultChunk precise.ResultChunkData) { e.emitResultChunk(resultChunk) }); err != nil {
+		return err
+	}
+	trace.Log(log.Int("numElements", int(e.emitter.NumElements())))
+
+	return nil
+}
+
+const exportDocumentsQuery = `
+-- source: internal/codeintel/stores/lsifstore/export.go:Export
+SELECT
+	dump_id,
+	path,
+	data,
+	ranges,
+	hovers,
+	monikers,
+	packages,
+	NULL AS diagnostics
+FROM
+	lsif_data_documents
+WHERE
+	dump_id = %s
+ORDER BY path
+`
+
+const exportResultChunksQuery = `
+-- source: internal/codeintel/stores/lsifstore/export.go:Export
+SELECT idx, data FROM lsif_data_result_chunks WHERE dump_id = %s ORDER BY idx
+`
+
+type resultKind int
+
+const (
+	definitionResultKind resultKind = iota
+	referenceResultKind
+	implementationResultKind
+)
+
+type exportedResult struct {
+	id   uint64
+	kind resultKind
+}
+
+// bundleExporter tracks the identifiers of the vertices emitted for a bundle so that edges
+// referencing data from other documents or result chunks can be emitted later in the stream.
+type bundleExporter struct {
+	emitter     *writer.Emitter
+	root        string
+	documentIDs map[string]uint64
+	rangeIDs    map[string]map[precise.ID]uint64
+	results     map[precise.ID]exportedResult
+}
+
+// newBundleExporter creates a new bundle exporter and emits the metadata vertex of the dump.
+func newBundleExporter(emitter *writer.Emitter, root string, toolInfo protocol.ToolInfo) *bundleExporter {
+	emitter.EmitMetaData("file:///"+root, toolInfo)
+
+	return &bundleExporter{
+		emitter:     emitter,
+		root:        root,
+		documentIDs: map[string]uint64{},
+		rangeIDs:    map[string]map[precise.ID]uint64{},
+		results:     map[precise.ID]exportedResult{},
+	}
+}
+
+// emitDocument emits a document vertex along with its ranges and the hover results, monikers,
+// and package information attached to them. Edges to definition, reference, and implementation
+// results are emitted here; the items of those results are emitted by emitResultChunk.
+func (e *bundleExporter) emitDocument(path string, document precise.DocumentData) {
+	documentID := e.emitter.EmitDocument("", "/"+e.root+path)
+	e.documentIDs[path] = documentID
+
+	hoverResultIDs := map[precise.ID]uint64{}
+	monikerIDs := map[precise.ID]uint64{}
+	packageInformationIDs := map[precise.ID]uint64{}
+
+	rangeIDs := make(map[precise.ID]uint64, len(document.Ranges))
+	containedIDs := make([]uint64, 0, len(document.Ranges))
+	for _, id := range sortedRangeIDs(document.Ranges) {
+		r := document.Ranges[id]
+		rangeID := e.emitter.EmitRange(
+			protocol.Pos{Line: r.StartLine, Character: r.StartCharacter},
+			protocol.Pos{Line: r.EndLine, Character: r.EndCharacter},
+		)
+		rangeIDs[id] = rangeID
+		containedIDs = append(containedIDs, rangeID)
+
+		if hoverText, ok := document.HoverResults[r.HoverResultID]; ok {
+			hoverResultID, ok := hoverResultIDs[r.HoverResultID]
+			if !ok {
+				hoverResultID = e.emitter.EmitHoverResult(protocol.NewMarkupContent(hoverText, protocol.Markdown))
+				hoverResultIDs[r.HoverResultID] = hoverResultID
+			}
+			e.emitter.EmitTextDocumentHover(rangeID, hoverResultID)
+		}
+
+		for _, id := range r.MonikerIDs {
+			moniker, ok := document.Monikers[id]
+			if !ok {
+				continue
+			}
+
+			monikerID, ok := monikerIDs[id]
+			if !ok {
+				monikerID = e.emitter.EmitMoniker(moniker.Kind, moniker.Scheme, moniker.Identifier)
+				monikerIDs[id] = monikerID
+
+				if packageInformation, ok := document.PackageInformation[moniker.PackageInformationID]; ok {
+					packageInformationID, ok := packageInformationIDs[moniker.PackageInformationID]
+					if !ok {
+						packageInformationID = e.emitter.EmitPackageInformation(packageInformation.Name, moniker.Scheme, packageInformation.Version)
+						packageInformationIDs[moniker.PackageInformationID] = packageInformationID
+					}
+					e.emitter.EmitPackageInformationEdge(monikerID, packageInformationID)
+				}
+			}
+			e.emitter.EmitMonikerEdge(rangeID, monikerID)
+		}
+
+		if r.DefinitionResultID != "" {
+			e.emitter.EmitTextDocumentDefinition(rangeID, e.result(r.DefinitionResultID, definitionResultKind))
+		}
+		if r.ReferenceResultID != "" {
+			e.emitter.EmitTextDocumentReferences(rangeID, e.result(r.ReferenceResultID, referenceResultKind))
+		}
+		if r.ImplementationResultID != "" {
+			e.emitter.EmitTextDocumentImplementation(rangeID, e.result(r.ImplementationResultID, implementationResultKind))
+		}
+	}
+
+	e.rangeIDs[path] = rangeIDs
+	if len(containedIDs) > 0 {
+		e.emitter.EmitContains(documentID, containedIDs)
+	}
+}
+
+// result returns the identifier of the result vertex for the given result identifier,
+// emitting a new vertex if one has not yet been emitted.
+func (e *bundleExporter) result(id precise.ID, kind resultKind) uint64 {
+	if result, ok := e.results[id]; ok {
+		return result.id
+	}
+
+	var resultID uint64
+	switch kind {
+	case definitionResultKind:
+		resultID = e.emitter.EmitDefinitionResult()
+	case referenceResultKind:
+		resultID = e.emitter.EmitReferenceResult()
+	case implementationResultKind:
+		resultID = e.emitter.EmitImplementationResult()
+	}
+
+	e.results[id] = exportedResult{id: resultID, kind: kind}
+	return resultID
+}
+
+// emitResultChunk emits the item edges connecting the result vertices referenced by the
+// previously emitted documents to the ranges composing each result.
+func (e *bundleExporter) emitResultChunk(resultChunk precise.ResultChunkData) {
+	ids := make([]precise.ID, 0, len(resultChunk.DocumentIDRangeIDs))
+	for id := range resultChunk.DocumentIDRangeIDs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		result, ok := e.results[id]
+		if !ok {
+			continue
+		}
+
+		var paths []string
+		rangeIDsByPath := map[string][]uint64{}
+		for _, documentIDRangeID := range resultChunk.DocumentIDRangeIDs[id] {
+			path := resultChunk.DocumentPaths[documentIDRangeID.DocumentID]
+			rangeID, ok := e.rangeIDs[path][documentIDRangeID.RangeID]
+			if !ok {
+				continue
+			}
+
+			if _, ok := rangeIDsByPath[path]; !ok {
+				paths = append(paths, path)
+			}
+			rangeIDsByPath[path] = append(rangeIDsByPath[path], rangeID)
+		}
+
+		for _, path := range paths {
+			documentID := e.documentIDs[path]
+
+			switch result.kind {
+			case definitionResultKind:
+				e.emitter.EmitItemOfDefinitions(result.id, rangeIDsByPath[path], documentID)
+			case referenceResultKind:
+				e.emitter.EmitItemOfReferences(result.id, rangeIDsByPath[path], documentID)
+			case implementationResultKind:
+				e.emitter.EmitItem(result.id, rangeIDsByPath[path], documentID)
+			}
+		}
+	}
+}
+
+// sortedRangeIDs returns the keys of the given range map ordered by the starting position of
+// the range they identify.
+func sortedRangeIDs(rangeMap map[precise.ID]precise.RangeData) []precise.ID {
+	ids := make([]precise.ID, 0, len(rangeMap))
+	for id := range rangeMap {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		ri, rj := rangeMap[ids[i]], rangeMap[ids[j]]
+		if ri.StartLine != rj.StartLine {
+			return ri.StartLine < rj.StartLine
+		}
+		if ri.StartCharacter != rj.StartCharacter {
+			return ri.StartCharacter < rj.StartCharacter
+		}
+		return ids[i] < ids[j]
+	})
+
+	return ids
+}
@@ -0,0 +1,154 @@
+package lsifstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/conversion"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/writer"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+)
+
+func TestBundleExporterRoundTrip(t *testing.T) {
+	documents := map[string]precise.DocumentData{
+		"foo.go": {
+			Ranges: map[precise.ID]precise.RangeData{
+				"r1": {StartLine: 1, StartCharacter: 5, EndLine: 1, EndCharacter: 8, DefinitionResultID: "d1", ReferenceResultID: "x1", HoverResultID: "h1", MonikerIDs: []precise.ID{"m1"}},
+			},
+			HoverResults:       map[precise.ID]string{"h1": "```go\nfunc Foo()\n```"},
+			Monikers:           map[precise.ID]precise.MonikerData{"m1": {Kind: "export", Scheme: "gomod", Identifier: "pkg:Foo", PackageInformationID: "p1"}},
+			PackageInformation: map[precise.ID]precise.PackageInformationData{"p1": {Name: "pkg", Version: "v1.0.0"}},
+		},
+		"bar.go": {
+			Ranges: map[precise.ID]precise.RangeData{
+				"r2": {StartLine: 3, StartCharacter: 2, EndLine: 3, EndCharacter: 5, DefinitionResultID: "d1", ReferenceResultID: "x1", HoverResultID: "h2"},
+			},
+			HoverResults: map[precise.ID]string{"h2": "```go\nfunc Foo()\n```"},
+		},
+	}
+	resultChunk := precise.ResultChunkData{
+		DocumentPaths: map[precise.ID]string{"doc1": "foo.go", "doc2": "bar.go"},
+		DocumentIDRangeIDs: map[precise.ID][]precise.DocumentIDRangeID{
+			"d1": {{DocumentID: "doc1", RangeID: "r1"}},
+			"x1": {{DocumentID: "doc1", RangeID: "r1"}, {DocumentID: "doc2", RangeID: "r2"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	emitter := writer.NewEmitter(writer.NewJSONWriter(&buf))
+	e := newBundleExporter(emitter, "sub/", protocol.ToolInfo{Name: "lsif-test"})
+	for _, path := range []string{"bar.go", "foo.go"} {
+		e.emitDocument(path, documents[path])
+	}
+	e.emitResultChunk(resultChunk)
+	if err := emitter.Flush(); err != nil {
+		t.Fatalf("unexpected error flushing emitter: %s", err)
+	}
+
+	chans, err := conversion.Correlate(context.Background(), &buf, "sub/", nil)
+	if err != nil {
+		t.Fatalf("unexpected error correlating exported dump: %s", err)
+	}
+	bundle := precise.GroupedBundleDataChansToMaps(chans)
+
+	var paths []string
+	for path := range bundle.Documents {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	if diff := cmp.Diff([]string{"bar.go", "foo.go"}, paths); diff != "" {
+		t.Fatalf("unexpected paths (-want +got):\n%s", diff)
+	}
+
+	foo := bundle.Documents["foo.go"]
+	if len(foo.Ranges) != 1 {
+		t.Fatalf("unexpected number of ranges. want=%d have=%d", 1, len(foo.Ranges))
+	}
+	for _, r := range foo.Ranges {
+		if hoverText := foo.HoverResults[r.HoverResultID]; hoverText != "```go\nfunc Foo()\n```" {
+			t.Errorf("unexpected hover text %q", hoverText)
+		}
+
+		var monikers []precise.QualifiedMonikerData
+		for _, id := range r.MonikerIDs {
+			moniker := foo.Monikers[id]
+			monikers = append(monikers, precise.QualifiedMonikerData{
+				MonikerData:            precise.MonikerData{Kind: moniker.Kind, Scheme: moniker.Scheme, Identifier: moniker.Identifier},
+				PackageInformationData: foo.PackageInformation[moniker.PackageInformationID],
+			})
+		}
+		expectedMonikers := []precise.QualifiedMonikerData{{
+			MonikerData:            precise.MonikerData{Kind: "export", Scheme: "gomod", Identifier: "pkg:Foo"},
+			PackageInformationData: precise.PackageInformationData{Name: "pkg", Version: "v1.0.0"},
+		}}
+		if diff := cmp.Diff(expectedMonikers, monikers); diff != "" {
+			t.Errorf("unexpected monikers (-want +got):\n%s", diff)
+		}
+
+		if diff := cmp.Diff([]string{"foo.go:1:5"}, resolveResult(bundle, r.DefinitionResultID)); diff != "" {
+			t.Errorf("unexpected definitions (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]string{"bar.go:3:2", "foo.go:1:5"}, resolveResult(bundle, r.ReferenceResultID)); diff != "" {
+			t.Errorf("unexpected references (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestDatabaseExport(t *testing.T) {
+	store := populateTestStore(t)
+
+	var buf bytes.Buffer
+	if err := store.Export(context.Background(), testBundleID, "", protocol.ToolInfo{Name: "lsif-go"}, &buf); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	chans, err := conversion.Correlate(context.Background(), &buf, "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error correlating exported dump: %s", err)
+	}
+	bundle := precise.GroupedBundleDataChansToMaps(chans)
+
+	// `func NewMetaData(id, root string, info ToolInfo) *MetaData {`
+	//       ^^^^^^^^^^^
+
+	document, ok := bundle.Documents["protocol/protocol.go"]
+	if !ok {
+		t.Fatalf("expected protocol/protocol.go to be exported")
+	}
+
+	var found bool
+	for _, r := range document.Ranges {
+		if r.StartLine == 92 && r.StartCharacter == 5 {
+			found = true
+
+			if diff := cmp.Diff([]string{"protocol/protocol.go:92:5"}, resolveResult(bundle, r.DefinitionResultID)); diff != "" {
+				t.Errorf("unexpected definitions (-want +got):\n%s", diff)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected NewMetaData range to be exported")
+	}
+}
+
+// resolveResult returns the sorted path:line:character locations of the ranges composing the
+// given definition or reference result of a correlated bundle.
+func resolveResult(bundle *precise.GroupedBundleDataMaps, id precise.ID) []string {
+	var locations []string
+	for _, resultChunk := range bundle.ResultChunks {
+		for _, documentIDRangeID := range resultChunk.DocumentIDRangeIDs[id] {
+			path := resultChunk.DocumentPaths[documentIDRangeID.DocumentID]
+			r := bundle.Documents[path].Ranges[documentIDRangeID.RangeID]
+			locations = append(locations, fmt.Sprintf("%s:%d:%d", path, r.StartLine, r.StartCharacter))
+		}
+	}
+	sort.Strings(locations)
+
+	return locations
+}
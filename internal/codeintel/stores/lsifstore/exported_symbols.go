@@ -0,0 +1,106 @@
+package lsifstore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/precise"
+)
+
+// ExportedSymbols returns the set of symbols with an attached export moniker within the given
+// bundle. If a moniker is attached to multiple ranges, the first range (ordered by path then
+// position) with hover text is chosen to represent the symbol. The resulting slice is ordered
+// by moniker scheme and identifier.
+func (s *Store) ExportedSymbols(ctx context.Context, bundleID int) (_ []ExportedSymbol, err error) {
+	ctx, trace, endObservation := s.operations.exportedSymbols.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("bundleID", bundleID),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	symbolsByMoniker := map[precise.MonikerData]ExportedSymbol{}
+
+	visitDocuments := s.makeDocumentVisitor(func(path string, document precise.DocumentData) {
+		for _, r := range sortedRanges(document.Ranges) {
+			hoverText := document.HoverResults[r.HoverResultID]
+
+			for _, monikerID := range r.MonikerIDs {
+				moniker, ok := document.Monikers[monikerID]
+				if !ok || moniker.Kind != "export" {
+					continue
+				}
+
+				key := precise.MonikerData{Scheme: moniker.Scheme, Identifier: moniker.Identifier}
+				if symbol, ok := symbolsByMoniker[key]; ok && (symbol.HoverText != "" || hoverText == "") {
+					continue
+				}
+
+				symbolsByMoniker[key] = ExportedSymbol{
+					Scheme:     moniker.Scheme,
+					Identifier: moniker.Identifier,
+					Location: Location{
+						DumpID: bundleID,
+						Path:   path,
+						Range:  newRange(r.StartLine, r.StartCharacter, r.EndLine, r.EndCharacter),
+					},
+					HoverText: hoverText,
+					Package:   document.PackageInformation[moniker.PackageInformationID],
+				}
+			}
+		}
+	})
+	if err := visitDocuments(s.Store.Query(ctx, sqlf.Sprintf(exportedSymbolsQuery, bundleID))); err != nil {
+		return nil, err
+	}
+	trace.Log(log.Int("numSymbols", len(symbolsByMoniker)))
+
+	symbols := make([]ExportedSymbol, 0, len(symbolsByMoniker))
+	for _, symbol := range symbolsByMoniker {
+		symbols = append(symbols, symbol)
+	}
+	sort.Slice(symbols, func(i, j int) bool {
+		if symbols[i].Scheme != symbols[j].Scheme {
+			return symbols[i].Scheme < symbols[j].Scheme
+		}
+		return symbols[i].Identifier < symbols[j].Identifier
+	})
+
+	return symbols, nil
+}
+
+const exportedSymbolsQuery = `
+-- source: internal/codeintel/stores/lsifstore/exported_symbols.go:ExportedSymbols
+SELECT
+	dump_id,
+	path,
+	data,
+	ranges,
+	hovers,
+	monikers,
+	packages,
+	NULL AS diagnostics
+FROM
+	lsif_data_documents
+WHERE
+	dump_id = %s
+ORDER BY path
+`
+
+// sortedRanges returns the values of the given range map ordered by their starting position.
+func sortedRanges(rangeMap map[precise.ID]precise.RangeData) []precise.RangeData {
+	ranges := make([]precise.RangeData, 0, len(rangeMap))
+	for _, r := range rangeMap {
+		ranges = append(ranges, r)
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		if ranges[i].StartLine != ranges[j].StartLine {
+			return ranges[i].StartLine < ranges[j].StartLine
+		}
+		return ranges[i].StartCharacter < ranges[j].StartCharacter
+	})
+
+	return ranges
+}
@@ -0,0 +1,45 @@
+package lsifstore
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDatabaseExportedSymbols(t *testing.T) {
+	store := populateTestStore(t)
+
+	symbols, err := store.ExportedSymbols(context.Background(), testBundleID)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if !sort.SliceIsSorted(symbols, func(i, j int) bool { return symbols[i].Identifier < symbols[j].Identifier }) {
+		t.Errorf("expected symbols to be sorted by identifier")
+	}
+
+	// `func NewMetaData(id, root string, info ToolInfo) *MetaData {`
+	//       ^^^^^^^^^^^
+
+	var found bool
+	for _, symbol := range symbols {
+		if symbol.Identifier != "github.com/sourcegraph/lsif-go/protocol:NewMetaData" {
+			continue
+		}
+		found = true
+
+		expectedLocation := Location{DumpID: testBundleID, Path: "protocol/protocol.go", Range: newRange(92, 5, 92, 16)}
+		if diff := cmp.Diff(expectedLocation, symbol.Location); diff != "" {
+			t.Errorf("unexpected location (-want +got):\n%s", diff)
+		}
+		if !strings.Contains(symbol.HoverText, "func NewMetaData(") {
+			t.Errorf("unexpected hover text %q", symbol.HoverText)
+		}
+	}
+	if !found {
+		t.Errorf("expected NewMetaData to be exported")
+	}
+}
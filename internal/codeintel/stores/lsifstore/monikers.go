@@ -153,6 +153,50 @@ const bulkMonikerResultsQuery = `
 SELECT dump_id, scheme, identifier, data FROM %s WHERE dump_id IN (%s) AND (scheme, identifier) IN (%s) ORDER BY (dump_id, scheme, identifier)
 `
 
+// ReferenceCounts returns the number of reference locations (within each of the given uploads) with an
+// attached moniker whose scheme+identifier matches one of the given monikers, keyed by upload identifier.
+// Uploads without a matching reference are omitted.
+func (s *Store) ReferenceCounts(ctx context.Context, uploadIDs []int, monikers []precise.MonikerData) (_ map[int]int, err error) {
+	ctx, trace, endObservation := s.operations.referenceCounts.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("numUploadIDs", len(uploadIDs)),
+		log.String("uploadIDs", intsToString(uploadIDs)),
+		log.Int("numMonikers", len(monikers)),
+		log.String("monikers", monikersToString(monikers)),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	if len(uploadIDs) == 0 || len(monikers) == 0 {
+		return nil, nil
+	}
+
+	idQueries := make([]*sqlf.Query, 0, len(uploadIDs))
+	for _, id := range uploadIDs {
+		idQueries = append(idQueries, sqlf.Sprintf("%s", id))
+	}
+
+	monikerQueries := make([]*sqlf.Query, 0, len(monikers))
+	for _, arg := range monikers {
+		monikerQueries = append(monikerQueries, sqlf.Sprintf("(%s, %s)", arg.Scheme, arg.Identifier))
+	}
+
+	counts, err := scanIntPairs(s.Store.Query(ctx, sqlf.Sprintf(
+		referenceCountsQuery,
+		sqlf.Join(idQueries, ", "),
+		sqlf.Join(monikerQueries, ", "),
+	)))
+	if err != nil {
+		return nil, err
+	}
+	trace.Log(log.Int("numDumps", len(counts)))
+
+	return counts, nil
+}
+
+const referenceCountsQuery = `
+-- source: internal/codeintel/stores/lsifstore/monikers.go:ReferenceCounts
+SELECT dump_id, SUM(num_locations) FROM lsif_data_references WHERE dump_id IN (%s) AND (scheme, identifier) IN (%s) GROUP BY dump_id
+`
+
 func monikersToString(vs []precise.MonikerData) string {
 	strs := make([]string, 0, len(vs))
 	for _, v := range vs {
@@ -110,3 +110,39 @@ func TestDatabaseBulkMonikerResults(t *testing.T) {
 		})
 	}
 }
+
+func TestDatabaseReferenceCounts(t *testing.T) {
+	store := populateTestStore(t)
+
+	edgeMoniker := precise.MonikerData{Scheme: "gomod", Identifier: "github.com/sourcegraph/lsif-go/protocol:Edge"}
+	markdownMoniker := precise.MonikerData{Scheme: "gomod", Identifier: "github.com/slimsag/godocmd:ToMarkdown"}
+	unknownMoniker := precise.MonikerData{Scheme: "gomod", Identifier: "github.com/sourcegraph/lsif-go/protocol:Unknown"}
+
+	testCases := []struct {
+		uploadIDs      []int
+		monikers       []precise.MonikerData
+		expectedCounts map[int]int
+	}{
+		// empty cases
+		{[]int{}, []precise.MonikerData{edgeMoniker}, nil},
+		{[]int{testBundleID}, []precise.MonikerData{}, nil},
+		{[]int{testBundleID}, []precise.MonikerData{unknownMoniker}, map[int]int{}},
+		{[]int{testBundleID + 1}, []precise.MonikerData{edgeMoniker}, map[int]int{}},
+
+		{[]int{testBundleID}, []precise.MonikerData{edgeMoniker}, map[int]int{testBundleID: 19}},
+		{[]int{testBundleID}, []precise.MonikerData{markdownMoniker}, map[int]int{testBundleID: 1}},
+		{[]int{testBundleID}, []precise.MonikerData{edgeMoniker, markdownMoniker}, map[int]int{testBundleID: 20}},
+	}
+
+	for i, testCase := range testCases {
+		t.Run(fmt.Sprintf("i=%d", i), func(t *testing.T) {
+			counts, err := store.ReferenceCounts(context.Background(), testCase.uploadIDs, testCase.monikers)
+			if err != nil {
+				t.Fatalf("unexpected error for test case #%d: %s", i, err)
+			}
+			if diff := cmp.Diff(testCase.expectedCounts, counts); diff != "" {
+				t.Errorf("unexpected reference counts for test case #%d (-want +got):\n%s", i, diff)
+			}
+		})
+	}
+}
@@ -14,12 +14,15 @@ type operations struct {
 	deleteOldSearchRecords *observation.Operation
 	diagnostics            *observation.Operation
 	exists                 *observation.Operation
+	export                 *observation.Operation
+	exportedSymbols        *observation.Operation
 	hover                  *observation.Operation
 	implementations        *observation.Operation
 	monikerResults         *observation.Operation
 	monikersByPosition     *observation.Operation
 	packageInformation     *observation.Operation
 	ranges                 *observation.Operation
+	referenceCounts        *observation.Operation
 	references             *observation.Operation
 	stencil                *observation.Operation
 	writeDefinitions       *observation.Operation
@@ -65,12 +68,15 @@ func newOperations(observationContext *observation.Context) *operations {
 		deleteOldSearchRecords: op("DeleteOldSearchRecords"),
 		diagnostics:            op("Diagnostics"),
 		exists:                 op("Exists"),
+		export:                 op("Export"),
+		exportedSymbols:        op("ExportedSymbols"),
 		hover:                  op("Hover"),
 		implementations:        op("Implementations"),
 		monikerResults:         op("MonikerResults"),
 		monikersByPosition:     op("MonikersByPosition"),
 		packageInformation:     op("PackageInformation"),
 		ranges:                 op("Ranges"),
+		referenceCounts:        op("ReferenceCounts"),
 		references:             op("References"),
 		stencil:                op("Stencil"),
 		writeDefinitions:       op("WriteDefinitions"),
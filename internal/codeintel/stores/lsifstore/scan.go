@@ -181,3 +181,24 @@ func (s *Store) scanSingleQualifiedMonikerLocationsObject(rows *sql.Rows) (Quali
 
 	return record, nil
 }
+
+// scanIntPairs scans a map from the first to the second integer column of each row.
+func scanIntPairs(rows *sql.Rows, queryErr error) (_ map[int]int, err error) {
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer func() { err = basestore.CloseRows(rows, err) }()
+
+	values := map[int]int{}
+	for rows.Next() {
+		var value1 int
+		var value2 int
+		if err := rows.Scan(&value1, &value2); err != nil {
+			return nil, err
+		}
+
+		values[value1] = value2
+	}
+
+	return values, nil
+}
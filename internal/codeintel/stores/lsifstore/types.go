@@ -37,3 +37,13 @@ type CodeIntelligenceRange struct {
 	Implementations []Location
 	HoverText       string
 }
+
+// ExportedSymbol pairs an export moniker with the location of the range it is attached
+// to, the hover text of that range, and the package the moniker belongs to (if any).
+type ExportedSymbol struct {
+	Scheme     string
+	Identifier string
+	Location   Location
+	HoverText  string
+	Package    precise.PackageInformationData
+}
@@ -25,16 +25,22 @@ type Config struct {
 	GCSProjectID               string
 	GCSCredentialsFile         string
 	GCSCredentialsFileContents string
+
+	AzureAccountName string
+	AzureAccountKey  string
+	AzureEndpoint    string
+
+	FilesystemDir string
 }
 
 func (c *Config) Load() {
-	c.Backend = strings.ToLower(c.Get("PRECISE_CODE_INTEL_UPLOAD_BACKEND", "MinIO", "The target file service for code intelligence uploads. S3, GCS, and MinIO are supported."))
+	c.Backend = strings.ToLower(c.Get("PRECISE_CODE_INTEL_UPLOAD_BACKEND", "MinIO", "The target file service for code intelligence uploads. S3, GCS, MinIO, Azure, and Filesystem are supported."))
 	c.ManageBucket = c.GetBool("PRECISE_CODE_INTEL_UPLOAD_MANAGE_BUCKET", "false", "Whether or not the client should manage the target bucket configuration.")
 	c.Bucket = c.Get("PRECISE_CODE_INTEL_UPLOAD_BUCKET", "lsif-uploads", "The name of the bucket to store LSIF uploads in.")
 	c.TTL = c.GetInterval("PRECISE_CODE_INTEL_UPLOAD_TTL", "168h", "The maximum age of an upload before deletion.")
 
-	if c.Backend != "minio" && c.Backend != "s3" && c.Backend != "gcs" {
-		c.AddError(errors.Errorf("invalid backend %q for PRECISE_CODE_INTEL_UPLOAD_BACKEND: must be S3, GCS, or MinIO", c.Backend))
+	if c.Backend != "minio" && c.Backend != "s3" && c.Backend != "gcs" && c.Backend != "azure" && c.Backend != "filesystem" {
+		c.AddError(errors.Errorf("invalid backend %q for PRECISE_CODE_INTEL_UPLOAD_BACKEND: must be S3, GCS, MinIO, Azure, or Filesystem", c.Backend))
 	}
 
 	if c.Backend == "minio" || c.Backend == "s3" {
@@ -51,5 +57,17 @@ func (c *Config) Load() {
 		c.GCSProjectID = c.Get("PRECISE_CODE_INTEL_UPLOAD_GCP_PROJECT_ID", "", "The project containing the GCS bucket.")
 		c.GCSCredentialsFile = c.GetOptional("PRECISE_CODE_INTEL_UPLOAD_GOOGLE_APPLICATION_CREDENTIALS_FILE", "The path to a service account key file with access to GCS.")
 		c.GCSCredentialsFileContents = c.GetOptional("PRECISE_CODE_INTEL_UPLOAD_GOOGLE_APPLICATION_CREDENTIALS_FILE_CONTENT", "The contents of a service account key file with access to GCS.")
+	} else if c.Backend == "azure" {
+		c.AzureAccountName = c.Get("PRECISE_CODE_INTEL_UPLOAD_AZURE_ACCOUNT_NAME", "", "The name of the Azure storage account containing the upload container.")
+		c.AzureAccountKey = c.Get("PRECISE_CODE_INTEL_UPLOAD_AZURE_ACCOUNT_KEY", "", "A shared key of the Azure storage account.")
+		c.AzureEndpoint = c.GetOptional("PRECISE_CODE_INTEL_UPLOAD_AZURE_ENDPOINT", "The Azure Blob Storage endpoint, if not the default endpoint of the storage account.")
+	} else if c.Backend == "filesystem" {
+		c.FilesystemDir = c.Get("PRECISE_CODE_INTEL_UPLOAD_FILESYSTEM_DIR", "/data/lsif-uploads", "The directory to store uploads in. It must be shared by all services accessing uploads.")
 	}
 }
+
+// RequiresExpirer returns true if the backend does not support lifecycle rules, in which case
+// uploads older than the TTL must be removed by a routine returned by NewExpirer.
+func (c *Config) RequiresExpirer() bool {
+	return c.Backend == "azure" || c.Backend == "filesystem"
+}
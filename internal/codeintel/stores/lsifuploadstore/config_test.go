@@ -106,6 +106,58 @@ func TestConfigGCS(t *testing.T) {
 	}
 }
 
+func TestConfigAzure(t *testing.T) {
+	env := map[string]string{
+		"PRECISE_CODE_INTEL_UPLOAD_BACKEND":            "Azure",
+		"PRECISE_CODE_INTEL_UPLOAD_BUCKET":             "lsif-uploads",
+		"PRECISE_CODE_INTEL_UPLOAD_AZURE_ACCOUNT_NAME": "test-account-name",
+		"PRECISE_CODE_INTEL_UPLOAD_AZURE_ACCOUNT_KEY":  "test-account-key",
+		"PRECISE_CODE_INTEL_UPLOAD_AZURE_ENDPOINT":     "http://azurite:10000/test-account-name",
+	}
+
+	config := Config{}
+	config.SetMockGetter(mapGetter(env))
+	config.Load()
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+
+	if config.AzureAccountName != "test-account-name" {
+		t.Errorf("unexpected value for Azure.AccountName. want=%s have=%s", "test-account-name", config.AzureAccountName)
+	}
+	if config.AzureAccountKey != "test-account-key" {
+		t.Errorf("unexpected value for Azure.AccountKey. want=%s have=%s", "test-account-key", config.AzureAccountKey)
+	}
+	if config.AzureEndpoint != "http://azurite:10000/test-account-name" {
+		t.Errorf("unexpected value for Azure.Endpoint. want=%s have=%s", "http://azurite:10000/test-account-name", config.AzureEndpoint)
+	}
+	if !config.RequiresExpirer() {
+		t.Errorf("expected Azure backend to require an expirer")
+	}
+}
+
+func TestConfigFilesystem(t *testing.T) {
+	env := map[string]string{
+		"PRECISE_CODE_INTEL_UPLOAD_BACKEND": "Filesystem",
+	}
+
+	config := Config{}
+	config.SetMockGetter(mapGetter(env))
+	config.Load()
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+
+	if config.FilesystemDir != "/data/lsif-uploads" {
+		t.Errorf("unexpected value for Filesystem.Dir. want=%s have=%s", "/data/lsif-uploads", config.FilesystemDir)
+	}
+	if !config.RequiresExpirer() {
+		t.Errorf("expected Filesystem backend to require an expirer")
+	}
+}
+
 func mapGetter(env map[string]string) func(name, defaultValue, description string) string {
 	return func(name, defaultValue, description string) string {
 		if v, ok := env[name]; ok {
@@ -2,7 +2,9 @@ package lsifuploadstore
 
 import (
 	"context"
+	"time"
 
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
 	"github.com/sourcegraph/sourcegraph/internal/observation"
 	"github.com/sourcegraph/sourcegraph/internal/uploadstore"
 )
@@ -25,7 +27,24 @@ func New(ctx context.Context, conf *Config, observationContext *observation.Cont
 			CredentialsFile:         conf.GCSCredentialsFile,
 			CredentialsFileContents: conf.GCSCredentialsFileContents,
 		},
+		Azure: uploadstore.AzureConfig{
+			AccountName: conf.AzureAccountName,
+			AccountKey:  conf.AzureAccountKey,
+			Endpoint:    conf.AzureEndpoint,
+		},
+		Filesystem: uploadstore.FilesystemConfig{
+			Dir: conf.FilesystemDir,
+		},
 	}
 
 	return uploadstore.CreateLazy(ctx, c, uploadstore.NewOperations(observationContext, "codeintel", "uploadstore"))
 }
+
+// expirerInterval is the interval between two expirations of uploads older than the TTL.
+const expirerInterval = time.Hour
+
+// NewExpirer returns a background routine removing uploads older than the configured TTL.
+// It is only required if conf.RequiresExpirer returns true.
+func NewExpirer(store uploadstore.Store, conf *Config) goroutine.BackgroundRoutine {
+	return uploadstore.NewExpirer(context.Background(), store, "", conf.TTL, expirerInterval)
+}
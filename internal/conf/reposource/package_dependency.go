@@ -54,4 +54,7 @@ var (
 	_ PackageDependency = (*NpmDependency)(nil)
 	_ PackageDependency = (*GoDependency)(nil)
 	_ PackageDependency = (*PythonDependency)(nil)
+	_ PackageDependency = (*RustDependency)(nil)
+	_ PackageDependency = (*RubyDependency)(nil)
+	_ PackageDependency = (*PhpDependency)(nil)
 )
@@ -0,0 +1,87 @@
+package reposource
+
+import (
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// PhpDependency is a Composer package, identified by its '<vendor>/<package>' name.
+type PhpDependency struct {
+	Name    string
+	Version string
+}
+
+func NewPhpDependency(name, version string) *PhpDependency {
+	return &PhpDependency{
+		Name:    name,
+		Version: version,
+	}
+}
+
+// ParsePhpDependency parses a string in a '<vendor>/<package>(:<version>)?' format into
+// a PhpDependency.
+func ParsePhpDependency(dependency string) (*PhpDependency, error) {
+	var dep PhpDependency
+	if i := strings.LastIndex(dependency, ":"); i == -1 {
+		dep.Name = dependency
+	} else {
+		dep.Name = strings.TrimSpace(dependency[:i])
+		dep.Version = strings.TrimSpace(dependency[i+1:])
+	}
+	if !strings.Contains(dep.Name, "/") {
+		return nil, errors.Newf("invalid PHP dependency %q, expected '<vendor>/<package>' name", dependency)
+	}
+	return &dep, nil
+}
+
+// ParsePhpDependencyFromRepoName is a convenience function to parse a repo name in a
+// 'packagist/<vendor>/<package>(:<version>)?' format into a PhpDependency.
+func ParsePhpDependencyFromRepoName(name string) (*PhpDependency, error) {
+	dependency := strings.TrimPrefix(name, "packagist/")
+	if len(dependency) == len(name) {
+		return nil, errors.Newf("invalid PHP dependency repo name, missing packagist/ prefix '%s'", name)
+	}
+	return ParsePhpDependency(dependency)
+}
+
+func (p *PhpDependency) Scheme() string {
+	return "composer"
+}
+
+func (p *PhpDependency) PackageSyntax() string {
+	return p.Name
+}
+
+func (p *PhpDependency) PackageManagerSyntax() string {
+	if p.Version == "" {
+		return p.Name
+	}
+	return p.Name + ":" + p.Version
+}
+
+func (p *PhpDependency) PackageVersion() string {
+	return p.Version
+}
+
+func (p *PhpDependency) Description() string { return "" }
+
+func (p *PhpDependency) RepoName() api.RepoName {
+	return api.RepoName("packagist/" + p.Name)
+}
+
+func (p *PhpDependency) GitTagFromVersion() string {
+	version := strings.TrimPrefix(p.Version, "v")
+	return "v" + version
+}
+
+func (p *PhpDependency) Less(other PackageDependency) bool {
+	o := other.(*PhpDependency)
+
+	if p.Name == o.Name {
+		return versionGreaterThan(p.Version, o.Version)
+	}
+
+	return p.Name > o.Name
+}
@@ -0,0 +1,20 @@
+package reposource
+
+import (
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// SubversionRepoName returns the repository name for the Subversion repository at the
+// given path of the server whose URL host and path is base.
+func SubversionRepoName(repositoryPathPattern, base, repo string) api.RepoName {
+	if repositoryPathPattern == "" {
+		repositoryPathPattern = "{base}/{repo}"
+	}
+
+	return api.RepoName(strings.Trim(strings.NewReplacer(
+		"{base}", otherRepoNameReplacer.Replace(strings.Trim(base, "/")),
+		"{repo}", strings.Trim(repo, "/"),
+	).Replace(repositoryPathPattern), "/"))
+}
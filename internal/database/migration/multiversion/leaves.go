@@ -0,0 +1,31 @@
+package multiversion
+
+import "github.com/sourcegraph/sourcegraph/internal/oobmigration"
+
+// SchemaLeaves maps released versions to the leaf migrations of each schema at that version.
+// A multi-version upgrade can only pause at a version listed here in order to run the required
+// out-of-band migrations against the schema of that version. Upgrades to a version that is not
+// listed here apply all the migrations defined in the migrator.
+//
+// Versions released before the migrations were last squashed cannot be listed: their leaves
+// are not defined in migrations/ anymore.
+//
+// Add an entry for each minor release once its branch is cut, with the leaves of the schemas
+// defined in migrations/ on the release branch.
+var SchemaLeaves = map[oobmigration.Version]map[string][]int{
+	oobmigration.NewVersion(3, 38): {
+		"frontend":     {1646652951, 1647282553},
+		"codeintel":    {1000000033},
+		"codeinsights": {1646761143},
+	},
+	oobmigration.NewVersion(3, 39): {
+		"frontend":     {1649159359, 1649432863, 1649441222, 1649759318},
+		"codeintel":    {1000000034},
+		"codeinsights": {1649801281},
+	},
+	oobmigration.NewVersion(3, 40): {
+		"frontend":     {1652143849, 1652189866, 1652228814},
+		"codeintel":    {1000000034},
+		"codeinsights": {1651021000, 1652289966},
+	},
+}
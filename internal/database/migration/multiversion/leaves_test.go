@@ -0,0 +1,84 @@
+package multiversion
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/migration/schemas"
+	"github.com/sourcegraph/sourcegraph/internal/oobmigration"
+)
+
+func TestSchemaLeaves(t *testing.T) {
+	versions := make([]oobmigration.Version, 0, len(SchemaLeaves))
+	for version := range SchemaLeaves {
+		versions = append(versions, version)
+	}
+	sortVersions(versions)
+
+	for _, schema := range schemas.Schemas {
+		// applied tracks the migrations applied by the previous versions
+		var applied []int
+
+		for _, version := range versions {
+			leaves, ok := SchemaLeaves[version][schema.Name]
+			if !ok {
+				t.Errorf("no leaves for schema %q at %s", schema.Name, version)
+				continue
+			}
+
+			ancestors, err := schema.Definitions.Up(nil, leaves)
+			if err != nil {
+				t.Errorf("invalid leaves for schema %q at %s: %s", schema.Name, version, err)
+				continue
+			}
+
+			ids := make(map[int]struct{}, len(ancestors))
+			for _, definition := range ancestors {
+				for _, parent := range definition.Parents {
+					ids[parent] = struct{}{}
+				}
+			}
+			for _, leaf := range leaves {
+				if _, ok := ids[leaf]; ok {
+					t.Errorf("migration %d of schema %q at %s is an ancestor of another leaf", leaf, schema.Name, version)
+				}
+			}
+
+			previous := make(map[int]struct{}, len(ancestors))
+			for _, definition := range ancestors {
+				previous[definition.ID] = struct{}{}
+			}
+			for _, id := range applied {
+				if _, ok := previous[id]; !ok {
+					t.Errorf("migration %d of schema %q is applied before %s but is not an ancestor of its leaves", id, schema.Name, version)
+				}
+			}
+
+			applied = applied[:0]
+			for _, definition := range ancestors {
+				applied = append(applied, definition.ID)
+			}
+		}
+	}
+}
+
+func TestNewPlanWithSchemaLeaves(t *testing.T) {
+	migrations := []oobmigration.Migration{
+		// Deprecated in 3.40: must complete at 3.39 when upgrading from 3.38
+		{ID: 1, Introduced: oobmigration.NewVersion(3, 36), Deprecated: versionPtr(3, 40), Progress: 0.25},
+	}
+
+	plan, err := NewPlan(oobmigration.NewVersion(3, 38), oobmigration.NewVersion(3, 41), migrations, SchemaLeaves)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(plan.Steps) != 2 {
+		t.Fatalf("unexpected number of steps: want 2, got %d:\n%s", len(plan.Steps), plan)
+	}
+	if step := plan.Steps[0]; step.Version != oobmigration.NewVersion(3, 39) || step.Latest || len(step.Leaves) != len(schemas.Schemas) || len(step.OutOfBandMigrationIDs) != 1 {
+		t.Errorf("expected the upgrade to pause at the schema of 3.39 to run migration 1:\n%s", plan)
+	}
+	if step := plan.Steps[1]; step.Version != oobmigration.NewVersion(3, 41) || !step.Latest {
+		t.Errorf("expected the upgrade to apply all schema migrations of 3.41:\n%s", plan)
+	}
+}
@@ -0,0 +1,195 @@
+package multiversion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/oobmigration"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// Plan is the sequence of steps upgrading the databases of an instance from one version to a
+// later one, possibly skipping several minor versions.
+type Plan struct {
+	From  oobmigration.Version
+	To    oobmigration.Version
+	Steps []Step
+}
+
+// Step is a stop of a multi-version upgrade: the schemas are migrated to the version of the
+// step, then the out-of-band migrations of the step are run to completion.
+type Step struct {
+	// Version is the version whose schema migrations are applied in this step.
+	Version oobmigration.Version
+
+	// Leaves maps schema names to the migrations to apply (along with their ancestors).
+	Leaves map[string][]int
+
+	// Latest is set if all migrations defined in the migrator are applied in this step. No
+	// schema migrations are applied if neither Leaves nor Latest are set.
+	Latest bool
+
+	// OutOfBandMigrationIDs are the out-of-band migrations that must complete before the
+	// schema migrations of the next step are applied.
+	OutOfBandMigrationIDs []int
+}
+
+// NewPlan returns the plan to upgrade from the given version to the given later version. The
+// upgrade pauses at a version whose leaves are known every time out-of-band migrations must
+// complete before the schema migrations of the next version are applied. An out-of-band
+// migration must complete before the version that deprecates it, and can only run once the
+// version that introduces it has been reached.
+//
+// Only the given out-of-band migrations are taken into account: the migrations introduced
+// by a later version are not known until the schema migrations of that version are applied,
+// so the plan should be computed again after each step.
+func NewPlan(from, to oobmigration.Version, migrations []oobmigration.Migration, leaves map[oobmigration.Version]map[string][]int) (Plan, error) {
+	if before(to, from) {
+		return Plan{}, errors.Newf("cannot upgrade from %s to earlier version %s", from, to)
+	}
+
+	// Group the unfinished migrations by the version deprecating them
+	required := map[oobmigration.Version][]oobmigration.Migration{}
+	for _, migration := range migrations {
+		if migration.Deprecated == nil || migration.Complete() {
+			continue
+		}
+
+		deprecated := *migration.Deprecated
+		if !before(from, deprecated) {
+			return Plan{}, errors.Newf(
+				"out-of-band migration %d was deprecated in %s but is only %.2f%% complete: the databases are not in a valid state for %s",
+				migration.ID, deprecated, migration.Progress*100, from,
+			)
+		}
+		if before(to, deprecated) {
+			// Still supported by the target version
+			continue
+		}
+
+		required[deprecated] = append(required[deprecated], migration)
+	}
+
+	deprecatedVersions := make([]oobmigration.Version, 0, len(required))
+	for version := range required {
+		deprecatedVersions = append(deprecatedVersions, version)
+	}
+	sortVersions(deprecatedVersions)
+
+	// The upgrade can pause at its starting point, or at any version in between whose
+	// leaves are known
+	stops := []oobmigration.Version{from}
+	for version := range leaves {
+		if before(from, version) && before(version, to) {
+			stops = append(stops, version)
+		}
+	}
+	sortVersions(stops)
+
+	plan := Plan{From: from, To: to}
+	for _, deprecated := range deprecatedVersions {
+		introduced := from
+		ids := make([]int, 0, len(required[deprecated]))
+		for _, migration := range required[deprecated] {
+			if before(introduced, migration.Introduced) {
+				introduced = migration.Introduced
+			}
+			ids = append(ids, migration.ID)
+		}
+		sort.Ints(ids)
+
+		// Pause at the latest version at which all of the migrations can run
+		var stop *oobmigration.Version
+		for i, version := range stops {
+			if before(version, deprecated) && !before(version, introduced) {
+				stop = &stops[i]
+			}
+		}
+		if stop == nil {
+			return Plan{}, errors.Newf(
+				"cannot upgrade from %s to %s: out-of-band migrations %s must complete on a version between %s and %s, but the schema of no such version is known; upgrade to an intermediate version first",
+				from, to, formatIDs(ids), introduced, deprecated,
+			)
+		}
+
+		if n := len(plan.Steps); n > 0 && plan.Steps[n-1].Version == *stop {
+			plan.Steps[n-1].OutOfBandMigrationIDs = append(plan.Steps[n-1].OutOfBandMigrationIDs, ids...)
+			sort.Ints(plan.Steps[n-1].OutOfBandMigrationIDs)
+			continue
+		}
+		plan.Steps = append(plan.Steps, Step{
+			Version:               *stop,
+			Leaves:                leaves[*stop],
+			OutOfBandMigrationIDs: ids,
+		})
+	}
+
+	// Finally, apply the schema migrations of the target version. If its leaves are not
+	// known, the migrator is expected to be the target version itself.
+	plan.Steps = append(plan.Steps, Step{
+		Version: to,
+		Leaves:  leaves[to],
+		Latest:  leaves[to] == nil,
+	})
+
+	return plan, nil
+}
+
+// String returns a human-readable description of the plan, one step per line.
+func (p Plan) String() string {
+	lines := make([]string, 0, len(p.Steps)+1)
+	lines = append(lines, fmt.Sprintf("Upgrade from %s to %s:", p.From, p.To))
+
+	for i, step := range p.Steps {
+		var actions []string
+		switch {
+		case step.Latest:
+			actions = append(actions, fmt.Sprintf("apply all schema migrations of %s", step.Version))
+		case len(step.Leaves) > 0:
+			actions = append(actions, fmt.Sprintf("apply schema migrations of %s (%s)", step.Version, formatLeaves(step.Leaves)))
+		default:
+			actions = append(actions, fmt.Sprintf("keep the schema of %s", step.Version))
+		}
+		if len(step.OutOfBandMigrationIDs) > 0 {
+			actions = append(actions, fmt.Sprintf("run out-of-band migrations %s to completion", formatIDs(step.OutOfBandMigrationIDs)))
+		}
+
+		lines = append(lines, fmt.Sprintf("  %d. %s", i+1, strings.Join(actions, ", then ")))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// before returns true if a is before b.
+func before(a, b oobmigration.Version) bool {
+	return oobmigration.CompareVersions(a, b) == oobmigration.VersionOrderBefore
+}
+
+func sortVersions(versions []oobmigration.Version) {
+	sort.Slice(versions, func(i, j int) bool { return before(versions[i], versions[j]) })
+}
+
+func formatIDs(ids []int) string {
+	strs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		strs = append(strs, fmt.Sprintf("%d", id))
+	}
+
+	return strings.Join(strs, ", ")
+}
+
+func formatLeaves(leaves map[string][]int) string {
+	schemaNames := make([]string, 0, len(leaves))
+	for schemaName := range leaves {
+		schemaNames = append(schemaNames, schemaName)
+	}
+	sort.Strings(schemaNames)
+
+	strs := make([]string, 0, len(schemaNames))
+	for _, schemaName := range schemaNames {
+		strs = append(strs, fmt.Sprintf("%s: %s", schemaName, formatIDs(leaves[schemaName])))
+	}
+
+	return strings.Join(strs, "; ")
+}
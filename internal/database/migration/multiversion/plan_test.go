@@ -0,0 +1,130 @@
+package multiversion
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/oobmigration"
+)
+
+var testLeaves = map[oobmigration.Version]map[string][]int{
+	oobmigration.NewVersion(3, 38): {"frontend": {1000}, "codeintel": {2000}},
+	oobmigration.NewVersion(3, 39): {"frontend": {1010}, "codeintel": {2010}},
+	oobmigration.NewVersion(3, 40): {"frontend": {1020, 1021}, "codeintel": {2020}},
+}
+
+func TestNewPlan(t *testing.T) {
+	migrations := []oobmigration.Migration{
+		// Deprecated in 3.40: must complete at 3.39
+		{ID: 1, Introduced: oobmigration.NewVersion(3, 30), Deprecated: versionPtr(3, 40), Progress: 0.5},
+		{ID: 2, Introduced: oobmigration.NewVersion(3, 36), Deprecated: versionPtr(3, 40)},
+		// Deprecated in 3.39: must complete at 3.38
+		{ID: 3, Introduced: oobmigration.NewVersion(3, 32), Deprecated: versionPtr(3, 39)},
+		// Already complete
+		{ID: 4, Introduced: oobmigration.NewVersion(3, 30), Deprecated: versionPtr(3, 39), Progress: 1},
+		// Deprecated after the target version
+		{ID: 5, Introduced: oobmigration.NewVersion(3, 30), Deprecated: versionPtr(3, 42)},
+		// Never deprecated
+		{ID: 6, Introduced: oobmigration.NewVersion(3, 30)},
+	}
+
+	plan, err := NewPlan(oobmigration.NewVersion(3, 37), oobmigration.NewVersion(3, 41), migrations, testLeaves)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []Step{
+		{Version: oobmigration.NewVersion(3, 38), Leaves: testLeaves[oobmigration.NewVersion(3, 38)], OutOfBandMigrationIDs: []int{3}},
+		{Version: oobmigration.NewVersion(3, 39), Leaves: testLeaves[oobmigration.NewVersion(3, 39)], OutOfBandMigrationIDs: []int{1, 2}},
+		{Version: oobmigration.NewVersion(3, 41), Latest: true},
+	}
+	if diff := cmp.Diff(expected, plan.Steps); diff != "" {
+		t.Errorf("unexpected steps (-want +got):\n%s", diff)
+	}
+
+	description := plan.String()
+	for _, line := range []string{
+		"Upgrade from 3.37 to 3.41:",
+		"  1. apply schema migrations of 3.38 (codeintel: 2000; frontend: 1000), then run out-of-band migrations 3 to completion",
+		"  2. apply schema migrations of 3.39 (codeintel: 2010; frontend: 1010), then run out-of-band migrations 1, 2 to completion",
+		"  3. apply all schema migrations of 3.41",
+	} {
+		if !strings.Contains(description, line) {
+			t.Errorf("expected line %q in plan description:\n%s", line, description)
+		}
+	}
+}
+
+func TestNewPlanPausesAtStartingVersion(t *testing.T) {
+	migrations := []oobmigration.Migration{
+		{ID: 1, Introduced: oobmigration.NewVersion(3, 30), Deprecated: versionPtr(3, 38)},
+	}
+
+	plan, err := NewPlan(oobmigration.NewVersion(3, 37), oobmigration.NewVersion(3, 40), migrations, testLeaves)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []Step{
+		{Version: oobmigration.NewVersion(3, 37), OutOfBandMigrationIDs: []int{1}},
+		{Version: oobmigration.NewVersion(3, 40), Leaves: testLeaves[oobmigration.NewVersion(3, 40)]},
+	}
+	if diff := cmp.Diff(expected, plan.Steps); diff != "" {
+		t.Errorf("unexpected steps (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewPlanWithoutRequiredMigrations(t *testing.T) {
+	plan, err := NewPlan(oobmigration.NewVersion(3, 37), oobmigration.NewVersion(3, 41), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []Step{
+		{Version: oobmigration.NewVersion(3, 41), Latest: true},
+	}
+	if diff := cmp.Diff(expected, plan.Steps); diff != "" {
+		t.Errorf("unexpected steps (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewPlanErrors(t *testing.T) {
+	testCases := map[string]struct {
+		from, to   oobmigration.Version
+		migrations []oobmigration.Migration
+	}{
+		"downgrade": {
+			from: oobmigration.NewVersion(3, 40),
+			to:   oobmigration.NewVersion(3, 38),
+		},
+		"incomplete deprecated migration": {
+			from: oobmigration.NewVersion(3, 40),
+			to:   oobmigration.NewVersion(3, 41),
+			migrations: []oobmigration.Migration{
+				{ID: 1, Introduced: oobmigration.NewVersion(3, 30), Deprecated: versionPtr(3, 39), Progress: 0.5},
+			},
+		},
+		"unknown intermediate version": {
+			from: oobmigration.NewVersion(3, 37),
+			to:   oobmigration.NewVersion(3, 45),
+			migrations: []oobmigration.Migration{
+				{ID: 1, Introduced: oobmigration.NewVersion(3, 42), Deprecated: versionPtr(3, 44)},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewPlan(testCase.from, testCase.to, testCase.migrations, testLeaves); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+}
+
+func versionPtr(major, minor int) *oobmigration.Version {
+	version := oobmigration.NewVersion(major, minor)
+	return &version
+}
@@ -29,6 +29,8 @@ type Key struct {
 	cache *lru.Cache
 }
 
+var _ encryption.BatchKey = &Key{}
+
 // Decrypt attempts to find the decrypted ciphertext in the cache, if it is not found, the
 // underlying key implementation is used, and the result is added to the cache.
 func (k *Key) Decrypt(ctx context.Context, ciphertext []byte) (*encryption.Secret, error) {
@@ -51,6 +53,51 @@ func (k *Key) Decrypt(ctx context.Context, ciphertext []byte) (*encryption.Secre
 	return &s, nil
 }
 
+// EncryptBatch encrypts values with the underlying key implementation, in a single request
+// if it supports batches.
+func (k *Key) EncryptBatch(ctx context.Context, values [][]byte) ([][]byte, error) {
+	return encryption.EncryptBatch(ctx, k.Key, values)
+}
+
+// DecryptBatch attempts to find the decrypted ciphertexts in the cache. The ones that are
+// not found are decrypted with the underlying key implementation, in a single request if it
+// supports batches, and the results are added to the cache.
+func (k *Key) DecryptBatch(ctx context.Context, ciphertexts [][]byte) ([]*encryption.Secret, error) {
+	secrets := make([]*encryption.Secret, len(ciphertexts))
+	var (
+		missed []int
+		misses [][]byte
+		hashes = make([]uint64, len(ciphertexts))
+	)
+	for i, ciphertext := range ciphertexts {
+		hashes[i] = hash(ciphertext)
+		v, found := k.cache.Get(hashes[i])
+		if s, ok := v.(encryption.Secret); ok && found {
+			hitTotal.WithLabelValues().Inc()
+			secrets[i] = &s
+			continue
+		}
+		missTotal.WithLabelValues().Inc()
+		missed = append(missed, i)
+		misses = append(misses, ciphertext)
+	}
+	if len(misses) == 0 {
+		return secrets, nil
+	}
+
+	decrypted, err := encryption.DecryptBatch(ctx, k.Key, misses)
+	if err != nil {
+		loadErrorTotal.WithLabelValues().Inc()
+		return nil, err
+	}
+	loadSuccessTotal.WithLabelValues().Inc()
+	for j, i := range missed {
+		k.cache.Add(hashes[i], *decrypted[j])
+		secrets[i] = decrypted[j]
+	}
+	return secrets, nil
+}
+
 func hash(v []byte) uint64 {
 	h := fnv.New64()
 	h.Write(v)
@@ -37,6 +37,49 @@ type Decrypter interface {
 	Decrypt(ctx context.Context, cipherText []byte) (*Secret, error)
 }
 
+// BatchKey is a Key that can encrypt or decrypt many values with a single request to
+// its backend.
+type BatchKey interface {
+	Key
+
+	// EncryptBatch encrypts values, returning the ciphertexts in the same order.
+	EncryptBatch(ctx context.Context, values [][]byte) ([][]byte, error)
+	// DecryptBatch decrypts cipherTexts, returning the secrets in the same order.
+	DecryptBatch(ctx context.Context, cipherTexts [][]byte) ([]*Secret, error)
+}
+
+// EncryptBatch encrypts values with key, in a single request if it is a BatchKey.
+func EncryptBatch(ctx context.Context, key Key, values [][]byte) ([][]byte, error) {
+	if bk, ok := key.(BatchKey); ok {
+		return bk.EncryptBatch(ctx, values)
+	}
+	cipherTexts := make([][]byte, 0, len(values))
+	for _, value := range values {
+		cipherText, err := key.Encrypt(ctx, value)
+		if err != nil {
+			return nil, err
+		}
+		cipherTexts = append(cipherTexts, cipherText)
+	}
+	return cipherTexts, nil
+}
+
+// DecryptBatch decrypts cipherTexts with key, in a single request if it is a BatchKey.
+func DecryptBatch(ctx context.Context, key Key, cipherTexts [][]byte) ([]*Secret, error) {
+	if bk, ok := key.(BatchKey); ok {
+		return bk.DecryptBatch(ctx, cipherTexts)
+	}
+	secrets := make([]*Secret, 0, len(cipherTexts))
+	for _, cipherText := range cipherTexts {
+		secret, err := key.Decrypt(ctx, cipherText)
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets, nil
+}
+
 func NewSecret(v string) Secret {
 	return Secret{
 		value: v,
@@ -0,0 +1,181 @@
+package rotation
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/keegancsmith/sqlf"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/encryption/keyring"
+)
+
+// Row holds the encrypted columns of a row, in the order of the columns of its table. A
+// nil value is a NULL column.
+type Row struct {
+	ID     int64
+	KeyID  string
+	Values [][]byte
+}
+
+// Store reads and re-encrypts the rows of the tables with encrypted columns.
+type Store struct {
+	*basestore.Store
+}
+
+// NewStore returns a new Store backed by the given database.
+func NewStore(db dbutil.DB) *Store {
+	return &Store{Store: basestore.NewWithHandle(basestore.NewHandleWithDB(db, sql.TxOptions{}))}
+}
+
+// With creates a new store with the underlying database handle from the given store.
+func (s *Store) With(other basestore.ShareableStore) *Store {
+	return &Store{Store: s.Store.With(other)}
+}
+
+// Transact returns a new store whose methods operate within the context of a new transaction.
+func (s *Store) Transact(ctx context.Context) (*Store, error) {
+	txBase, err := s.Store.Transact(ctx)
+	return &Store{Store: txBase}, err
+}
+
+// Status returns the number of rows of the given table by key version. The current key
+// version is determined from ring.
+func (s *Store) Status(ctx context.Context, t Table, ring keyring.Ring) (_ *Status, err error) {
+	currentKeyID, err := t.CurrentKeyID(ctx, ring)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.Query(ctx, sqlf.Sprintf(statusQueryFmtstr, sqlf.Sprintf(t.Name)))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = basestore.CloseRows(rows, err) }()
+
+	status := &Status{Table: t, CurrentKeyID: currentKeyID, Counts: map[string]int{}}
+	for rows.Next() {
+		var (
+			keyID string
+			count int
+		)
+		if err := rows.Scan(&keyID, &count); err != nil {
+			return nil, err
+		}
+		status.Counts[keyID] = count
+	}
+	return status, nil
+}
+
+const statusQueryFmtstr = `
+-- source: internal/encryption/rotation/store.go:Store.Status
+SELECT encryption_key_id, COUNT(*) FROM %s GROUP BY encryption_key_id
+`
+
+// KeyIDs returns the distinct values of the encryption_key_id column of the given table.
+// The index on the column is walked from one value to the next, so that the number of
+// rows read is the number of distinct values rather than the number of rows of the table.
+func (s *Store) KeyIDs(ctx context.Context, t Table) ([]string, error) {
+	return basestore.ScanStrings(s.Query(ctx, sqlf.Sprintf(keyIDsQueryFmtstr, sqlf.Sprintf(t.Name), sqlf.Sprintf(t.Name))))
+}
+
+const keyIDsQueryFmtstr = `
+-- source: internal/encryption/rotation/store.go:Store.KeyIDs
+WITH RECURSIVE key_ids AS (
+	(SELECT encryption_key_id FROM %s ORDER BY encryption_key_id LIMIT 1)
+	UNION ALL
+	SELECT (SELECT t.encryption_key_id FROM %s t WHERE t.encryption_key_id > k.encryption_key_id ORDER BY t.encryption_key_id LIMIT 1)
+	FROM key_ids k
+	WHERE k.encryption_key_id IS NOT NULL
+)
+SELECT encryption_key_id FROM key_ids WHERE encryption_key_id IS NOT NULL
+`
+
+// ListStale returns and locks up to limit rows of the given table which are encrypted with
+// another key version than the one identified by currentKeyID. Rows locked by another
+// transaction are skipped, so that concurrent callers do not re-encrypt the same rows.
+func (s *Store) ListStale(ctx context.Context, t Table, currentKeyID string, limit int) (_ []*Row, err error) {
+	keyIDs, err := s.KeyIDs(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	// The stale key versions are listed explicitly rather than excluding the current
+	// one, so that the rows can be looked up with the index on encryption_key_id.
+	stale := make([]*sqlf.Query, 0, len(keyIDs))
+	for _, keyID := range keyIDs {
+		if keyID != currentKeyID && t.Encrypted(keyID) {
+			stale = append(stale, sqlf.Sprintf("%s", keyID))
+		}
+	}
+	if len(stale) == 0 {
+		return nil, nil
+	}
+
+	columns := make([]*sqlf.Query, 0, len(t.Columns))
+	for _, c := range t.Columns {
+		columns = append(columns, sqlf.Sprintf(c.Name))
+	}
+
+	rows, err := s.Query(ctx, sqlf.Sprintf(
+		listStaleQueryFmtstr,
+		sqlf.Join(columns, ", "),
+		sqlf.Sprintf(t.Name),
+		sqlf.Join(stale, ", "),
+		limit,
+	))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = basestore.CloseRows(rows, err) }()
+
+	var staleRows []*Row
+	for rows.Next() {
+		row := &Row{Values: make([][]byte, len(t.Columns))}
+		dest := []any{&row.ID, &row.KeyID}
+		for i := range row.Values {
+			dest = append(dest, &row.Values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		staleRows = append(staleRows, row)
+	}
+	return staleRows, nil
+}
+
+const listStaleQueryFmtstr = `
+-- source: internal/encryption/rotation/store.go:Store.ListStale
+SELECT id, encryption_key_id, %s FROM %s
+WHERE encryption_key_id IN (%s)
+ORDER BY id
+LIMIT %s
+FOR UPDATE SKIP LOCKED
+`
+
+// Update stores the given values of the encrypted columns of a row of the given table,
+// along with the key version they are encrypted with.
+func (s *Store) Update(ctx context.Context, t Table, row *Row, keyID string) error {
+	assignments := make([]*sqlf.Query, 0, len(t.Columns)+1)
+	for i, c := range t.Columns {
+		var value any
+		switch {
+		case row.Values[i] == nil:
+			value = nil
+		case c.Text:
+			value = string(row.Values[i])
+		default:
+			value = row.Values[i]
+		}
+		assignments = append(assignments, sqlf.Sprintf(c.Name+" = %s", value))
+	}
+	assignments = append(assignments, sqlf.Sprintf("encryption_key_id = %s", keyID))
+
+	return s.Exec(ctx, sqlf.Sprintf(updateQueryFmtstr, sqlf.Sprintf(t.Name), sqlf.Join(assignments, ", "), row.ID))
+}
+
+const updateQueryFmtstr = `
+-- source: internal/encryption/rotation/store.go:Store.Update
+UPDATE %s SET %s WHERE id = %s
+`
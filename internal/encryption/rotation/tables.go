@@ -0,0 +1,159 @@
+// Package rotation keeps track of the database columns encrypted with the keys of the
+// keyring, so that the rows encrypted with a previous version of a key can be re-encrypted
+// with its current version once it is rotated.
+package rotation
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/encryption"
+	"github.com/sourcegraph/sourcegraph/internal/encryption/keyring"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// Column is an encrypted column of a table.
+type Column struct {
+	Name string
+	// Text is true if the ciphertext is stored in a text column rather than in a bytea
+	// column.
+	Text bool
+}
+
+// Table is a table whose rows have columns encrypted with a key of the keyring. The
+// version of the key a row is encrypted with is stored in its encryption_key_id column.
+type Table struct {
+	Name    string
+	Columns []Column
+	// Key returns the key of the keyring the columns are encrypted with.
+	Key func(keyring.Ring) encryption.Key
+	// Skipped are the values of encryption_key_id, other than the empty string, of rows
+	// which are not encrypted or whose key version is not known yet. They are handled
+	// by the migrations encrypting the table in the first place.
+	Skipped []string
+}
+
+// Tables are the tables with encrypted columns.
+var Tables = []Table{
+	{
+		Name:    "external_services",
+		Columns: []Column{{Name: "config", Text: true}},
+		Key:     func(r keyring.Ring) encryption.Key { return r.ExternalServiceKey },
+	},
+	{
+		Name:    "user_external_accounts",
+		Columns: []Column{{Name: "auth_data", Text: true}, {Name: "account_data", Text: true}},
+		Key:     func(r keyring.Ring) encryption.Key { return r.UserExternalAccountKey },
+	},
+	{
+		Name:    "user_credentials",
+		Columns: []Column{{Name: "credential"}},
+		Key:     func(r keyring.Ring) encryption.Key { return r.BatchChangesCredentialKey },
+		Skipped: []string{database.UserCredentialPlaceholderEncryptionKeyID, database.UserCredentialUnmigratedEncryptionKeyID},
+	},
+	{
+		// Site credentials use the same placeholder key IDs as user credentials.
+		Name:    "batch_changes_site_credentials",
+		Columns: []Column{{Name: "credential"}},
+		Key:     func(r keyring.Ring) encryption.Key { return r.BatchChangesCredentialKey },
+		Skipped: []string{database.UserCredentialPlaceholderEncryptionKeyID, database.UserCredentialUnmigratedEncryptionKeyID},
+	},
+	{
+		Name:    "webhook_logs",
+		Columns: []Column{{Name: "request"}, {Name: "response"}},
+		Key:     func(r keyring.Ring) encryption.Key { return r.WebhookLogKey },
+	},
+}
+
+// Encrypted returns true if the rows of the table with the given encryption_key_id
+// are encrypted with a known key version.
+func (t Table) Encrypted(keyID string) bool {
+	if keyID == "" {
+		return false
+	}
+	for _, skipped := range t.Skipped {
+		if keyID == skipped {
+			return false
+		}
+	}
+	return true
+}
+
+// CurrentKeyID returns the encryption_key_id of the rows of the table encrypted with the
+// current version of its key, or an empty string if no key is configured.
+func (t Table) CurrentKeyID(ctx context.Context, ring keyring.Ring) (string, error) {
+	key := t.Key(ring)
+	if key == nil {
+		return "", nil
+	}
+	version, err := key.Version(ctx)
+	if err != nil {
+		return "", errors.Wrapf(err, "getting key version of %s", t.Name)
+	}
+	return version.JSON(), nil
+}
+
+// Status is the number of rows of a table by version of the key they are encrypted with.
+type Status struct {
+	Table Table
+	// CurrentKeyID is the encryption_key_id of the rows encrypted with the current
+	// version of the key, or an empty string if no key is configured.
+	CurrentKeyID string
+	// Counts are the number of rows by encryption_key_id.
+	Counts map[string]int
+}
+
+// Progress returns the fraction of the encrypted rows which are encrypted with the current
+// version of the key, from 0 to 1.
+func (s *Status) Progress() float64 {
+	current, encrypted := s.counts()
+	if encrypted == 0 {
+		return 1
+	}
+	return float64(current) / float64(encrypted)
+}
+
+// Reencrypted updates the counts after the given rows are re-encrypted with the key
+// version identified by keyID, so that they do not need to be counted again.
+func (s *Status) Reencrypted(rows []*Row, keyID string) {
+	for _, row := range rows {
+		if s.Counts[row.KeyID]--; s.Counts[row.KeyID] <= 0 {
+			delete(s.Counts, row.KeyID)
+		}
+		s.Counts[keyID]++
+	}
+}
+
+// counts returns the number of rows encrypted with the current version of the key and
+// the number of encrypted rows.
+func (s *Status) counts() (current, encrypted int) {
+	for keyID, count := range s.Counts {
+		if !s.Table.Encrypted(keyID) {
+			continue
+		}
+		encrypted += count
+		if keyID == s.CurrentKeyID {
+			current += count
+		}
+	}
+	return current, encrypted
+}
+
+// Progress returns the fraction of the encrypted rows of the given tables which are
+// encrypted with the current version of their key, from 0 to 1. Tables without a
+// configured key are ignored, since their rows cannot be re-encrypted.
+func Progress(statuses []*Status) float64 {
+	var current, encrypted int
+	for _, s := range statuses {
+		if s.CurrentKeyID == "" {
+			continue
+		}
+		c, e := s.counts()
+		current += c
+		encrypted += e
+	}
+	if encrypted == 0 {
+		return 1
+	}
+	return float64(current) / float64(encrypted)
+}
@@ -0,0 +1,88 @@
+package rotation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/encryption/keyring"
+	et "github.com/sourcegraph/sourcegraph/internal/encryption/testing"
+)
+
+func TestTable_Encrypted(t *testing.T) {
+	table := Table{Name: "user_credentials", Skipped: []string{"previously-migrated", "unmigrated"}}
+	for keyID, want := range map[string]bool{
+		"":                    false,
+		"unmigrated":          false,
+		"previously-migrated": false,
+		`{"type":"testkey"}`:  true,
+	} {
+		if got := table.Encrypted(keyID); got != want {
+			t.Errorf("Encrypted(%q) = %v, want %v", keyID, got, want)
+		}
+	}
+}
+
+func TestTable_CurrentKeyID(t *testing.T) {
+	ctx := context.Background()
+	table := Tables[0]
+
+	if keyID, err := table.CurrentKeyID(ctx, keyring.Ring{}); err != nil || keyID != "" {
+		t.Fatalf("got key ID %q (err %v) without a key, want none", keyID, err)
+	}
+	if keyID, err := table.CurrentKeyID(ctx, keyring.Ring{ExternalServiceKey: et.TestKey{}}); err != nil || keyID != `{"Type":"testkey","Name":"","Version":""}` {
+		t.Fatalf("got key ID %q (err %v), want the test key", keyID, err)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	table := Table{Name: "webhook_logs"}
+	statuses := []*Status{
+		{
+			Table:        table,
+			CurrentKeyID: "v2",
+			Counts:       map[string]int{"": 5, "v1": 3, "v2": 1},
+		},
+		{
+			Table:        Table{Name: "user_credentials", Skipped: []string{"unmigrated"}},
+			CurrentKeyID: "v2",
+			Counts:       map[string]int{"unmigrated": 2, "v2": 4},
+		},
+		{
+			// Ignored since no key is configured.
+			Table:  table,
+			Counts: map[string]int{"v1": 10},
+		},
+	}
+
+	if got, want := statuses[0].Progress(), 0.25; got != want {
+		t.Errorf("got progress %v, want %v", got, want)
+	}
+	if got, want := statuses[1].Progress(), 1.0; got != want {
+		t.Errorf("got progress %v, want %v", got, want)
+	}
+	if got, want := Progress(statuses), 5.0/8.0; got != want {
+		t.Errorf("got overall progress %v, want %v", got, want)
+	}
+	if got, want := Progress(nil), 1.0; got != want {
+		t.Errorf("got progress %v without tables, want %v", got, want)
+	}
+}
+
+func TestStatus_Reencrypted(t *testing.T) {
+	status := &Status{
+		Table:        Table{Name: "webhook_logs"},
+		CurrentKeyID: "v3",
+		Counts:       map[string]int{"v1": 2, "v2": 1, "v3": 1},
+	}
+
+	status.Reencrypted([]*Row{{ID: 1, KeyID: "v1"}, {ID: 2, KeyID: "v2"}}, "v3")
+
+	if diff := cmp.Diff(map[string]int{"v1": 1, "v3": 3}, status.Counts); diff != "" {
+		t.Errorf("unexpected counts (-want +got):\n%s", diff)
+	}
+	if got, want := status.Progress(), 0.75; got != want {
+		t.Errorf("got progress %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,297 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// client sends requests to the HTTP API of a Vault server, authenticated with a token it
+// obtains from its login function. The token is renewed once half of its TTL has elapsed,
+// and replaced with a new one from login if it cannot be renewed.
+type client struct {
+	doer      httpcli.Doer
+	address   string
+	namespace string
+	login     func(ctx context.Context, c *client) (*token, error)
+	now       func() time.Time
+
+	mu    sync.Mutex
+	token *token
+}
+
+// token is a Vault token along with its lease.
+type token struct {
+	value     string
+	renewable bool
+	// ttl is zero if the token never expires.
+	ttl       time.Duration
+	renewedAt time.Time
+}
+
+// needsRenewal returns true if half of the TTL of the token has elapsed.
+func (t *token) needsRenewal(now time.Time) bool {
+	return t.ttl > 0 && now.Sub(t.renewedAt) >= t.ttl/2
+}
+
+// responseError is an error response of the Vault API.
+type responseError struct {
+	StatusCode int
+	Errors     []string `json:"errors"`
+}
+
+func (e *responseError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("Vault API returned status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("Vault API returned status %d: %s", e.StatusCode, strings.Join(e.Errors, ", "))
+}
+
+// response is the body of the responses of the Vault API. Data is the payload of the
+// secrets engines and Auth the token issued by the auth methods.
+type response struct {
+	Data json.RawMessage `json:"data"`
+	Auth *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+}
+
+// authToken returns the token issued by an auth method in resp.
+func (c *client) authToken(resp *response) (*token, error) {
+	if resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return nil, errors.New("Vault API returned no token")
+	}
+	return &token{
+		value:     resp.Auth.ClientToken,
+		renewable: resp.Auth.Renewable,
+		ttl:       time.Duration(resp.Auth.LeaseDuration) * time.Second,
+		renewedAt: c.now(),
+	}, nil
+}
+
+// do sends a request to the given path of the Vault API, authenticated with the current
+// token. If the request is denied, it is retried once with a new token from login, in case
+// the token was revoked.
+func (c *client) do(ctx context.Context, method, path string, body, data any) error {
+	t, err := c.currentToken(ctx)
+	if err != nil {
+		return err
+	}
+	resp, err := c.send(ctx, method, path, t.value, body)
+	var respErr *responseError
+	if errors.As(err, &respErr) && respErr.StatusCode == http.StatusForbidden {
+		if t, err = c.relogin(ctx, t); err != nil {
+			return err
+		}
+		resp, err = c.send(ctx, method, path, t.value, body)
+	}
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Data, data)
+}
+
+// currentToken returns the current token, logging in or renewing it first if needed.
+func (c *client) currentToken(ctx context.Context) (*token, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != nil && !c.token.needsRenewal(c.now()) {
+		return c.token, nil
+	}
+	if c.token != nil && c.token.renewable {
+		resp, err := c.send(ctx, http.MethodPost, "auth/token/renew-self", c.token.value, nil)
+		if err == nil {
+			if t, err := c.authToken(resp); err == nil {
+				c.token = t
+				return c.token, nil
+			}
+		}
+		// The token might have reached its max TTL: log in again.
+	}
+
+	t, err := c.login(ctx, c)
+	if err != nil {
+		return nil, errors.Wrap(err, "logging in to Vault")
+	}
+	c.token = t
+	return c.token, nil
+}
+
+// relogin replaces the token stale with a new one from login, unless it has been replaced
+// already.
+func (c *client) relogin(ctx context.Context, stale *token) (*token, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != stale {
+		return c.token, nil
+	}
+	t, err := c.login(ctx, c)
+	if err != nil {
+		return nil, errors.Wrap(err, "logging in to Vault")
+	}
+	c.token = t
+	return c.token, nil
+}
+
+// send sends a request to the given path of the Vault API, authenticated with the given
+// token if any.
+func (c *client) send(ctx context.Context, method, path, tokenValue string, body any) (*response, error) {
+	var reqBody io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.address+"/v1/"+path, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if tokenValue != "" {
+		req.Header.Set("X-Vault-Token", tokenValue)
+	}
+	if c.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.namespace)
+	}
+
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respErr := &responseError{StatusCode: resp.StatusCode}
+		_ = json.Unmarshal(respBody, respErr)
+		return nil, respErr
+	}
+
+	var r response
+	if len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, &r); err != nil {
+			return nil, errors.Wrap(err, "decoding Vault API response")
+		}
+	}
+	return &r, nil
+}
+
+// loginFunc returns the function obtaining a token for the auth method of config.
+func loginFunc(config schema.VaultEncryptionKey) (func(ctx context.Context, c *client) (*token, error), error) {
+	if config.AppRole != nil {
+		if config.TokenEnvVarName != "" || config.TokenFilepath != "" {
+			return nil, errors.New("must use only one of appRole, tokenEnvVarName and tokenFilepath")
+		}
+		return appRoleLogin(*config.AppRole)
+	}
+	if (config.TokenEnvVarName == "") == (config.TokenFilepath == "") {
+		return nil, errors.Errorf(
+			"must use exactly one of appRole, tokenEnvVarName and tokenFilepath, tokenEnvVarName: %q, tokenFilepath: %q",
+			config.TokenEnvVarName, config.TokenFilepath,
+		)
+	}
+	return tokenLogin(config.TokenEnvVarName, config.TokenFilepath), nil
+}
+
+// tokenLogin returns a login function for the token auth method. The token is read
+// again on each login, so that it can be replaced, for instance by Vault Agent.
+func tokenLogin(envVarName, filepath string) func(ctx context.Context, c *client) (*token, error) {
+	return func(ctx context.Context, c *client) (*token, error) {
+		value, err := readSecret(envVarName, filepath)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading token")
+		}
+		if value == "" {
+			return nil, errors.New("empty token")
+		}
+
+		// Look up the token to know its TTL.
+		resp, err := c.send(ctx, http.MethodGet, "auth/token/lookup-self", value, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "looking up token")
+		}
+		var data struct {
+			TTL       int  `json:"ttl"`
+			Renewable bool `json:"renewable"`
+		}
+		if err := json.Unmarshal(resp.Data, &data); err != nil {
+			return nil, errors.Wrap(err, "decoding token")
+		}
+		return &token{
+			value:     value,
+			renewable: data.Renewable,
+			ttl:       time.Duration(data.TTL) * time.Second,
+			renewedAt: c.now(),
+		}, nil
+	}
+}
+
+// appRoleLogin returns a login function for the AppRole auth method.
+func appRoleLogin(config schema.VaultAppRole) (func(ctx context.Context, c *client) (*token, error), error) {
+	if config.SecretIdEnvVarName != "" && config.SecretIdFilepath != "" {
+		return nil, errors.Errorf(
+			"must use only one of secretIdEnvVarName and secretIdFilepath, secretIdEnvVarName: %q, secretIdFilepath: %q",
+			config.SecretIdEnvVarName, config.SecretIdFilepath,
+		)
+	}
+	mountPath := config.MountPath
+	if mountPath == "" {
+		mountPath = "approle"
+	}
+
+	return func(ctx context.Context, c *client) (*token, error) {
+		body := map[string]string{"role_id": config.RoleId}
+		if config.SecretIdEnvVarName != "" || config.SecretIdFilepath != "" {
+			secretID, err := readSecret(config.SecretIdEnvVarName, config.SecretIdFilepath)
+			if err != nil {
+				return nil, errors.Wrap(err, "reading AppRole SecretID")
+			}
+			body["secret_id"] = secretID
+		}
+
+		resp, err := c.send(ctx, http.MethodPost, "auth/"+strings.Trim(mountPath, "/")+"/login", "", body)
+		if err != nil {
+			return nil, err
+		}
+		return c.authToken(resp)
+	}, nil
+}
+
+// readSecret reads a secret from the given environment variable, or else from the given
+// file.
+func readSecret(envVarName, filepath string) (string, error) {
+	if envVarName != "" {
+		return os.Getenv(envVarName), nil
+	}
+	buf, err := os.ReadFile(filepath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(buf)), nil
+}
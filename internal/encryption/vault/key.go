@@ -0,0 +1,173 @@
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/encryption"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func NewKey(ctx context.Context, config schema.VaultEncryptionKey) (encryption.Key, error) {
+	return newKey(ctx, config, httpcli.ExternalDoer)
+}
+
+func newKey(ctx context.Context, config schema.VaultEncryptionKey, doer httpcli.Doer) (*Key, error) {
+	login, err := loginFunc(config)
+	if err != nil {
+		return nil, err
+	}
+	mountPath := config.MountPath
+	if mountPath == "" {
+		mountPath = "transit"
+	}
+
+	k := &Key{
+		mountPath: strings.Trim(mountPath, "/"),
+		name:      config.KeyName,
+		client: &client{
+			doer:      doer,
+			address:   strings.TrimSuffix(config.Address, "/"),
+			namespace: config.Namespace,
+			login:     login,
+			now:       time.Now,
+		},
+	}
+	// Test client connection.
+	_, err = k.Version(ctx)
+	return k, err
+}
+
+// Key is an encryption.Key implementation that uses a key of the Transit secrets engine of
+// a HashiCorp Vault server. The plaintext is encrypted by Vault, and the ciphertexts it
+// returns are stored as is. They are prefixed with the version of the key they are
+// encrypted with, so Vault decrypts them with any version of the key that has not been
+// trimmed.
+type Key struct {
+	mountPath string
+	name      string
+	client    *client
+}
+
+var _ encryption.BatchKey = &Key{}
+
+// Version returns the latest version of the key, which Vault encrypts new values with.
+func (k *Key) Version(ctx context.Context) (encryption.KeyVersion, error) {
+	var data struct {
+		LatestVersion int `json:"latest_version"`
+	}
+	if err := k.client.do(ctx, http.MethodGet, k.path("keys"), nil, &data); err != nil {
+		return encryption.KeyVersion{}, errors.Wrap(err, "getting key version")
+	}
+	return encryption.KeyVersion{
+		Type:    "vault",
+		Name:    k.mountPath + "/keys/" + k.name,
+		Version: strconv.Itoa(data.LatestVersion),
+	}, nil
+}
+
+// Encrypt a secret, storing it as the ciphertext returned by Vault, e.g. "vault:v1:...".
+func (k *Key) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	cipherTexts, err := k.EncryptBatch(ctx, [][]byte{plaintext})
+	if err != nil {
+		return nil, err
+	}
+	return cipherTexts[0], nil
+}
+
+// Decrypt a secret, it must have been encrypted with the same Key.
+func (k *Key) Decrypt(ctx context.Context, cipherText []byte) (*encryption.Secret, error) {
+	secrets, err := k.DecryptBatch(ctx, [][]byte{cipherText})
+	if err != nil {
+		return nil, err
+	}
+	return secrets[0], nil
+}
+
+type batchItem struct {
+	Plaintext  string `json:"plaintext,omitempty"`
+	Ciphertext string `json:"ciphertext,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// EncryptBatch encrypts values with a single request to Vault.
+func (k *Key) EncryptBatch(ctx context.Context, values [][]byte) (_ [][]byte, err error) {
+	defer func() {
+		cryptographicTotal.WithLabelValues("encrypt", strconv.FormatBool(err == nil)).Add(float64(len(values)))
+	}()
+
+	input := make([]batchItem, 0, len(values))
+	for _, value := range values {
+		input = append(input, batchItem{Plaintext: base64.StdEncoding.EncodeToString(value)})
+	}
+	results, err := k.batch(ctx, "encrypt", input)
+	if err != nil {
+		return nil, err
+	}
+
+	cipherTexts := make([][]byte, 0, len(results))
+	for _, r := range results {
+		cipherTexts = append(cipherTexts, []byte(r.Ciphertext))
+	}
+	return cipherTexts, nil
+}
+
+// DecryptBatch decrypts cipherTexts with a single request to Vault.
+func (k *Key) DecryptBatch(ctx context.Context, cipherTexts [][]byte) (_ []*encryption.Secret, err error) {
+	defer func() {
+		cryptographicTotal.WithLabelValues("decrypt", strconv.FormatBool(err == nil)).Add(float64(len(cipherTexts)))
+	}()
+
+	input := make([]batchItem, 0, len(cipherTexts))
+	for _, cipherText := range cipherTexts {
+		input = append(input, batchItem{Ciphertext: string(cipherText)})
+	}
+	results, err := k.batch(ctx, "decrypt", input)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make([]*encryption.Secret, 0, len(results))
+	for _, r := range results {
+		plaintext, err := base64.StdEncoding.DecodeString(r.Plaintext)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding plaintext")
+		}
+		s := encryption.NewSecret(string(plaintext))
+		secrets = append(secrets, &s)
+	}
+	return secrets, nil
+}
+
+// batch sends the batch input to the given endpoint of the key, and returns its results.
+// It returns an error if any item failed.
+func (k *Key) batch(ctx context.Context, endpoint string, input []batchItem) ([]batchItem, error) {
+	var data struct {
+		BatchResults []batchItem `json:"batch_results"`
+	}
+	if err := k.client.do(ctx, http.MethodPost, k.path(endpoint), map[string]any{"batch_input": input}, &data); err != nil {
+		return nil, errors.Wrapf(err, "%s request", endpoint)
+	}
+	if len(data.BatchResults) != len(input) {
+		return nil, errors.Errorf("%s request returned %d results for %d values", endpoint, len(data.BatchResults), len(input))
+	}
+
+	var errs error
+	for i, r := range data.BatchResults {
+		if r.Error != "" {
+			errs = errors.Append(errs, errors.Errorf("%s value %d: %s", endpoint, i, r.Error))
+		}
+	}
+	return data.BatchResults, errs
+}
+
+// path returns the path of the given endpoint of the key in the Vault API.
+func (k *Key) path(endpoint string) string {
+	return k.mountPath + "/" + endpoint + "/" + k.name
+}
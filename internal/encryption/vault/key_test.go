@@ -0,0 +1,413 @@
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/encryption"
+	"github.com/sourcegraph/sourcegraph/internal/encryption/cache"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestKey(t *testing.T) {
+	ctx := context.Background()
+	vault := newFakeVault(t)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte(vault.rootToken+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	key, err := newKey(ctx, schema.VaultEncryptionKey{
+		Type:          "vault",
+		Address:       vault.URL + "/",
+		KeyName:       "sourcegraph",
+		TokenFilepath: tokenFile,
+	}, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testKeyRotation(ctx, t, key, vault.rotate)
+
+	t.Run("batch", func(t *testing.T) {
+		cipherTexts, err := key.EncryptBatch(ctx, [][]byte{[]byte("a"), []byte("b"), []byte("")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		requests := vault.requests("encrypt")
+
+		secrets, err := key.DecryptBatch(ctx, cipherTexts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, want := range []string{"a", "b", ""} {
+			if got := secrets[i].Secret(); got != want {
+				t.Errorf("got secret %q at index %d, want %q", got, i, want)
+			}
+		}
+		if got := vault.requests("encrypt") - requests; got != 0 {
+			t.Fatalf("got %d more encrypt requests, want none", got)
+		}
+
+		if _, err := key.DecryptBatch(ctx, [][]byte{cipherTexts[0], []byte("vault:v1:invalid")}); err == nil {
+			t.Fatal("expected an error for an invalid ciphertext")
+		}
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		cached, err := cache.New(key, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cipherTexts, err := encryption.EncryptBatch(ctx, cached, [][]byte{[]byte("a"), []byte("b")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cached.Decrypt(ctx, cipherTexts[0]); err != nil {
+			t.Fatal(err)
+		}
+
+		requests := vault.requests("decrypt")
+		secrets, err := encryption.DecryptBatch(ctx, cached, cipherTexts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if secrets[0].Secret() != "a" || secrets[1].Secret() != "b" {
+			t.Fatalf("got secrets %q and %q, want a and b", secrets[0].Secret(), secrets[1].Secret())
+		}
+		// Only the value missing from the cache is decrypted, with a single request.
+		if got := vault.requests("decrypt") - requests; got != 1 {
+			t.Fatalf("got %d decrypt requests, want 1", got)
+		}
+	})
+}
+
+func TestKey_AppRole(t *testing.T) {
+	ctx := context.Background()
+	vault := newFakeVault(t)
+	t.Setenv("VAULT_TEST_SECRET_ID", "secret-id")
+
+	key, err := newKey(ctx, schema.VaultEncryptionKey{
+		Type:    "vault",
+		Address: vault.URL,
+		KeyName: "sourcegraph",
+		AppRole: &schema.VaultAppRole{
+			RoleId:             "role-id",
+			SecretIdEnvVarName: "VAULT_TEST_SECRET_ID",
+		},
+	}, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	key.client.now = func() time.Time { return now }
+
+	encrypt := func() {
+		t.Helper()
+		if _, err := key.Encrypt(ctx, []byte("secret")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	encrypt()
+	if got := vault.requests("login"); got != 1 {
+		t.Fatalf("got %d logins, want 1", got)
+	}
+
+	// The token is renewed once half of its TTL has elapsed.
+	now = now.Add(vault.tokenTTL / 2)
+	encrypt()
+	if got := vault.requests("renew-self"); got != 1 {
+		t.Fatalf("got %d renewals, want 1", got)
+	}
+
+	// A new token is obtained once the token cannot be renewed anymore.
+	vault.revokeTokens()
+	now = now.Add(vault.tokenTTL)
+	encrypt()
+	if got := vault.requests("login"); got != 2 {
+		t.Fatalf("got %d logins, want 2", got)
+	}
+
+	// Or if it is revoked before.
+	vault.revokeTokens()
+	encrypt()
+	if got := vault.requests("login"); got != 3 {
+		t.Fatalf("got %d logins, want 3", got)
+	}
+
+	t.Setenv("VAULT_TEST_SECRET_ID", "invalid")
+	vault.revokeTokens()
+	if _, err := key.Encrypt(ctx, []byte("secret")); err == nil {
+		t.Fatal("expected an error with an invalid SecretID")
+	}
+}
+
+func TestNewKey_InvalidConfig(t *testing.T) {
+	for name, config := range map[string]schema.VaultEncryptionKey{
+		"no auth":           {},
+		"token and approle": {TokenEnvVarName: "VAULT_TOKEN", AppRole: &schema.VaultAppRole{RoleId: "role-id"}},
+		"token env and file": {
+			TokenEnvVarName: "VAULT_TOKEN",
+			TokenFilepath:   "/vault/token",
+		},
+		"secret env and file": {
+			AppRole: &schema.VaultAppRole{RoleId: "role-id", SecretIdEnvVarName: "SECRET_ID", SecretIdFilepath: "/vault/secret-id"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := newKey(context.Background(), config, http.DefaultClient); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+// TestKey_DevServer runs against a Vault dev server, for instance started with `vault
+// server -dev`, if the VAULT_ADDR and VAULT_TOKEN environment variables are set. It mounts
+// the Transit secrets engine and the AppRole auth method at temporary paths.
+func TestKey_DevServer(t *testing.T) {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" || os.Getenv("VAULT_TOKEN") == "" {
+		t.Skip("VAULT_ADDR and VAULT_TOKEN are not set")
+	}
+	ctx := context.Background()
+
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	transitPath, appRolePath := "transit-"+suffix, "approle-"+suffix
+	root := &client{
+		doer:    http.DefaultClient,
+		address: strings.TrimSuffix(addr, "/"),
+		login:   tokenLogin("VAULT_TOKEN", ""),
+		now:     time.Now,
+	}
+	request := func(method, path string, body, data any) {
+		t.Helper()
+		if err := root.do(ctx, method, path, body, data); err != nil {
+			t.Fatalf("%s %s: %s", method, path, err)
+		}
+	}
+
+	request(http.MethodPost, "sys/mounts/"+transitPath, map[string]any{"type": "transit"}, nil)
+	t.Cleanup(func() { request(http.MethodDelete, "sys/mounts/"+transitPath, nil, nil) })
+	request(http.MethodPost, transitPath+"/keys/sourcegraph", map[string]any{}, nil)
+
+	request(http.MethodPost, "sys/auth/"+appRolePath, map[string]any{"type": "approle"}, nil)
+	t.Cleanup(func() { request(http.MethodDelete, "sys/auth/"+appRolePath, nil, nil) })
+	request(http.MethodPost, "sys/policies/acl/"+transitPath, map[string]any{"policy": fmt.Sprintf(`
+path "%[1]s/keys/sourcegraph" { capabilities = ["read"] }
+path "%[1]s/encrypt/sourcegraph" { capabilities = ["update"] }
+path "%[1]s/decrypt/sourcegraph" { capabilities = ["update"] }
+`, transitPath)}, nil)
+	t.Cleanup(func() { request(http.MethodDelete, "sys/policies/acl/"+transitPath, nil, nil) })
+	request(http.MethodPost, "auth/"+appRolePath+"/role/sourcegraph", map[string]any{"token_policies": []string{transitPath}, "token_ttl": "1h"}, nil)
+
+	var roleID struct {
+		RoleID string `json:"role_id"`
+	}
+	request(http.MethodGet, "auth/"+appRolePath+"/role/sourcegraph/role-id", nil, &roleID)
+	var secretID struct {
+		SecretID string `json:"secret_id"`
+	}
+	request(http.MethodPost, "auth/"+appRolePath+"/role/sourcegraph/secret-id", map[string]any{}, &secretID)
+	secretIDFile := filepath.Join(t.TempDir(), "secret-id")
+	if err := os.WriteFile(secretIDFile, []byte(secretID.SecretID), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := newKey(ctx, schema.VaultEncryptionKey{
+		Type:      "vault",
+		Address:   addr,
+		MountPath: transitPath,
+		KeyName:   "sourcegraph",
+		AppRole: &schema.VaultAppRole{
+			MountPath:        appRolePath,
+			RoleId:           roleID.RoleID,
+			SecretIdFilepath: secretIDFile,
+		},
+	}, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testKeyRotation(ctx, t, key, func() {
+		request(http.MethodPost, transitPath+"/keys/sourcegraph/rotate", map[string]any{}, nil)
+	})
+}
+
+// testKeyRotation checks that values encrypted with the previous version of the key can
+// still be decrypted after rotate rotates it.
+func testKeyRotation(ctx context.Context, t *testing.T, key *Key, rotate func()) {
+	t.Helper()
+
+	v1, err := key.Version(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v1.Type != "vault" || v1.Version != "1" {
+		t.Fatalf("unexpected key version %+v", v1)
+	}
+
+	cipherText, err := key.Encrypt(ctx, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(cipherText) == "secret" {
+		t.Fatal("value was not encrypted")
+	}
+
+	rotate()
+	v2, err := key.Version(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v2.Version != "2" || v2.Name != v1.Name {
+		t.Fatalf("got key version %+v after rotation, want version 2 of %s", v2, v1.Name)
+	}
+
+	secret, err := key.Decrypt(ctx, cipherText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Secret() != "secret" {
+		t.Fatalf("got secret %q, want %q", secret.Secret(), "secret")
+	}
+}
+
+// fakeVault is a fake of the Vault API serving a Transit key named sourcegraph, the token
+// auth method and the AppRole auth method. Values are "encrypted" by base64 encoding them
+// and prefixing them with the key version.
+type fakeVault struct {
+	*httptest.Server
+	rootToken string
+	tokenTTL  time.Duration
+
+	mu         sync.Mutex
+	version    int
+	tokens     map[string]bool
+	nextToken  int
+	requestsBy map[string]int
+}
+
+func newFakeVault(t *testing.T) *fakeVault {
+	v := &fakeVault{
+		rootToken:  "root",
+		tokenTTL:   time.Hour,
+		version:    1,
+		tokens:     map[string]bool{"root": true},
+		requestsBy: map[string]int{},
+	}
+	v.Server = httptest.NewServer(http.HandlerFunc(v.serveHTTP))
+	t.Cleanup(v.Close)
+	return v
+}
+
+func (v *fakeVault) rotate() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.version++
+}
+
+func (v *fakeVault) revokeTokens() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.tokens = map[string]bool{v.rootToken: true}
+}
+
+func (v *fakeVault) requests(endpoint string) int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.requestsBy[endpoint]
+}
+
+func (v *fakeVault) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// Requests are counted by endpoint, e.g. "login" or "encrypt".
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	endpoint := path[strings.LastIndex(path, "/")+1:]
+	if strings.HasPrefix(path, "transit/") {
+		endpoint = strings.Split(path, "/")[1]
+	}
+	v.requestsBy[endpoint]++
+
+	var body struct {
+		RoleID     string      `json:"role_id"`
+		SecretID   string      `json:"secret_id"`
+		BatchInput []batchItem `json:"batch_input"`
+	}
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+	reply := func(status int, resp any) {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+	issueToken := func() {
+		v.nextToken++
+		value := "token-" + strconv.Itoa(v.nextToken)
+		v.tokens[value] = true
+		reply(http.StatusOK, map[string]any{"auth": map[string]any{
+			"client_token":   value,
+			"lease_duration": int(v.tokenTTL.Seconds()),
+			"renewable":      true,
+		}})
+	}
+
+	if path == "auth/approle/login" {
+		if body.RoleID != "role-id" || body.SecretID != "secret-id" {
+			reply(http.StatusBadRequest, map[string]any{"errors": []string{"invalid role or secret ID"}})
+			return
+		}
+		issueToken()
+		return
+	}
+
+	tokenValue := r.Header.Get("X-Vault-Token")
+	if !v.tokens[tokenValue] {
+		reply(http.StatusForbidden, map[string]any{"errors": []string{"permission denied"}})
+		return
+	}
+
+	switch path {
+	case "auth/token/lookup-self":
+		reply(http.StatusOK, map[string]any{"data": map[string]any{"ttl": 0, "renewable": false}})
+	case "auth/token/renew-self":
+		delete(v.tokens, tokenValue)
+		issueToken()
+	case "transit/keys/sourcegraph":
+		reply(http.StatusOK, map[string]any{"data": map[string]any{"latest_version": v.version}})
+	case "transit/encrypt/sourcegraph", "transit/decrypt/sourcegraph":
+		results := make([]batchItem, 0, len(body.BatchInput))
+		for _, item := range body.BatchInput {
+			if endpoint == "encrypt" {
+				results = append(results, batchItem{Ciphertext: fmt.Sprintf("vault:v%d:%s", v.version, item.Plaintext)})
+				continue
+			}
+			parts := strings.SplitN(item.Ciphertext, ":", 3)
+			if len(parts) != 3 {
+				results = append(results, batchItem{Error: "invalid ciphertext"})
+				continue
+			}
+			if _, err := base64.StdEncoding.DecodeString(parts[2]); err != nil {
+				results = append(results, batchItem{Error: "invalid ciphertext"})
+				continue
+			}
+			results = append(results, batchItem{Plaintext: parts[2]})
+		}
+		reply(http.StatusOK, map[string]any{"data": map[string]any{"batch_results": results}})
+	default:
+		reply(http.StatusNotFound, map[string]any{"errors": []string{}})
+	}
+}
@@ -0,0 +1,14 @@
+package vault
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var cryptographicTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "src_vault_cryptographic_total",
+		Help: "Total number of values encrypted or decrypted by Vault",
+	},
+	[]string{"operation", "success"},
+)
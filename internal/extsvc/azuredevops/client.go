@@ -0,0 +1,237 @@
+//nolint:bodyclose // Body is closed in Client.Do, but the response is still returned to provide access to the headers
+package azuredevops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// apiVersion is the version of the REST API used by the client. 5.0 is the
+// latest version supported by Azure DevOps Server 2019, and is still supported
+// by Azure DevOps Services.
+const apiVersion = "5.0"
+
+// continuationTokenHeader is the response header that holds the token of the
+// next page of a paginated list.
+const continuationTokenHeader = "X-Ms-Continuationtoken"
+
+// Client access Azure DevOps Services or Server via the REST API.
+type Client struct {
+	// Config is the code host connection config for this client
+	Config *schema.AzureDevOpsConnection
+
+	// URL is the base URL of Azure DevOps, without an organization.
+	URL *url.URL
+
+	// HTTP Client used to communicate with the API
+	httpClient httpcli.Doer
+
+	// RateLimit is the self-imposed rate limiter (since Azure DevOps only
+	// reports rate limits once requests are already being delayed).
+	rateLimit *ratelimit.InstrumentedLimiter
+}
+
+// NewClient returns an authenticated Azure DevOps API client with
+// the provided configuration. If a nil httpClient is provided, http.DefaultClient
+// will be used.
+func NewClient(urn string, config *schema.AzureDevOpsConnection, httpClient httpcli.Doer) (*Client, error) {
+	u, err := url.Parse(config.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	// Organizations and projects are appended to the base URL, so it must end
+	// with a slash to be resolved against.
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+
+	if httpClient == nil {
+		httpClient = httpcli.ExternalDoer
+	}
+
+	return &Client{
+		Config:     config,
+		URL:        u,
+		httpClient: httpClient,
+		rateLimit:  ratelimit.DefaultRegistry.Get(urn),
+	}, nil
+}
+
+// ListProjectsArgs defines options to be set on ListProjects method calls.
+type ListProjectsArgs struct {
+	// Org is the organization (or collection on Azure DevOps Server) to list
+	// the projects of.
+	Org string
+
+	// Top is the maximum number of projects returned in a page.
+	Top int
+
+	// ContinuationToken is the token of the page to return, as returned by
+	// the previous call. It is empty for the first page.
+	ContinuationToken string
+}
+
+// ListProjectsResponse defines a response struct returned from ListProjects method calls.
+type ListProjectsResponse struct {
+	Projects []*Project
+
+	// ContinuationToken is the token of the next page. It is empty if this is
+	// the last page.
+	ContinuationToken string
+}
+
+// ListProjects returns a page of the projects of an organization.
+func (c *Client) ListProjects(ctx context.Context, opts ListProjectsArgs) (*ListProjectsResponse, error) {
+	qs := make(url.Values)
+	qs.Set("api-version", apiVersion)
+	if opts.Top > 0 {
+		qs.Set("$top", strconv.Itoa(opts.Top))
+	}
+	if opts.ContinuationToken != "" {
+		qs.Set("continuationToken", opts.ContinuationToken)
+	}
+
+	u := url.URL{Path: url.PathEscape(opts.Org) + "/_apis/projects", RawQuery: qs.Encode()}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var list listResponse[*Project]
+	resp, err := c.do(ctx, req, &list)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListProjectsResponse{
+		Projects:          list.Value,
+		ContinuationToken: resp.Header.Get(continuationTokenHeader),
+	}, nil
+}
+
+// ListRepositoriesByProject returns all Git repositories of a project. The
+// repositories of a project are not paginated by the API.
+func (c *Client) ListRepositoriesByProject(ctx context.Context, org, project string) ([]*Repository, error) {
+	qs := make(url.Values)
+	qs.Set("api-version", apiVersion)
+
+	u := url.URL{
+		Path:     url.PathEscape(org) + "/" + url.PathEscape(project) + "/_apis/git/repositories",
+		RawQuery: qs.Encode(),
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var list listResponse[*Repository]
+	if _, err = c.do(ctx, req, &list); err != nil {
+		return nil, err
+	}
+
+	return list.Value, nil
+}
+
+func (c *Client) do(ctx context.Context, req *http.Request, result any) (*http.Response, error) {
+	req.URL = c.URL.ResolveReference(req.URL)
+	if req.Header.Get("Content-Type") == "" && req.Method != "GET" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	// Personal access tokens are sent as the password of basic authentication;
+	// the username is ignored by Azure DevOps.
+	if c.Config.Token != "" {
+		req.SetBasicAuth(c.Config.Username, c.Config.Token)
+	}
+
+	if err := c.rateLimit.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	bs, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Azure DevOps redirects unauthenticated requests to a sign-in page
+	// instead of answering with a 401, which surfaces as a 203 with an HTML
+	// body.
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 || resp.StatusCode == http.StatusNonAuthoritativeInfo {
+		return nil, errors.WithStack(&httpError{
+			URL:        req.URL,
+			StatusCode: resp.StatusCode,
+			Body:       bs,
+		})
+	}
+
+	return resp, json.Unmarshal(bs, result)
+}
+
+// listResponse is the envelope of all list responses of the API.
+type listResponse[T any] struct {
+	Count int `json:"count"`
+	Value []T `json:"value"`
+}
+
+type Project struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
+	State       string `json:"state"`
+	Visibility  string `json:"visibility"`
+}
+
+type Repository struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	URL           string  `json:"url"`
+	Project       Project `json:"project"`
+	DefaultBranch string  `json:"defaultBranch,omitempty"`
+	Size          int64   `json:"size"`
+	RemoteURL     string  `json:"remoteUrl"`
+	SSHURL        string  `json:"sshUrl"`
+	WebURL        string  `json:"webUrl"`
+	IsDisabled    bool    `json:"isDisabled"`
+	IsFork        bool    `json:"isFork"`
+}
+
+type httpError struct {
+	StatusCode int
+	URL        *url.URL
+	Body       []byte
+}
+
+func (e *httpError) Error() string {
+	return fmt.Sprintf("Azure DevOps API HTTP error: code=%d url=%q body=%q", e.StatusCode, e.URL, e.Body)
+}
+
+func (e *httpError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusNonAuthoritativeInfo
+}
+
+func (e *httpError) NotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
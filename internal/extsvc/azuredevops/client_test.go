@@ -0,0 +1,59 @@
+package azuredevops
+
+import (
+	"context"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/testutil"
+)
+
+var update = flag.Bool("update", false, "update testdata")
+
+func TestClient_ListProjects(t *testing.T) {
+	cli, save := NewTestClient(t, "ListProjects", *update)
+	defer save()
+
+	ctx := context.Background()
+
+	args := ListProjectsArgs{Org: "sgtest", Top: 2}
+
+	var pages []*ListProjectsResponse
+	for {
+		resp, err := cli.ListProjects(ctx, args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, resp)
+
+		if resp.ContinuationToken == "" {
+			break
+		}
+		args.ContinuationToken = resp.ContinuationToken
+	}
+
+	testutil.AssertGolden(t, "testdata/golden/ListProjects.json", *update, pages)
+}
+
+func TestClient_ListRepositoriesByProject(t *testing.T) {
+	cli, save := NewTestClient(t, "ListRepositoriesByProject", *update)
+	defer save()
+
+	repos, err := cli.ListRepositoriesByProject(context.Background(), "sgtest", "backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.AssertGolden(t, "testdata/golden/ListRepositoriesByProject.json", *update, repos)
+}
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if !testing.Verbose() {
+		log15.Root().SetHandler(log15.LvlFilterHandler(log15.LvlError, log15.Root().GetHandler()))
+	}
+	os.Exit(m.Run())
+}
@@ -0,0 +1,6 @@
+package subversion
+
+// Repository contains information of a Subversion repository.
+type Repository struct {
+	Path string `json:"path"` // Path is the path of the repository relative to the root URL of the server.
+}
@@ -75,6 +75,7 @@ const (
 	// in preference to the Type values below.
 
 	KindAWSCodeCommit   = "AWSCODECOMMIT"
+	KindAzureDevOps     = "AZUREDEVOPS"
 	KindBitbucketServer = "BITBUCKETSERVER"
 	KindBitbucketCloud  = "BITBUCKETCLOUD"
 	KindGerrit          = "GERRIT"
@@ -88,6 +89,7 @@ const (
 	KindPythonPackages  = "PYTHONPACKAGES"
 	KindRustPackages    = "RUSTPACKAGES"
 	KindPagure          = "PAGURE"
+	KindSubversion      = "SUBVERSION"
 	KindNpmPackages     = "NPMPACKAGES"
 	KindOther           = "OTHER"
 )
@@ -100,6 +102,10 @@ const (
 	// suffix (e.g., "arn:aws:codecommit:us-west-1:123456789:").
 	TypeAWSCodeCommit = "awscodecommit"
 
+	// TypeAzureDevOps is the (api.ExternalRepoSpec).ServiceType value for Azure DevOps repositories. The
+	// ServiceID value is the base URL to Azure DevOps Services or Server, without an organization.
+	TypeAzureDevOps = "azureDevOps"
+
 	// TypeBitbucketServer is the (api.ExternalRepoSpec).ServiceType value for Bitbucket Server projects. The
 	// ServiceID value is the base URL to the Bitbucket Server instance.
 	TypeBitbucketServer = "bitbucketServer"
@@ -134,6 +140,10 @@ const (
 	// TypePagure is the (api.ExternalRepoSpec).ServiceType value for Pagure projects.
 	TypePagure = "pagure"
 
+	// TypeSubversion is the (api.ExternalRepoSpec).ServiceType value for Subversion repositories. The
+	// ServiceID value is the normalized root URL of the Subversion server.
+	TypeSubversion = "subversion"
+
 	// TypeNpmPackages is the (api.ExternalRepoSpec).ServiceType value for Npm packages (JavaScript/TypeScript ecosystem libraries).
 	TypeNpmPackages = "npmPackages"
 
@@ -156,6 +166,8 @@ func KindToType(kind string) string {
 	switch kind {
 	case KindAWSCodeCommit:
 		return TypeAWSCodeCommit
+	case KindAzureDevOps:
+		return TypeAzureDevOps
 	case KindBitbucketServer:
 		return TypeBitbucketServer
 	case KindBitbucketCloud:
@@ -184,6 +196,8 @@ func KindToType(kind string) string {
 		return TypeGoModules
 	case KindPagure:
 		return TypePagure
+	case KindSubversion:
+		return TypeSubversion
 	case KindOther:
 		return TypeOther
 	default:
@@ -197,6 +211,8 @@ func TypeToKind(t string) string {
 	switch t {
 	case TypeAWSCodeCommit:
 		return KindAWSCodeCommit
+	case TypeAzureDevOps:
+		return KindAzureDevOps
 	case TypeBitbucketServer:
 		return KindBitbucketServer
 	case TypeBitbucketCloud:
@@ -225,6 +241,8 @@ func TypeToKind(t string) string {
 		return KindGoModules
 	case TypePagure:
 		return KindPagure
+	case TypeSubversion:
+		return KindSubversion
 	case TypeOther:
 		return KindOther
 	default:
@@ -234,6 +252,7 @@ func TypeToKind(t string) string {
 
 var (
 	// Precompute these for use in ParseServiceType below since the constants are mixed case
+	adoLower    = strings.ToLower(TypeAzureDevOps)
 	bbsLower    = strings.ToLower(TypeBitbucketServer)
 	bbcLower    = strings.ToLower(TypeBitbucketCloud)
 	jvmLower    = strings.ToLower(TypeJVMPackages)
@@ -249,6 +268,8 @@ func ParseServiceType(s string) (string, bool) {
 	switch strings.ToLower(s) {
 	case TypeAWSCodeCommit:
 		return TypeAWSCodeCommit, true
+	case adoLower:
+		return TypeAzureDevOps, true
 	case bbsLower:
 		return TypeBitbucketServer, true
 	case bbcLower:
@@ -277,6 +298,8 @@ func ParseServiceType(s string) (string, bool) {
 		return TypeRustPackages, true
 	case TypePagure:
 		return TypePagure, true
+	case TypeSubversion:
+		return TypeSubversion, true
 	case TypeOther:
 		return TypeOther, true
 	default:
@@ -290,6 +313,8 @@ func ParseServiceKind(s string) (string, bool) {
 	switch strings.ToUpper(s) {
 	case KindAWSCodeCommit:
 		return KindAWSCodeCommit, true
+	case KindAzureDevOps:
+		return KindAzureDevOps, true
 	case KindBitbucketServer:
 		return KindBitbucketServer, true
 	case KindBitbucketCloud:
@@ -316,6 +341,8 @@ func ParseServiceKind(s string) (string, bool) {
 		return KindRustPackages, true
 	case KindPagure:
 		return KindPagure, true
+	case KindSubversion:
+		return KindSubversion, true
 	case KindOther:
 		return KindOther, true
 	default:
@@ -341,6 +368,8 @@ func ParseConfig(kind, config string) (cfg any, _ error) {
 	switch strings.ToUpper(kind) {
 	case KindAWSCodeCommit:
 		cfg = &schema.AWSCodeCommitConnection{}
+	case KindAzureDevOps:
+		cfg = &schema.AzureDevOpsConnection{}
 	case KindBitbucketServer:
 		cfg = &schema.BitbucketServerConnection{}
 	case KindBitbucketCloud:
@@ -369,6 +398,8 @@ func ParseConfig(kind, config string) (cfg any, _ error) {
 		cfg = &schema.PythonPackagesConnection{}
 	case KindRustPackages:
 		cfg = &schema.RustPackagesConnection{}
+	case KindSubversion:
+		cfg = &schema.SubversionConnection{}
 	case KindOther:
 		cfg = &schema.OtherExternalServiceConnection{}
 	default:
@@ -427,6 +458,8 @@ func ExtractToken(config string, kind string) (string, error) {
 		return c.Token, nil
 	case *schema.PagureConnection:
 		return c.Token, nil
+	case *schema.AzureDevOpsConnection:
+		return c.Token, nil
 	default:
 		return "", errors.Errorf("unable to extract token for service kind %q", kind)
 	}
@@ -496,6 +529,12 @@ func GetLimitFromConfig(kind string, config any) (rate.Limit, error) {
 		if c != nil && c.RateLimit != nil {
 			limit = limitOrInf(c.RateLimit.Enabled, c.RateLimit.RequestsPerHour)
 		}
+	case *schema.AzureDevOpsConnection:
+		// 8/s is the default limit we enforce
+		limit = rate.Limit(8)
+		if c != nil && c.RateLimit != nil {
+			limit = limitOrInf(c.RateLimit.Enabled, c.RateLimit.RequestsPerHour)
+		}
 	case *schema.NpmPackagesConnection:
 		limit = rate.Limit(6000 / 3600.0) // Same as the default in npm-packages.schema.json
 		if c != nil && c.RateLimit != nil {
@@ -631,6 +670,10 @@ func UniqueCodeHostIdentifier(kind, config string) (string, error) {
 		return KindRustPackages, nil
 	case *schema.PagureConnection:
 		rawURL = c.Url
+	case *schema.AzureDevOpsConnection:
+		rawURL = c.Url
+	case *schema.SubversionConnection:
+		rawURL = c.Url
 	default:
 		return "", errors.Errorf("unknown external service kind: %s", kind)
 	}
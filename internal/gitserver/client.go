@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/binary"
 	"encoding/gob"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -214,6 +216,10 @@ type Client interface {
 	// RemoveFrom removes the repository clone from the given gitserver.
 	RemoveFrom(ctx context.Context, repo api.RepoName, from string) error
 
+	// RefsChecksumFrom returns a checksum of all refs of the repository clone on the given
+	// gitserver. Two clones have the same checksum if their refs point to the same objects.
+	RefsChecksumFrom(ctx context.Context, repo api.RepoName, from string) (string, error)
+
 	// RendezvousAddrForRepo returns the gitserver address to use for the given
 	// repo name using the Rendezvous hashing scheme.
 	RendezvousAddrForRepo(api.RepoName) string
@@ -327,20 +333,61 @@ func AddrForRepo(ctx context.Context, userAgent string, db database.DB, repo api
 	addrForRepoInvoked.WithLabelValues(userAgent).Inc()
 
 	repo = protocol.NormalizeRepo(repo) // in case the caller didn't already normalize it
-	rs := string(repo)
 	if repoPinned, addr := getPinnedRepoAddr(string(repo), addresses.PinnedServers); repoPinned {
 		return addr, nil
 	}
 
+	if conf.ExperimentalFeatures().GitServerRebalancing {
+		r, err := migration.GetRebalance(ctx, db)
+		if err != nil {
+			return "", err
+		}
+		if r != nil {
+			return rebalanceAddrForRepo(ctx, db, repo, r, addresses.Addresses)
+		}
+	}
+
+	return ShardAddrForRepo(ctx, db, repo, addresses.Addresses)
+}
+
+// ShardAddrForRepo returns the address of the gitserver instance owning the given repo
+// according to the hashing scheme alone, without taking pinned repositories or a rebalance
+// in progress into account.
+func ShardAddrForRepo(ctx context.Context, db database.DB, repo api.RepoName, addrs []string) (string, error) {
+	rs := string(protocol.NormalizeRepo(repo))
 	useRendezvous, err := shouldUseRendezvousHashing(ctx, db, rs)
 	if err != nil {
 		return "", err
 	}
 	if useRendezvous {
-		return RendezvousAddrForRepo(repo, addresses.Addresses), nil
+		return RendezvousAddrForRepo(repo, addrs), nil
+	}
+
+	return addrForKey(rs, addrs), nil
+}
+
+// rebalanceAddrForRepo returns the address of the gitserver instance which currently stores
+// the given repo while repositories are moved between instances. Repositories which have not
+// been moved yet are routed to their previous owner, unless that instance no longer exists.
+//
+// If the topology changed again after r finished, repositories keep being routed to their
+// owner in r.TargetAddrs until the rebalancer starts moving them.
+func rebalanceAddrForRepo(ctx context.Context, db database.DB, repo api.RepoName, r *migration.Rebalance, addrs []string) (string, error) {
+	owners := r.TargetAddrs
+	if !r.Finished() && string(repo) > r.Cursor {
+		owners = r.PreviousAddrs
 	}
 
-	return addrForKey(rs, addresses.Addresses), nil
+	addr, err := ShardAddrForRepo(ctx, db, repo, owners)
+	if err != nil {
+		return "", err
+	}
+	for _, a := range addrs {
+		if a == addr {
+			return addr, nil
+		}
+	}
+	return ShardAddrForRepo(ctx, db, repo, addrs)
 }
 
 type GitServerAddresses struct {
@@ -1292,6 +1339,23 @@ func (c *ClientImplementor) RemoveFrom(ctx context.Context, repo api.RepoName, f
 	return nil
 }
 
+func (c *ClientImplementor) RefsChecksumFrom(ctx context.Context, repo api.RepoName, from string) (string, error) {
+	cmd := &RemoteGitCommand{
+		repo: repo,
+		execFn: func(ctx context.Context, repo api.RepoName, op string, payload any) (*http.Response, error) {
+			return c.httpPostWithURI(ctx, repo, "http://"+from+"/"+op, payload)
+		},
+		args: []string{git, "for-each-ref", "--format=%(objectname) %(refname)"},
+	}
+	out, err := cmd.Output(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(out)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 // httpPost will apply the MD5 hashing scheme on the repo name to determine the gitserver instance
 // to which the HTTP POST request is sent. To use the rendezvous hashing scheme, see
 // httpPostWithURI.
@@ -18,6 +18,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
@@ -653,6 +654,90 @@ func TestClient_AddrForRepo_Rendezvous(t *testing.T) {
 	}
 }
 
+func TestClient_AddrForRepo_Rebalance(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	inProgress := &migration.Rebalance{
+		PreviousAddrs: []string{"gitserver-1", "gitserver-2"},
+		TargetAddrs:   []string{"gitserver-1", "gitserver-2", "gitserver-3"},
+		Cursor:        "repo-m",
+	}
+	finished := &migration.Rebalance{
+		PreviousAddrs: []string{"gitserver-1"},
+		TargetAddrs:   []string{"gitserver-1", "gitserver-2"},
+		FinishedAt:    &now,
+	}
+
+	tests := []struct {
+		name      string
+		disabled  bool
+		rebalance *migration.Rebalance
+		addrs     []string
+		repoName  api.RepoName
+		wantAddr  string
+	}{
+		{
+			name:      "Moved repos are routed to their new owner",
+			rebalance: inProgress,
+			addrs:     []string{"gitserver-1", "gitserver-2", "gitserver-3"},
+			repoName:  "repo-a",
+			wantAddr:  "gitserver-3",
+		},
+		{
+			name:      "Not yet moved repos are routed to their previous owner",
+			rebalance: inProgress,
+			addrs:     []string{"gitserver-1", "gitserver-2", "gitserver-3"},
+			repoName:  "repo-x",
+			wantAddr:  "gitserver-1",
+		},
+		{
+			name:      "Repos are routed to their previous owner until the rebalance started",
+			rebalance: finished,
+			addrs:     []string{"gitserver-1", "gitserver-2", "gitserver-3"},
+			repoName:  "repo-a",
+			wantAddr:  "gitserver-2",
+		},
+		{
+			name:      "Repos of removed instances are routed to their new owner",
+			rebalance: finished,
+			addrs:     []string{"gitserver-1", "gitserver-3"},
+			repoName:  "repo-y",
+			wantAddr:  "gitserver-3",
+		},
+		{
+			name:      "Rebalance is ignored if disabled",
+			disabled:  true,
+			rebalance: inProgress,
+			addrs:     []string{"gitserver-1", "gitserver-2", "gitserver-3"},
+			repoName:  "repo-x",
+			wantAddr:  "gitserver-2",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				ExperimentalFeatures: &schema.ExperimentalFeatures{
+					GitServerRebalancing: !tc.disabled,
+				},
+			}})
+			defer conf.Mock(nil)
+			migration.MigrationMocks.GetRebalance = func(ctx context.Context, db dbutil.DB) (*migration.Rebalance, error) {
+				return tc.rebalance, nil
+			}
+			defer migration.ResetMigrationMocks()
+
+			client := gitserver.NewTestClient(&http.Client{}, database.NewMockDB(), tc.addrs)
+			addr, err := client.AddrForRepo(ctx, tc.repoName)
+			if err != nil {
+				t.Fatal("Error during getting gitserver address")
+			}
+			require.Equal(t, tc.wantAddr, addr)
+		})
+	}
+}
+
 func TestClient_BatchLog(t *testing.T) {
 	addrs := []string{"172.16.8.1:8080", "172.16.8.2:8080", "172.16.8.3:8080"}
 
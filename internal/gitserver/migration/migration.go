@@ -9,7 +9,8 @@ import (
 )
 
 var MigrationMocks, emptyMigrationMocks struct {
-	GetCursor func(ctx context.Context, db dbutil.DB) (string, error)
+	GetCursor    func(ctx context.Context, db dbutil.DB) (string, error)
+	GetRebalance func(ctx context.Context, db dbutil.DB) (*Rebalance, error)
 }
 
 // ResetMigrationMocks clears the mock functions set on Mocks (so that subsequent
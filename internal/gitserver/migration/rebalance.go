@@ -0,0 +1,317 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+// Rebalance describes the move of repositories between gitserver instances after the list of
+// gitserver addresses changed.
+//
+// Repositories are moved in the byte-wise order of their normalized names. Cursor is the name of
+// the last repository up to which every move has been completed, so clients route repositories
+// with a name less than or equal to Cursor with TargetAddrs and all others with PreviousAddrs.
+type Rebalance struct {
+	ID            int
+	PreviousAddrs []string
+	TargetAddrs   []string
+	Cursor        string
+	TotalRepos    int
+	MovedRepos    int
+	FailedRepos   int
+	StartedAt     time.Time
+	UpdatedAt     time.Time
+	FinishedAt    *time.Time
+}
+
+// Finished returns true if there are no more repositories left to move.
+func (r *Rebalance) Finished() bool {
+	return r.FinishedAt != nil
+}
+
+// Settled returns true if the rebalance finished and addrs is the topology it moved
+// repositories to. If it returns false, repositories are being moved or will be moved as soon
+// as the rebalancer notices the new topology.
+func (r *Rebalance) Settled(addrs []string) bool {
+	return r.Finished() && equalAddrs(r.TargetAddrs, addrs)
+}
+
+func equalAddrs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// RebalanceMove is a repository which is owned by a different gitserver instance after the
+// topology changed.
+type RebalanceMove struct {
+	RepoID api.RepoID
+	Name   api.RepoName
+	From   string
+	To     string
+}
+
+var rebalanceCache struct {
+	sync.Mutex
+	value     *Rebalance
+	fetchedAt time.Time
+}
+
+// rebalanceCacheTTL is how long GetRebalance reuses the state it read from the database. The
+// old copy of a moved repository is only removed once the whole rebalance finished, so routing
+// based on slightly stale state is safe.
+const rebalanceCacheTTL = 10 * time.Second
+
+// GetRebalance returns the most recent rebalance, or nil if the topology has never been
+// recorded. The result is cached for a few seconds since it is consulted every time a
+// gitserver address is computed.
+func GetRebalance(ctx context.Context, db dbutil.DB) (*Rebalance, error) {
+	if MigrationMocks.GetRebalance != nil {
+		return MigrationMocks.GetRebalance(ctx, db)
+	}
+
+	rebalanceCache.Lock()
+	defer rebalanceCache.Unlock()
+
+	if time.Since(rebalanceCache.fetchedAt) < rebalanceCacheTTL {
+		return rebalanceCache.value, nil
+	}
+
+	r, err := NewRebalanceStore(db).Latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rebalanceCache.value, rebalanceCache.fetchedAt = r, time.Now()
+	return r, nil
+}
+
+// RebalanceStore persists the progress of moving repositories between gitserver instances.
+type RebalanceStore struct {
+	*basestore.Store
+}
+
+// NewRebalanceStore returns a new RebalanceStore backed by the given database.
+func NewRebalanceStore(db dbutil.DB) *RebalanceStore {
+	return &RebalanceStore{Store: basestore.NewWithHandle(basestore.NewHandleWithDB(db, sql.TxOptions{}))}
+}
+
+// With creates a new store with the underlying database handle from the given store.
+func (s *RebalanceStore) With(other basestore.ShareableStore) *RebalanceStore {
+	return &RebalanceStore{Store: s.Store.With(other)}
+}
+
+// Transact returns a new store whose methods operate within the context of a new transaction.
+func (s *RebalanceStore) Transact(ctx context.Context) (*RebalanceStore, error) {
+	txBase, err := s.Store.Transact(ctx)
+	return &RebalanceStore{Store: txBase}, err
+}
+
+const rebalanceColumns = `id, previous_addrs, target_addrs, cursor, total_repos, moved_repos, failed_repos, started_at, updated_at, finished_at`
+
+func scanRebalance(sc dbutil.Scanner) (*Rebalance, error) {
+	var r Rebalance
+	if err := sc.Scan(
+		&r.ID,
+		pq.Array(&r.PreviousAddrs),
+		pq.Array(&r.TargetAddrs),
+		&r.Cursor,
+		&r.TotalRepos,
+		&r.MovedRepos,
+		&r.FailedRepos,
+		&r.StartedAt,
+		&r.UpdatedAt,
+		&r.FinishedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Latest returns the most recent rebalance, or nil if there is none.
+func (s *RebalanceStore) Latest(ctx context.Context) (*Rebalance, error) {
+	r, err := scanRebalance(s.QueryRow(ctx, sqlf.Sprintf(`
+-- source: internal/gitserver/migration/rebalance.go:RebalanceStore.Latest
+SELECT `+rebalanceColumns+` FROM gitserver_rebalances ORDER BY id DESC LIMIT 1
+`)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return r, err
+}
+
+// Record stores addrs as the current topology without moving any repositories. It is used
+// when the rebalancer sees a topology for the first time.
+func (s *RebalanceStore) Record(ctx context.Context, addrs []string) (*Rebalance, error) {
+	return scanRebalance(s.QueryRow(ctx, sqlf.Sprintf(`
+-- source: internal/gitserver/migration/rebalance.go:RebalanceStore.Record
+INSERT INTO gitserver_rebalances (previous_addrs, target_addrs, finished_at)
+VALUES (%s, %s, NOW())
+RETURNING `+rebalanceColumns+`
+`, pq.Array(addrs), pq.Array(addrs))))
+}
+
+// Start creates a new rebalance from previous to target and enqueues a relocator job for every
+// move, in the order of the repository names. The rebalance is finished right away if there is
+// nothing to move.
+func (s *RebalanceStore) Start(ctx context.Context, previous, target []string, moves []RebalanceMove) (_ *Rebalance, err error) {
+	tx, err := s.Transact(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = tx.Done(err) }()
+
+	finishedAt := sqlf.Sprintf("NULL")
+	if len(moves) == 0 {
+		finishedAt = sqlf.Sprintf("NOW()")
+	}
+	r, err := scanRebalance(tx.QueryRow(ctx, sqlf.Sprintf(`
+-- source: internal/gitserver/migration/rebalance.go:RebalanceStore.Start
+INSERT INTO gitserver_rebalances (previous_addrs, target_addrs, total_repos, finished_at)
+VALUES (%s, %s, %s, %s)
+RETURNING `+rebalanceColumns+`
+`, pq.Array(previous), pq.Array(target), len(moves), finishedAt)))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(moves, func(i, j int) bool {
+		return protocol.NormalizeRepo(moves[i].Name) < protocol.NormalizeRepo(moves[j].Name)
+	})
+
+	const batchSize = 1000
+	for len(moves) > 0 {
+		n := batchSize
+		if len(moves) < n {
+			n = len(moves)
+		}
+
+		values := make([]*sqlf.Query, 0, n)
+		for _, m := range moves[:n] {
+			values = append(values, sqlf.Sprintf("(%s, %s, %s, false, %s)", m.RepoID, m.From, m.To, r.ID))
+		}
+		if err := tx.Exec(ctx, sqlf.Sprintf(`
+-- source: internal/gitserver/migration/rebalance.go:RebalanceStore.Start
+INSERT INTO gitserver_relocator_jobs (repo_id, source_hostname, dest_hostname, delete_source, rebalance_id)
+VALUES %s
+`, sqlf.Join(values, ","))); err != nil {
+			return nil, err
+		}
+
+		moves = moves[n:]
+	}
+
+	return r, nil
+}
+
+type rebalanceJobState struct {
+	name  api.RepoName
+	state string
+}
+
+// Refresh recomputes the cursor and the counters of the rebalance from the state of its
+// relocator jobs and marks it finished once every job either completed or failed for good.
+func (s *RebalanceStore) Refresh(ctx context.Context, id int) (*Rebalance, error) {
+	rows, err := s.Query(ctx, sqlf.Sprintf(`
+-- source: internal/gitserver/migration/rebalance.go:RebalanceStore.Refresh
+SELECT r.name, j.state
+FROM gitserver_relocator_jobs j
+JOIN repo r ON r.id = j.repo_id
+WHERE j.rebalance_id = %s
+`, id))
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []rebalanceJobState
+	for rows.Next() {
+		var j rebalanceJobState
+		if err := rows.Scan(&j.name, &dbutil.NullString{S: &j.state}); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	if err := basestore.CloseRows(rows, rows.Err()); err != nil {
+		return nil, err
+	}
+
+	p := rebalanceProgress(jobs)
+
+	finishedAt := sqlf.Sprintf("NULL")
+	if p.moved+p.failed == p.total {
+		finishedAt = sqlf.Sprintf("NOW()")
+	}
+	return scanRebalance(s.QueryRow(ctx, sqlf.Sprintf(`
+-- source: internal/gitserver/migration/rebalance.go:RebalanceStore.Refresh
+UPDATE gitserver_rebalances
+SET cursor = %s, total_repos = %s, moved_repos = %s, failed_repos = %s, updated_at = NOW(), finished_at = %s
+WHERE id = %s
+RETURNING `+rebalanceColumns+`
+`, p.cursor, p.total, p.moved, p.failed, finishedAt, id)))
+}
+
+type progress struct {
+	cursor               string
+	total, moved, failed int
+}
+
+// rebalanceProgress computes the cursor and counters of a rebalance. Failed jobs advance the
+// cursor as well: their repository is cloned from the code host by the new owner on demand
+// instead of blocking the rest of the rebalance.
+func rebalanceProgress(jobs []rebalanceJobState) progress {
+	names := make([]string, len(jobs))
+	for i, j := range jobs {
+		names[i] = string(protocol.NormalizeRepo(j.name))
+	}
+	sort.Sort(byName{jobs: jobs, names: names})
+
+	p := progress{total: len(jobs)}
+	contiguous := true
+	for i, j := range jobs {
+		done := true
+		switch j.state {
+		case "completed":
+			p.moved++
+		case "failed":
+			p.failed++
+		default:
+			done = false
+		}
+
+		if !done {
+			contiguous = false
+		} else if contiguous {
+			p.cursor = names[i]
+		}
+	}
+	return p
+}
+
+type byName struct {
+	jobs  []rebalanceJobState
+	names []string
+}
+
+func (s byName) Len() int           { return len(s.jobs) }
+func (s byName) Less(i, j int) bool { return s.names[i] < s.names[j] }
+func (s byName) Swap(i, j int) {
+	s.jobs[i], s.jobs[j] = s.jobs[j], s.jobs[i]
+	s.names[i], s.names[j] = s.names[j], s.names[i]
+}
@@ -0,0 +1,78 @@
+package migration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRebalanceProgress(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		jobs []rebalanceJobState
+		want progress
+	}{
+		{
+			name: "nothing done",
+			jobs: []rebalanceJobState{
+				{name: "github.com/foo/b", state: "queued"},
+				{name: "github.com/foo/a", state: "processing"},
+			},
+			want: progress{total: 2},
+		},
+		{
+			name: "contiguous prefix",
+			jobs: []rebalanceJobState{
+				{name: "github.com/foo/c", state: "completed"},
+				{name: "github.com/foo/a", state: "completed"},
+				{name: "github.com/foo/b", state: "errored"},
+				{name: "github.com/foo/d", state: "failed"},
+			},
+			want: progress{cursor: "github.com/foo/a", total: 4, moved: 2, failed: 1},
+		},
+		{
+			name: "failed jobs advance the cursor",
+			jobs: []rebalanceJobState{
+				{name: "github.com/foo/a", state: "failed"},
+				{name: "github.com/foo/b", state: "completed"},
+			},
+			want: progress{cursor: "github.com/foo/b", total: 2, moved: 1, failed: 1},
+		},
+		{
+			name: "normalized names",
+			jobs: []rebalanceJobState{
+				{name: "GitHub.com/foo/B.git", state: "completed"},
+				{name: "github.com/foo/a", state: "completed"},
+			},
+			want: progress{cursor: "github.com/foo/b", total: 2, moved: 2},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := rebalanceProgress(tc.jobs)
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(progress{})); diff != "" {
+				t.Errorf("unexpected progress (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRebalanceSettled(t *testing.T) {
+	now := time.Now()
+
+	r := &Rebalance{PreviousAddrs: []string{"a"}, TargetAddrs: []string{"a", "b"}}
+	if r.Settled([]string{"a", "b"}) {
+		t.Error("expected rebalance in progress not to be settled")
+	}
+
+	r.FinishedAt = &now
+	if !r.Settled([]string{"a", "b"}) {
+		t.Error("expected finished rebalance to be settled")
+	}
+	if r.Settled([]string{"a", "b", "c"}) {
+		t.Error("expected rebalance not to be settled after the topology changed")
+	}
+	if r.Settled([]string{"b", "a"}) {
+		t.Error("expected rebalance not to be settled after the order of addresses changed")
+	}
+}
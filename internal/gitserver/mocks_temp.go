@@ -75,6 +75,9 @@ type MockClient struct {
 	// ReadDirFunc is an instance of a mock function object controlling the
 	// behavior of the method ReadDir.
 	ReadDirFunc *ClientReadDirFunc
+	// RefsChecksumFromFunc is an instance of a mock function object
+	// controlling the behavior of the method RefsChecksumFrom.
+	RefsChecksumFromFunc *ClientRefsChecksumFromFunc
 	// RemoveFunc is an instance of a mock function object controlling the
 	// behavior of the method Remove.
 	RemoveFunc *ClientRemoveFunc
@@ -194,6 +197,11 @@ func NewMockClient() *MockClient {
 				return
 			},
 		},
+		RefsChecksumFromFunc: &ClientRefsChecksumFromFunc{
+			defaultHook: func(context.Context, api.RepoName, string) (r0 string, r1 error) {
+				return
+			},
+		},
 		RemoveFunc: &ClientRemoveFunc{
 			defaultHook: func(context.Context, api.RepoName) (r0 error) {
 				return
@@ -336,6 +344,11 @@ func NewStrictMockClient() *MockClient {
 				panic("unexpected invocation of MockClient.ReadDir")
 			},
 		},
+		RefsChecksumFromFunc: &ClientRefsChecksumFromFunc{
+			defaultHook: func(context.Context, api.RepoName, string) (string, error) {
+				panic("unexpected invocation of MockClient.RefsChecksumFrom")
+			},
+		},
 		RemoveFunc: &ClientRemoveFunc{
 			defaultHook: func(context.Context, api.RepoName) error {
 				panic("unexpected invocation of MockClient.Remove")
@@ -446,6 +459,9 @@ func NewMockClientFrom(i Client) *MockClient {
 		ReadDirFunc: &ClientReadDirFunc{
 			defaultHook: i.ReadDir,
 		},
+		RefsChecksumFromFunc: &ClientRefsChecksumFromFunc{
+			defaultHook: i.RefsChecksumFrom,
+		},
 		RemoveFunc: &ClientRemoveFunc{
 			defaultHook: i.Remove,
 		},
@@ -2253,6 +2269,117 @@ func (c ClientReadDirFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// ClientRefsChecksumFromFunc describes the behavior when the
+// RefsChecksumFrom method of the parent MockClient instance is invoked.
+type ClientRefsChecksumFromFunc struct {
+	defaultHook func(context.Context, api.RepoName, string) (string, error)
+	hooks       []func(context.Context, api.RepoName, string) (string, error)
+	history     []ClientRefsChecksumFromFuncCall
+	mutex       sync.Mutex
+}
+
+// RefsChecksumFrom delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockClient) RefsChecksumFrom(v0 context.Context, v1 api.RepoName, v2 string) (string, error) {
+	r0, r1 := m.RefsChecksumFromFunc.nextHook()(v0, v1, v2)
+	m.RefsChecksumFromFunc.appendCall(ClientRefsChecksumFromFuncCall{v0, v1, v2, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the RefsChecksumFrom
+// method of the parent MockClient instance is invoked and the hook queue is
+// empty.
+func (f *ClientRefsChecksumFromFunc) SetDefaultHook(hook func(context.Context, api.RepoName, string) (string, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// RefsChecksumFrom method of the parent MockClient instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *ClientRefsChecksumFromFunc) PushHook(hook func(context.Context, api.RepoName, string) (string, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *ClientRefsChecksumFromFunc) SetDefaultReturn(r0 string, r1 error) {
+	f.SetDefaultHook(func(context.Context, api.RepoName, string) (string, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *ClientRefsChecksumFromFunc) PushReturn(r0 string, r1 error) {
+	f.PushHook(func(context.Context, api.RepoName, string) (string, error) {
+		return r0, r1
+	})
+}
+
+func (f *ClientRefsChecksumFromFunc) nextHook() func(context.Context, api.RepoName, string) (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *ClientRefsChecksumFromFunc) appendCall(r0 ClientRefsChecksumFromFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of ClientRefsChecksumFromFuncCall objects
+// describing the invocations of this function.
+func (f *ClientRefsChecksumFromFunc) History() []ClientRefsChecksumFromFuncCall {
+	f.mutex.Lock()
+	history := make([]ClientRefsChecksumFromFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// ClientRefsChecksumFromFuncCall is an object that describes an invocation
+// of method RefsChecksumFrom on an instance of MockClient.
+type ClientRefsChecksumFromFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 api.RepoName
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 string
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c ClientRefsChecksumFromFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c ClientRefsChecksumFromFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // ClientRemoveFunc describes the behavior when the Remove method of the
 // parent MockClient instance is invoked.
 type ClientRemoveFunc struct {
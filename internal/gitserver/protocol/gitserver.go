@@ -19,6 +19,10 @@ type SearchRequest struct {
 	IncludeDiff          bool
 	Limit                int
 	IncludeModifiedFiles bool
+
+	// RangeDiff, if true, searches the aggregated diff between the two revisions of the single
+	// revision spec base...head in Revisions, rather than the diff of each commit in between.
+	RangeDiff bool
 }
 
 type RevisionSpecifier struct {
@@ -15,6 +15,7 @@ import (
 // started with StartDiffFetcher
 type DiffFetcher struct {
 	dir string
+	env []string
 
 	startOnce sync.Once
 	stdin     io.Writer
@@ -25,10 +26,11 @@ type DiffFetcher struct {
 }
 
 // NewDiffFetcher starts a git diff-tree subprocess that waits, listening on stdin
-// for comimt hashes to generate patches for.
-func NewDiffFetcher(dir string) (*DiffFetcher, error) {
+// for comimt hashes to generate patches for. env is added to the environment of the
+// subprocess.
+func NewDiffFetcher(dir string, env []string) (*DiffFetcher, error) {
 
-	return &DiffFetcher{dir: dir}, nil
+	return &DiffFetcher{dir: dir, env: env}, nil
 }
 
 func (d *DiffFetcher) Stop() {
@@ -42,7 +44,7 @@ func (d *DiffFetcher) start() (err error) {
 	d.startOnce.Do(func() {
 		ctx := context.Background()
 		ctx, d.cancel = context.WithCancel(ctx)
-		d.cmd = exec.CommandContext(ctx, "git",
+		d.cmd = newGitCmd(ctx, d.dir, d.env,
 			"diff-tree",
 			"--stdin",          // Read commit hashes from stdin
 			"--no-prefix",      // Do not prefix file names with a/ and b/
@@ -50,7 +52,6 @@ func (d *DiffFetcher) start() (err error) {
 			"--format=format:", // Output only the patch, not any other commit metadata
 			"--root",           // Treat the root commit as a big creation event (otherwise the diff would be empty)
 		)
-		d.cmd.Dir = d.dir
 
 		var stdoutReader io.ReadCloser
 		stdoutReader, err = d.cmd.StdoutPipe()
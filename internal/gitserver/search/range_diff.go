@@ -0,0 +1,168 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/sourcegraph/go-diff/diff"
+	"github.com/sourcegraph/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// IsRangeDiffSpec returns whether the revision spec is a range of two revisions, such as
+// v1.2.0...v1.3.0, whose aggregated diff can be searched with a RangeDiffSearcher.
+func IsRangeDiffSpec(revSpec string) bool {
+	base, head, ok := strings.Cut(revSpec, "...")
+	return ok && base != "" && head != "" && !strings.Contains(head, "...")
+}
+
+// RangeDiffSearcher searches the diff between two revisions as if all the changes between them
+// had been made in a single commit, instead of searching the diff of each commit in between.
+type RangeDiffSearcher struct {
+	Logger  log.Logger
+	RepoDir string
+	// Env is added to the environment of the git commands, for example to fetch the
+	// missing objects of partial clones.
+	Env   []string
+	Query MatchTree
+	// RevSpec is the range of revisions to compare, in the form base...head. Like git diff, the
+	// changes are those on head since the merge base of both revisions.
+	RevSpec string
+	// Limit is the number of matches after which the search stops, if positive. One match past
+	// the limit is still sent, so that the caller can tell that the limit was hit.
+	Limit int
+}
+
+// Search calls onMatch with one match per file changed between the revisions whose changes
+// satisfy the query. The commit fields of the matches are the ones of the head revision, and
+// message and author predicates are matched against that commit.
+func (rs *RangeDiffSearcher) Search(ctx context.Context, onMatch func(*protocol.CommitMatch)) error {
+	if !IsRangeDiffSpec(rs.RevSpec) {
+		return errors.Errorf("invalid revision range %q: expected base...head", rs.RevSpec)
+	}
+	_, head, _ := strings.Cut(rs.RevSpec, "...")
+
+	headCommit, err := rs.headCommit(ctx, head)
+	if err != nil || headCommit == nil {
+		return err
+	}
+
+	// The diff of a large range can be big, so it is parsed as git writes it, and git is stopped
+	// once enough matches are found.
+	gitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := newGitCmd(gitCtx, rs.RepoDir, rs.Env, "diff", "--no-prefix", rs.RevSpec, "--")
+	stdoutReader, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderrBuf bytes.Buffer
+	cmd.Stderr = &stderrBuf
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	complete, err := rs.searchDiff(ctx, headCommit, diff.NewMultiFileDiffReader(stdoutReader), onMatch)
+	if !complete {
+		cancel()
+	}
+	// Always call cmd.Wait to avoid leaving zombie processes around.
+	waitErr := cmd.Wait()
+	if err != nil {
+		return err
+	}
+	if complete && waitErr != nil {
+		return tryInterpretErrorWithStderr(ctx, waitErr, stderrBuf.String(), rs.Logger)
+	}
+	return nil
+}
+
+// searchDiff matches the file diffs read from r one by one against the query. It returns
+// whether the whole diff was read, which is not the case if it stopped at the limit.
+func (rs *RangeDiffSearcher) searchDiff(ctx context.Context, headCommit *RawCommit, r *diff.MultiFileDiffReader, onMatch func(*protocol.CommitMatch)) (complete bool, _ error) {
+	matches := 0
+	startBuf := make([]byte, 1024)
+	for {
+		if ctx.Err() != nil {
+			return false, nil
+		}
+		fileDiff, err := r.ReadFile()
+		if err == io.EOF {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		// Each changed file is matched on its own, so that the results are the files whose
+		// changes mention the pattern, rather than the whole range if any file does.
+		lc := &LazyCommit{
+			RawCommit: rangeDiffCommit(headCommit, fileDiff),
+			diff:      []*diff.FileDiff{fileDiff},
+			LowerBuf:  startBuf,
+		}
+		mergedResult, highlights, err := rs.Query.Match(lc)
+		if err != nil {
+			return false, err
+		}
+		if !mergedResult.Satisfies() {
+			continue
+		}
+		cm, err := CreateCommitMatch(lc, highlights, true)
+		if err != nil {
+			return false, err
+		}
+		onMatch(cm)
+
+		matches++
+		if rs.Limit > 0 && matches > rs.Limit {
+			return false, nil
+		}
+	}
+}
+
+// headCommit returns the commit the head of the range resolves to, or nil if the
+// repository has no commits.
+func (rs *RangeDiffSearcher) headCommit(ctx context.Context, head string) (*RawCommit, error) {
+	out, err := rs.git(ctx, "log", "-1", "--decorate=full", "-z", formatArg, head, "--")
+	if err != nil || len(out) == 0 {
+		return nil, err
+	}
+	scanner := NewCommitScanner(bytes.NewReader(out))
+	if !scanner.Scan() {
+		return nil, scanner.Err()
+	}
+	return scanner.NextRawCommit(), nil
+}
+
+func (rs *RangeDiffSearcher) git(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := newGitCmd(ctx, rs.RepoDir, rs.Env, args...)
+	var stderrBuf bytes.Buffer
+	cmd.Stderr = &stderrBuf
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, tryInterpretErrorWithStderr(ctx, err, stderrBuf.String(), rs.Logger)
+	}
+	return out, nil
+}
+
+// rangeDiffCommit returns a copy of the head commit whose modified files are the ones of the
+// file diff.
+func rangeDiffCommit(head *RawCommit, fileDiff *diff.FileDiff) *RawCommit {
+	commit := *head
+	commit.ModifiedFiles = nil
+	if fileDiff.OrigName != "/dev/null" {
+		commit.ModifiedFiles = append(commit.ModifiedFiles, []byte(fileDiff.OrigName))
+	}
+	if fileDiff.NewName != "/dev/null" && fileDiff.NewName != fileDiff.OrigName {
+		commit.ModifiedFiles = append(commit.ModifiedFiles, []byte(fileDiff.NewName))
+	}
+	return &commit
+}
@@ -0,0 +1,106 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sourcegraph/log/logtest"
+
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+func TestIsRangeDiffSpec(t *testing.T) {
+	for spec, want := range map[string]bool{
+		"v1.2.0...v1.3.0": true,
+		"main...HEAD~2":   true,
+		"v1.2.0..v1.3.0":  false,
+		"...v1.3.0":       false,
+		"v1.2.0...":       false,
+		"HEAD":            false,
+		"a...b...c":       false,
+	} {
+		require.Equal(t, want, IsRangeDiffSpec(spec), spec)
+	}
+}
+
+func TestRangeDiffSearcher(t *testing.T) {
+	dir := initGitRepository(t,
+		"git config user.name camden",
+		"git config user.email camden@ccheek.com",
+		"echo lorem ipsum > file1",
+		"echo dolor sit > file2",
+		"git add -A",
+		"git commit -m commit1",
+		"git tag v1",
+		"echo lorem ipsum amet > file1",
+		"git commit -am commit2",
+		"echo dolor sit amet > file2",
+		"git commit -am commit3",
+		"echo consectetur > file3",
+		"git add -A",
+		"git commit -m commit4",
+		"git tag v2",
+		"git rm file3",
+		"git commit -m commit5",
+	)
+
+	searchWithLimit := func(t *testing.T, revSpec string, query protocol.Node, limit int) []*protocol.CommitMatch {
+		tree, err := ToMatchTree(query)
+		require.NoError(t, err)
+		searcher := &RangeDiffSearcher{
+			Logger:  logtest.Scoped(t),
+			RepoDir: dir,
+			Query:   tree,
+			RevSpec: revSpec,
+			Limit:   limit,
+		}
+		var matches []*protocol.CommitMatch
+		err = searcher.Search(context.Background(), func(match *protocol.CommitMatch) {
+			matches = append(matches, match)
+		})
+		require.NoError(t, err)
+		return matches
+	}
+	search := func(t *testing.T, revSpec string, query protocol.Node) []*protocol.CommitMatch {
+		return searchWithLimit(t, revSpec, query, 0)
+	}
+
+	t.Run("one match per changed file", func(t *testing.T) {
+		matches := search(t, "v1...v2", &protocol.DiffMatches{Expr: "amet"})
+		require.Len(t, matches, 2)
+		require.Equal(t, []string{"file1"}, matches[0].ModifiedFiles)
+		require.Equal(t, []string{"file2"}, matches[1].ModifiedFiles)
+		require.Equal(t, "commit4", matches[0].Message.Content)
+		require.Equal(t, "file1 file1\n@@ -1,1 +1,1 @@ \n-lorem ipsum\n+lorem ipsum amet\n", matches[0].Diff.Content)
+		require.Len(t, matches[0].Diff.MatchedRanges, 1)
+	})
+
+	t.Run("intermediate changes are not matched", func(t *testing.T) {
+		// file3 was created and removed again between v1 and HEAD.
+		require.Empty(t, search(t, "v1...HEAD", &protocol.DiffModifiesFile{Expr: "file3"}))
+		require.Len(t, search(t, "v1...v2", &protocol.DiffModifiesFile{Expr: "file3"}), 1)
+	})
+
+	t.Run("stops one match past the limit", func(t *testing.T) {
+		query := &protocol.DiffModifiesFile{Expr: "file"}
+		require.Len(t, search(t, "v1...v2", query), 3)
+		require.Len(t, searchWithLimit(t, "v1...v2", query, 1), 2)
+		require.Len(t, searchWithLimit(t, "v1...v2", query, 3), 3)
+	})
+
+	t.Run("unknown base", func(t *testing.T) {
+		tree, err := ToMatchTree(protocol.NewAnd())
+		require.NoError(t, err)
+		searcher := &RangeDiffSearcher{Logger: logtest.Scoped(t), RepoDir: dir, Query: tree, RevSpec: "v0...v2"}
+		require.Error(t, searcher.Search(context.Background(), func(*protocol.CommitMatch) {}))
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		tree, err := ToMatchTree(protocol.NewAnd())
+		require.NoError(t, err)
+		searcher := &RangeDiffSearcher{RepoDir: dir, Query: tree, RevSpec: "v1"}
+		require.Error(t, searcher.Search(context.Background(), func(*protocol.CommitMatch) {}))
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"os"
 	"os/exec"
 	"strings"
 
@@ -58,12 +59,14 @@ var (
 	// Note that we begin each commit with a special string constant. This allows us
 	// to easily separate each commit since the number of parts in each commit varies
 	// depending on the number of files modified.
+	formatArg = "--format=format:" + "%x1E" + strings.Join(commitFields, "%x00") + "%x00"
+
 	logArgs = []string{
 		"log",
 		"--decorate=full",
 		"-z",
 		"--no-merges",
-		"--format=format:" + "%x1E" + strings.Join(commitFields, "%x00") + "%x00",
+		formatArg,
 	}
 
 	sep = []byte{0x0}
@@ -83,8 +86,11 @@ const (
 type CommitSearcher struct {
 	// Logger is a standardized, strongly-typed, and structured logging interface
 	// Production output from this Logger (SRC_LOG_FORMAT=json) complies with the OpenTelemetry log data model
-	Logger               log.Logger
-	RepoDir              string
+	Logger  log.Logger
+	RepoDir string
+	// Env is added to the environment of the git commands, for example to fetch the
+	// missing objects of partial clones.
+	Env                  []string
 	Query                MatchTree
 	Revisions            []protocol.RevisionSpecifier
 	IncludeDiff          bool
@@ -140,8 +146,7 @@ func (cs *CommitSearcher) feedBatches(ctx context.Context, jobs chan job, result
 	if cs.IncludeModifiedFiles {
 		args = append(args, "--name-only")
 	}
-	cmd := exec.CommandContext(ctx, "git", args...)
-	cmd.Dir = cs.RepoDir
+	cmd := newGitCmd(ctx, cs.RepoDir, cs.Env, args...)
 	stdoutReader, err := cmd.StdoutPipe()
 	if err != nil {
 		return err
@@ -190,6 +195,17 @@ func (cs *CommitSearcher) feedBatches(ctx context.Context, jobs chan job, result
 	return scanner.Err()
 }
 
+// newGitCmd returns a git command run in dir, with env added to the environment of
+// the process.
+func newGitCmd(ctx context.Context, dir string, env []string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	return cmd
+}
+
 func tryInterpretErrorWithStderr(ctx context.Context, err error, stderr string, logger log.Logger) error {
 	if ctx.Err() != nil {
 		// Ignore errors when context is cancelled
@@ -205,7 +221,7 @@ func tryInterpretErrorWithStderr(ctx context.Context, err error, stderr string,
 
 func (cs *CommitSearcher) runJobs(ctx context.Context, jobs chan job) error {
 	// Create a new diff fetcher subprocess for each worker
-	diffFetcher, err := NewDiffFetcher(cs.RepoDir)
+	diffFetcher, err := NewDiffFetcher(cs.RepoDir, cs.Env)
 	if err != nil {
 		return err
 	}
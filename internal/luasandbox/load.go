@@ -0,0 +1,320 @@
+package luasandbox
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/ast"
+	"github.com/yuin/gopher-lua/parse"
+
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+const (
+	// builtinPrefix prefixes the names of the builtins that the scripts loaded in a sandbox
+	// are compiled to call, so that the memory limiter of the sandbox can account for the
+	// strings and tables they create. The names cannot be written in Lua source, so scripts
+	// cannot refer to, shadow or reassign them.
+	builtinPrefix = "__sandbox."
+
+	concatFunctionName   = builtinPrefix + "concat"
+	setIndexFunctionName = builtinPrefix + "setindex"
+	newTableFunctionName = builtinPrefix + "newtable"
+
+	// builtinsRegistryKey is the key of the table of builtins in the registry of a sandbox.
+	builtinsRegistryKey = builtinPrefix + "builtins"
+)
+
+// builtinNames are the names of the builtins bound to the scripts loaded in a sandbox.
+var builtinNames = []string{concatFunctionName, setIndexFunctionName, newTableFunctionName}
+
+// LoadString compiles the given Lua source into a function bound to the given sandbox state.
+// Scripts run in a sandbox must be loaded with this function rather than with the methods
+// of the state, which do not account for string concatenations and table assignments in
+// the memory limit.
+func LoadString(state *lua.LState, source string) (*lua.LFunction, error) {
+	return load(state, strings.NewReader(source), "<string>")
+}
+
+// load compiles the script read from the given reader into a function whose upvalues are
+// the builtins of the sandbox, which the rewritten script calls.
+func load(state *lua.LState, reader io.Reader, name string) (*lua.LFunction, error) {
+	builtins, ok := state.G.Registry.RawGetString(builtinsRegistryKey).(*lua.LTable)
+	if !ok {
+		return nil, errors.New("state is not a sandbox state")
+	}
+
+	chunk, err := parse.Parse(reader, name)
+	if err != nil {
+		return nil, err
+	}
+	r := &rewriter{}
+	r.rewriteStmts(chunk)
+
+	// local <builtins> = ...
+	// return function(...) <chunk> end
+	wrapper := []ast.Stmt{
+		&ast.LocalAssignStmt{Names: builtinNames, Exprs: []ast.Expr{&ast.Comma3Expr{}}},
+		&ast.ReturnStmt{Exprs: []ast.Expr{&ast.FunctionExpr{ParList: &ast.ParList{HasVargs: true}, Stmts: chunk}}},
+	}
+	proto, err := lua.Compile(wrapper, name)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]lua.LValue, 0, len(builtinNames))
+	for _, name := range builtinNames {
+		args = append(args, builtins.RawGetString(name))
+	}
+	if err := state.CallByParam(lua.P{Fn: state.NewFunctionFromProto(proto), NRet: 1, Protect: true}, args...); err != nil {
+		return nil, err
+	}
+	fn, _ := state.Get(-1).(*lua.LFunction)
+	state.Pop(1)
+	if fn == nil {
+		return nil, errors.New("unexpected chunk")
+	}
+
+	return fn, nil
+}
+
+// registerBuiltins binds the builtins of the given memory limiter to the scripts loaded in
+// the given sandbox state.
+func registerBuiltins(state *lua.LState, memory *memoryLimiter) {
+	builtins := state.NewTable()
+	builtins.RawSetString(concatFunctionName, state.NewFunction(memory.concat))
+	builtins.RawSetString(setIndexFunctionName, state.NewFunction(memory.setIndex))
+	builtins.RawSetString(newTableFunctionName, state.NewFunction(memory.newTable))
+	state.G.Registry.RawSetString(builtinsRegistryKey, builtins)
+
+	protectBuiltins(state)
+}
+
+// protectBuiltins replaces the debug.setupvalue function of the given state with one that
+// refuses to replace the builtins bound to the scripts of the sandbox.
+func protectBuiltins(state *lua.LState) {
+	lib, ok := state.GetGlobal(lua.DebugLibName).(*lua.LTable)
+	if !ok {
+		return
+	}
+	setUpvalue, ok := lib.RawGetString("setupvalue").(*lua.LFunction)
+	if !ok || !setUpvalue.IsG {
+		return
+	}
+
+	lib.RawSetString("setupvalue", state.NewFunction(func(state *lua.LState) int {
+		fn, i := state.CheckFunction(1), state.CheckInt(2)
+		if !fn.IsG && i >= 1 && i <= len(fn.Proto.DbgUpvalues) && strings.HasPrefix(fn.Proto.DbgUpvalues[i-1], builtinPrefix) {
+			state.RaiseError("cannot set upvalue %s", fn.Proto.DbgUpvalues[i-1])
+			return 0
+		}
+		return setUpvalue.GFunction(state)
+	}))
+}
+
+// rewriter replaces the statements and expressions of a script that allocate strings and
+// tables with calls to the builtins of the sandbox:
+//
+//   - a .. b is replaced with concat(a, b)
+//   - t[k] = v is replaced with setindex(t, k, v)
+//   - {...} is replaced with newtable({...})
+type rewriter struct {
+	temporaries int
+}
+
+func (r *rewriter) rewriteStmts(stmts []ast.Stmt) {
+	for i, stmt := range stmts {
+		stmts[i] = r.rewriteStmt(stmt)
+	}
+}
+
+func (r *rewriter) rewriteStmt(stmt ast.Stmt) ast.Stmt {
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		r.rewriteExprs(s.Lhs)
+		r.rewriteExprs(s.Rhs)
+		return r.rewriteAssignStmt(s)
+	case *ast.LocalAssignStmt:
+		r.rewriteExprs(s.Exprs)
+	case *ast.FuncCallStmt:
+		s.Expr = r.rewriteExpr(s.Expr)
+	case *ast.DoBlockStmt:
+		r.rewriteStmts(s.Stmts)
+	case *ast.WhileStmt:
+		s.Condition = r.rewriteExpr(s.Condition)
+		r.rewriteStmts(s.Stmts)
+	case *ast.RepeatStmt:
+		s.Condition = r.rewriteExpr(s.Condition)
+		r.rewriteStmts(s.Stmts)
+	case *ast.IfStmt:
+		s.Condition = r.rewriteExpr(s.Condition)
+		r.rewriteStmts(s.Then)
+		r.rewriteStmts(s.Else)
+	case *ast.NumberForStmt:
+		s.Init = r.rewriteExpr(s.Init)
+		s.Limit = r.rewriteExpr(s.Limit)
+		s.Step = r.rewriteExpr(s.Step)
+		r.rewriteStmts(s.Stmts)
+	case *ast.GenericForStmt:
+		r.rewriteExprs(s.Exprs)
+		r.rewriteStmts(s.Stmts)
+	case *ast.FuncDefStmt:
+		r.rewriteStmts(s.Func.Stmts)
+	case *ast.ReturnStmt:
+		r.rewriteExprs(s.Exprs)
+	}
+
+	return stmt
+}
+
+// rewriteAssignStmt replaces the assignments to table fields of the given statement with
+// calls to the setindex builtin.
+func (r *rewriter) rewriteAssignStmt(s *ast.AssignStmt) ast.Stmt {
+	indexed := false
+	for _, lhs := range s.Lhs {
+		if _, ok := lhs.(*ast.AttrGetExpr); ok {
+			indexed = true
+		}
+	}
+	if !indexed {
+		return s
+	}
+
+	if len(s.Lhs) == 1 && len(s.Rhs) == 1 {
+		lhs := s.Lhs[0].(*ast.AttrGetExpr)
+		return callStmt(s, setIndexFunctionName, lhs.Object, lhs.Key, s.Rhs[0])
+	}
+
+	// A multiple assignment evaluates the tables and keys of its targets and then its values
+	// before assigning any of them, so they are stored in temporaries first:
+	//
+	//	do
+	//	  local t1, k1 = t, k
+	//	  local v1, v2 = <values>
+	//	  setindex(t1, k1, v1)
+	//	  x = v2
+	//	end
+	var targetNames []string
+	var targetExprs []ast.Expr
+	valueNames := make([]string, 0, len(s.Lhs))
+	assignments := make([]ast.Stmt, 0, len(s.Lhs))
+	for _, lhs := range s.Lhs {
+		value := r.temporary()
+		valueNames = append(valueNames, value)
+
+		if attr, ok := lhs.(*ast.AttrGetExpr); ok {
+			object, key := r.temporary(), r.temporary()
+			targetNames = append(targetNames, object, key)
+			targetExprs = append(targetExprs, attr.Object, attr.Key)
+			assignments = append(assignments, callStmt(s, setIndexFunctionName, identExpr(s, object), identExpr(s, key), identExpr(s, value)))
+			continue
+		}
+
+		assignment := &ast.AssignStmt{Lhs: []ast.Expr{lhs}, Rhs: []ast.Expr{identExpr(s, value)}}
+		assignment.SetLine(s.Line())
+		assignment.SetLastLine(s.LastLine())
+		assignments = append(assignments, assignment)
+	}
+
+	targets := &ast.LocalAssignStmt{Names: targetNames, Exprs: targetExprs}
+	values := &ast.LocalAssignStmt{Names: valueNames, Exprs: s.Rhs}
+	block := &ast.DoBlockStmt{Stmts: append([]ast.Stmt{targets, values}, assignments...)}
+	for _, stmt := range block.Stmts[:2] {
+		stmt.SetLine(s.Line())
+		stmt.SetLastLine(s.LastLine())
+	}
+	block.SetLine(s.Line())
+	block.SetLastLine(s.LastLine())
+	return block
+}
+
+// temporary returns the name of a new local variable, which cannot be written in Lua source.
+func (r *rewriter) temporary() string {
+	r.temporaries++
+	return fmt.Sprintf("%stmp%d", builtinPrefix, r.temporaries)
+}
+
+func (r *rewriter) rewriteExprs(exprs []ast.Expr) {
+	for i, expr := range exprs {
+		exprs[i] = r.rewriteExpr(expr)
+	}
+}
+
+func (r *rewriter) rewriteExpr(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.StringConcatOpExpr:
+		return callExpr(e, concatFunctionName, r.rewriteExpr(e.Lhs), r.rewriteExpr(e.Rhs))
+	case *ast.AttrGetExpr:
+		e.Object = r.rewriteExpr(e.Object)
+		e.Key = r.rewriteExpr(e.Key)
+	case *ast.TableExpr:
+		for _, field := range e.Fields {
+			field.Key = r.rewriteExpr(field.Key)
+			field.Value = r.rewriteExpr(field.Value)
+		}
+		return callExpr(e, newTableFunctionName, e)
+	case *ast.FuncCallExpr:
+		e.Func = r.rewriteExpr(e.Func)
+		e.Receiver = r.rewriteExpr(e.Receiver)
+		r.rewriteExprs(e.Args)
+	case *ast.LogicalOpExpr:
+		e.Lhs = r.rewriteExpr(e.Lhs)
+		e.Rhs = r.rewriteExpr(e.Rhs)
+	case *ast.RelationalOpExpr:
+		e.Lhs = r.rewriteExpr(e.Lhs)
+		e.Rhs = r.rewriteExpr(e.Rhs)
+	case *ast.ArithmeticOpExpr:
+		e.Lhs = r.rewriteExpr(e.Lhs)
+		e.Rhs = r.rewriteExpr(e.Rhs)
+	case *ast.UnaryMinusOpExpr:
+		e.Expr = r.rewriteExpr(e.Expr)
+	case *ast.UnaryNotOpExpr:
+		e.Expr = r.rewriteExpr(e.Expr)
+	case *ast.UnaryLenOpExpr:
+		e.Expr = r.rewriteExpr(e.Expr)
+	case *ast.FunctionExpr:
+		r.rewriteStmts(e.Stmts)
+	}
+
+	return expr
+}
+
+// callExpr returns a call to the builtin with the given name that replaces the given
+// expression, adjusted to a single return value.
+func callExpr(expr ast.Expr, name string, args ...ast.Expr) *ast.FuncCallExpr {
+	call := &ast.FuncCallExpr{
+		Func:      identExpr(expr, name),
+		Args:      args,
+		AdjustRet: true,
+	}
+	call.SetLine(expr.Line())
+	call.SetLastLine(expr.LastLine())
+	return call
+}
+
+// callStmt returns a call to the builtin with the given name that replaces the given
+// statement.
+func callStmt(stmt ast.Stmt, name string, args ...ast.Expr) *ast.FuncCallStmt {
+	call := &ast.FuncCallExpr{Func: identExpr(stmt, name), Args: args}
+	call.SetLine(stmt.Line())
+	call.SetLastLine(stmt.LastLine())
+
+	s := &ast.FuncCallStmt{Expr: call}
+	s.SetLine(stmt.Line())
+	s.SetLastLine(stmt.LastLine())
+	return s
+}
+
+// identExpr returns a reference to the variable with the given name located at the given
+// node.
+func identExpr(node interface {
+	Line() int
+	LastLine() int
+}, name string) *ast.IdentExpr {
+	ident := &ast.IdentExpr{Value: name}
+	ident.SetLine(node.Line())
+	ident.SetLastLine(node.LastLine())
+	return ident
+}
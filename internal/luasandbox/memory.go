@@ -0,0 +1,242 @@
+package luasandbox
+
+import (
+	"math"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// memoryLimiter accounts for the memory allocated by the scripts of a single sandbox.
+//
+// The Lua VM does not track its own allocations, so the limiter counts the bytes of the
+// strings and tables grown by the builtins of the sandbox instead. This includes string
+// concatenations, table constructors and assignments to table fields, which the sandbox
+// compiles into calls to builtins (see load.go). The other values created by the VM, such
+// as closures and the values on its stacks, are bounded by the size of the script and of
+// the stacks.
+type memoryLimiter struct {
+	limit     uint64
+	allocated uint64
+}
+
+// valueSize approximates the size of a Lua value stored in a table.
+const valueSize = 16
+
+// tableSize approximates the size of an empty table.
+const tableSize = 64
+
+// coroutineSize approximates the size of the stacks allocated for a new coroutine.
+const coroutineSize = registrySize * valueSize
+
+// reset starts accounting for a new run with the given limit. No limit is enforced when
+// the limit is zero.
+func (m *memoryLimiter) reset(limit uint64) {
+	m.limit = limit
+	m.allocated = 0
+}
+
+// exceeded returns true if the current run allocated more than its limit.
+func (m *memoryLimiter) exceeded() bool {
+	return m.limit > 0 && m.allocated > m.limit
+}
+
+// allocate accounts for the given number of bytes, and raises an error in the given state
+// once the limit of the run is exceeded.
+func (m *memoryLimiter) allocate(state *lua.LState, n uint64) {
+	if m.allocated > math.MaxUint64-n {
+		m.allocated = math.MaxUint64
+	} else {
+		m.allocated += n
+	}
+
+	if m.exceeded() {
+		state.RaiseError("%s", ErrMemoryLimitExceeded)
+	}
+}
+
+// concat implements the concatenation operator of the scripts loaded in the sandbox.
+func (m *memoryLimiter) concat(state *lua.LState) int {
+	lhs, rhs := state.Get(1), state.Get(2)
+
+	if lua.LVCanConvToString(lhs) && lua.LVCanConvToString(rhs) {
+		l, r := lua.LVAsString(lhs), lua.LVAsString(rhs)
+		m.allocate(state, uint64(len(l))+uint64(len(r)))
+		state.Push(lua.LString(l + r))
+		return 1
+	}
+
+	op := state.GetMetaField(lhs, "__concat")
+	if op == lua.LNil {
+		op = state.GetMetaField(rhs, "__concat")
+	}
+	if op.Type() != lua.LTFunction {
+		state.RaiseError("cannot perform concat operation between %s and %s", lhs.Type(), rhs.Type())
+		return 0
+	}
+
+	state.Push(op)
+	state.Push(lhs)
+	state.Push(rhs)
+	state.Call(2, 1)
+	return 1
+}
+
+// setIndex implements the assignments to table fields of the scripts loaded in the sandbox.
+// Assignments that add a field to a table are accounted for before the field is set.
+func (m *memoryLimiter) setIndex(state *lua.LState) int {
+	obj, key, value := state.Get(1), state.Get(2), state.Get(3)
+
+	if t, ok := obj.(*lua.LTable); ok && value != lua.LNil && t.RawGet(key) == lua.LNil {
+		m.allocate(state, 2*valueSize)
+	}
+
+	state.SetTable(obj, key, value)
+	return 0
+}
+
+// newTable implements the table constructors of the scripts loaded in the sandbox. The
+// table is accounted for once it is constructed, and its fields are bounded by the size of
+// the script and of the stack.
+func (m *memoryLimiter) newTable(state *lua.LState) int {
+	t := state.CheckTable(1)
+
+	n := uint64(tableSize)
+	t.ForEach(func(_, _ lua.LValue) { n += 2 * valueSize })
+	m.allocate(state, n)
+
+	state.Push(t)
+	return 1
+}
+
+// builtinLimit describes how the allocations of a builtin are accounted for.
+type builtinLimit struct {
+	// before returns the size allocated by the builtin from its arguments. It is accounted
+	// for before the builtin runs, so that a single call cannot exceed the limit by an
+	// arbitrary amount.
+	before func(state *lua.LState) uint64
+
+	// after is set if the strings returned by the builtin are accounted for.
+	after bool
+}
+
+var builtinLimits = map[string]map[string]builtinLimit{
+	lua.BaseLibName: {
+		"rawset": {before: func(state *lua.LState) uint64 { return 2 * valueSize }},
+	},
+	lua.StringLibName: {
+		"rep":     {before: func(state *lua.LState) uint64 { return repSize(len(state.CheckString(1)), state.CheckInt(2)) }},
+		"lower":   {before: func(state *lua.LState) uint64 { return uint64(len(state.CheckString(1))) }},
+		"upper":   {before: func(state *lua.LState) uint64 { return uint64(len(state.CheckString(1))) }},
+		"reverse": {before: func(state *lua.LState) uint64 { return uint64(len(state.CheckString(1))) }},
+		"char":    {before: func(state *lua.LState) uint64 { return uint64(state.GetTop()) }},
+		"format":  {before: func(state *lua.LState) uint64 { checkFormatWidths(state, state.CheckString(1)); return 0 }, after: true},
+		"gsub":    {after: true},
+	},
+	lua.TabLibName: {
+		"insert": {before: func(state *lua.LState) uint64 { return valueSize }},
+		"concat": {after: true},
+	},
+	lua.CoroutineLibName: {
+		"create": {before: func(state *lua.LState) uint64 { return coroutineSize }},
+		"wrap":   {before: func(state *lua.LState) uint64 { return coroutineSize }},
+	},
+	lua.ChannelLibName: {
+		"make": {before: func(state *lua.LState) uint64 { return channelSize(state.OptInt(1, 0)) }},
+	},
+}
+
+// limitBuiltins replaces the builtins that grow strings and tables in the given state with
+// functions that account for their allocations.
+func (m *memoryLimiter) limitBuiltins(state *lua.LState) {
+	for libName, limits := range builtinLimits {
+		// The functions of the base library are globals
+		lib, ok := state.Get(lua.GlobalsIndex).(*lua.LTable)
+		if libName != lua.BaseLibName {
+			lib, ok = state.GetGlobal(libName).(*lua.LTable)
+		}
+		if !ok {
+			continue
+		}
+
+		for name, limit := range limits {
+			builtin, ok := lib.RawGetString(name).(*lua.LFunction)
+			if !ok || !builtin.IsG {
+				continue
+			}
+
+			lib.RawSetString(name, state.NewFunction(m.limitBuiltin(builtin.GFunction, limit)))
+		}
+	}
+}
+
+func (m *memoryLimiter) limitBuiltin(builtin lua.LGFunction, limit builtinLimit) lua.LGFunction {
+	return func(state *lua.LState) int {
+		if limit.before != nil {
+			m.allocate(state, limit.before(state))
+		}
+
+		n := builtin(state)
+
+		if limit.after {
+			for i := 1; i <= n; i++ {
+				if s, ok := state.Get(-i).(lua.LString); ok {
+					m.allocate(state, uint64(len(s)))
+				}
+			}
+		}
+
+		return n
+	}
+}
+
+// repSize returns the size of the string returned by string.rep.
+func repSize(length, n int) uint64 {
+	if length <= 0 || n <= 0 {
+		return 0
+	}
+	if uint64(n) > math.MaxUint64/uint64(length) {
+		return math.MaxUint64
+	}
+	return uint64(length) * uint64(n)
+}
+
+// channelSize returns the size of the buffer of a channel created by channel.make.
+func channelSize(n int) uint64 {
+	if n <= 0 {
+		return 0
+	}
+	return repSize(valueSize, n)
+}
+
+// checkFormatWidths raises an error if a directive of the given string.format format has a
+// width or precision of more than two digits, as Lua itself does. Otherwise, the formatted
+// string could be arbitrarily larger than its arguments.
+func checkFormatWidths(state *lua.LState, format string) {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+
+		digits := 0
+		for ; i < len(format); i++ {
+			switch c := format[i]; {
+			case c >= '0' && c <= '9':
+				digits++
+			case c == '.':
+				digits = 0
+			case c == '-' || c == '+' || c == ' ' || c == '#':
+			default:
+				// End of the directive
+				digits = -1
+			}
+			if digits > 2 {
+				state.RaiseError("invalid format (width or precision too long)")
+				return
+			}
+			if digits < 0 {
+				break
+			}
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,14 +13,16 @@ import (
 
 	"github.com/sourcegraph/sourcegraph/internal/luasandbox/util"
 	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
 )
 
 type Sandbox struct {
 	// note: operations around vm state are kept isolated in
 	// the run function, which ensures mutual access with the
 	// mutex.
-	state *lua.LState
-	m     sync.Mutex
+	state  *lua.LState
+	memory *memoryLimiter
+	m      sync.Mutex
 
 	operations *operations
 }
@@ -71,7 +74,12 @@ func (s *Sandbox) RunScriptNamed(ctx context.Context, opts RunOptions, fs FS, na
 		state.SetGlobal("loadfile", makeScopedLoadfile(state, fs))
 		defer state.SetGlobal("loadfile", lua.LNil)
 
-		if err := state.DoString(script); err != nil {
+		fn, err := load(state, strings.NewReader(script), name)
+		if err != nil {
+			return err
+		}
+		state.Push(fn)
+		if err := state.PCall(0, lua.MultRet, nil); err != nil {
 			return err
 		}
 
@@ -93,7 +101,7 @@ func makeScopedLoadfile(state *lua.LState, fs FS) *lua.LFunction {
 			return err
 		}
 
-		fn, err := state.Load(bytes.NewReader(contents), filename)
+		fn, err := load(state, bytes.NewReader(contents), filename)
 		if err != nil {
 			return err
 		}
@@ -165,11 +173,23 @@ func (s *Sandbox) CallGenerator(ctx context.Context, opts RunOptions, luaFunctio
 }
 
 type RunOptions struct {
+	// Timeout bounds the wall-clock time of the run, including the time spent waiting on
+	// the Go functions called by the script. It is not a CPU time limit.
 	Timeout time.Duration
+
+	// MemoryLimit is the number of bytes that the scripts run in the sandbox may allocate
+	// over the run. It is enforced per sandbox by accounting for the strings and tables
+	// grown by string concatenations, table constructors, assignments to table fields and
+	// the builtins of the sandbox. No limit is enforced when zero.
+	MemoryLimit uint64
 }
 
 const DefaultTimeout = time.Millisecond * 200
 
+// ErrMemoryLimitExceeded is returned when a sandbox run allocates more than the memory
+// limit of its run options.
+var ErrMemoryLimitExceeded = errors.New("memory limit exceeded")
+
 // RunGoCallback invokes the given Go callback with exclusive access to the state of the
 // sandbox.
 func (s *Sandbox) RunGoCallback(ctx context.Context, opts RunOptions, f func(ctx context.Context, state *lua.LState) error) (err error) {
@@ -185,8 +205,16 @@ func (s *Sandbox) RunGoCallback(ctx context.Context, opts RunOptions, f func(ctx
 	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
 	defer cancel()
 
+	s.memory.reset(opts.MemoryLimit)
+
 	s.state.SetContext(ctx)
 	defer s.state.RemoveContext()
 
-	return f(ctx, s.state)
+	err = f(ctx, s.state)
+	// The script may have caught the error raised once the limit was exceeded
+	if s.memory.exceeded() {
+		return ErrMemoryLimitExceeded
+	}
+
+	return err
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/sourcegraph/sourcegraph/internal/luasandbox/util"
 	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
 )
 
 func TestSandboxHasNoIO(t *testing.T) {
@@ -220,3 +221,151 @@ func TestCallGenerator(t *testing.T) {
 		t.Errorf("unexpected file contents (-want +got):\n%s", diff)
 	}
 }
+
+func TestSandboxMemoryLimit(t *testing.T) {
+	ctx := context.Background()
+
+	sandbox, err := newService(&observation.TestContext).CreateSandbox(ctx, CreateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error creating sandbox: %s", err)
+	}
+	defer sandbox.Close()
+
+	opts := RunOptions{Timeout: time.Minute, MemoryLimit: 16 * 1024 * 1024}
+
+	for name, script := range map[string]string{
+		"concatenation": `
+			local s = "x"
+			for i=1,40 do s = s .. s end
+			return #s
+		`,
+		"builtin": `
+			return #string.rep("x", 1024 * 1024 * 1024)
+		`,
+		"method": `
+			local s = "x"
+			return #s:rep(1024 * 1024 * 1024)
+		`,
+		"table": `
+			local t = {}
+			for i=1,1024 * 1024 * 1024 do table.insert(t, i) end
+			return #t
+		`,
+		"caught": `
+			pcall(function() return string.rep("x", 1024 * 1024 * 1024) end)
+			return 42
+		`,
+		"table field": `
+			local t = {}
+			for i=1,1024 * 1024 * 1024 do t[i] = i end
+			return #t
+		`,
+		"table constructor": `
+			local t = {}
+			for i=1,1024 * 1024 * 1024 do t[i % 2] = {i} end
+			return #t
+		`,
+		"rawset": `
+			local t = {}
+			for i=1,1024 * 1024 * 1024 do rawset(t, i, i) end
+			return #t
+		`,
+		"multiple assignment": `
+			local t, u = {}, {}
+			for i=1,1024 * 1024 * 1024 do t[i], u[i] = i, i end
+			return #t
+		`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := sandbox.RunScript(ctx, opts, script); !errors.Is(err, ErrMemoryLimitExceeded) {
+				t.Fatalf("unexpected error running script. want=%q have=%v", ErrMemoryLimitExceeded, err)
+			}
+		})
+	}
+
+	// The sandbox remains usable after exceeding the limit
+	retValue, err := sandbox.RunScript(ctx, opts, `return #string.rep("x", 1024 * 1024)`)
+	if err != nil {
+		t.Fatalf("unexpected error running script: %s", err)
+	}
+	if lua.LVAsNumber(retValue) != 1024*1024 {
+		t.Errorf("unexpected return value. want=%d have=%v", 1024*1024, retValue)
+	}
+}
+
+func TestSandboxConcatenation(t *testing.T) {
+	ctx := context.Background()
+
+	sandbox, err := newService(&observation.TestContext).CreateSandbox(ctx, CreateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error creating sandbox: %s", err)
+	}
+	defer sandbox.Close()
+
+	script := `
+		local mt = {__concat = function(lhs, rhs) return "meta" end}
+		local t = setmetatable({}, mt)
+		local f = function(a, b) return a .. "-" .. b end
+		return f("a", 1) .. ":" .. (t .. "x") .. ":" .. ("x" .. t)
+	`
+	retValue, err := sandbox.RunScript(ctx, RunOptions{}, script)
+	if err != nil {
+		t.Fatalf("unexpected error running script: %s", err)
+	}
+	if want := "a-1:meta:meta"; lua.LVAsString(retValue) != want {
+		t.Errorf("unexpected return value. want=%q have=%v", want, retValue)
+	}
+
+	if _, err := sandbox.RunScript(ctx, RunOptions{}, `return "x" .. {}`); err == nil {
+		t.Fatalf("expected error running script")
+	} else if !strings.Contains(err.Error(), "cannot perform concat operation between string and table") {
+		t.Fatalf("unexpected error running script: %s", err)
+	}
+
+	// The builtins of the sandbox cannot be replaced by scripts
+	script = `
+		local f = function(s) return s .. s end
+		for i=1,10 do
+			if debug.getupvalue(f, i) == nil then break end
+			debug.setupvalue(f, i, function(lhs, rhs) return lhs end)
+		end
+		return f("x")
+	`
+	if _, err := sandbox.RunScript(ctx, RunOptions{}, script); err == nil {
+		t.Fatalf("expected error running script")
+	} else if !strings.Contains(err.Error(), "cannot set upvalue") {
+		t.Fatalf("unexpected error running script: %s", err)
+	}
+}
+
+func TestSandboxAssignment(t *testing.T) {
+	ctx := context.Background()
+
+	sandbox, err := newService(&observation.TestContext).CreateSandbox(ctx, CreateOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error creating sandbox: %s", err)
+	}
+	defer sandbox.Close()
+
+	script := `
+		local t = {1, 2, x = {y = "a"}}
+		t.x.y = t.x.y .. "b"
+		t[3] = t[1] + t[2]
+
+		local i = 1
+		i, t[i], t.z = i + 1, 20
+
+		local log = {}
+		local proxy = setmetatable({}, {__newindex = function(_, k, v) log[#log + 1] = k .. "=" .. v end})
+		proxy.a, proxy.b = "1", "2"
+
+		return table.concat({t.x.y, t[1], t[3], i, tostring(t.z), table.concat(log, ",")}, ":")
+	`
+	retValue, err := sandbox.RunScript(ctx, RunOptions{}, script)
+	if err != nil {
+		t.Fatalf("unexpected error running script: %s", err)
+	}
+	if want := "ab:20:3:2:nil:a=1,b=2"; lua.LVAsString(retValue) != want {
+		t.Errorf("unexpected return value. want=%q have=%v", want, retValue)
+	}
+}
@@ -31,6 +31,12 @@ func (s *Service) CreateSandbox(ctx context.Context, opts CreateOptions) (_ *San
 	state := lua.NewState(lua.Options{
 		// Do not open libraries implicitly
 		SkipOpenLibs: true,
+
+		// Bound the stacks of the VM, which are not accounted for by the memory limiter
+		CallStackSize:       callStackSize,
+		MinimizeStackMemory: true,
+		RegistrySize:        registrySize,
+		RegistryMaxSize:     registryMaxSize,
 	})
 
 	for _, lib := range builtinLibs {
@@ -50,8 +56,22 @@ func (s *Service) CreateSandbox(ctx context.Context, opts CreateOptions) (_ *San
 		state.SetGlobal(name, lua.LNil)
 	}
 
+	memory := &memoryLimiter{}
+	memory.limitBuiltins(state)
+	registerBuiltins(state, memory)
+
 	return &Sandbox{
 		state:      state,
+		memory:     memory,
 		operations: s.operations,
 	}, nil
 }
+
+const (
+	// callStackSize bounds the depth of nested function calls in a sandbox.
+	callStackSize = 200
+
+	// registrySize and registryMaxSize bound the number of values on the stack of a sandbox.
+	registrySize    = 1024
+	registryMaxSize = 256 * 1024
+)
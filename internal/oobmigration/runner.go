@@ -93,6 +93,95 @@ func (r *Runner) Register(id int, migrator Migrator, options MigratorOptions) er
 	return nil
 }
 
+// Registered returns true if a migrator is associated with the given migration identifier.
+func (r *Runner) Registered(id int) bool {
+	_, ok := r.migrators[id]
+	return ok
+}
+
+// maxStalledIterations is the number of consecutive invocations of a migrator without progress
+// after which RunToCompletion gives up on its migration.
+const maxStalledIterations = 5
+
+// RunToCompletion runs the migrators associated with the given migration identifiers in the
+// forward direction, one after the other, until each of the migrations is complete. Unlike
+// Start, this method blocks and stops at the first error returned by a migrator. The given
+// callback is invoked with the migration record after each invocation of a migrator.
+// Invocations are spaced by the interval of the migrator, and a migration fails if it makes no
+// progress in maxStalledIterations consecutive invocations.
+//
+// This is used to run required migrations inline during multi-version upgrades, while no
+// Sourcegraph instance is running against the database.
+func (r *Runner) RunToCompletion(ctx context.Context, ids []int, onProgress func(migration Migration)) error {
+	// IMPORTANT: migration tasks should always be privileged (see newRunner).
+	ctx = actor.WithInternalActor(ctx)
+
+	migrations, err := r.store.List(ctx)
+	if err != nil {
+		return err
+	}
+	migrationsByID := make(map[int]Migration, len(migrations))
+	for _, migration := range migrations {
+		migrationsByID[migration.ID] = migration
+	}
+
+	for _, id := range ids {
+		migration, ok := migrationsByID[id]
+		if !ok {
+			return errors.Newf("unknown migration %d", id)
+		}
+		migrator, ok := r.migrators[id]
+		if !ok {
+			return errors.Newf("no migrator registered for migration %d", id)
+		}
+		if migration.ApplyReverse {
+			return errors.Newf("migration %d is being applied in the reverse direction", id)
+		}
+
+		// Refresh our progress before migrating, as in runMigrator
+		if err := updateProgress(ctx, r.store, &migration, migrator.Migrator); err != nil {
+			return err
+		}
+		onProgress(migration)
+
+		stalledIterations := 0
+		for !migration.Complete() {
+			previousProgress := migration.Progress
+
+			if migrationErr := runMigrationUp(ctx, &migration, migrator.Migrator, r.operations); migrationErr != nil {
+				if err := r.store.AddError(ctx, migration.ID, migrationErr.Error()); err != nil {
+					return err
+				}
+
+				return errors.Wrapf(migrationErr, "migration %d", id)
+			}
+
+			if err := updateProgress(ctx, r.store, &migration, migrator.Migrator); err != nil {
+				return err
+			}
+			onProgress(migration)
+
+			if migration.Complete() {
+				break
+			}
+			if migration.Progress > previousProgress {
+				stalledIterations = 0
+			} else if stalledIterations++; stalledIterations >= maxStalledIterations {
+				return errors.Newf("migration %d made no progress in %d iterations", id, stalledIterations)
+			}
+
+			// Wait between invocations of the migrator, as when running in the background
+			select {
+			case <-migrator.ticker.Chan():
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	return nil
+}
+
 type migrationStatusError struct {
 	id               int
 	expectedProgress float64
@@ -130,7 +219,7 @@ func (r *Runner) Validate(ctx context.Context, currentVersion, firstVersion Vers
 
 	errs := make([]error, 0, len(migrations))
 	for _, migration := range migrations {
-		currentVersionCmpIntroduced := compareVersions(currentVersion, migration.Introduced)
+		currentVersionCmpIntroduced := CompareVersions(currentVersion, migration.Introduced)
 		if currentVersionCmpIntroduced == VersionOrderBefore && migration.Progress != 0 {
 			// Unfinished rollback: currentVersion before introduced version and progress > 0
 			errs = append(errs, newMigrationStatusError(migration.ID, 0, migration.Progress))
@@ -140,13 +229,13 @@ func (r *Runner) Validate(ctx context.Context, currentVersion, firstVersion Vers
 			continue
 		}
 
-		firstVersionCmpDeprecated := compareVersions(firstVersion, *migration.Deprecated)
+		firstVersionCmpDeprecated := CompareVersions(firstVersion, *migration.Deprecated)
 		if firstVersionCmpDeprecated != VersionOrderBefore {
 			// Edge case: sourcegraph instance booted on or after deprecation version
 			continue
 		}
 
-		currentVersionCmpDeprecated := compareVersions(currentVersion, *migration.Deprecated)
+		currentVersionCmpDeprecated := CompareVersions(currentVersion, *migration.Deprecated)
 		if currentVersionCmpDeprecated != VersionOrderBefore && migration.Progress != 1 {
 			// Unfinished migration: currentVersion on or after deprecated version, progress < 1
 			errs = append(errs, newMigrationStatusError(migration.ID, 1, migration.Progress))
@@ -78,6 +78,110 @@ func TestRunnerError(t *testing.T) {
 	}
 }
 
+func TestRunnerRunToCompletion(t *testing.T) {
+	store := NewMockStoreIface()
+	store.ListFunc.SetDefaultReturn([]Migration{
+		{ID: 1, Progress: 0},
+		{ID: 2, Progress: 1},
+	}, nil)
+
+	runner := newRunner(store, nil, &observation.TestContext)
+
+	migrator1 := NewMockMigrator()
+	migrator1.ProgressFunc.PushReturn(0, nil)
+	migrator1.ProgressFunc.PushReturn(0.5, nil)
+	migrator1.ProgressFunc.SetDefaultReturn(1, nil)
+	migrator2 := NewMockMigrator()
+	migrator2.ProgressFunc.SetDefaultReturn(1, nil)
+
+	ticker := glock.NewMockTicker(time.Second)
+	if err := runner.Register(1, migrator1, MigratorOptions{ticker: ticker}); err != nil {
+		t.Fatalf("unexpected error registering migrator: %s", err)
+	}
+	if err := runner.Register(2, migrator2, MigratorOptions{}); err != nil {
+		t.Fatalf("unexpected error registering migrator: %s", err)
+	}
+
+	var progress []float64
+	errs := make(chan error, 1)
+	go func() {
+		errs <- runner.RunToCompletion(context.Background(), []int{1, 2}, func(migration Migration) {
+			progress = append(progress, migration.Progress)
+		})
+	}()
+	tickN(ticker, 1)
+	if err := <-errs; err != nil {
+		t.Fatalf("unexpected error running migrations: %s", err)
+	}
+
+	if diff := cmp.Diff([]float64{0, 0.5, 1, 1}, progress); diff != "" {
+		t.Errorf("unexpected progress (-want +got):\n%s", diff)
+	}
+	if callCount := len(migrator1.UpFunc.History()); callCount != 2 {
+		t.Errorf("unexpected number of calls to Up. want=%d have=%d", 2, callCount)
+	}
+	if callCount := len(migrator2.UpFunc.History()); callCount != 0 {
+		t.Errorf("unexpected number of calls to Up. want=%d have=%d", 0, callCount)
+	}
+}
+
+func TestRunnerRunToCompletionError(t *testing.T) {
+	store := NewMockStoreIface()
+	store.ListFunc.SetDefaultReturn([]Migration{
+		{ID: 1, Progress: 0.5},
+	}, nil)
+
+	runner := newRunner(store, nil, &observation.TestContext)
+
+	migrator := NewMockMigrator()
+	migrator.ProgressFunc.SetDefaultReturn(0.5, nil)
+	migrator.UpFunc.SetDefaultReturn(errors.New("uh-oh"))
+
+	if err := runner.Register(1, migrator, MigratorOptions{}); err != nil {
+		t.Fatalf("unexpected error registering migrator: %s", err)
+	}
+
+	if err := runner.RunToCompletion(context.Background(), []int{1}, func(Migration) {}); err == nil {
+		t.Fatalf("expected error running migrations")
+	}
+	if calls := store.AddErrorFunc.History(); len(calls) != 1 {
+		t.Fatalf("unexpected number of calls to AddError. want=%d have=%d", 1, len(calls))
+	}
+
+	if err := runner.RunToCompletion(context.Background(), []int{2}, func(Migration) {}); err == nil {
+		t.Fatalf("expected error running unknown migration")
+	}
+}
+
+func TestRunnerRunToCompletionStalled(t *testing.T) {
+	store := NewMockStoreIface()
+	store.ListFunc.SetDefaultReturn([]Migration{
+		{ID: 1, Progress: 0.5},
+	}, nil)
+
+	runner := newRunner(store, nil, &observation.TestContext)
+
+	migrator := NewMockMigrator()
+	migrator.ProgressFunc.SetDefaultReturn(0.5, nil)
+
+	ticker := glock.NewMockTicker(time.Second)
+	if err := runner.Register(1, migrator, MigratorOptions{ticker: ticker}); err != nil {
+		t.Fatalf("unexpected error registering migrator: %s", err)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- runner.RunToCompletion(context.Background(), []int{1}, func(Migration) {})
+	}()
+	tickN(ticker, maxStalledIterations-1)
+	if err := <-errs; err == nil {
+		t.Fatalf("expected error running stalled migration")
+	}
+	if callCount := len(migrator.UpFunc.History()); callCount != maxStalledIterations {
+		t.Errorf("unexpected number of calls to Up. want=%d have=%d", maxStalledIterations, callCount)
+	}
+}
+
 func TestRunnerRemovesCompleted(t *testing.T) {
 	store := NewMockStoreIface()
 	ticker1 := glock.NewMockTicker(time.Second)
@@ -1,6 +1,10 @@
 package oobmigration
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver"
+)
 
 type Version struct {
 	Major int
@@ -14,6 +18,17 @@ func NewVersion(major, minor int) Version {
 	}
 }
 
+// NewVersionFromString parses a version of the form `X.Y`, `X.Y.Z` or `vX.Y.Z`. Only the
+// major and minor components of the version are retained.
+func NewVersionFromString(v string) (Version, error) {
+	semverVersion, err := semver.NewVersion(v)
+	if err != nil {
+		return Version{}, err
+	}
+
+	return toVersion(semverVersion), nil
+}
+
 func (v Version) String() string {
 	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
 }
@@ -26,8 +41,8 @@ const (
 	VersionOrderAfter
 )
 
-// compareVersions returns the relationship between `a (op) b`.
-func compareVersions(a, b Version) VersionOrder {
+// CompareVersions returns the relationship between `a (op) b`.
+func CompareVersions(a, b Version) VersionOrder {
 	for _, pair := range [][2]int{
 		{a.Major, b.Major},
 		{a.Minor, b.Minor},
@@ -17,9 +17,32 @@ func TestCompareVersions(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		order := compareVersions(testCase.left, testCase.right)
+		order := CompareVersions(testCase.left, testCase.right)
 		if order != testCase.expected {
 			t.Errorf("unexpected order. want=%d have=%d", testCase.expected, order)
 		}
 	}
 }
+
+func TestNewVersionFromString(t *testing.T) {
+	testCases := map[string]Version{
+		"3.41":    NewVersion(3, 41),
+		"3.41.2":  NewVersion(3, 41),
+		"v4.0.0":  NewVersion(4, 0),
+		"v3.40.1": NewVersion(3, 40),
+	}
+
+	for value, expected := range testCases {
+		version, err := NewVersionFromString(value)
+		if err != nil {
+			t.Fatalf("unexpected error parsing %q: %s", value, err)
+		}
+		if version != expected {
+			t.Errorf("unexpected version for %q. want=%s have=%s", value, expected, version)
+		}
+	}
+
+	if _, err := NewVersionFromString("latest"); err == nil {
+		t.Errorf("expected error parsing %q", "latest")
+	}
+}
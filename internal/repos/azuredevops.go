@@ -0,0 +1,161 @@
+package repos
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/azuredevops"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// An AzureDevOpsSource yields repositories from a single Azure DevOps connection
+// configured in Sourcegraph via the external services configuration.
+type AzureDevOpsSource struct {
+	svc       *types.ExternalService
+	cli       *azuredevops.Client
+	serviceID string
+	exclude   excludeFunc
+	perPage   int
+}
+
+// NewAzureDevOpsSource returns a new AzureDevOpsSource from the given external service.
+func NewAzureDevOpsSource(svc *types.ExternalService, cf *httpcli.Factory) (*AzureDevOpsSource, error) {
+	var c schema.AzureDevOpsConnection
+	if err := jsonc.Unmarshal(svc.Config, &c); err != nil {
+		return nil, errors.Wrapf(err, "external service id=%d config error", svc.ID)
+	}
+
+	if cf == nil {
+		cf = httpcli.ExternalClientFactory
+	}
+
+	httpCli, err := cf.Doer()
+	if err != nil {
+		return nil, err
+	}
+
+	var eb excludeBuilder
+	for _, r := range c.Exclude {
+		eb.Exact(r.Name)
+		eb.Exact(r.Id)
+		eb.Pattern(r.Pattern)
+	}
+	exclude, err := eb.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	cli, err := azuredevops.NewClient(svc.URN(), &c, httpCli)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AzureDevOpsSource{
+		svc:       svc,
+		cli:       cli,
+		serviceID: extsvc.NormalizeBaseURL(cli.URL).String(),
+		exclude:   exclude,
+		perPage:   100,
+	}, nil
+}
+
+// ListRepos returns all Azure DevOps repositories of the organizations and
+// projects configured with this AzureDevOpsSource's config.
+func (s *AzureDevOpsSource) ListRepos(ctx context.Context, results chan SourceResult) {
+	// A project can be configured both directly and through its organization.
+	seen := make(map[string]bool)
+
+	listProject := func(org, project string) error {
+		repos, err := s.cli.ListRepositoriesByProject(ctx, org, project)
+		if err != nil {
+			return err
+		}
+
+		for _, r := range repos {
+			if seen[r.ID] || s.excludes(org, r) {
+				continue
+			}
+			seen[r.ID] = true
+
+			results <- SourceResult{Source: s, Repo: s.makeRepo(org, r)}
+		}
+		return nil
+	}
+
+	for _, org := range s.cli.Config.Orgs {
+		args := azuredevops.ListProjectsArgs{Org: org, Top: s.perPage}
+		for {
+			page, err := s.cli.ListProjects(ctx, args)
+			if err != nil {
+				results <- SourceResult{Source: s, Err: err}
+				break
+			}
+
+			for _, p := range page.Projects {
+				if err := listProject(org, p.Name); err != nil {
+					results <- SourceResult{Source: s, Err: err}
+				}
+			}
+
+			if page.ContinuationToken == "" {
+				break
+			}
+			args.ContinuationToken = page.ContinuationToken
+		}
+	}
+
+	for _, project := range s.cli.Config.Projects {
+		org, name, ok := strings.Cut(project, "/")
+		if !ok {
+			results <- SourceResult{Source: s, Err: errors.Errorf("invalid Azure DevOps project %q, expected \"org/project\"", project)}
+			continue
+		}
+
+		if err := listProject(org, name); err != nil {
+			results <- SourceResult{Source: s, Err: err}
+		}
+	}
+}
+
+// ExternalServices returns a singleton slice containing the external service.
+func (s *AzureDevOpsSource) ExternalServices() types.ExternalServices {
+	return types.ExternalServices{s.svc}
+}
+
+// excludes returns true if the given repository is excluded by the config, or
+// cannot be cloned because it has been disabled.
+func (s *AzureDevOpsSource) excludes(org string, r *azuredevops.Repository) bool {
+	return r.IsDisabled || s.exclude(path.Join(org, r.Project.Name, r.Name)) || s.exclude(r.ID)
+}
+
+func (s *AzureDevOpsSource) makeRepo(org string, r *azuredevops.Repository) *types.Repo {
+	urn := s.svc.URN()
+
+	name := path.Join(s.cli.URL.Host, s.cli.URL.Path, org, r.Project.Name, r.Name)
+
+	return &types.Repo{
+		Name:    api.RepoName(name),
+		URI:     name,
+		Fork:    r.IsFork,
+		Private: r.Project.Visibility != "public",
+		ExternalRepo: api.ExternalRepoSpec{
+			ID:          r.ID,
+			ServiceType: extsvc.TypeAzureDevOps,
+			ServiceID:   s.serviceID,
+		},
+		Sources: map[string]*types.SourceInfo{
+			urn: {
+				ID:       urn,
+				CloneURL: r.RemoteURL,
+			},
+		},
+		Metadata: r,
+	}
+}
@@ -0,0 +1,46 @@
+package repos
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/testutil"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestAzureDevOpsSource_ListRepos(t *testing.T) {
+	conf := &schema.AzureDevOpsConnection{
+		Url:      "https://dev.azure.com",
+		Username: "admin",
+		Orgs:     []string{"sgtest"},
+		// The backend project is also listed through its organization.
+		Projects: []string{"sgtest/backend", "other/tools"},
+		Exclude: []*schema.ExcludedAzureDevOpsRepo{
+			{Name: "sgtest/backend/billing"},
+			{Pattern: "^sgtest/infrastructure/legacy-.*"},
+		},
+	}
+	cf, save := newClientFactory(t, t.Name())
+	defer save(t)
+
+	svc := &types.ExternalService{
+		Kind:   extsvc.KindAzureDevOps,
+		Config: marshalJSON(t, conf),
+	}
+
+	src, err := NewAzureDevOpsSource(svc, cf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src.perPage = 2 // 2 pages for 3 projects
+
+	repos, err := listAll(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.AssertGolden(t, "testdata/sources/"+t.Name(), update(t.Name()), repos)
+}
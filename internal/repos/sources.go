@@ -68,12 +68,16 @@ func NewSource(ctx context.Context, db database.DB, svc *types.ExternalService,
 		return NewJVMPackagesSource(svc)
 	case extsvc.KindPagure:
 		return NewPagureSource(svc, cf)
+	case extsvc.KindAzureDevOps:
+		return NewAzureDevOpsSource(svc, cf)
 	case extsvc.KindNpmPackages:
 		return NewNpmPackagesSource(svc, cf)
 	case extsvc.KindPythonPackages:
 		return NewPythonPackagesSource(svc, cf)
 	case extsvc.KindRustPackages:
 		return NewRustPackagesSource(svc, cf)
+	case extsvc.KindSubversion:
+		return NewSubversionSource(svc)
 	case extsvc.KindOther:
 		return NewOtherSource(svc, cf)
 	default:
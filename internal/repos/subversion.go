@@ -0,0 +1,102 @@
+package repos
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/subversion"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// A SubversionSource yields repositories from a single Subversion connection
+// configured in Sourcegraph via the external services configuration.
+type SubversionSource struct {
+	svc    *types.ExternalService
+	config *schema.SubversionConnection
+	url    *url.URL
+}
+
+// NewSubversionSource returns a new SubversionSource from the given external
+// service.
+func NewSubversionSource(svc *types.ExternalService) (*SubversionSource, error) {
+	var c schema.SubversionConnection
+	if err := jsonc.Unmarshal(svc.Config, &c); err != nil {
+		return nil, errors.Errorf("external service id=%d config error: %s", svc.ID, err)
+	}
+	return newSubversionSource(svc, &c)
+}
+
+func newSubversionSource(svc *types.ExternalService, c *schema.SubversionConnection) (*SubversionSource, error) {
+	u, err := url.Parse(c.Url)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse url")
+	}
+	// Credentials are configured separately and added to the clone URLs by CloneURL.
+	u.User = nil
+	return &SubversionSource{
+		svc:    svc,
+		config: c,
+		url:    extsvc.NormalizeBaseURL(u),
+	}, nil
+}
+
+// ListRepos returns all Subversion repositories configured in the connection.
+func (s SubversionSource) ListRepos(ctx context.Context, results chan SourceResult) {
+	for _, path := range s.config.Repos {
+		results <- SourceResult{Source: s, Repo: s.makeRepo(path)}
+	}
+}
+
+// composeSubversionCloneURL composes a clone URL for the Subversion repository
+// at the given path of the server, e.g. https://svn.example.com/repos/project.
+func composeSubversionCloneURL(base *url.URL, path string) string {
+	cloneURL := *base
+	cloneURL.Path = strings.TrimSuffix(base.Path, "/") + "/" + strings.Trim(path, "/")
+	return cloneURL.String()
+}
+
+func (s SubversionSource) makeRepo(path string) *types.Repo {
+	path = strings.Trim(path, "/")
+	urn := s.svc.URN()
+	base := s.url.Host + s.url.Path
+
+	return &types.Repo{
+		Name: reposource.SubversionRepoName(
+			s.config.RepositoryPathPattern,
+			base,
+			path,
+		),
+		URI: string(reposource.SubversionRepoName(
+			"",
+			base,
+			path,
+		)),
+		ExternalRepo: api.ExternalRepoSpec{
+			ID:          path,
+			ServiceType: extsvc.TypeSubversion,
+			ServiceID:   s.url.String(),
+		},
+		Private: true,
+		Sources: map[string]*types.SourceInfo{
+			urn: {
+				ID:       urn,
+				CloneURL: composeSubversionCloneURL(s.url, path),
+			},
+		},
+		Metadata: &subversion.Repository{
+			Path: path,
+		},
+	}
+}
+
+// ExternalServices returns a singleton slice containing the external service.
+func (s SubversionSource) ExternalServices() types.ExternalServices {
+	return types.ExternalServices{s.svc}
+}
@@ -0,0 +1,67 @@
+package repos
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/subversion"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestSubversionSource_makeRepo(t *testing.T) {
+	svc := types.ExternalService{ID: 1, Kind: extsvc.KindSubversion}
+
+	tests := []struct {
+		name     string
+		schema   *schema.SubversionConnection
+		path     string
+		wantName api.RepoName
+		wantURL  string
+	}{
+		{
+			name: "simple",
+			schema: &schema.SubversionConnection{
+				Url:      "https://admin@SVN.example.com/repos",
+				Username: "admin",
+				Password: "pa$$word",
+			},
+			path:     "/legacy/app/",
+			wantName: "svn.example.com/repos/legacy/app",
+			wantURL:  "https://svn.example.com/repos/legacy/app",
+		},
+		{
+			name: "path-pattern",
+			schema: &schema.SubversionConnection{
+				Url:                   "svn://svn.example.com:3690",
+				RepositoryPathPattern: "svn/{repo}",
+			},
+			path:     "project",
+			wantName: "svn/project",
+			wantURL:  "svn://svn.example.com:3690/project",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := newSubversionSource(&svc, test.schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			repo := s.makeRepo(test.path)
+			if repo.Name != test.wantName {
+				t.Errorf("wrong name, got: %q, want: %q", repo.Name, test.wantName)
+			}
+			if got := repo.Sources[svc.URN()].CloneURL; got != test.wantURL {
+				t.Errorf("wrong clone URL, got: %q, want: %q", got, test.wantURL)
+			}
+			wantMetadata := &subversion.Repository{Path: string(repo.ExternalRepo.ID)}
+			if diff := cmp.Diff(wantMetadata, repo.Metadata); diff != "" {
+				t.Errorf("unexpected metadata (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -66,11 +66,19 @@ func (j *SearchJob) Run(ctx context.Context, clients job.RuntimeClients, stream
 			IncludeDiff:          j.Diff,
 			Limit:                j.Limit,
 			IncludeModifiedFiles: j.IncludeModifiedFiles,
+			RangeDiff:            j.Diff && isRangeDiff(repoRev.Revs),
 		}
 
 		onMatches := func(in []protocol.CommitMatch) {
 			res := make([]result.Match, 0, len(in))
 			for _, protocolMatch := range in {
+				if args.RangeDiff {
+					// Range diff searches return one match per changed file.
+					for _, m := range protocolMatchToCommitMatch(repoRev.Repo, true, protocolMatch).CommitToDiffMatches() {
+						res = append(res, m)
+					}
+					continue
+				}
 				res = append(res, protocolMatchToCommitMatch(repoRev.Repo, j.Diff, protocolMatch))
 			}
 			stream.Send(streaming.SearchEvent{
@@ -228,6 +236,16 @@ func QueryToGitQuery(b query.Basic, diff bool) gitprotocol.Node {
 	return gitprotocol.Reduce(gitprotocol.NewAnd(res...))
 }
 
+// isRangeDiff returns whether the revisions are a single range of two revisions, such as
+// rev:v1.2.0...v1.3.0, in which case diff searches search the aggregated diff of the range.
+func isRangeDiff(revs []search.RevisionSpecifier) bool {
+	if len(revs) != 1 {
+		return false
+	}
+	_, _, ok := revs[0].RangeDiff()
+	return ok
+}
+
 func searchRevsToGitserverRevs(in []search.RevisionSpecifier) []gitprotocol.RevisionSpecifier {
 	out := make([]gitprotocol.RevisionSpecifier, 0, len(in))
 	for _, rev := range in {
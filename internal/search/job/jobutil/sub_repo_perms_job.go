@@ -104,6 +104,15 @@ func applySubRepoFiltering(ctx context.Context, checker authz.SubRepoPermissionC
 			if allowed {
 				filtered = append(filtered, m)
 			}
+		case *result.CommitDiffMatch:
+			allowed, err := authz.CanReadAllPaths(ctx, checker, mm.Repo.Name, mm.Paths())
+			if err != nil {
+				errs = errors.Append(errs, err)
+				continue
+			}
+			if allowed {
+				filtered = append(filtered, m)
+			}
 		case *result.RepoMatch:
 			// Repo filtering is taking care of by our usual repo filtering logic
 			filtered = append(filtered, m)
@@ -39,6 +39,16 @@ func (r1 RevisionSpecifier) String() string {
 	return r1.RevSpec
 }
 
+// RangeDiff returns the base and head revisions if the revision specifier is a range of two
+// revisions in the form base...head, whose aggregated diff can be searched.
+func (r1 RevisionSpecifier) RangeDiff() (base, head string, ok bool) {
+	base, head, ok = strings.Cut(r1.RevSpec, "...")
+	if !ok || base == "" || head == "" || strings.Contains(head, "...") {
+		return "", "", false
+	}
+	return base, head, true
+}
+
 // Less compares two revspecOrRefGlob entities, suitable for use
 // with sort.Slice()
 //
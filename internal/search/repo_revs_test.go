@@ -43,3 +43,21 @@ func TestParseRepositoryRevisions(t *testing.T) {
 		})
 	}
 }
+
+func TestRevisionSpecifierRangeDiff(t *testing.T) {
+	tests := map[string][2]string{
+		"v1.2.0...v1.3.0": {"v1.2.0", "v1.3.0"},
+		"main...HEAD~2":   {"main", "HEAD~2"},
+		"v1.2.0..v1.3.0":  {},
+		"v1.2.0...":       {},
+		"...v1.3.0":       {},
+		"a...b...c":       {},
+		"HEAD":            {},
+	}
+	for revSpec, want := range tests {
+		base, head, ok := RevisionSpecifier{RevSpec: revSpec}.RangeDiff()
+		if ok != (want != [2]string{}) || base != want[0] || head != want[1] {
+			t.Errorf("%q: got (%q, %q, %v), want %q", revSpec, base, head, ok, want)
+		}
+	}
+}
@@ -342,8 +342,21 @@ func (r *Resolver) Resolve(ctx context.Context, op search.RepoOptions) (Resolved
 					rev.RevSpec = "HEAD"
 				}
 
-				trimmedRefSpec := strings.TrimPrefix(rev.RevSpec, "^") // handle negated revisions, such as ^<branch>, ^<tag>, or ^<commit>
-				commitID, err := gitserver.NewClient(r.DB).ResolveRevision(ctx, repoRev.Repo.Name, trimmedRefSpec, gitserver.ResolveRevisionOptions{NoEnsureRevision: true})
+				specs := []string{rev.RevSpec}
+				if base, head, ok := rev.RangeDiff(); ok {
+					// Both revisions of a range must exist, the head is resolved last.
+					specs = []string{base, head}
+				}
+
+				var commitID api.CommitID
+				var err error
+				for _, spec := range specs {
+					trimmedRefSpec := strings.TrimPrefix(spec, "^") // handle negated revisions, such as ^<branch>, ^<tag>, or ^<commit>
+					commitID, err = gitserver.NewClient(r.DB).ResolveRevision(ctx, repoRev.Repo.Name, trimmedRefSpec, gitserver.ResolveRevisionOptions{NoEnsureRevision: true})
+					if err != nil {
+						break
+					}
+				}
 				if err != nil {
 					if errors.Is(err, context.DeadlineExceeded) || errors.HasType(err, gitdomain.BadCommitError{}) {
 						return err
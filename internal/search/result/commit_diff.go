@@ -33,6 +33,30 @@ func (cm *CommitDiffMatch) Path() string {
 	return nonEmptyPath
 }
 
+// Paths returns the paths of the file before and after the change, omitting the path of a
+// file that does not exist on either side of the change and duplicates.
+func (cm *CommitDiffMatch) Paths() []string {
+	var paths []string
+	if cm.OrigName != "/dev/null" {
+		paths = append(paths, cm.OrigName)
+	}
+	if cm.NewName != "/dev/null" && cm.NewName != cm.OrigName {
+		paths = append(paths, cm.NewName)
+	}
+	return paths
+}
+
+// ToCommitMatch returns a commit match whose diff preview is the one of the diff match, for
+// consumers that only know how to present commit matches.
+func (cm *CommitDiffMatch) ToCommitMatch() *CommitMatch {
+	return &CommitMatch{
+		Commit:        cm.Commit,
+		Repo:          cm.Repo,
+		DiffPreview:   cm.Preview,
+		ModifiedFiles: cm.Paths(),
+	}
+}
+
 func (cm *CommitDiffMatch) PathStatus() PathStatus {
 	if cm.OrigName == "/dev/null" {
 		return Added
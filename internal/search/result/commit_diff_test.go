@@ -3,6 +3,7 @@ package result
 import (
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/hexops/autogold"
 )
 
@@ -56,3 +57,22 @@ func TestCommitDiffMatch(t *testing.T) {
 		"client/web/src/enterprise/codeintel/badge/components/IndexerSummary.module.scss").
 		Equal(t, commitDiff.Path())
 }
+
+func TestCommitDiffMatchPaths(t *testing.T) {
+	tests := []struct {
+		file DiffFile
+		want []string
+	}{
+		{file: DiffFile{OrigName: "a", NewName: "a"}, want: []string{"a"}},
+		{file: DiffFile{OrigName: "a", NewName: "b"}, want: []string{"a", "b"}},
+		{file: DiffFile{OrigName: "/dev/null", NewName: "b"}, want: []string{"b"}},
+		{file: DiffFile{OrigName: "a", NewName: "/dev/null"}, want: []string{"a"}},
+	}
+	for _, test := range tests {
+		file := test.file
+		got := (&CommitDiffMatch{DiffFile: &file}).Paths()
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("unexpected paths for %s -> %s (-want +got):\n%s", file.OrigName, file.NewName, diff)
+		}
+	}
+}
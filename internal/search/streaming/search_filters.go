@@ -142,6 +142,8 @@ func (s *SearchFilters) Update(event SearchEvent) {
 			// We leave "rev" empty, instead of using "CommitMatch.Commit.ID". This way we
 			// get 1 filter per repo instead of 1 filter per sha in the side-bar.
 			addRepoFilter(v.Repo.Name, v.Repo.ID, "", int32(v.ResultCount()))
+		case *result.CommitDiffMatch:
+			addRepoFilter(v.Repo.Name, v.Repo.ID, "", int32(v.ResultCount()))
 		}
 	}
 }
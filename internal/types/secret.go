@@ -78,8 +78,12 @@ func (e *ExternalService) RedactedConfig() (string, error) {
 		}
 	case *schema.PagureConnection:
 		es.redactString(c.Token, "token")
+	case *schema.AzureDevOpsConnection:
+		es.redactString(c.Token, "token")
 	case *schema.NpmPackagesConnection:
 		es.redactString(c.Credentials, "credentials")
+	case *schema.SubversionConnection:
+		es.redactString(c.Password, "password")
 	case *schema.OtherExternalServiceConnection:
 		err = es.redactURL(c.Url, "url")
 		if err != nil {
@@ -168,9 +172,15 @@ func (e *ExternalService) UnredactConfig(old *ExternalService) error {
 	case *schema.PagureConnection:
 		o := oldCfg.(*schema.PagureConnection)
 		es.unredactString(c.Token, o.Token, "token")
+	case *schema.AzureDevOpsConnection:
+		o := oldCfg.(*schema.AzureDevOpsConnection)
+		es.unredactString(c.Token, o.Token, "token")
 	case *schema.NpmPackagesConnection:
 		o := oldCfg.(*schema.NpmPackagesConnection)
 		es.unredactString(c.Credentials, o.Credentials, "credentials")
+	case *schema.SubversionConnection:
+		o := oldCfg.(*schema.SubversionConnection)
+		es.unredactString(c.Password, o.Password, "password")
 	case *schema.OtherExternalServiceConnection:
 		o := oldCfg.(*schema.OtherExternalServiceConnection)
 		err = es.unredactURL(c.Url, o.Url, "url")
@@ -0,0 +1,379 @@
+package uploadstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+type azureAPI interface {
+	CreateContainer(ctx context.Context, container string) error
+	GetBlob(ctx context.Context, container, name string, offset int64) (io.ReadCloser, error)
+	GetBlobProperties(ctx context.Context, container, name string) (*azureBlobProperties, error)
+	PutBlock(ctx context.Context, container, name, blockID string, content []byte) error
+	PutBlockFromBlob(ctx context.Context, container, name, blockID, source string, offset, count int64) error
+	PutBlockList(ctx context.Context, container, name string, blockIDs []string) error
+	DeleteBlob(ctx context.Context, container, name string) error
+	ListBlobs(ctx context.Context, container, prefix, marker string) (*azureBlobList, error)
+}
+
+// azureBlobList is a page of the blobs of a container.
+type azureBlobList struct {
+	Blobs      []azureBlob `xml:"Blobs>Blob"`
+	NextMarker string      `xml:"NextMarker"`
+}
+
+type azureBlob struct {
+	Name       string              `xml:"Name"`
+	Properties azureBlobProperties `xml:"Properties"`
+}
+
+type azureBlobProperties struct {
+	LastModified  string `xml:"Last-Modified"`
+	ContentLength int64  `xml:"Content-Length"`
+}
+
+// azureError is returned by the Azure Blob Storage REST API on failure. To learn more, see:
+//
+// https://docs.microsoft.com/en-us/rest/api/storageservices/blob-service-error-codes
+type azureError struct {
+	StatusCode int
+	Code       string
+}
+
+func (e *azureError) Error() string {
+	return fmt.Sprintf("azure blob storage: unexpected status code %d (%s)", e.StatusCode, e.Code)
+}
+
+// azureAPIVersion is the version of the Azure Blob Storage REST API used by azureClient.
+const azureAPIVersion = "2020-10-02"
+
+// azureClient is a client of the Azure Blob Storage REST API that authorizes requests with
+// the shared key of a storage account. To learn more, see:
+//
+// https://docs.microsoft.com/en-us/rest/api/storageservices/blob-service-rest-api
+//
+// The store only needs a handful of operations, so the client implements them against the
+// REST API directly instead of adding the Azure SDK for Go and its dependencies to the module.
+// This includes signing requests and shared access signatures with the shared key.
+type azureClient struct {
+	endpoint    *url.URL
+	accountName string
+	accountKey  []byte
+	doer        httpcli.Doer
+}
+
+var _ azureAPI = &azureClient{}
+
+func newAzureClient(doer httpcli.Doer, config AzureConfig) (*azureClient, error) {
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", config.AccountName)
+	}
+	u, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid endpoint")
+	}
+
+	accountKey, err := base64.StdEncoding.DecodeString(config.AccountKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid account key")
+	}
+
+	return &azureClient{
+		endpoint:    u,
+		accountName: config.AccountName,
+		accountKey:  accountKey,
+		doer:        doer,
+	}, nil
+}
+
+func (c *azureClient) CreateContainer(ctx context.Context, container string) error {
+	query := url.Values{"restype": {"container"}}
+	resp, err := c.do(ctx, http.MethodPut, container, "", query, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *azureClient) GetBlob(ctx context.Context, container, name string, offset int64) (io.ReadCloser, error) {
+	header := http.Header{}
+	if offset > 0 {
+		header.Set("x-ms-range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, container, name, nil, header, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *azureClient) GetBlobProperties(ctx context.Context, container, name string) (*azureBlobProperties, error) {
+	resp, err := c.do(ctx, http.MethodHead, container, name, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return &azureBlobProperties{
+		LastModified:  resp.Header.Get("Last-Modified"),
+		ContentLength: resp.ContentLength,
+	}, nil
+}
+
+func (c *azureClient) PutBlock(ctx context.Context, container, name, blockID string, content []byte) error {
+	query := url.Values{"comp": {"block"}, "blockid": {blockID}}
+	resp, err := c.do(ctx, http.MethodPut, container, name, query, nil, content)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// PutBlockFromBlob writes count bytes of the source blob of the same container starting at
+// offset into a block of the given blob, without the content going through the client. The
+// source is read by Azure through a shared access signature. To learn more, see:
+//
+// https://docs.microsoft.com/en-us/rest/api/storageservices/put-block-from-url
+func (c *azureClient) PutBlockFromBlob(ctx context.Context, container, name, blockID, source string, offset, count int64) error {
+	sourceURL, err := c.blobURL(container, source)
+	if err != nil {
+		return err
+	}
+	sourceURL.RawQuery = c.sharedAccessSignature(container, source, time.Now().Add(azureSharedAccessSignatureTTL)).Encode()
+
+	header := http.Header{}
+	header.Set("x-ms-copy-source", sourceURL.String())
+	header.Set("x-ms-source-range", fmt.Sprintf("bytes=%d-%d", offset, offset+count-1))
+
+	query := url.Values{"comp": {"block"}, "blockid": {blockID}}
+	resp, err := c.do(ctx, http.MethodPut, container, name, query, header, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *azureClient) PutBlockList(ctx context.Context, container, name string, blockIDs []string) error {
+	var body bytes.Buffer
+	body.WriteString(xml.Header)
+	body.WriteString("<BlockList>")
+	for _, blockID := range blockIDs {
+		body.WriteString("<Latest>")
+		_ = xml.EscapeText(&body, []byte(blockID))
+		body.WriteString("</Latest>")
+	}
+	body.WriteString("</BlockList>")
+
+	query := url.Values{"comp": {"blocklist"}}
+	resp, err := c.do(ctx, http.MethodPut, container, name, query, nil, body.Bytes())
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *azureClient) DeleteBlob(ctx context.Context, container, name string) error {
+	resp, err := c.do(ctx, http.MethodDelete, container, name, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (c *azureClient) ListBlobs(ctx context.Context, container, prefix, marker string) (*azureBlobList, error) {
+	query := url.Values{"restype": {"container"}, "comp": {"list"}}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if marker != "" {
+		query.Set("marker", marker)
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, container, "", query, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var list azureBlobList
+	if err := xml.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, errors.Wrap(err, "failed to decode blob list")
+	}
+	return &list, nil
+}
+
+// do sends a signed request for the given container, or blob if name is non-empty. An
+// *azureError is returned if the response does not have a 2xx status code.
+func (c *azureClient) do(ctx context.Context, method, container, name string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	u, err := c.blobURL(container, name)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	req.Header.Set("Authorization", "SharedKey "+c.accountName+":"+c.sign(req))
+
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, &azureError{StatusCode: resp.StatusCode, Code: resp.Header.Get("x-ms-error-code")}
+	}
+	return resp, nil
+}
+
+// blobURL returns the URL of the given container, or blob if name is non-empty.
+func (c *azureClient) blobURL(container, name string) (_ *url.URL, err error) {
+	segments := []string{container}
+	if name != "" {
+		segments = append(segments, strings.Split(name, "/")...)
+	}
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	u := *c.endpoint
+	u.RawPath = u.EscapedPath() + "/" + strings.Join(segments, "/")
+	u.Path, err = url.PathUnescape(u.RawPath)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// azureSharedAccessSignatureTTL is how long the shared access signatures created by the
+// client are valid.
+const azureSharedAccessSignatureTTL = 15 * time.Minute
+
+// sharedAccessSignature returns the query parameters of a service shared access signature
+// that grants read access to the given blob until expiry. To learn more, see:
+//
+// https://docs.microsoft.com/en-us/rest/api/storageservices/create-service-sas
+func (c *azureClient) sharedAccessSignature(container, name string, expiry time.Time) url.Values {
+	query := url.Values{
+		"sv": {azureAPIVersion},
+		"sr": {"b"},
+		"sp": {"r"},
+		"se": {expiry.UTC().Format("2006-01-02T15:04:05Z")},
+	}
+
+	stringToSign := strings.Join([]string{
+		query.Get("sp"),
+		"", // signedStart
+		query.Get("se"),
+		fmt.Sprintf("/blob/%s/%s/%s", c.accountName, container, name),
+		"", // signedIdentifier
+		"", // signedIP
+		"", // signedProtocol
+		query.Get("sv"),
+		query.Get("sr"),
+		"", // signedSnapshotTime
+		"", // rscc
+		"", // rscd
+		"", // rsce
+		"", // rscl
+		"", // rsct
+	}, "\n")
+
+	mac := hmac.New(sha256.New, c.accountKey)
+	_, _ = mac.Write([]byte(stringToSign))
+	query.Set("sig", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	return query
+}
+
+// sign returns the shared key signature of the given request. To learn more, see:
+//
+// https://docs.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key
+func (c *azureClient) sign(req *http.Request) string {
+	mac := hmac.New(sha256.New, c.accountKey)
+	_, _ = mac.Write([]byte(c.stringToSign(req)))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (c *azureClient) stringToSign(req *http.Request) string {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	var b strings.Builder
+	for _, value := range []string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, superseded by x-ms-date
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	} {
+		b.WriteString(value)
+		b.WriteString("\n")
+	}
+
+	// Canonicalized headers
+	var headerNames []string
+	for k := range req.Header {
+		if k := strings.ToLower(k); strings.HasPrefix(k, "x-ms-") {
+			headerNames = append(headerNames, k)
+		}
+	}
+	sort.Strings(headerNames)
+	for _, k := range headerNames {
+		fmt.Fprintf(&b, "%s:%s\n", k, strings.TrimSpace(req.Header.Get(k)))
+	}
+
+	// Canonicalized resource
+	fmt.Fprintf(&b, "/%s%s", c.accountName, req.URL.EscapedPath())
+	query := req.URL.Query()
+	var queryNames []string
+	for k := range query {
+		queryNames = append(queryNames, k)
+	}
+	sort.Strings(queryNames)
+	for _, k := range queryNames {
+		values := query[k]
+		sort.Strings(values)
+		fmt.Fprintf(&b, "\n%s:%s", strings.ToLower(k), strings.Join(values, ","))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,330 @@
+package uploadstore
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+type azureStore struct {
+	container       string
+	manageContainer bool
+	client          azureAPI
+	operations      *Operations
+}
+
+var _ Store = &azureStore{}
+
+type AzureConfig struct {
+	AccountName string
+	AccountKey  string
+
+	// Endpoint overrides the default endpoint of the storage account, e.g. to target an
+	// Azurite emulator.
+	Endpoint string
+}
+
+// newAzureFromConfig creates a new store backed by Azure Blob Storage. The bucket of the
+// configuration is used as the name of the container.
+//
+// Lifecycle management policies of Azure storage accounts cannot be configured through the
+// Blob Storage API, so the container is only created when the bucket is managed. Objects
+// must be expired by calling ExpireObjects periodically instead.
+func newAzureFromConfig(ctx context.Context, config Config, operations *Operations) (Store, error) {
+	client, err := newAzureClient(azureHTTPClient, config.Azure)
+	if err != nil {
+		return nil, err
+	}
+
+	return newAzureWithClient(client, config.Bucket, config.ManageBucket, operations), nil
+}
+
+// azureHTTPClient is the client used to send requests to Azure Blob Storage. Unlike
+// httpcli.ExternalDoer, it neither caches responses nor times out requests, as blobs can be
+// large and are read in ranges when a download is resumed.
+var azureHTTPClient, _ = httpcli.NewFactory(
+	httpcli.NewMiddleware(
+		httpcli.ContextErrorMiddleware,
+		httpcli.HeadersMiddleware("User-Agent", "Sourcegraph-Bot"),
+	),
+	httpcli.ExternalTransportOpt,
+	httpcli.TracedTransportOpt,
+).Doer()
+
+func newAzureWithClient(client azureAPI, container string, manageContainer bool, operations *Operations) *azureStore {
+	return &azureStore{
+		container:       container,
+		manageContainer: manageContainer,
+		client:          client,
+		operations:      operations,
+	}
+}
+
+func (s *azureStore) Init(ctx context.Context) error {
+	if !s.manageContainer {
+		return nil
+	}
+
+	if err := s.client.CreateContainer(ctx, s.container); err != nil {
+		var e *azureError
+		if errors.As(err, &e) && e.Code == "ContainerAlreadyExists" {
+			return nil
+		}
+
+		return errors.Wrap(err, "failed to create container")
+	}
+
+	return nil
+}
+
+func (s *azureStore) Get(ctx context.Context, key string) (_ io.ReadCloser, err error) {
+	ctx, _, endObservation := s.operations.Get.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("key", key),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	reader := writeToPipe(func(w io.Writer) error {
+		zeroReads := 0
+		byteOffset := int64(0)
+
+		for {
+			n, err := s.readObjectInto(ctx, w, key, byteOffset)
+			if err == nil || !isConnectionResetError(err) {
+				return err
+			}
+
+			byteOffset += n
+			log15.Warn("Transient error while reading payload", "key", key, "error", err)
+
+			if n == 0 {
+				zeroReads++
+
+				if zeroReads > maxZeroReads {
+					return errNoDownloadProgress
+				}
+			} else {
+				zeroReads = 0
+			}
+		}
+	})
+
+	return io.NopCloser(reader), nil
+}
+
+// readObjectInto reads the content of the given key starting at the given byte offset into the
+// given writer. The number of bytes read is returned. On successful read, the error value is nil.
+func (s *azureStore) readObjectInto(ctx context.Context, w io.Writer, key string, byteOffset int64) (int64, error) {
+	rc, err := s.client.GetBlob(ctx, s.container, key, byteOffset)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get object")
+	}
+	defer rc.Close()
+
+	return ioCopyHook(w, rc)
+}
+
+func (s *azureStore) Upload(ctx context.Context, key string, r io.Reader) (_ int64, err error) {
+	ctx, _, endObservation := s.operations.Upload.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("key", key),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	blockIDs, n, err := s.putBlocks(ctx, key, r, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to upload object")
+	}
+
+	if err := s.client.PutBlockList(ctx, s.container, key, blockIDs); err != nil {
+		return 0, errors.Wrap(err, "failed to upload object")
+	}
+
+	return n, nil
+}
+
+// Compose copies the content of the source objects into blocks of the destination object with
+// Put Block From URL, so the content is copied by Azure without going through this process. The
+// sources are read through shared access signatures signed by the account key.
+func (s *azureStore) Compose(ctx context.Context, destination string, sources ...string) (_ int64, err error) {
+	ctx, _, endObservation := s.operations.Compose.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("destination", destination),
+		log.String("sources", strings.Join(sources, ", ")),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	defer func() {
+		if err == nil {
+			// Delete sources on success
+			if err := s.deleteSources(ctx, sources); err != nil {
+				log15.Error("Failed to delete source objects", "error", err)
+			}
+		}
+	}()
+
+	var (
+		blockIDs []string
+		total    int64
+	)
+	for _, source := range sources {
+		n, err := s.composeSource(ctx, destination, source, &blockIDs)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to compose objects")
+		}
+		total += n
+	}
+
+	// Blocks that are not committed are garbage collected by Azure after a week
+	if err := s.client.PutBlockList(ctx, s.container, destination, blockIDs); err != nil {
+		return 0, errors.Wrap(err, "failed to compose objects")
+	}
+
+	return total, nil
+}
+
+// composeSource copies the content of the given source object into blocks of the destination
+// object. The identifiers of the blocks are appended to the given ones.
+func (s *azureStore) composeSource(ctx context.Context, destination, source string, blockIDs *[]string) (int64, error) {
+	properties, err := s.client.GetBlobProperties(ctx, s.container, source)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get source object properties")
+	}
+
+	for offset := int64(0); offset < properties.ContentLength; offset += azureCopyBlockSize {
+		count := properties.ContentLength - offset
+		if count > azureCopyBlockSize {
+			count = azureCopyBlockSize
+		}
+
+		blockID := azureBlockID(len(*blockIDs))
+		if err := s.client.PutBlockFromBlob(ctx, s.container, destination, blockID, source, offset, count); err != nil {
+			return 0, errors.Wrap(err, "failed to copy block")
+		}
+		*blockIDs = append(*blockIDs, blockID)
+	}
+
+	return properties.ContentLength, nil
+}
+
+func (s *azureStore) Delete(ctx context.Context, key string) (err error) {
+	ctx, _, endObservation := s.operations.Delete.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("key", key),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	return errors.Wrap(s.deleteBlob(ctx, key), "failed to delete object")
+}
+
+func (s *azureStore) ExpireObjects(ctx context.Context, prefix string, maxAge time.Duration) (err error) {
+	ctx, _, endObservation := s.operations.ExpireObjects.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("prefix", prefix),
+		log.String("maxAge", maxAge.String()),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	var keys []string
+	threshold := time.Now().Add(-maxAge)
+
+	for marker := ""; ; {
+		list, err := s.client.ListBlobs(ctx, s.container, prefix, marker)
+		if err != nil {
+			return errors.Wrap(err, "failed to list objects")
+		}
+
+		for _, blob := range list.Blobs {
+			lastModified, err := time.Parse(http.TimeFormat, blob.Properties.LastModified)
+			if err != nil {
+				return errors.Wrapf(err, "invalid last modified time of object %q", blob.Name)
+			}
+
+			if lastModified.Before(threshold) {
+				keys = append(keys, blob.Name)
+			}
+		}
+
+		if list.NextMarker == "" {
+			break
+		}
+		marker = list.NextMarker
+	}
+
+	return s.deleteSources(ctx, keys)
+}
+
+// azureBlockSize is the maximum size of the blocks written by putBlocks.
+const azureBlockSize = 8 * 1024 * 1024
+
+// azureCopyBlockSize is the maximum size of the blocks copied by composeSource, which is the
+// maximum size of a block put from a URL.
+const azureCopyBlockSize = 100 * 1024 * 1024
+
+// azureBlockID returns the identifier of the block at the given index of a blob. Block
+// identifiers of a blob must all have the same length.
+func azureBlockID(index int) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%010d", index)))
+}
+
+// putBlocks writes the content of the given reader into uncommitted blocks of the object at
+// the given key. The identifiers of the written blocks are appended to the given ones and
+// returned together with the number of bytes written.
+func (s *azureStore) putBlocks(ctx context.Context, key string, r io.Reader, blockIDs []string) ([]string, int64, error) {
+	var n int64
+	buf := make([]byte, azureBlockSize)
+
+	for {
+		m, err := io.ReadFull(r, buf)
+		if m > 0 {
+			blockID := azureBlockID(len(blockIDs))
+
+			if err := s.client.PutBlock(ctx, s.container, key, blockID, buf[:m]); err != nil {
+				return nil, 0, errors.Wrap(err, "failed to put block")
+			}
+
+			blockIDs = append(blockIDs, blockID)
+			n += int64(m)
+		}
+
+		if err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return blockIDs, n, nil
+			}
+
+			return nil, 0, err
+		}
+	}
+}
+
+// deleteBlob removes the object at the given key. Removing an object that does not exist is
+// not an error, as with S3.
+func (s *azureStore) deleteBlob(ctx context.Context, key string) error {
+	if err := s.client.DeleteBlob(ctx, s.container, key); err != nil {
+		var e *azureError
+		if errors.As(err, &e) && e.StatusCode == http.StatusNotFound {
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *azureStore) deleteSources(ctx context.Context, sources []string) error {
+	return goroutine.RunWorkersOverStrings(sources, func(index int, source string) error {
+		if err := s.deleteBlob(ctx, source); err != nil {
+			return errors.Wrap(err, "failed to delete source object")
+		}
+
+		return nil
+	})
+}
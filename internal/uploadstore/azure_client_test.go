@@ -0,0 +1,418 @@
+package uploadstore
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+)
+
+func TestAzureInit(t *testing.T) {
+	server := newFakeAzureServer(t)
+	client := testAzureClient(t, server, true)
+
+	if err := client.Init(context.Background()); err != nil {
+		t.Fatalf("unexpected error initializing client: %s", err)
+	}
+	if !server.containerCreated {
+		t.Errorf("expected container to be created")
+	}
+
+	// Creating an existing container is not an error
+	if err := rawAzureClient(t, server, true).Init(context.Background()); err != nil {
+		t.Fatalf("unexpected error initializing client: %s", err)
+	}
+}
+
+func TestAzureUnmanagedInit(t *testing.T) {
+	server := newFakeAzureServer(t)
+	client := testAzureClient(t, server, false)
+
+	if err := client.Init(context.Background()); err != nil {
+		t.Fatalf("unexpected error initializing client: %s", err)
+	}
+	if server.containerCreated {
+		t.Errorf("unexpected container creation")
+	}
+}
+
+func TestAzureUploadGet(t *testing.T) {
+	server := newFakeAzureServer(t)
+	client := testAzureClient(t, server, false)
+
+	payload := strings.Repeat("TEST PAYLOAD ", azureBlockSize/8)
+	size, err := client.Upload(context.Background(), "nested/test key", strings.NewReader(payload))
+	if err != nil {
+		t.Fatalf("unexpected error uploading key: %s", err)
+	}
+	if size != int64(len(payload)) {
+		t.Errorf("unexpected size. want=%d have=%d", len(payload), size)
+	}
+	if value := server.committedBlocks["nested/test key"]; value != 2 {
+		t.Errorf("unexpected number of blocks. want=%d have=%d", 2, value)
+	}
+
+	if contents := readKey(t, client, "nested/test key"); contents != payload {
+		t.Errorf("unexpected contents. want=%d bytes have=%d bytes", len(payload), len(contents))
+	}
+}
+
+func TestAzureGetTransientErrors(t *testing.T) {
+	server := newFakeAzureServer(t)
+	server.blobs["test-key"] = &fakeAzureBlob{content: []byte("TEST PAYLOAD"), lastModified: time.Now()}
+	client := testAzureClient(t, server, false)
+
+	var calls int
+	ioCopyHook = func(w io.Writer, r io.Reader) (int64, error) {
+		calls++
+		if calls == 1 {
+			n, _ := io.CopyN(w, r, 5)
+			return n, fmt.Errorf("read: connection reset by peer")
+		}
+		return io.Copy(w, r)
+	}
+	t.Cleanup(func() { ioCopyHook = io.Copy })
+
+	if contents := readKey(t, client, "test-key"); contents != "TEST PAYLOAD" {
+		t.Errorf("unexpected contents. want=%s have=%s", "TEST PAYLOAD", contents)
+	}
+	if diff := cmp.Diff([]string{"", "bytes=5-"}, server.ranges); diff != "" {
+		t.Errorf("unexpected ranges (-want +got):\n%s", diff)
+	}
+}
+
+func TestAzureCombine(t *testing.T) {
+	server := newFakeAzureServer(t)
+	client := testAzureClient(t, server, false)
+
+	for _, key := range []string{"test-src1", "test-src2", "test-src3"} {
+		if _, err := client.Upload(context.Background(), key, strings.NewReader(key+"\n")); err != nil {
+			t.Fatalf("unexpected error uploading key: %s", err)
+		}
+	}
+
+	size, err := client.Compose(context.Background(), "test-key", "test-src1", "test-src2", "test-src3")
+	if err != nil {
+		t.Fatalf("unexpected error composing objects: %s", err)
+	}
+	if size != 30 {
+		t.Errorf("unexpected size. want=%d have=%d", 30, size)
+	}
+	if len(server.ranges) != 0 {
+		t.Errorf("unexpected reads of the source objects: %d", len(server.ranges))
+	}
+
+	if contents := readKey(t, client, "test-key"); contents != "test-src1\ntest-src2\ntest-src3\n" {
+		t.Errorf("unexpected contents. have=%q", contents)
+	}
+	if diff := cmp.Diff([]string{"test-key"}, server.blobNames()); diff != "" {
+		t.Errorf("unexpected blobs (-want +got):\n%s", diff)
+	}
+}
+
+func TestAzureDelete(t *testing.T) {
+	server := newFakeAzureServer(t)
+	server.blobs["test-key"] = &fakeAzureBlob{content: []byte("TEST PAYLOAD"), lastModified: time.Now()}
+	client := testAzureClient(t, server, false)
+
+	if err := client.Delete(context.Background(), "test-key"); err != nil {
+		t.Fatalf("unexpected error deleting key: %s", err)
+	}
+	if err := client.Delete(context.Background(), "test-key"); err != nil {
+		t.Fatalf("unexpected error deleting missing key: %s", err)
+	}
+	if names := server.blobNames(); len(names) != 0 {
+		t.Errorf("unexpected blobs: %v", names)
+	}
+}
+
+func TestAzureExpireObjects(t *testing.T) {
+	server := newFakeAzureServer(t)
+	server.pageSize = 1
+	old := time.Now().Add(-2 * time.Hour)
+	server.blobs["upload-1"] = &fakeAzureBlob{lastModified: old}
+	server.blobs["upload-2"] = &fakeAzureBlob{lastModified: time.Now()}
+	server.blobs["upload-3"] = &fakeAzureBlob{lastModified: old}
+	server.blobs["other-1"] = &fakeAzureBlob{lastModified: old}
+	client := testAzureClient(t, server, false)
+
+	if err := client.ExpireObjects(context.Background(), "upload-", time.Hour); err != nil {
+		t.Fatalf("unexpected error expiring objects: %s", err)
+	}
+
+	if diff := cmp.Diff([]string{"other-1", "upload-2"}, server.blobNames()); diff != "" {
+		t.Errorf("unexpected blobs (-want +got):\n%s", diff)
+	}
+}
+
+func TestAzureStringToSign(t *testing.T) {
+	client, err := newAzureClient(nil, AzureConfig{AccountName: "myaccount", AccountKey: base64.StdEncoding.EncodeToString([]byte("key"))})
+	if err != nil {
+		t.Fatalf("unexpected error creating client: %s", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, "https://myaccount.blob.core.windows.net/mycontainer/my%20blob?comp=block&blockid=YmxvY2s%3D", strings.NewReader("content"))
+	req.Header.Set("x-ms-date", "Fri, 26 Jun 2015 23:39:12 GMT")
+	req.Header.Set("x-ms-version", azureAPIVersion)
+
+	expected := strings.Join([]string{
+		"PUT",
+		"",
+		"",
+		"7",
+		"",
+		"",
+		"",
+		"",
+		"",
+		"",
+		"",
+		"",
+		"x-ms-date:Fri, 26 Jun 2015 23:39:12 GMT",
+		"x-ms-version:" + azureAPIVersion,
+		"/myaccount/mycontainer/my%20blob",
+		"blockid:YmxvY2s=",
+		"comp:block",
+	}, "\n")
+	if diff := cmp.Diff(expected, client.stringToSign(req)); diff != "" {
+		t.Errorf("unexpected string to sign (-want +got):\n%s", diff)
+	}
+}
+
+func testAzureClient(t *testing.T, server *fakeAzureServer, manageContainer bool) Store {
+	return newLazyStore(rawAzureClient(t, server, manageContainer))
+}
+
+func rawAzureClient(t *testing.T, server *fakeAzureServer, manageContainer bool) *azureStore {
+	client, err := newAzureClient(http.DefaultClient, AzureConfig{
+		AccountName: "devstoreaccount1",
+		AccountKey:  base64.StdEncoding.EncodeToString([]byte("test-key")),
+		Endpoint:    server.URL + "/devstoreaccount1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating client: %s", err)
+	}
+	server.client = client
+
+	return newAzureWithClient(client, "test-container", manageContainer, NewOperations(&observation.TestContext, "test", "brittlestore"))
+}
+
+// fakeAzureServer is an in-memory implementation of the subset of the Azure Blob Storage
+// REST API used by azureClient.
+type fakeAzureServer struct {
+	*httptest.Server
+	t        *testing.T
+	client   *azureClient
+	pageSize int
+
+	m                sync.Mutex
+	containerCreated bool
+	blobs            map[string]*fakeAzureBlob
+	blocks           map[string]map[string][]byte
+	committedBlocks  map[string]int
+	ranges           []string
+}
+
+type fakeAzureBlob struct {
+	content      []byte
+	lastModified time.Time
+}
+
+func newFakeAzureServer(t *testing.T) *fakeAzureServer {
+	s := &fakeAzureServer{
+		t:               t,
+		blobs:           map[string]*fakeAzureBlob{},
+		blocks:          map[string]map[string][]byte{},
+		committedBlocks: map[string]int{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeAzureServer) blobNames() (names []string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	for name := range s.blobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *fakeAzureServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.client != nil {
+		if expected := "SharedKey devstoreaccount1:" + s.client.sign(r); r.Header.Get("Authorization") != expected {
+			s.t.Errorf("unexpected authorization header for %s %s", r.Method, r.URL)
+		}
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/devstoreaccount1/test-container")
+	name := strings.TrimPrefix(path, "/")
+	query := r.URL.Query()
+	body, _ := io.ReadAll(r.Body)
+
+	switch {
+	case r.Method == http.MethodPut && query.Get("restype") == "container":
+		if s.containerCreated {
+			w.Header().Set("x-ms-error-code", "ContainerAlreadyExists")
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.containerCreated = true
+		w.WriteHeader(http.StatusCreated)
+
+	case r.Method == http.MethodGet && query.Get("comp") == "list":
+		s.serveList(w, query)
+
+	case r.Method == http.MethodPut && query.Get("comp") == "block" && r.Header.Get("x-ms-copy-source") != "":
+		content, ok := s.copySource(r.Header.Get("x-ms-copy-source"), r.Header.Get("x-ms-source-range"))
+		if !ok {
+			w.Header().Set("x-ms-error-code", "CannotVerifyCopySource")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if s.blocks[name] == nil {
+			s.blocks[name] = map[string][]byte{}
+		}
+		s.blocks[name][query.Get("blockid")] = content
+		w.WriteHeader(http.StatusCreated)
+
+	case r.Method == http.MethodPut && query.Get("comp") == "block":
+		if s.blocks[name] == nil {
+			s.blocks[name] = map[string][]byte{}
+		}
+		s.blocks[name][query.Get("blockid")] = body
+		w.WriteHeader(http.StatusCreated)
+
+	case r.Method == http.MethodPut && query.Get("comp") == "blocklist":
+		var blockList struct {
+			Latest []string `xml:"Latest"`
+		}
+		if err := xml.Unmarshal(body, &blockList); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var content []byte
+		for _, blockID := range blockList.Latest {
+			content = append(content, s.blocks[name][blockID]...)
+		}
+		delete(s.blocks, name)
+		s.blobs[name] = &fakeAzureBlob{content: content, lastModified: time.Now()}
+		s.committedBlocks[name] = len(blockList.Latest)
+		w.WriteHeader(http.StatusCreated)
+
+	case r.Method == http.MethodHead:
+		blob, ok := s.blobs[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(blob.content)))
+		w.Header().Set("Last-Modified", blob.lastModified.UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusOK)
+
+	case r.Method == http.MethodGet:
+		blob, ok := s.blobs[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		rangeHeader := r.Header.Get("x-ms-range")
+		s.ranges = append(s.ranges, rangeHeader)
+		offset, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rangeHeader, "bytes="), "-"))
+		_, _ = w.Write(blob.content[offset:])
+
+	case r.Method == http.MethodDelete:
+		if _, ok := s.blobs[name]; !ok {
+			w.Header().Set("x-ms-error-code", "BlobNotFound")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(s.blobs, name)
+		w.WriteHeader(http.StatusAccepted)
+
+	default:
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// copySource returns the requested range of the blob at the given URL, if the URL carries a
+// valid shared access signature.
+func (s *fakeAzureServer) copySource(source, sourceRange string) ([]byte, bool) {
+	u, err := url.Parse(source)
+	if err != nil {
+		return nil, false
+	}
+	name := strings.TrimPrefix(u.Path, "/devstoreaccount1/test-container/")
+	blob, ok := s.blobs[name]
+	if !ok {
+		return nil, false
+	}
+
+	query := u.Query()
+	expiry, err := time.Parse("2006-01-02T15:04:05Z", query.Get("se"))
+	if err != nil || expiry.Before(time.Now()) {
+		return nil, false
+	}
+	if s.client != nil && s.client.sharedAccessSignature("test-container", name, expiry).Get("sig") != query.Get("sig") {
+		s.t.Errorf("unexpected shared access signature for %s", source)
+		return nil, false
+	}
+
+	var start, end int
+	if _, err := fmt.Sscanf(sourceRange, "bytes=%d-%d", &start, &end); err != nil || end >= len(blob.content) {
+		return nil, false
+	}
+	return blob.content[start : end+1], true
+}
+
+func (s *fakeAzureServer) serveList(w http.ResponseWriter, query url.Values) {
+	var names []string
+	for name := range s.blobs {
+		if strings.HasPrefix(name, query.Get("prefix")) && name > query.Get("marker") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var list azureBlobList
+	for _, name := range names {
+		if s.pageSize > 0 && len(list.Blobs) == s.pageSize {
+			list.NextMarker = list.Blobs[len(list.Blobs)-1].Name
+			break
+		}
+		list.Blobs = append(list.Blobs, azureBlob{
+			Name: name,
+			Properties: azureBlobProperties{
+				LastModified:  s.blobs[name].lastModified.UTC().Format(http.TimeFormat),
+				ContentLength: int64(len(s.blobs[name].content)),
+			},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"EnumerationResults"`
+		*azureBlobList
+	}{azureBlobList: &list})
+}
@@ -0,0 +1,18 @@
+package uploadstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+)
+
+// NewExpirer returns a background routine that periodically removes the objects of the given
+// store with the given prefix that are older than maxAge. This must be run for backends that
+// do not support lifecycle rules (Azure and the local filesystem). Multiple expirers may run
+// concurrently over the same store.
+func NewExpirer(ctx context.Context, store Store, prefix string, maxAge, interval time.Duration) goroutine.BackgroundRoutine {
+	return goroutine.NewPeriodicGoroutine(ctx, interval, goroutine.NewHandlerWithErrorMessage("expire upload store objects", func(ctx context.Context) error {
+		return store.ExpireObjects(ctx, prefix, maxAge)
+	}))
+}
@@ -0,0 +1,245 @@
+package uploadstore
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+type filesystemStore struct {
+	dir        string
+	operations *Operations
+}
+
+var _ Store = &filesystemStore{}
+
+type FilesystemConfig struct {
+	Dir string
+}
+
+// newFilesystemFromConfig creates a new store backed by a directory of the local filesystem.
+// Objects are stored in a subdirectory named after the bucket. This store is only suitable
+// for single-node deployments, where every service sharing the store can access the directory.
+func newFilesystemFromConfig(ctx context.Context, config Config, operations *Operations) (Store, error) {
+	if config.Filesystem.Dir == "" {
+		return nil, errors.New("no directory configured for filesystem upload store")
+	}
+
+	return newFilesystemWithDir(filepath.Join(config.Filesystem.Dir, config.Bucket), operations), nil
+}
+
+func newFilesystemWithDir(dir string, operations *Operations) *filesystemStore {
+	return &filesystemStore{
+		dir:        dir,
+		operations: operations,
+	}
+}
+
+func (s *filesystemStore) Init(ctx context.Context) error {
+	if err := os.MkdirAll(s.dir, os.ModePerm); err != nil {
+		return errors.Wrap(err, "failed to create directory")
+	}
+
+	return nil
+}
+
+func (s *filesystemStore) Get(ctx context.Context, key string) (_ io.ReadCloser, err error) {
+	_, _, endObservation := s.operations.Get.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("key", key),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	f, err := os.Open(s.path(key))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get object")
+	}
+
+	return f, nil
+}
+
+func (s *filesystemStore) Upload(ctx context.Context, key string, r io.Reader) (_ int64, err error) {
+	_, _, endObservation := s.operations.Upload.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("key", key),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	n, err := s.writeFile(key, func(w io.Writer) (int64, error) { return io.Copy(w, r) })
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to upload object")
+	}
+
+	return n, nil
+}
+
+func (s *filesystemStore) Compose(ctx context.Context, destination string, sources ...string) (_ int64, err error) {
+	_, _, endObservation := s.operations.Compose.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("destination", destination),
+		log.String("sources", strings.Join(sources, ", ")),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	defer func() {
+		if err == nil {
+			// Delete sources on success
+			if err := s.deleteSources(sources); err != nil {
+				log15.Error("Failed to delete source objects", "error", err)
+			}
+		}
+	}()
+
+	n, err := s.writeFile(destination, func(w io.Writer) (int64, error) {
+		var total int64
+		for _, source := range sources {
+			n, err := s.copyFrom(w, source)
+			total += n
+			if err != nil {
+				return total, err
+			}
+		}
+
+		return total, nil
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to compose objects")
+	}
+
+	return n, nil
+}
+
+func (s *filesystemStore) Delete(ctx context.Context, key string) (err error) {
+	_, _, endObservation := s.operations.Delete.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("key", key),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	return errors.Wrap(s.remove(key), "failed to delete object")
+}
+
+func (s *filesystemStore) ExpireObjects(ctx context.Context, prefix string, maxAge time.Duration) (err error) {
+	_, _, endObservation := s.operations.ExpireObjects.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("prefix", prefix),
+		log.String("maxAge", maxAge.String()),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	var keys []string
+	threshold := time.Now().Add(-maxAge)
+
+	if err := filepath.WalkDir(s.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().Before(threshold) {
+			keys = append(keys, key)
+		}
+
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "failed to list objects")
+	}
+
+	return s.deleteSources(keys)
+}
+
+// path returns the path of the file of the object at the given key. Keys cannot refer
+// to files outside of the store directory.
+func (s *filesystemStore) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(filepath.Clean("/"+key)))
+}
+
+// writeFile invokes the given function with a temporary file, which is moved to the file
+// of the object at the given key on success. This ensures that readers never observe
+// partially written objects.
+func (s *filesystemStore) writeFile(key string, fn func(w io.Writer) (int64, error)) (_ int64, err error) {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return 0, err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	n, err := fn(f)
+	if closeErr := f.Close(); closeErr != nil {
+		err = errors.Append(err, errors.Wrap(closeErr, "failed to close file"))
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// copyFrom copies the content of the object at the given key into the given writer.
+func (s *filesystemStore) copyFrom(w io.Writer, key string) (int64, error) {
+	f, err := os.Open(s.path(key))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return io.Copy(w, f)
+}
+
+// remove removes the file of the object at the given key. Removing an object that does
+// not exist is not an error.
+func (s *filesystemStore) remove(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *filesystemStore) deleteSources(sources []string) error {
+	var errs error
+	for _, source := range sources {
+		if err := s.remove(source); err != nil {
+			errs = errors.Append(errs, errors.Wrap(err, "failed to delete source object"))
+		}
+	}
+
+	return errs
+}
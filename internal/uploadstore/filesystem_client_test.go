@@ -0,0 +1,195 @@
+package uploadstore
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+)
+
+func TestFilesystemInit(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "test-bucket")
+
+	client := testFilesystemClient(dir)
+	if err := client.Init(context.Background()); err != nil {
+		t.Fatalf("unexpected error initializing client: %s", err)
+	}
+
+	if info, err := os.Stat(dir); err != nil {
+		t.Fatalf("unexpected error statting directory: %s", err)
+	} else if !info.IsDir() {
+		t.Errorf("expected directory to be created")
+	}
+}
+
+func TestFilesystemUploadGet(t *testing.T) {
+	client := testFilesystemClient(t.TempDir())
+
+	size, err := client.Upload(context.Background(), "nested/test-key", strings.NewReader("TEST PAYLOAD"))
+	if err != nil {
+		t.Fatalf("unexpected error uploading key: %s", err)
+	}
+	if size != 12 {
+		t.Errorf("unexpected size. want=%d have=%d", 12, size)
+	}
+
+	if contents := readKey(t, client, "nested/test-key"); contents != "TEST PAYLOAD" {
+		t.Errorf("unexpected contents. want=%s have=%s", "TEST PAYLOAD", contents)
+	}
+}
+
+func TestFilesystemGetMissing(t *testing.T) {
+	client := testFilesystemClient(t.TempDir())
+
+	if _, err := client.Get(context.Background(), "test-key"); err == nil {
+		t.Fatalf("expected error getting missing key")
+	}
+}
+
+func TestFilesystemKeysStayInDirectory(t *testing.T) {
+	dir := t.TempDir()
+	client := testFilesystemClient(filepath.Join(dir, "test-bucket"))
+
+	if _, err := client.Upload(context.Background(), "../../escaped", strings.NewReader("TEST PAYLOAD")); err != nil {
+		t.Fatalf("unexpected error uploading key: %s", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "test-bucket", "escaped")); err != nil {
+		t.Errorf("expected object to be written in the store directory: %s", err)
+	}
+}
+
+func TestFilesystemCombine(t *testing.T) {
+	dir := t.TempDir()
+	client := testFilesystemClient(dir)
+
+	for _, key := range []string{"test-src1", "test-src2", "test-src3"} {
+		if _, err := client.Upload(context.Background(), key, strings.NewReader(key+"\n")); err != nil {
+			t.Fatalf("unexpected error uploading key: %s", err)
+		}
+	}
+
+	size, err := client.Compose(context.Background(), "test-key", "test-src1", "test-src2", "test-src3")
+	if err != nil {
+		t.Fatalf("unexpected error composing objects: %s", err)
+	}
+	if size != 30 {
+		t.Errorf("unexpected size. want=%d have=%d", 30, size)
+	}
+
+	if contents := readKey(t, client, "test-key"); contents != "test-src1\ntest-src2\ntest-src3\n" {
+		t.Errorf("unexpected contents. have=%q", contents)
+	}
+
+	if diff := cmp.Diff([]string{"test-key"}, listFiles(t, dir)); diff != "" {
+		t.Errorf("unexpected files (-want +got):\n%s", diff)
+	}
+}
+
+func TestFilesystemCombineMissingSource(t *testing.T) {
+	dir := t.TempDir()
+	client := testFilesystemClient(dir)
+
+	if _, err := client.Upload(context.Background(), "test-src1", strings.NewReader("TEST PAYLOAD")); err != nil {
+		t.Fatalf("unexpected error uploading key: %s", err)
+	}
+
+	if _, err := client.Compose(context.Background(), "test-key", "test-src1", "test-src2"); err == nil {
+		t.Fatalf("expected error composing objects")
+	}
+
+	// Sources are kept and no partial destination is left behind
+	if diff := cmp.Diff([]string{"test-src1"}, listFiles(t, dir)); diff != "" {
+		t.Errorf("unexpected files (-want +got):\n%s", diff)
+	}
+}
+
+func TestFilesystemDelete(t *testing.T) {
+	dir := t.TempDir()
+	client := testFilesystemClient(dir)
+
+	if _, err := client.Upload(context.Background(), "test-key", strings.NewReader("TEST PAYLOAD")); err != nil {
+		t.Fatalf("unexpected error uploading key: %s", err)
+	}
+
+	if err := client.Delete(context.Background(), "test-key"); err != nil {
+		t.Fatalf("unexpected error deleting key: %s", err)
+	}
+	if err := client.Delete(context.Background(), "test-key"); err != nil {
+		t.Fatalf("unexpected error deleting missing key: %s", err)
+	}
+
+	if files := listFiles(t, dir); len(files) != 0 {
+		t.Errorf("unexpected files: %v", files)
+	}
+}
+
+func TestFilesystemExpireObjects(t *testing.T) {
+	dir := t.TempDir()
+	client := testFilesystemClient(dir)
+
+	for _, key := range []string{"upload-1", "upload-2", "nested/upload-3", "other-1"} {
+		if _, err := client.Upload(context.Background(), key, strings.NewReader("TEST PAYLOAD")); err != nil {
+			t.Fatalf("unexpected error uploading key: %s", err)
+		}
+	}
+
+	old := time.Now().Add(-2 * time.Hour)
+	for _, key := range []string{"upload-1", "other-1"} {
+		if err := os.Chtimes(filepath.Join(dir, key), old, old); err != nil {
+			t.Fatalf("unexpected error changing times: %s", err)
+		}
+	}
+
+	if err := client.ExpireObjects(context.Background(), "upload-", time.Hour); err != nil {
+		t.Fatalf("unexpected error expiring objects: %s", err)
+	}
+
+	if diff := cmp.Diff([]string{"nested/upload-3", "other-1", "upload-2"}, listFiles(t, dir)); diff != "" {
+		t.Errorf("unexpected files (-want +got):\n%s", diff)
+	}
+}
+
+func testFilesystemClient(dir string) Store {
+	return newLazyStore(newFilesystemWithDir(dir, NewOperations(&observation.TestContext, "test", "brittlestore")))
+}
+
+func readKey(t *testing.T, client Store, key string) string {
+	rc, err := client.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("unexpected error getting key: %s", err)
+	}
+	defer rc.Close()
+
+	contents, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("unexpected error reading object: %s", err)
+	}
+
+	return string(contents)
+}
+
+func listFiles(t *testing.T, dir string) (files []string) {
+	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		files = append(files, filepath.ToSlash(rel))
+		return err
+	}); err != nil {
+		t.Fatalf("unexpected error listing files: %s", err)
+	}
+
+	sort.Strings(files)
+	return files
+}
@@ -16,6 +16,11 @@ type gcsBucketHandle interface {
 	Create(ctx context.Context, projectID string, attrs *storage.BucketAttrs) error
 	Update(ctx context.Context, attrs storage.BucketAttrsToUpdate) error
 	Object(name string) gcsObjectHandle
+	Objects(ctx context.Context, query *storage.Query) gcsObjectIterator
+}
+
+type gcsObjectIterator interface {
+	Next() (*storage.ObjectAttrs, error)
 }
 
 type gcsObjectHandle interface {
@@ -64,6 +69,10 @@ func (s *bucketHandleShim) Object(name string) gcsObjectHandle {
 	return &objectHandleShim{handle: s.handle.Object(name)}
 }
 
+func (s *bucketHandleShim) Objects(ctx context.Context, query *storage.Query) gcsObjectIterator {
+	return s.handle.Objects(ctx, query)
+}
+
 func (s *objectHandleShim) Delete(ctx context.Context) error {
 	return s.handle.Delete(ctx)
 }
@@ -9,6 +9,7 @@ import (
 	"cloud.google.com/go/storage"
 	"github.com/inconshreveable/log15"
 	"github.com/opentracing/opentracing-go/log"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 
 	"github.com/sourcegraph/sourcegraph/internal/goroutine"
@@ -160,6 +161,36 @@ func (s *gcsStore) Delete(ctx context.Context, key string) (err error) {
 	return errors.Wrap(s.client.Bucket(s.bucket).Object(key).Delete(ctx), "failed to delete object")
 }
 
+func (s *gcsStore) ExpireObjects(ctx context.Context, prefix string, maxAge time.Duration) (err error) {
+	ctx, _, endObservation := s.operations.ExpireObjects.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("prefix", prefix),
+		log.String("maxAge", maxAge.String()),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	var keys []string
+	threshold := time.Now().Add(-maxAge)
+	bucket := s.client.Bucket(s.bucket)
+	it := bucket.Objects(ctx, &storage.Query{Prefix: prefix})
+
+	for {
+		attrs, err := it.Next()
+		if err != nil {
+			if err == iterator.Done {
+				break
+			}
+
+			return errors.Wrap(err, "failed to list objects")
+		}
+
+		if attrs.Updated.Before(threshold) {
+			keys = append(keys, attrs.Name)
+		}
+	}
+
+	return s.deleteSources(ctx, bucket, keys)
+}
+
 func (s *gcsStore) create(ctx context.Context, bucket gcsBucketHandle) error {
 	return bucket.Create(ctx, s.config.ProjectID, &storage.BucketAttrs{
 		Lifecycle: s.lifecycle(),
@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"sync"
 	"testing"
 	"time"
 
 	"cloud.google.com/go/storage"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/iterator"
 
 	"github.com/sourcegraph/sourcegraph/internal/observation"
 )
@@ -249,6 +252,49 @@ func TestGCSDelete(t *testing.T) {
 	}
 }
 
+func TestGCSExpireObjects(t *testing.T) {
+	now := time.Now()
+
+	gcsClient := NewMockGcsAPI()
+	bucketHandle := NewMockGcsBucketHandle()
+	gcsClient.BucketFunc.SetDefaultReturn(bucketHandle)
+	bucketHandle.ObjectsFunc.SetDefaultReturn(&testObjectIterator{objects: []*storage.ObjectAttrs{
+		{Name: "upload-1", Updated: now.Add(-2 * time.Hour)},
+		{Name: "upload-2", Updated: now},
+	}})
+
+	var m sync.Mutex
+	objectHandles := map[string]*MockGcsObjectHandle{}
+	bucketHandle.ObjectFunc.SetDefaultHook(func(name string) gcsObjectHandle {
+		objectHandle := NewMockGcsObjectHandle()
+		m.Lock()
+		objectHandles[name] = objectHandle
+		m.Unlock()
+		return objectHandle
+	})
+
+	client := testGCSClient(gcsClient, false)
+	if err := client.ExpireObjects(context.Background(), "upload-", time.Hour); err != nil {
+		t.Fatalf("unexpected error expiring objects: %s", err)
+	}
+
+	if calls := bucketHandle.ObjectsFunc.History(); len(calls) != 1 {
+		t.Fatalf("unexpected number of Objects calls. want=%d have=%d", 1, len(calls))
+	} else if value := calls[0].Arg1.Prefix; value != "upload-" {
+		t.Errorf("unexpected prefix argument. want=%s have=%s", "upload-", value)
+	}
+
+	var keys []string
+	for name, objectHandle := range objectHandles {
+		if len(objectHandle.DeleteFunc.History()) > 0 {
+			keys = append(keys, name)
+		}
+	}
+	if diff := cmp.Diff([]string{"upload-1"}, keys); diff != "" {
+		t.Errorf("unexpected deleted keys (-want +got):\n%s", diff)
+	}
+}
+
 func TestGCSLifecycle(t *testing.T) {
 	client := rawGCSClient(nil, true)
 
@@ -274,3 +320,17 @@ type nopCloser struct {
 func (nopCloser) Close() error {
 	return nil
 }
+
+type testObjectIterator struct {
+	objects []*storage.ObjectAttrs
+}
+
+func (it *testObjectIterator) Next() (*storage.ObjectAttrs, error) {
+	if len(it.objects) == 0 {
+		return nil, iterator.Done
+	}
+
+	attrs := it.objects[0]
+	it.objects = it.objects[1:]
+	return attrs, nil
+}
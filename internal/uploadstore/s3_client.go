@@ -271,6 +271,41 @@ func (s *s3Store) Delete(ctx context.Context, key string) (err error) {
 	return errors.Wrap(err, "failed to delete object")
 }
 
+func (s *s3Store) ExpireObjects(ctx context.Context, prefix string, maxAge time.Duration) (err error) {
+	ctx, _, endObservation := s.operations.ExpireObjects.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("prefix", prefix),
+		log.String("maxAge", maxAge.String()),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	var keys []string
+	threshold := time.Now().Add(-maxAge)
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	}
+
+	for {
+		page, err := s.client.ListObjectsV2(ctx, input)
+		if err != nil {
+			return errors.Wrap(err, "failed to list objects")
+		}
+
+		for _, object := range page.Contents {
+			if object.LastModified != nil && object.LastModified.Before(threshold) {
+				keys = append(keys, *object.Key)
+			}
+		}
+
+		if !page.IsTruncated {
+			break
+		}
+		input.ContinuationToken = page.NextContinuationToken
+	}
+
+	return s.deleteSources(ctx, s.bucket, keys)
+}
+
 func (s *s3Store) create(ctx context.Context) error {
 	_, err := s.client.CreateBucket(ctx, &s3.CreateBucketInput{
 		Bucket: aws.String(s.bucket),
@@ -3,6 +3,7 @@ package uploadstore
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/sourcegraph/sourcegraph/lib/errors"
 )
@@ -26,12 +27,19 @@ type Store interface {
 
 	// Delete removes the content at the given key.
 	Delete(ctx context.Context, key string) error
+
+	// ExpireObjects removes all objects with the given prefix that were last modified more
+	// than maxAge ago. This is required for backends that do not support lifecycle rules.
+	ExpireObjects(ctx context.Context, prefix string, maxAge time.Duration) error
 }
 
 var storeConstructors = map[string]func(ctx context.Context, config Config, operations *Operations) (Store, error){
 	"s3":    newS3FromConfig,
 	"minio": newS3FromConfig,
 	"gcs":   newGCSFromConfig,
+
+	"azure":      newAzureFromConfig,
+	"filesystem": newFilesystemFromConfig,
 }
 
 // CreateLazy initialize a new store from the given configuration that is initialized
@@ -45,4 +45,47 @@ func InitPrometheusMetric(observationContext *observation.Context, workerStore s
 
 		return float64(age) / float64(time.Second)
 	}))
+
+	observationContext.Registerer.MustRegister(NewQueuedCountByFairnessKeyCollector(
+		workerStore,
+		fmt.Sprintf("src_%s_fairness_key_total", teamAndResource),
+		fmt.Sprintf("Total number of %s records in the queued state per fairness key.", resource),
+		constLabels,
+	))
+}
+
+// maxReportedFairnessKeys is the maximum number of fairness keys reported by the collector
+// returned from NewQueuedCountByFairnessKeyCollector. Only the keys with the most queued
+// records are reported to bound the cardinality of the metric.
+const maxReportedFairnessKeys = 25
+
+// NewQueuedCountByFairnessKeyCollector returns a Prometheus collector reporting the number of
+// queued records of the given store per fairness key under the label `key`. No values are
+// reported if the store was not configured with a fairness key.
+func NewQueuedCountByFairnessKeyCollector(workerStore store.Store, name, help string, constLabels prometheus.Labels) prometheus.Collector {
+	return &queuedCountByFairnessKeyCollector{
+		workerStore: workerStore,
+		desc:        prometheus.NewDesc(name, help, []string{"key"}, constLabels),
+	}
+}
+
+type queuedCountByFairnessKeyCollector struct {
+	workerStore store.Store
+	desc        *prometheus.Desc
+}
+
+func (c *queuedCountByFairnessKeyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *queuedCountByFairnessKeyCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := c.workerStore.QueuedCountByFairnessKey(context.Background(), maxReportedFairnessKeys)
+	if err != nil {
+		log15.Error("Failed to determine queue size by fairness key", "error", err)
+		return
+	}
+
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), key)
+	}
 }
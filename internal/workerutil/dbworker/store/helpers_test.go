@@ -120,7 +120,9 @@ func setupStoreTest(t *testing.T) dbutil.DB {
 			num_failures      integer NOT NULL default 0,
 			created_at        timestamp with time zone NOT NULL default NOW(),
 			execution_logs    json[],
-			worker_hostname   text NOT NULL default ''
+			worker_hostname   text NOT NULL default '',
+			fairness_key      text,
+			priority          integer NOT NULL default 0
 		)
 	`); err != nil {
 		t.Fatalf("unexpected error creating test table: %s", err)
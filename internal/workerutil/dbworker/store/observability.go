@@ -8,17 +8,18 @@ import (
 )
 
 type operations struct {
-	addExecutionLogEntry    *observation.Operation
-	dequeue                 *observation.Operation
-	heartbeat               *observation.Operation
-	markComplete            *observation.Operation
-	markErrored             *observation.Operation
-	markFailed              *observation.Operation
-	maxDurationInQueue      *observation.Operation
-	queuedCount             *observation.Operation
-	requeue                 *observation.Operation
-	resetStalled            *observation.Operation
-	updateExecutionLogEntry *observation.Operation
+	addExecutionLogEntry     *observation.Operation
+	dequeue                  *observation.Operation
+	heartbeat                *observation.Operation
+	markComplete             *observation.Operation
+	markErrored              *observation.Operation
+	markFailed               *observation.Operation
+	maxDurationInQueue       *observation.Operation
+	queuedCount              *observation.Operation
+	queuedCountByFairnessKey *observation.Operation
+	requeue                  *observation.Operation
+	resetStalled             *observation.Operation
+	updateExecutionLogEntry  *observation.Operation
 }
 
 func newOperations(storeName string, observationContext *observation.Context) *operations {
@@ -38,16 +39,17 @@ func newOperations(storeName string, observationContext *observation.Context) *o
 	}
 
 	return &operations{
-		addExecutionLogEntry:    op("AddExecutionLogEntry"),
-		dequeue:                 op("Dequeue"),
-		heartbeat:               op("Heartbeat"),
-		markComplete:            op("MarkComplete"),
-		markErrored:             op("MarkErrored"),
-		markFailed:              op("MarkFailed"),
-		maxDurationInQueue:      op("MaxDurationInQueue"),
-		queuedCount:             op("QueuedCount"),
-		requeue:                 op("Requeue"),
-		resetStalled:            op("ResetStalled"),
-		updateExecutionLogEntry: op("UpdateExecutionLogEntry"),
+		addExecutionLogEntry:     op("AddExecutionLogEntry"),
+		dequeue:                  op("Dequeue"),
+		heartbeat:                op("Heartbeat"),
+		markComplete:             op("MarkComplete"),
+		markErrored:              op("MarkErrored"),
+		markFailed:               op("MarkFailed"),
+		maxDurationInQueue:       op("MaxDurationInQueue"),
+		queuedCount:              op("QueuedCount"),
+		queuedCountByFairnessKey: op("QueuedCountByFairnessKey"),
+		requeue:                  op("Requeue"),
+		resetStalled:             op("ResetStalled"),
+		updateExecutionLogEntry:  op("UpdateExecutionLogEntry"),
 	}
 }
@@ -16,6 +16,7 @@ import (
 	"github.com/opentracing/opentracing-go/log"
 
 	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
 	"github.com/sourcegraph/sourcegraph/internal/observation"
 	"github.com/sourcegraph/sourcegraph/internal/workerutil"
 	"github.com/sourcegraph/sourcegraph/lib/errors"
@@ -81,6 +82,10 @@ type Store interface {
 	// QueuedCount returns the number of queued records matching the given conditions.
 	QueuedCount(ctx context.Context, includeProcessing bool, conditions []*sqlf.Query) (int, error)
 
+	// QueuedCountByFairnessKey returns the number of queued records for each value of `FairnessKeyExpression`.
+	// Only the limit keys with the most queued records are returned. Returns nil if the store has no fairness key.
+	QueuedCountByFairnessKey(ctx context.Context, limit int) (map[string]int, error)
+
 	// MaxDurationInQueue returns the maximum age of queued records in this store. Returns 0 if there are no queued records.
 	MaxDurationInQueue(ctx context.Context) (time.Duration, error)
 
@@ -222,6 +227,25 @@ type Options struct {
 	// supplied.
 	OrderByExpression *sqlf.Query
 
+	// PriorityExpression is an optional SQL expression used to prioritize candidate records when selecting
+	// the next batch of work to perform. Candidate records with a lower priority value are selected before
+	// records with a higher priority value, regardless of `FairnessKeyExpression`. Records with the same
+	// priority are ordered by `OrderByExpression`. This expression may use the alias provided in `ViewName`,
+	// if one was supplied.
+	PriorityExpression *sqlf.Query
+
+	// FairnessKeyExpression is an optional SQL expression used to partition candidate records (e.g. by
+	// repository or namespace) so that a single partition with a large number of records cannot starve
+	// the others. If supplied, records are selected round-robin between partitions: each candidate record
+	// is ranked by the number of records of its partition that are already processing plus the number of
+	// records of its partition ordered before it. This expression may use the alias provided in `ViewName`,
+	// if one was supplied.
+	//
+	// The records currently processing are counted over the target view, which must therefore not filter
+	// out processing records. Every candidate record is ranked on each dequeue, so the columns referenced
+	// by this expression should be indexed on large tables.
+	FairnessKeyExpression *sqlf.Query
+
 	// ColumnExpressions are the target columns provided to the query when selecting a job record. These
 	// expressions may use the alias provided in `ViewName`, if one was supplied.
 	ColumnExpressions []*sqlf.Query
@@ -390,6 +414,41 @@ SELECT COUNT(*) FROM %s WHERE (
 ) %s
 `
 
+// QueuedCountByFairnessKey returns the number of queued records for each value of `FairnessKeyExpression`.
+// Only the limit keys with the most queued records are returned. Returns nil if the store has no fairness key.
+func (s *store) QueuedCountByFairnessKey(ctx context.Context, limit int) (_ map[string]int, err error) {
+	ctx, _, endObservation := s.operations.queuedCountByFairnessKey.With(ctx, &err, observation.Args{})
+	defer endObservation(1, observation.Args{})
+
+	if s.options.FairnessKeyExpression == nil {
+		return nil, nil
+	}
+
+	return scanCountsByKey(s.Query(ctx, s.formatQuery(
+		queuedCountByFairnessKeyQuery,
+		s.options.FairnessKeyExpression,
+		quote(s.options.ViewName),
+		s.options.MaxNumRetries,
+		limit,
+	)))
+}
+
+var scanCountsByKey = basestore.NewMapScanner(func(s dbutil.Scanner) (key string, count int, _ error) {
+	err := s.Scan(&key, &count)
+	return key, count, err
+})
+
+const queuedCountByFairnessKeyQuery = `
+-- source: internal/workerutil/store.go:QueuedCountByFairnessKey
+SELECT COALESCE((%s)::text, '') AS fairness_key, COUNT(*) FROM %s WHERE (
+	{state} = 'queued' OR
+	({state} = 'errored' AND {num_failures} < %s)
+)
+GROUP BY 1
+ORDER BY 2 DESC, 1
+LIMIT %s
+`
+
 // MaxDurationInQueue returns the longest duration for which a job associated with this store instance has
 // been in the queued state (including errored records that can be retried in the future). This method returns
 // an duration of zero if there are no jobs ready for processing.
@@ -517,21 +576,64 @@ func (s *store) Dequeue(ctx context.Context, workerHostname string, conditions [
 		s.columnReplacer.Replace("{worker_hostname}"):   workerHostnameExpr,
 	}
 
-	record, exists, err := s.options.Scan(s.Query(ctx, s.formatQuery(
-		dequeueQuery,
-		quote(s.options.ViewName),
-		now,
-		retryAfter,
-		now,
-		retryAfter,
-		s.options.MaxNumRetries,
-		makeConditionSuffix(conditions),
-		s.options.OrderByExpression,
-		quote(s.options.TableName),
-		sqlf.Join(s.makeDequeueUpdateStatements(updatedColumns), ", "),
-		sqlf.Join(s.makeDequeueSelectExpressions(updatedColumns), ", "),
-		quote(s.options.ViewName),
-	)))
+	orderByExpression := s.options.OrderByExpression
+	if s.options.PriorityExpression != nil {
+		orderByExpression = sqlf.Sprintf("%s, %s", s.options.PriorityExpression, orderByExpression)
+	}
+
+	var q *sqlf.Query
+	if s.options.FairnessKeyExpression == nil {
+		q = s.formatQuery(
+			dequeueQuery,
+			quote(s.options.ViewName),
+			now,
+			retryAfter,
+			now,
+			retryAfter,
+			s.options.MaxNumRetries,
+			makeConditionSuffix(conditions),
+			orderByExpression,
+			quote(s.options.TableName),
+			sqlf.Join(s.makeDequeueUpdateStatements(updatedColumns), ", "),
+			sqlf.Join(s.makeDequeueSelectExpressions(updatedColumns), ", "),
+			quote(s.options.ViewName),
+		)
+	} else {
+		priorityExpression := s.options.PriorityExpression
+		if priorityExpression == nil {
+			priorityExpression = sqlf.Sprintf("0")
+		}
+
+		q = s.formatQuery(
+			fairDequeueQuery,
+			// candidates
+			priorityExpression,
+			s.options.FairnessKeyExpression,
+			s.options.FairnessKeyExpression,
+			orderByExpression,
+			orderByExpression,
+			quote(s.options.ViewName),
+			now,
+			retryAfter,
+			now,
+			retryAfter,
+			s.options.MaxNumRetries,
+			makeConditionSuffix(conditions),
+			// processing
+			s.options.FairnessKeyExpression,
+			quote(s.options.ViewName),
+			// candidate
+			quote(s.options.TableName),
+			quote(extractTableName(s.options.TableName)),
+			// updated_record
+			quote(s.options.TableName),
+			sqlf.Join(s.makeDequeueUpdateStatements(updatedColumns), ", "),
+			sqlf.Join(s.makeDequeueSelectExpressions(updatedColumns), ", "),
+			quote(s.options.ViewName),
+		)
+	}
+
+	record, exists, err := s.options.Scan(s.Query(ctx, q))
 	if err != nil {
 		return nil, false, err
 	}
@@ -580,6 +682,69 @@ WHERE
 	{id} IN (SELECT {id} FROM candidate)
 `
 
+// fairDequeueQuery selects the next record like dequeueQuery, but ranks candidate records
+// round-robin between the partitions of the fairness key. Window functions cannot be used
+// with FOR UPDATE, so candidates are ranked first and locked in a second step. As candidates
+// are not locked while ranked, the lock re-checks that the record has not been dequeued
+// concurrently.
+const fairDequeueQuery = `
+-- source: internal/workerutil/store.go:Dequeue
+WITH candidates AS (
+	SELECT
+		{id} AS candidate_id,
+		%s AS priority,
+		%s AS fairness_key,
+		ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) AS fairness_rank,
+		ROW_NUMBER() OVER (ORDER BY %s) AS rank
+	FROM %s
+	WHERE
+		(
+			(
+				{state} = 'queued' AND
+				({process_after} IS NULL OR {process_after} <= %s)
+			) OR (
+				%s > 0 AND
+				{state} = 'errored' AND
+				%s - {finished_at} > (%s * '1 second'::interval) AND
+				{num_failures} < %s
+			)
+		)
+		%s
+),
+processing AS (
+	SELECT %s AS fairness_key, COUNT(*) AS count
+	FROM %s
+	WHERE {state} = 'processing'
+	GROUP BY 1
+),
+candidate AS (
+	SELECT {id} FROM %s
+	JOIN candidates ON candidates.candidate_id = {id}
+	LEFT JOIN processing ON processing.fairness_key IS NOT DISTINCT FROM candidates.fairness_key
+	WHERE {state} IN ('queued', 'errored')
+	ORDER BY
+		candidates.priority,
+		candidates.fairness_rank + COALESCE(processing.count, 0),
+		candidates.rank
+	FOR UPDATE OF %s SKIP LOCKED
+	LIMIT 1
+),
+updated_record AS (
+	UPDATE
+		%s
+	SET
+		%s
+	WHERE
+		{id} IN (SELECT {id} FROM candidate)
+)
+SELECT
+	%s
+FROM
+	%s
+WHERE
+	{id} IN (SELECT {id} FROM candidate)
+`
+
 // makeDequeueSelectExpressions constructs the ordered set of SQL expressions that are returned
 // from the dequeue query. This method returns a copy of the configured column expressions slice
 // where expressions referencing one of the column updated by dequeue are replaced by the updated
@@ -115,6 +115,50 @@ func TestStoreQueuedCountConditions(t *testing.T) {
 	}
 }
 
+func TestStoreQueuedCountByFairnessKey(t *testing.T) {
+	db := setupStoreTest(t)
+
+	if _, err := db.ExecContext(context.Background(), `
+		INSERT INTO workerutil_test (id, state, fairness_key)
+		VALUES
+			(1, 'queued', 'a'),
+			(2, 'queued', 'a'),
+			(3, 'queued', 'b'),
+			(4, 'processing', 'b'),
+			(5, 'queued', 'c'),
+			(6, 'queued', 'c'),
+			(7, 'queued', 'c'),
+			(8, 'queued', NULL)
+	`); err != nil {
+		t.Fatalf("unexpected error inserting records: %s", err)
+	}
+
+	options := defaultTestStoreOptions(nil)
+	options.FairnessKeyExpression = sqlf.Sprintf("w.fairness_key")
+
+	counts, err := testStore(db, options).QueuedCountByFairnessKey(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("unexpected error getting queued counts: %s", err)
+	}
+
+	expected := map[string]int{"c": 3, "a": 2, "": 1}
+	if diff := cmp.Diff(expected, counts); diff != "" {
+		t.Errorf("unexpected counts (-want +got):\n%s", diff)
+	}
+}
+
+func TestStoreQueuedCountByFairnessKeyNoFairnessKey(t *testing.T) {
+	db := setupStoreTest(t)
+
+	counts, err := testStore(db, defaultTestStoreOptions(nil)).QueuedCountByFairnessKey(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("unexpected error getting queued counts: %s", err)
+	}
+	if counts != nil {
+		t.Errorf("unexpected counts: %v", counts)
+	}
+}
+
 func TestStoreMaxDurationInQueue(t *testing.T) {
 	db := setupStoreTest(t)
 
@@ -327,6 +371,108 @@ func TestStoreDequeueView(t *testing.T) {
 	assertDequeueRecordViewResult(t, 2, 14, record, ok, err)
 }
 
+func TestStoreDequeuePriority(t *testing.T) {
+	db := setupStoreTest(t)
+
+	if _, err := db.ExecContext(context.Background(), `
+		INSERT INTO workerutil_test (id, state, created_at, priority)
+		VALUES
+			(1, 'queued', NOW() - '5 minute'::interval, 10),
+			(2, 'queued', NOW() - '4 minute'::interval, 1),
+			(3, 'queued', NOW() - '3 minute'::interval, 1),
+			(4, 'queued', NOW() - '2 minute'::interval, 100)
+	`); err != nil {
+		t.Fatalf("unexpected error inserting records: %s", err)
+	}
+
+	options := defaultTestStoreOptions(nil)
+	options.PriorityExpression = sqlf.Sprintf("w.priority")
+
+	record, ok, err := testStore(db, options).Dequeue(context.Background(), "test", nil)
+	assertDequeueRecordResult(t, 2, record, ok, err)
+}
+
+func TestStoreDequeueFairness(t *testing.T) {
+	db := setupStoreTest(t)
+
+	if _, err := db.ExecContext(context.Background(), `
+		INSERT INTO workerutil_test (id, state, created_at, fairness_key)
+		VALUES
+			(1, 'queued', NOW() - '7 minute'::interval, 'a'),
+			(2, 'queued', NOW() - '6 minute'::interval, 'a'),
+			(3, 'queued', NOW() - '5 minute'::interval, 'a'),
+			(4, 'queued', NOW() - '4 minute'::interval, 'b'),
+			(5, 'queued', NOW() - '3 minute'::interval, 'c'),
+			(6, 'queued', NOW() - '2 minute'::interval, 'b')
+	`); err != nil {
+		t.Fatalf("unexpected error inserting records: %s", err)
+	}
+
+	options := defaultTestStoreOptions(nil)
+	options.FairnessKeyExpression = sqlf.Sprintf("w.fairness_key")
+	store := testStore(db, options)
+
+	var ids []int
+	for {
+		record, ok, err := store.Dequeue(context.Background(), "test", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if !ok {
+			break
+		}
+
+		ids = append(ids, record.RecordID())
+	}
+
+	if diff := cmp.Diff([]int{1, 4, 5, 2, 6, 3}, ids); diff != "" {
+		t.Errorf("unexpected dequeue order (-want +got):\n%s", diff)
+	}
+}
+
+func TestStoreDequeueFairnessProcessing(t *testing.T) {
+	db := setupStoreTest(t)
+
+	if _, err := db.ExecContext(context.Background(), `
+		INSERT INTO workerutil_test (id, state, created_at, fairness_key)
+		VALUES
+			(1, 'processing', NOW() - '5 minute'::interval, 'a'),
+			(2, 'processing', NOW() - '4 minute'::interval, 'a'),
+			(3, 'queued', NOW() - '3 minute'::interval, 'a'),
+			(4, 'processing', NOW() - '2 minute'::interval, 'b'),
+			(5, 'queued', NOW() - '1 minute'::interval, 'b')
+	`); err != nil {
+		t.Fatalf("unexpected error inserting records: %s", err)
+	}
+
+	options := defaultTestStoreOptions(nil)
+	options.FairnessKeyExpression = sqlf.Sprintf("w.fairness_key")
+
+	record, ok, err := testStore(db, options).Dequeue(context.Background(), "test", nil)
+	assertDequeueRecordResult(t, 5, record, ok, err)
+}
+
+func TestStoreDequeueFairnessPriority(t *testing.T) {
+	db := setupStoreTest(t)
+
+	if _, err := db.ExecContext(context.Background(), `
+		INSERT INTO workerutil_test (id, state, created_at, fairness_key, priority)
+		VALUES
+			(1, 'processing', NOW() - '5 minute'::interval, 'a', 0),
+			(2, 'queued', NOW() - '4 minute'::interval, 'b', 1),
+			(3, 'queued', NOW() - '3 minute'::interval, 'a', 0)
+	`); err != nil {
+		t.Fatalf("unexpected error inserting records: %s", err)
+	}
+
+	options := defaultTestStoreOptions(nil)
+	options.PriorityExpression = sqlf.Sprintf("w.priority")
+	options.FairnessKeyExpression = sqlf.Sprintf("w.fairness_key")
+
+	record, ok, err := testStore(db, options).Dequeue(context.Background(), "test", nil)
+	assertDequeueRecordResult(t, 3, record, ok, err)
+}
+
 func TestStoreDequeueConcurrent(t *testing.T) {
 	db := setupStoreTest(t)
 
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS insight_series_alerts (
+    id SERIAL PRIMARY KEY,
+    insight_series_id INTEGER NOT NULL REFERENCES insight_series(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    threshold DOUBLE PRECISION NOT NULL,
+    intervals INTEGER NOT NULL DEFAULT 1,
+    code_monitor_id BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_fired_at TIMESTAMP,
+    CONSTRAINT insight_series_alerts_kind_valid CHECK (kind IN ('threshold', 'percent_change')),
+    CONSTRAINT insight_series_alerts_intervals_positive CHECK (intervals > 0)
+);
+
+CREATE INDEX IF NOT EXISTS insight_series_alerts_insight_series_id_idx ON insight_series_alerts USING btree (insight_series_id);
+
+COMMENT ON TABLE insight_series_alerts IS 'Alert rules on insight series that run the actions of a code monitor when met.';
+COMMENT ON COLUMN insight_series_alerts.kind IS 'Either threshold, which fires when the series value rises to or above the threshold, or percent_change, which fires when the series value changed by at least the threshold percentage over the given number of intervals.';
+COMMENT ON COLUMN insight_series_alerts.code_monitor_id IS 'The code monitor in the main app database whose actions are run when the alert fires.';
+COMMENT ON COLUMN insight_series_alerts.last_fired_at IS 'Timestamp when the alert last fired.';
@@ -0,0 +1,2 @@
+ALTER TABLE notebooks DROP CONSTRAINT IF EXISTS variables_is_array;
+ALTER TABLE notebooks DROP COLUMN IF EXISTS variables;
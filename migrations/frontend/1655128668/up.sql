@@ -0,0 +1,4 @@
+ALTER TABLE notebooks ADD COLUMN IF NOT EXISTS variables jsonb NOT NULL DEFAULT '[]'::jsonb;
+
+ALTER TABLE notebooks DROP CONSTRAINT IF EXISTS variables_is_array;
+ALTER TABLE notebooks ADD CONSTRAINT variables_is_array CHECK (jsonb_typeof(variables) = 'array'::text);
@@ -0,0 +1,10 @@
+DROP VIEW IF EXISTS gitserver_relocator_jobs_with_repo_name;
+
+ALTER TABLE gitserver_relocator_jobs DROP COLUMN IF EXISTS rebalance_id;
+
+CREATE VIEW gitserver_relocator_jobs_with_repo_name AS
+  SELECT glj.*, r.name AS repo_name
+  FROM gitserver_relocator_jobs glj
+  JOIN repo r ON r.id = glj.repo_id;
+
+DROP TABLE IF EXISTS gitserver_rebalances;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS gitserver_rebalances (
+    id              SERIAL PRIMARY KEY,
+    previous_addrs  text[] NOT NULL,
+    target_addrs    text[] NOT NULL,
+    cursor          text NOT NULL DEFAULT '',
+    total_repos     integer NOT NULL DEFAULT 0,
+    moved_repos     integer NOT NULL DEFAULT 0,
+    failed_repos    integer NOT NULL DEFAULT 0,
+    started_at      timestamp with time zone NOT NULL DEFAULT NOW(),
+    updated_at      timestamp with time zone NOT NULL DEFAULT NOW(),
+    finished_at     timestamp with time zone
+);
+
+ALTER TABLE gitserver_relocator_jobs ADD COLUMN IF NOT EXISTS rebalance_id integer REFERENCES gitserver_rebalances(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS gitserver_relocator_jobs_rebalance_id ON gitserver_relocator_jobs(rebalance_id);
+
+-- Recreate the view so that it picks up the new column.
+DROP VIEW IF EXISTS gitserver_relocator_jobs_with_repo_name;
+
+CREATE VIEW gitserver_relocator_jobs_with_repo_name AS
+  SELECT glj.*, r.name AS repo_name
+  FROM gitserver_relocator_jobs glj
+  JOIN repo r ON r.id = glj.repo_id;
@@ -0,0 +1 @@
+DELETE FROM out_of_band_migrations WHERE id = 15;
@@ -0,0 +1,3 @@
+INSERT INTO out_of_band_migrations (id, team, component, description, non_destructive, introduced_version_major, introduced_version_minor)
+VALUES (15, 'core-application', 'frontend-db.encrypted-columns', 'Re-encrypt with the current key version', true, 3, 41)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS external_services_encryption_key_id_idx;
@@ -0,0 +1 @@
+CREATE INDEX CONCURRENTLY IF NOT EXISTS external_services_encryption_key_id_idx ON external_services (encryption_key_id);
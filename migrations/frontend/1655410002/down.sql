@@ -0,0 +1 @@
+DROP INDEX IF EXISTS user_external_accounts_encryption_key_id_idx;
@@ -0,0 +1 @@
+CREATE INDEX CONCURRENTLY IF NOT EXISTS user_external_accounts_encryption_key_id_idx ON user_external_accounts (encryption_key_id);
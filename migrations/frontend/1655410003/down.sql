@@ -0,0 +1 @@
+DROP INDEX IF EXISTS user_credentials_encryption_key_id_idx;
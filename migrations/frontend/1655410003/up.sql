@@ -0,0 +1 @@
+CREATE INDEX CONCURRENTLY IF NOT EXISTS user_credentials_encryption_key_id_idx ON user_credentials (encryption_key_id);
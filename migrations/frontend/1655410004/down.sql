@@ -0,0 +1 @@
+DROP INDEX IF EXISTS batch_changes_site_credentials_encryption_key_id_idx;
@@ -0,0 +1 @@
+CREATE INDEX CONCURRENTLY IF NOT EXISTS batch_changes_site_credentials_encryption_key_id_idx ON batch_changes_site_credentials (encryption_key_id);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS webhook_logs_encryption_key_id_idx;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS insights_query_runner_jobs_processable_series_id;
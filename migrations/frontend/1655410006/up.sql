@@ -0,0 +1 @@
+CREATE INDEX CONCURRENTLY IF NOT EXISTS insights_query_runner_jobs_processable_series_id ON insights_query_runner_jobs (series_id, id) WHERE state = 'queued' OR state = 'errored';
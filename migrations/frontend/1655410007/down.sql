@@ -0,0 +1 @@
+DROP INDEX IF EXISTS lsif_indexes_processable_repository_id;
@@ -0,0 +1 @@
+CREATE INDEX CONCURRENTLY IF NOT EXISTS lsif_indexes_processable_repository_id ON lsif_indexes (repository_id, queued_at, id) WHERE state = 'queued' OR state = 'errored';
@@ -0,0 +1,35 @@
+DROP VIEW IF EXISTS batch_spec_workspace_execution_queue;
+CREATE VIEW batch_spec_workspace_execution_queue AS
+WITH queue_candidates AS (
+    SELECT
+        exec.*,
+        RANK() OVER (
+            PARTITION BY exec.user_id
+            -- Make sure the jobs are still fulfilled in timely order, and that the ordering is stable.
+            ORDER BY exec.created_at ASC, exec.id ASC
+        ) AS place_in_user_queue
+    FROM batch_spec_workspace_execution_jobs exec
+    WHERE
+        -- Only queued records should get a rank.
+        exec.state = 'queued'
+),
+user_processing AS (
+    SELECT
+        exec.user_id,
+        COUNT(*) AS count
+    FROM batch_spec_workspace_execution_jobs exec
+    WHERE exec.state = 'processing'
+    GROUP BY exec.user_id
+)
+SELECT
+    -- Rank the jobs in the order the worker store dequeues them: round-robin between users,
+    -- starting with the users with the fewest jobs processing.
+    ROW_NUMBER() OVER (
+        ORDER BY
+            queue_candidates.place_in_user_queue + COALESCE(user_processing.count, 0),
+            queue_candidates.created_at ASC,
+            queue_candidates.id ASC
+    ) AS place_in_global_queue,
+    queue_candidates.*
+FROM queue_candidates
+LEFT JOIN user_processing ON user_processing.user_id IS NOT DISTINCT FROM queue_candidates.user_id;
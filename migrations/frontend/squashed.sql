@@ -623,12 +623,7 @@ CREATE SEQUENCE batch_spec_workspace_execution_jobs_id_seq
 ALTER SEQUENCE batch_spec_workspace_execution_jobs_id_seq OWNED BY batch_spec_workspace_execution_jobs.id;
 
 CREATE VIEW batch_spec_workspace_execution_queue AS
- WITH user_queues AS (
-         SELECT exec.user_id,
-            max(exec.started_at) AS latest_dequeue
-           FROM batch_spec_workspace_execution_jobs exec
-          GROUP BY exec.user_id
-        ), materialized_queue_candidates AS MATERIALIZED (
+ WITH queue_candidates AS (
          SELECT exec.id,
             exec.batch_spec_workspace_id,
             exec.state,
@@ -647,33 +642,38 @@ CREATE VIEW batch_spec_workspace_execution_queue AS
             exec.access_token_id,
             exec.queued_at,
             exec.user_id,
-            rank() OVER (PARTITION BY queue.user_id ORDER BY exec.created_at, exec.id) AS place_in_user_queue
-           FROM (batch_spec_workspace_execution_jobs exec
-             JOIN user_queues queue ON ((queue.user_id = exec.user_id)))
+            rank() OVER (PARTITION BY exec.user_id ORDER BY exec.created_at, exec.id) AS place_in_user_queue
+           FROM batch_spec_workspace_execution_jobs exec
           WHERE (exec.state = 'queued'::text)
-          ORDER BY (rank() OVER (PARTITION BY queue.user_id ORDER BY exec.created_at, exec.id)), queue.latest_dequeue NULLS FIRST
+        ), user_processing AS (
+         SELECT exec.user_id,
+            count(*) AS count
+           FROM batch_spec_workspace_execution_jobs exec
+          WHERE (exec.state = 'processing'::text)
+          GROUP BY exec.user_id
         )
- SELECT row_number() OVER () AS place_in_global_queue,
-    materialized_queue_candidates.id,
-    materialized_queue_candidates.batch_spec_workspace_id,
-    materialized_queue_candidates.state,
-    materialized_queue_candidates.failure_message,
-    materialized_queue_candidates.started_at,
-    materialized_queue_candidates.finished_at,
-    materialized_queue_candidates.process_after,
-    materialized_queue_candidates.num_resets,
-    materialized_queue_candidates.num_failures,
-    materialized_queue_candidates.execution_logs,
-    materialized_queue_candidates.worker_hostname,
-    materialized_queue_candidates.last_heartbeat_at,
-    materialized_queue_candidates.created_at,
-    materialized_queue_candidates.updated_at,
-    materialized_queue_candidates.cancel,
-    materialized_queue_candidates.access_token_id,
-    materialized_queue_candidates.queued_at,
-    materialized_queue_candidates.user_id,
-    materialized_queue_candidates.place_in_user_queue
-   FROM materialized_queue_candidates;
+ SELECT row_number() OVER (ORDER BY (queue_candidates.place_in_user_queue + COALESCE(user_processing.count, (0)::bigint)), queue_candidates.created_at, queue_candidates.id) AS place_in_global_queue,
+    queue_candidates.id,
+    queue_candidates.batch_spec_workspace_id,
+    queue_candidates.state,
+    queue_candidates.failure_message,
+    queue_candidates.started_at,
+    queue_candidates.finished_at,
+    queue_candidates.process_after,
+    queue_candidates.num_resets,
+    queue_candidates.num_failures,
+    queue_candidates.execution_logs,
+    queue_candidates.worker_hostname,
+    queue_candidates.last_heartbeat_at,
+    queue_candidates.created_at,
+    queue_candidates.updated_at,
+    queue_candidates.cancel,
+    queue_candidates.access_token_id,
+    queue_candidates.queued_at,
+    queue_candidates.user_id,
+    queue_candidates.place_in_user_queue
+   FROM (queue_candidates
+     LEFT JOIN user_processing ON ((NOT (user_processing.user_id IS DISTINCT FROM queue_candidates.user_id))));
 
 CREATE TABLE batch_spec_workspaces (
     id bigint NOT NULL,
@@ -3472,6 +3472,8 @@ CREATE INDEX batch_changes_namespace_user_id ON batch_changes USING btree (names
 
 CREATE INDEX batch_changes_site_credentials_credential_idx ON batch_changes_site_credentials USING btree (((encryption_key_id = ANY (ARRAY[''::text, 'previously-migrated'::text]))));
 
+CREATE INDEX batch_changes_site_credentials_encryption_key_id_idx ON batch_changes_site_credentials USING btree (encryption_key_id);
+
 CREATE UNIQUE INDEX batch_changes_site_credentials_unique ON batch_changes_site_credentials USING btree (external_service_type, external_service_id);
 
 CREATE UNIQUE INDEX batch_changes_unique_org_id ON batch_changes USING btree (name, namespace_org_id) WHERE (namespace_org_id IS NOT NULL);
@@ -3578,6 +3580,8 @@ CREATE INDEX external_service_sync_jobs_state_idx ON external_service_sync_jobs
 
 CREATE INDEX external_service_user_repos_idx ON external_service_repos USING btree (user_id, repo_id) WHERE (user_id IS NOT NULL);
 
+CREATE INDEX external_services_encryption_key_id_idx ON external_services USING btree (encryption_key_id);
+
 CREATE INDEX external_services_has_webhooks_idx ON external_services USING btree (has_webhooks);
 
 CREATE INDEX external_services_namespace_org_id_idx ON external_services USING btree (namespace_org_id);
@@ -3610,6 +3614,8 @@ CREATE INDEX insights_query_runner_jobs_priority_idx ON insights_query_runner_jo
 
 CREATE INDEX insights_query_runner_jobs_processable_priority_id ON insights_query_runner_jobs USING btree (priority, id) WHERE ((state = 'queued'::text) OR (state = 'errored'::text));
 
+CREATE INDEX insights_query_runner_jobs_processable_series_id ON insights_query_runner_jobs USING btree (series_id, id) WHERE ((state = 'queued'::text) OR (state = 'errored'::text));
+
 CREATE INDEX insights_query_runner_jobs_state_btree ON insights_query_runner_jobs USING btree (state);
 
 CREATE UNIQUE INDEX kind_cloud_default ON external_services USING btree (kind, cloud_default) WHERE ((cloud_default = true) AND (deleted_at IS NULL));
@@ -3620,6 +3626,8 @@ CREATE INDEX lsif_dependency_indexing_jobs_upload_id ON lsif_dependency_syncing_
 
 CREATE INDEX lsif_indexes_commit_last_checked_at ON lsif_indexes USING btree (commit_last_checked_at) WHERE (state <> 'deleted'::text);
 
+CREATE INDEX lsif_indexes_processable_repository_id ON lsif_indexes USING btree (repository_id, queued_at, id) WHERE ((state = 'queued'::text) OR (state = 'errored'::text));
+
 CREATE INDEX lsif_indexes_repository_id_commit ON lsif_indexes USING btree (repository_id, commit);
 
 CREATE INDEX lsif_indexes_state ON lsif_indexes USING btree (state);
@@ -3742,10 +3750,14 @@ CREATE INDEX sub_repo_perms_user_id ON sub_repo_permissions USING btree (user_id
 
 CREATE INDEX user_credentials_credential_idx ON user_credentials USING btree (((encryption_key_id = ANY (ARRAY[''::text, 'previously-migrated'::text]))));
 
+CREATE INDEX user_credentials_encryption_key_id_idx ON user_credentials USING btree (encryption_key_id);
+
 CREATE UNIQUE INDEX user_emails_user_id_is_primary_idx ON user_emails USING btree (user_id, is_primary) WHERE (is_primary = true);
 
 CREATE UNIQUE INDEX user_external_accounts_account ON user_external_accounts USING btree (service_type, service_id, client_id, account_id) WHERE (deleted_at IS NULL);
 
+CREATE INDEX user_external_accounts_encryption_key_id_idx ON user_external_accounts USING btree (encryption_key_id);
+
 CREATE INDEX user_external_accounts_user_id ON user_external_accounts USING btree (user_id) WHERE (deleted_at IS NULL);
 
 CREATE UNIQUE INDEX users_billing_customer_id ON users USING btree (billing_customer_id) WHERE (deleted_at IS NULL);
@@ -3754,6 +3766,8 @@ CREATE INDEX users_created_at_idx ON users USING btree (created_at);
 
 CREATE UNIQUE INDEX users_username ON users USING btree (username) WHERE (deleted_at IS NULL);
 
+CREATE INDEX webhook_logs_encryption_key_id_idx ON webhook_logs USING btree (encryption_key_id);
+
 CREATE INDEX webhook_logs_external_service_id_idx ON webhook_logs USING btree (external_service_id);
 
 CREATE INDEX webhook_logs_received_at_idx ON webhook_logs USING btree (received_at);
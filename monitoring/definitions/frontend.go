@@ -902,6 +902,21 @@ func Frontend() *monitoring.Dashboard {
 				},
 			},
 		},
+		ServiceLevelObjectives: []monitoring.ServiceLevelObjective{
+			{
+				Name:        "search_request_latency",
+				Description: "browser search requests with a first result within 5s",
+				Owner:       monitoring.ObservableOwnerSearch,
+				ErrorRatioQuery: `(sum(rate(src_search_streaming_latency_seconds_count{source="browser"}[$window])) - sum(rate(src_search_streaming_latency_seconds_bucket{source="browser",le="5"}[$window])))` +
+					` / sum(rate(src_search_streaming_latency_seconds_count{source="browser"}[$window]))`,
+				Objective: 0.99,
+				NextSteps: `
+					- **Get details on the exact queries that are slow** by configuring '"observability.logSlowSearches": 5,' in the site configuration and looking for 'frontend' warning logs prefixed with 'slow search request' for additional details.
+					- **Check that most repositories are indexed** by visiting https://sourcegraph.example.com/site-admin/repositories?filter=needs-index (it should show few or no results.)
+					- **Check CPU usage of zoekt-webserver** on the Zoekt Web Server dashboard, and consider increasing its CPU limits if regularly hitting max CPU utilization.
+				`,
+			},
+		},
 	}
 }
 
@@ -494,5 +494,21 @@ func GitServer() *monitoring.Dashboard {
 			shared.NewGolangMonitoringGroup(containerName, monitoring.ObservableOwnerRepoManagement, nil),
 			shared.NewKubernetesMonitoringGroup(containerName, monitoring.ObservableOwnerRepoManagement, nil),
 		},
+		ServiceLevelObjectives: []monitoring.ServiceLevelObjective{
+			{
+				Name:        "exec_errors",
+				Description: "git commands that exit successfully",
+				Owner:       monitoring.ObservableOwnerRepoManagement,
+				// repositories that are not cloned yet are not failures of gitserver
+				ErrorRatioQuery: `sum(rate(src_gitserver_exec_duration_seconds_count{status!~"0|repo-not-found|clone-in-progress"}[$window]))` +
+					` / sum(rate(src_gitserver_exec_duration_seconds_count[$window]))`,
+				Objective: 0.99,
+				NextSteps: `
+					- **Check the gitserver logs** for the commands that fail and their stderr output.
+					- **Check the disk usage** of gitserver instances, commands fail when disks are full.
+					- **Check the code host status indicator for errors:** on the Sourcegraph app homepage, when signed in as an admin click the cloud icon in the top right corner of the page.
+				`,
+			},
+		},
 	}
 }
@@ -29,5 +29,20 @@ func PreciseCodeIntelWorker() *monitoring.Dashboard {
 			shared.NewGolangMonitoringGroup(containerName, monitoring.ObservableOwnerCodeIntel, nil),
 			shared.NewKubernetesMonitoringGroup(containerName, monitoring.ObservableOwnerCodeIntel, nil),
 		},
+		ServiceLevelObjectives: []monitoring.ServiceLevelObjective{
+			{
+				Name:        "upload_processing_errors",
+				Description: "precise code intelligence uploads processed without errors",
+				Owner:       monitoring.ObservableOwnerCodeIntel,
+				ErrorRatioQuery: `sum(rate(src_codeintel_upload_processor_errors_total[$window]))` +
+					` / sum(rate(src_codeintel_upload_processor_total[$window]))`,
+				Objective: 0.95,
+				NextSteps: `
+					- **Check the precise-code-intel-worker logs** for the errors of failed uploads.
+					- **Check the failure reasons of recent uploads** on the code intelligence uploads page of the site admin area.
+					- **Check the memory usage** of precise-code-intel-worker instances, large uploads can exhaust their memory.
+				`,
+			},
+		},
 	}
 }
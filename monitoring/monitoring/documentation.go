@@ -80,7 +80,7 @@ func renderDocumentation(containers []*Dashboard) (*documentation, error) {
 		fprintSubtitle(&docs.dashboards, c.Description)
 		fmt.Fprintf(&docs.dashboards, "To see this dashboard, visit `/-/debug/grafana/d/%[1]s/%[1]s` on your Sourcegraph instance.\n\n", c.Name)
 
-		for gIndex, g := range c.Groups {
+		for gIndex, g := range c.groups() {
 			// the "General" group is top-level
 			if g.Title != "General" {
 				fmt.Fprintf(&docs.dashboards, "### %s: %s\n\n", c.Title, g.Title)
@@ -96,6 +96,10 @@ func renderDocumentation(containers []*Dashboard) (*documentation, error) {
 				}
 			}
 		}
+
+		for _, s := range c.ServiceLevelObjectives {
+			docs.renderSLOAlertSolutionEntry(c, s)
+		}
 	}
 
 	return &docs, nil
@@ -161,6 +165,33 @@ func (d *documentation) renderAlertSolutionEntry(c *Dashboard, o Observable) err
 	return nil
 }
 
+func (d *documentation) renderSLOAlertSolutionEntry(c *Dashboard, s ServiceLevelObjective) {
+	fmt.Fprintf(&d.alertDocs, "## %s: %s\n\n", c.Name, s.Name)
+	fprintSubtitle(&d.alertDocs, fmt.Sprintf("%s of %s over %s (service level objective)", s.objectivePercentage(), s.Description, s.windowString()))
+
+	var prometheusAlertNames []string // collect names for silencing configuration
+	fmt.Fprintf(&d.alertDocs, "**Descriptions**\n\n")
+	for _, level := range []string{"warning", "critical"} {
+		fmt.Fprintf(&d.alertDocs, "- <span class=\"badge badge-%s\">%s</span> %s\n", level, level, s.alertDescription(c.Name, level))
+		prometheusAlertNames = append(prometheusAlertNames,
+			fmt.Sprintf("  \"%s\"", prometheusAlertName(level, c.Name, s.Name)))
+	}
+	fmt.Fprint(&d.alertDocs, "\n")
+
+	fmt.Fprintf(&d.alertDocs, "**Next steps**\n\n")
+	nextSteps, _ := toMarkdown(s.NextSteps, true)
+	fmt.Fprintf(&d.alertDocs, "%s\n", nextSteps)
+	fmt.Fprintf(&d.alertDocs, "- Learn more about the burn rate of the error budget in the [dashboards reference](./%s#%s-%s-error-budget-burn-rate).\n",
+		dashboardsDocsFile, c.Name, strings.ReplaceAll(s.Name, "_", "-"))
+	fmt.Fprintf(&d.alertDocs, "- **Silence this alert:** If you are aware of this alert and want to silence notifications for it, add the following to your site configuration and set a reminder to re-evaluate the alert:\n\n")
+	fmt.Fprintf(&d.alertDocs, "```json\n%s\n```\n\n", fmt.Sprintf(`"observability.silenceAlerts": [
+%s
+]`, strings.Join(prometheusAlertNames, ",\n")))
+	fprintOwnedBy(&d.alertDocs, s.Owner)
+	// render break for readability
+	fmt.Fprint(&d.alertDocs, "\n<br />\n\n")
+}
+
 func (d *documentation) renderDashboardPanelEntry(c *Dashboard, o Observable, panelID uint) {
 	fprintObservableHeader(&d.dashboards, c, &o, 4)
 	fprintSubtitle(&d.dashboards, upperFirst(o.Description))
@@ -42,6 +42,10 @@ type Dashboard struct {
 	// Groups of observable information about the container.
 	Groups []Group
 
+	// ServiceLevelObjectives of the container, which are alerted on when their error budget
+	// is consumed too fast. They are rendered in a group after the other groups.
+	ServiceLevelObjectives []ServiceLevelObjective
+
 	// NoSourcegraphDebugServer indicates if this container does not export the standard
 	// Sourcegraph debug server (package `internal/debugserver`).
 	//
@@ -72,11 +76,24 @@ func (c *Dashboard) validate() error {
 			errs = errors.Append(errs, errors.Errorf("Group %d %q: %v", i, g.Title, err))
 		}
 	}
+	sloNames := map[string]struct{}{}
+	for i, s := range c.ServiceLevelObjectives {
+		if err := s.validate(); err != nil {
+			errs = errors.Append(errs, errors.Errorf("ServiceLevelObjective %d %q: %v", i, s.Name, err))
+		}
+		if _, ok := sloNames[s.Name]; ok {
+			errs = errors.Append(errs, errors.Errorf("ServiceLevelObjective %d: duplicate name %q", i, s.Name))
+		}
+		sloNames[s.Name] = struct{}{}
+	}
 	return errs
 }
 
 // noAlertsDefined indicates if a dashboard no alerts defined.
 func (c *Dashboard) noAlertsDefined() bool {
+	if len(c.ServiceLevelObjectives) > 0 {
+		return false
+	}
 	for _, g := range c.Groups {
 		for _, r := range g.Rows {
 			for _, o := range r {
@@ -214,7 +231,7 @@ func (c *Dashboard) renderDashboard() *sdk.Board {
 
 	baseY := 8
 	offsetY := baseY
-	for groupIndex, group := range c.Groups {
+	for groupIndex, group := range c.groups() {
 		// Non-general groups are shown as collapsible panels.
 		var rowPanel *sdk.Panel
 		if group.Title != "General" {
@@ -355,11 +372,17 @@ func (c *Dashboard) renderRules() (*promRulesFile, error) {
 			}
 		}
 	}
-	if err := group.validate(); err != nil {
-		return nil, err
+	groups := []promGroup{group}
+	if len(c.ServiceLevelObjectives) > 0 {
+		groups = append(groups, c.renderSLORules())
+	}
+	for _, g := range groups {
+		if err := g.validate(); err != nil {
+			return nil, err
+		}
 	}
 	return &promRulesFile{
-		Groups: []promGroup{group},
+		Groups: groups,
 	}, nil
 }
 
@@ -0,0 +1,272 @@
+package monitoring
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/sourcegraph/sourcegraph/lib/errors"
+)
+
+// ServiceLevelObjective describes a target for the proportion of good events of a service,
+// such as requests that succeed or that are fast enough, over a rolling window.
+//
+// Instead of alerting on static thresholds, objectives are alerted on with multi-window,
+// multi-burn-rate alerts: an alert fires when the error budget (the proportion of bad events
+// allowed by the objective) is consumed much faster than is sustainable over both a long
+// and a short window. The long window ignores short blips, and the short window resolves
+// the alert soon after the issue is over. To learn more, see:
+//
+// https://sre.google/workbook/alerting-on-slos/
+//
+// These correspond to a row of Grafana graphs in the "Service level objectives" group of
+// the dashboard.
+type ServiceLevelObjective struct {
+	// Name is a short and human-readable lower_snake_case name of the objective.
+	//
+	// It must be unique relative to the service name, including the names of observables.
+	Name string
+
+	// Description is a human-readable description of the good events of the objective,
+	// e.g. "search requests with a first result within 5s".
+	Description string
+
+	// Owner indicates the team that owns this objective (including its alerts).
+	Owner ObservableOwner
+
+	// ErrorRatioQuery is the service level indicator (SLI): a Prometheus query returning
+	// the ratio of bad events to all events, between 0 and 1, over the range `$window`.
+	// It should return a single series, for example:
+	//
+	// 	sum(rate(src_foo_errors_total[$window])) / sum(rate(src_foo_total[$window]))
+	//
+	ErrorRatioQuery string
+
+	// Objective is the target proportion of good events, e.g. 0.99 for 99%.
+	Objective float64
+
+	// Window is the rolling window over which the objective is measured. Defaults to 30
+	// days. It must be at least as long as the longest alerting window (3 days).
+	Window time.Duration
+
+	// NextSteps is Markdown describing possible next steps in the event that an alert
+	// of this objective is firing, processed like Observable.NextSteps.
+	NextSteps string
+}
+
+// defaultSLOWindow is the default rolling window of objectives.
+const defaultSLOWindow = 30 * 24 * time.Hour
+
+// sloErrorRatioRecord is the name of the recording rules of the error ratios of objectives,
+// labelled by service_name, slo and window.
+const sloErrorRatioRecord = "slo:sli_error:ratio"
+
+// burnRateAlert is a condition of a multi-window, multi-burn-rate alert.
+type burnRateAlert struct {
+	level       string
+	longWindow  string
+	shortWindow string
+	burnRate    float64
+}
+
+// burnRateAlerts are the conditions recommended for a 30 days window: critical alerts fire
+// when 2% of the error budget is consumed in an hour or 5% in 6 hours, warning alerts when
+// 10% is consumed in a day or in 3 days.
+var burnRateAlerts = []burnRateAlert{
+	{level: "critical", longWindow: "1h", shortWindow: "5m", burnRate: 14.4},
+	{level: "critical", longWindow: "6h", shortWindow: "30m", burnRate: 6},
+	{level: "warning", longWindow: "1d", shortWindow: "2h", burnRate: 3},
+	{level: "warning", longWindow: "3d", shortWindow: "6h", burnRate: 1},
+}
+
+// sloRecordWindows are the windows over which the error ratios are recorded.
+var sloRecordWindows = []string{"5m", "30m", "1h", "2h", "6h", "1d", "3d"}
+
+func (s ServiceLevelObjective) validate() error {
+	if strings.Contains(s.Name, " ") || strings.ToLower(s.Name) != s.Name {
+		return errors.Errorf("Name must be in lower_snake_case; found \"%s\"", s.Name)
+	}
+	if len(s.Description) == 0 {
+		return errors.New("Description must be set")
+	}
+	if first := string([]rune(s.Description)[0]); first != strings.ToLower(first) {
+		return errors.Errorf("Description must start with a lowercase letter; found \"%s\"", s.Description)
+	}
+	if !identifierPattern.Match([]byte(s.Owner.identifier)) {
+		return errors.Errorf(`Owner.identifier has invalid format: "%v"`, []byte(s.Owner.identifier))
+	}
+	if !strings.Contains(s.ErrorRatioQuery, "$window") {
+		return errors.New("ErrorRatioQuery must use the range $window")
+	}
+	if s.Objective <= 0 || s.Objective >= 1 {
+		return errors.Errorf("Objective must be between 0 and 1 exclusive; found %v", s.Objective)
+	}
+	if s.window() < 3*24*time.Hour {
+		return errors.Errorf("Window must be at least 3 days; found %s", s.window())
+	}
+	if s.NextSteps == "" || s.NextSteps == "none" {
+		return errors.New("NextSteps must be provided since critical alerts are defined")
+	}
+	if nextSteps, err := toMarkdown(s.NextSteps, true); err != nil {
+		return errors.Errorf("NextSteps cannot be converted to Markdown: %w", err)
+	} else if l := strings.ToLower(nextSteps); strings.Contains(l, "contact support") || strings.Contains(l, "contact us") {
+		return errors.Errorf("NextSteps should not include mentions of contacting support")
+	}
+	return nil
+}
+
+func (s ServiceLevelObjective) window() time.Duration {
+	if s.Window == 0 {
+		return defaultSLOWindow
+	}
+	return s.Window
+}
+
+// windowString returns the window formatted as a Prometheus duration, e.g. "30d".
+func (s ServiceLevelObjective) windowString() string {
+	return model.Duration(s.window()).String()
+}
+
+// errorBudget returns the proportion of bad events allowed by the objective.
+func (s ServiceLevelObjective) errorBudget() string {
+	return formatFloat(1 - s.Objective)
+}
+
+// objectivePercentage returns the objective formatted as a percentage, e.g. "99.9%".
+func (s ServiceLevelObjective) objectivePercentage() string {
+	return formatFloat(s.Objective*100) + "%"
+}
+
+// errorRatio returns a selector of the recorded error ratio over the given window.
+func (s ServiceLevelObjective) errorRatio(serviceName, window string) string {
+	return fmt.Sprintf(`%s{service_name=%q,slo=%q,window=%q}`, sloErrorRatioRecord, serviceName, s.Name, window)
+}
+
+// alertDescription generates the description of the alert of the objective at the given level.
+func (s ServiceLevelObjective) alertDescription(serviceName, level string) string {
+	var conditions []string
+	for _, a := range burnRateAlerts {
+		if a.level == level {
+			conditions = append(conditions, fmt.Sprintf("%vx+ over %s", a.burnRate, a.longWindow))
+		}
+	}
+	return fmt.Sprintf("%s: error budget of %s %s over %s burning %s",
+		serviceName, s.objectivePercentage(), s.Description, s.windowString(), strings.Join(conditions, " or "))
+}
+
+// alertQuery returns a query that returns a value when the alert of the objective at the
+// given level should be firing.
+func (s ServiceLevelObjective) alertQuery(serviceName, level string) string {
+	var conditions []string
+	for _, a := range burnRateAlerts {
+		if a.level != level {
+			continue
+		}
+		threshold := formatFloat(a.burnRate * (1 - s.Objective))
+		conditions = append(conditions, fmt.Sprintf("(%s > %s and ignoring(window) %s > %s)",
+			s.errorRatio(serviceName, a.longWindow), threshold,
+			s.errorRatio(serviceName, a.shortWindow), threshold))
+	}
+	return fmt.Sprintf("max(%s)", strings.Join(conditions, " or "))
+}
+
+// sloGroupTitle is the title of the group of the service level objectives of a dashboard.
+const sloGroupTitle = "Service level objectives"
+
+// sloGroup returns the group of observables rendering the service level objectives of the
+// dashboard. Each objective has a row with its burn rates and its remaining error budget.
+func (c *Dashboard) sloGroup() Group {
+	group := Group{Title: sloGroupTitle}
+	for _, s := range c.ServiceLevelObjectives {
+		alertsReference := fmt.Sprintf("[alerts reference](./%s#%s-%s)", alertsDocsFile, c.Name, strings.ReplaceAll(s.Name, "_", "-"))
+
+		group.Rows = append(group.Rows, Row{
+			{
+				Name:        s.Name + "_error_budget_burn_rate",
+				Description: fmt.Sprintf("error budget burn rate of %s by window", s.Description),
+				Owner:       s.Owner,
+				Query: fmt.Sprintf(`sum by (window)(%s{service_name=%q,slo=%q,window=~"1h|6h|1d|3d"}) / %s`,
+					sloErrorRatioRecord, c.Name, s.Name, s.errorBudget()),
+				NoAlert: true,
+				Interpretation: fmt.Sprintf(`
+					The rate at which the error budget of the %s objective is consumed over each alerting window, relative to the rate that would exactly consume it over %s.
+					Critical alerts fire when the burn rate exceeds 14.4 over 1h and 5m, or 6 over 6h and 30m.
+					Warning alerts fire when it exceeds 3 over 1d and 2h, or 1 over 3d and 6h.
+					Refer to the %s for next steps.
+				`, s.objectivePercentage(), s.windowString(), alertsReference),
+				Panel: Panel().LegendFormat("{{window}}"),
+			},
+			{
+				Name:        s.Name + "_error_budget_remaining",
+				Description: fmt.Sprintf("error budget remaining of %s over %s", s.Description, s.windowString()),
+				Owner:       s.Owner,
+				Query:       fmt.Sprintf(`(1 - %s / %s) * 100`, s.errorRatio(c.Name, s.windowString()), s.errorBudget()),
+				NoAlert:     true,
+				Interpretation: fmt.Sprintf(`
+					The proportion of the error budget of the %s objective that is left over the last %s.
+					The objective is not met once it is exhausted.
+				`, s.objectivePercentage(), s.windowString()),
+				Panel: Panel().LegendFormat("remaining").Unit(Percentage).Max(100),
+			},
+		})
+	}
+	return group
+}
+
+// groups returns the groups of the dashboard, followed by the group of its service level
+// objectives if it has any. Appending the latter keeps the panel IDs of other groups stable.
+func (c *Dashboard) groups() []Group {
+	if len(c.ServiceLevelObjectives) == 0 {
+		return c.Groups
+	}
+	return append(c.Groups[:len(c.Groups):len(c.Groups)], c.sloGroup())
+}
+
+// renderSLORules generates the recording rules of the error ratios of the service level
+// objectives of the dashboard, and their multi-window, multi-burn-rate alerts.
+func (c *Dashboard) renderSLORules() promGroup {
+	group := promGroup{Name: c.Name + "_slos"}
+	for sloIndex, s := range c.ServiceLevelObjectives {
+		labels := func(window string) map[string]string {
+			return map[string]string{"service_name": c.Name, "slo": s.Name, "window": window}
+		}
+
+		for _, window := range sloRecordWindows {
+			group.Rules = append(group.Rules, promRule{
+				Record: sloErrorRatioRecord,
+				Labels: labels(window),
+				Expr:   strings.ReplaceAll(s.ErrorRatioQuery, "$window", window),
+			})
+		}
+		// Averaging the error ratio over the whole window is cheaper than evaluating the
+		// SLI over it, at the cost of weighing all periods equally regardless of traffic.
+		group.Rules = append(group.Rules, promRule{
+			Record: sloErrorRatioRecord,
+			Labels: labels(s.windowString()),
+			Expr:   fmt.Sprintf("avg_over_time(%s[%s])", s.errorRatio(c.Name, "5m"), s.windowString()),
+		})
+
+		for _, level := range []string{"warning", "critical"} {
+			group.appendRow(s.alertQuery(c.Name, level), map[string]string{
+				"name":         s.Name,
+				"level":        level,
+				"service_name": c.Name,
+				"description":  s.alertDescription(c.Name, level),
+				"owner":        s.Owner.identifier,
+
+				// the burn rate panel of the objective in the corresponding dashboard
+				"grafana_panel_id": strconv.Itoa(int(observablePanelID(len(c.Groups), sloIndex, 0))),
+			}, 0)
+		}
+	}
+	return group
+}
+
+// formatFloat formats f without the rounding errors of floating point arithmetic.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(math.Round(f*1e9)/1e9, 'f', -1, 64)
+}
@@ -141,6 +141,31 @@ func (v *AuthProviders) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"builtin", "saml", "openidconnect", "http-header", "github", "gitlab"})
 }
 
+// AzureDevOpsConnection description: Configuration for a connection to Azure DevOps Services or Azure DevOps Server.
+type AzureDevOpsConnection struct {
+	// Exclude description: A list of repositories to never mirror from Azure DevOps.
+	Exclude []*ExcludedAzureDevOpsRepo `json:"exclude,omitempty"`
+	// Orgs description: A list of organizations (or collections on Azure DevOps Server). All repositories of all projects of each organization are mirrored.
+	Orgs []string `json:"orgs,omitempty"`
+	// Projects description: A list of projects, given as "org/project". All repositories of each project are mirrored.
+	Projects []string `json:"projects,omitempty"`
+	// RateLimit description: Rate limit applied when making API requests to Azure DevOps.
+	RateLimit *AzureDevOpsRateLimit `json:"rateLimit,omitempty"`
+	// Token description: A personal access token with the Code (Read) and Project and Team (Read) scopes. It is used for API requests and to clone repositories.
+	Token string `json:"token"`
+	// Url description: URL of Azure DevOps Services (https://dev.azure.com) or of an Azure DevOps Server instance, including the path to the server (such as https://azure-devops.example.com/tfs). Organizations (or collections on Azure DevOps Server) are configured with `orgs` and `projects`.
+	Url string `json:"url"`
+	// Username description: A username used together with the token to clone repositories over HTTPS.
+	Username string `json:"username"`
+}
+
+// AzureDevOpsRateLimit description: Rate limit applied when making API requests to Azure DevOps.
+type AzureDevOpsRateLimit struct {
+	// Enabled description: true if rate limiting is enabled.
+	Enabled bool `json:"enabled"`
+	// RequestsPerHour description: Requests per hour permitted. This is an average, calculated per second. Internally, the burst limit is set to 500, which implies that for a requests per hour limit as low as 1, users will continue to be able to send a maximum of 500 requests immediately, provided that the complexity cost of each request is 1.
+	RequestsPerHour float64 `json:"requestsPerHour"`
+}
 type BackendInsight struct {
 	// Description description: The description of this insight
 	Description string          `json:"description,omitempty"`
@@ -199,6 +224,8 @@ type BitbucketCloudConnection struct {
 	//
 	// Supports excluding by name ({"name": "myorg/myrepo"}) or by UUID ({"uuid": "{fceb73c7-cef6-4abe-956d-e471281126bd}"}).
 	Exclude []*ExcludedBitbucketCloudRepo `json:"exclude,omitempty"`
+	// GitLFS description: Fetch the Git LFS objects of the text files of the repositories, so that their content is searchable and shown instead of their pointer files. Only the objects referenced by the default branch are fetched.
+	GitLFS *BitbucketCloudGitLFS `json:"gitLFS,omitempty"`
 	// GitURLType description: The type of Git URLs to use for cloning and fetching Git repositories on this Bitbucket Cloud.
 	//
 	// If "http", Sourcegraph will access Bitbucket Cloud repositories using Git URLs of the form https://bitbucket.org/myteam/myproject.git.
@@ -225,6 +252,14 @@ type BitbucketCloudConnection struct {
 	WebhookSecret string `json:"webhookSecret,omitempty"`
 }
 
+// BitbucketCloudGitLFS description: Fetch the Git LFS objects of the text files of the repositories, so that their content is searchable and shown instead of their pointer files. Only the objects referenced by the default branch are fetched.
+type BitbucketCloudGitLFS struct {
+	// Enabled description: true if Git LFS objects are fetched.
+	Enabled bool `json:"enabled"`
+	// MaxFileSize description: The maximum size in bytes of the Git LFS objects to fetch. Larger files are left as pointer files.
+	MaxFileSize int `json:"maxFileSize,omitempty"`
+}
+
 // BitbucketCloudRateLimit description: Rate limit applied when making background API requests to Bitbucket Cloud.
 type BitbucketCloudRateLimit struct {
 	// Enabled description: true if rate limiting is enabled.
@@ -253,6 +288,8 @@ type BitbucketServerConnection struct {
 	Exclude []*ExcludedBitbucketServerRepo `json:"exclude,omitempty"`
 	// ExcludePersonalRepositories description: Whether or not personal repositories should be excluded or not. When true, Sourcegraph will ignore personal repositories it may have access to. See https://docs.sourcegraph.com/integration/bitbucket_server#excluding-personal-repositories for more information.
 	ExcludePersonalRepositories bool `json:"excludePersonalRepositories,omitempty"`
+	// GitLFS description: Fetch the Git LFS objects of the text files of the repositories, so that their content is searchable and shown instead of their pointer files. Only the objects referenced by the default branch are fetched.
+	GitLFS *BitbucketServerGitLFS `json:"gitLFS,omitempty"`
 	// GitURLType description: The type of Git URLs to use for cloning and fetching Git repositories on this Bitbucket Server / Bitbucket Data Center instance.
 	//
 	// If "http", Sourcegraph will access Bitbucket Server / Bitbucket Data Center repositories using Git URLs of the form http(s)://bitbucket.example.com/scm/myproject/myrepo.git (using https: if the Bitbucket Server / Bitbucket Data Center instance uses HTTPS).
@@ -299,6 +336,14 @@ type BitbucketServerConnection struct {
 	Webhooks *Webhooks `json:"webhooks,omitempty"`
 }
 
+// BitbucketServerGitLFS description: Fetch the Git LFS objects of the text files of the repositories, so that their content is searchable and shown instead of their pointer files. Only the objects referenced by the default branch are fetched.
+type BitbucketServerGitLFS struct {
+	// Enabled description: true if Git LFS objects are fetched.
+	Enabled bool `json:"enabled"`
+	// MaxFileSize description: The maximum size in bytes of the Git LFS objects to fetch. Larger files are left as pointer files.
+	MaxFileSize int `json:"maxFileSize,omitempty"`
+}
+
 // BitbucketServerIdentityProvider description: The source of identity to use when computing permissions. This defines how to compute the Bitbucket Server / Bitbucket Data Center identity to use for a given Sourcegraph user. When 'username' is used, Sourcegraph assumes usernames are identical in Sourcegraph and Bitbucket Server / Bitbucket Data Center accounts and `auth.enableUsernameChanges` must be set to false for security reasons.
 type BitbucketServerIdentityProvider struct {
 	Username *BitbucketServerUsernameIdentity
@@ -473,6 +518,7 @@ type EncryptionKey struct {
 	Cloudkms *CloudKMSEncryptionKey
 	Awskms   *AWSKMSEncryptionKey
 	Mounted  *MountedEncryptionKey
+	Vault    *VaultEncryptionKey
 	Noop     *NoOpEncryptionKey
 }
 
@@ -486,6 +532,9 @@ func (v EncryptionKey) MarshalJSON() ([]byte, error) {
 	if v.Mounted != nil {
 		return json.Marshal(v.Mounted)
 	}
+	if v.Vault != nil {
+		return json.Marshal(v.Vault)
+	}
 	if v.Noop != nil {
 		return json.Marshal(v.Noop)
 	}
@@ -507,8 +556,10 @@ func (v *EncryptionKey) UnmarshalJSON(data []byte) error {
 		return json.Unmarshal(data, &v.Mounted)
 	case "noop":
 		return json.Unmarshal(data, &v.Noop)
+	case "vault":
+		return json.Unmarshal(data, &v.Vault)
 	}
-	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"cloudkms", "awskms", "mounted", "noop"})
+	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"cloudkms", "awskms", "mounted", "vault", "noop"})
 }
 
 // EncryptionKeys description: Configuration for encryption keys used to encrypt data at rest in the database.
@@ -528,6 +579,14 @@ type ExcludedAWSCodeCommitRepo struct {
 	// Name description: The name of an AWS CodeCommit repository ("repo-name") to exclude from mirroring.
 	Name string `json:"name,omitempty"`
 }
+type ExcludedAzureDevOpsRepo struct {
+	// Id description: The ID of an Azure DevOps repository (as returned by the Azure DevOps API) to exclude from mirroring.
+	Id string `json:"id,omitempty"`
+	// Name description: The name of an Azure DevOps repository ("org/project/repo") to exclude from mirroring.
+	Name string `json:"name,omitempty"`
+	// Pattern description: Regular expression which matches against the name of an Azure DevOps repository ("org/project/repo").
+	Pattern string `json:"pattern,omitempty"`
+}
 type ExcludedBitbucketCloudRepo struct {
 	// Name description: The name of a Bitbucket Cloud repo ("myorg/myrepo") to exclude from mirroring.
 	Name string `json:"name,omitempty"`
@@ -589,6 +648,8 @@ type ExperimentalFeatures struct {
 	AndOrQuery string `json:"andOrQuery,omitempty"`
 	// ApidocsSearchIndexing description: Deprecated.
 	ApidocsSearchIndexing string `json:"apidocs.search.indexing,omitempty"`
+	// AzureDevOps description: Allow adding Azure DevOps code host connections
+	AzureDevOps string `json:"azureDevOps,omitempty"`
 	// BitbucketServerFastPerm description: DEPRECATED: Configure in Bitbucket Server config.
 	BitbucketServerFastPerm string `json:"bitbucketServerFastPerm,omitempty"`
 	// CustomGitFetch description: JSON array of configuration that maps from Git clone URL domain/path to custom git fetch command.
@@ -609,8 +670,12 @@ type ExperimentalFeatures struct {
 	EventLogging string `json:"eventLogging,omitempty"`
 	// Gerrit description: Allow adding Gerrit code host connections
 	Gerrit string `json:"gerrit,omitempty"`
+	// GitPartialClone description: JSON array of configuration that maps from Git clone URL domain/path to the blobs to fetch up front when cloning the repository. The other blobs are fetched from the code host on demand, which requires a code host that supports partial clones. Repositories which are already cloned in full are only cloned partially once they are re-cloned. Does not apply to repositories using customGitFetch, and Git LFS objects are not fetched for partial clones.
+	GitPartialClone []*GitPartialCloneMapping `json:"gitPartialClone,omitempty"`
 	// GitServerPinnedRepos description: List of repositories pinned to specific gitserver instances. The specified repositories will remain at their pinned servers on scaling the cluster. If the specified pinned server differs from the current server that stores the repository, then it must be re-cloned to the specified server.
 	GitServerPinnedRepos map[string]string `json:"gitServerPinnedRepos,omitempty"`
+	// GitServerRebalancing description: Move repositories between gitserver instances in the background when instances are added or removed. Until a repository has been copied to its new instance and verified, requests for it keep being routed to the instance that previously stored it. Requires the gitserver-rebalancer worker job.
+	GitServerRebalancing bool `json:"gitServerRebalancing,omitempty"`
 	// JvmPackages description: Allow adding JVM packages code host connections
 	JvmPackages string `json:"jvmPackages,omitempty"`
 	// NpmPackages description: Allow adding npm packages code host connections
@@ -636,6 +701,8 @@ type ExperimentalFeatures struct {
 	// StructuralSearch description: Enables structural search.
 	StructuralSearch   string              `json:"structuralSearch,omitempty"`
 	SubRepoPermissions *SubRepoPermissions `json:"subRepoPermissions,omitempty"`
+	// Subversion description: Allow adding Subversion code host connections
+	Subversion string `json:"subversion,omitempty"`
 	// TlsExternal description: Global TLS/SSL settings for Sourcegraph to use when communicating with code hosts.
 	TlsExternal *TlsExternal `json:"tls.external,omitempty"`
 	// VersionContexts description: DEPRECATED: Use search contexts instead.
@@ -765,6 +832,8 @@ type GitHubConnection struct {
 	//
 	// Note: ID is the GitHub GraphQL ID, not the GitHub database ID. eg: "curl https://api.github.com/repos/vuejs/vue | jq .node_id"
 	Exclude []*ExcludedGitHubRepo `json:"exclude,omitempty"`
+	// GitLFS description: Fetch the Git LFS objects of the text files of the repositories, so that their content is searchable and shown instead of their pointer files. Only the objects referenced by the default branch are fetched.
+	GitLFS *GitHubGitLFS `json:"gitLFS,omitempty"`
 	// GitURLType description: The type of Git URLs to use for cloning and fetching Git repositories on this GitHub instance.
 	//
 	// If "http", Sourcegraph will access GitHub repositories using Git URLs of the form http(s)://github.com/myteam/myproject.git (using https: if the GitHub instance uses HTTPS).
@@ -814,6 +883,14 @@ type GitHubConnection struct {
 	Webhooks []*GitHubWebhook `json:"webhooks,omitempty"`
 }
 
+// GitHubGitLFS description: Fetch the Git LFS objects of the text files of the repositories, so that their content is searchable and shown instead of their pointer files. Only the objects referenced by the default branch are fetched.
+type GitHubGitLFS struct {
+	// Enabled description: true if Git LFS objects are fetched.
+	Enabled bool `json:"enabled"`
+	// MaxFileSize description: The maximum size in bytes of the Git LFS objects to fetch. Larger files are left as pointer files.
+	MaxFileSize int `json:"maxFileSize,omitempty"`
+}
+
 // GitHubRateLimit description: Rate limit applied when making background API requests to GitHub.
 type GitHubRateLimit struct {
 	// Enabled description: true if rate limiting is enabled.
@@ -864,6 +941,8 @@ type GitLabConnection struct {
 	CloudGlobal bool `json:"cloudGlobal,omitempty"`
 	// Exclude description: A list of projects to never mirror from this GitLab instance. Takes precedence over "projects" and "projectQuery" configuration. Supports excluding by name ({"name": "group/name"}) or by ID ({"id": 42}).
 	Exclude []*ExcludedGitLabProject `json:"exclude,omitempty"`
+	// GitLFS description: Fetch the Git LFS objects of the text files of the repositories, so that their content is searchable and shown instead of their pointer files. Only the objects referenced by the default branch are fetched.
+	GitLFS *GitLabGitLFS `json:"gitLFS,omitempty"`
 	// GitURLType description: The type of Git URLs to use for cloning and fetching Git repositories on this GitLab instance.
 	//
 	// If "http", Sourcegraph will access GitLab repositories using Git URLs of the form http(s)://gitlab.example.com/myteam/myproject.git (using https: if the GitLab instance uses HTTPS).
@@ -901,6 +980,14 @@ type GitLabConnection struct {
 	// Webhooks description: An array of webhook configurations
 	Webhooks []*GitLabWebhook `json:"webhooks,omitempty"`
 }
+
+// GitLabGitLFS description: Fetch the Git LFS objects of the text files of the repositories, so that their content is searchable and shown instead of their pointer files. Only the objects referenced by the default branch are fetched.
+type GitLabGitLFS struct {
+	// Enabled description: true if Git LFS objects are fetched.
+	Enabled bool `json:"enabled"`
+	// MaxFileSize description: The maximum size in bytes of the Git LFS objects to fetch. Larger files are left as pointer files.
+	MaxFileSize int `json:"maxFileSize,omitempty"`
+}
 type GitLabNameTransformation struct {
 	// Regex description: The regex to match for the occurrences of its replacement.
 	Regex string `json:"regex,omitempty"`
@@ -926,6 +1013,16 @@ type GitLabWebhook struct {
 	Secret string `json:"secret"`
 }
 
+// GitPartialCloneMapping description: Mapping from Git clone URL domain/path to the blobs to fetch up front. With no other field than `domainPath`, no blob is fetched up front.
+type GitPartialCloneMapping struct {
+	// BlobSizeLimit description: Size in bytes up to which blobs are fetched up front. Larger blobs are fetched on demand. If unset, no blob is fetched up front.
+	BlobSizeLimit int `json:"blobSizeLimit,omitempty"`
+	// DomainPath description: Git clone URL domain/path
+	DomainPath string `json:"domainPath"`
+	// SparsePaths description: Paths of the directories or files whose blobs at the tip of the default branch are fetched up front, whatever their size.
+	SparsePaths []string `json:"sparsePaths,omitempty"`
+}
+
 // GithubAppCloud description: The config options for Sourcegraph Cloud GitHub App.
 type GithubAppCloud struct {
 	// AppID description: The app ID of the GitHub App for Sourcegraph Cloud.
@@ -1327,7 +1424,9 @@ type OrganizationInvitations struct {
 
 // OtherExternalServiceConnection description: Configuration for a Connection to Git repositories for which an external service integration isn't yet available.
 type OtherExternalServiceConnection struct {
-	Repos []string `json:"repos"`
+	// GitLFS description: Fetch the Git LFS objects of the text files of the repositories, so that their content is searchable and shown instead of their pointer files. Only the objects referenced by the default branch are fetched.
+	GitLFS *OtherGitLFS `json:"gitLFS,omitempty"`
+	Repos  []string     `json:"repos"`
 	// RepositoryPathPattern description: The pattern used to generate the corresponding Sourcegraph repository name for the repositories. In the pattern, the variable "{base}" is replaced with the Git clone base URL host and path, and "{repo}" is replaced with the repository path taken from the `repos` field.
 	//
 	// For example, if your Git clone base URL is https://git.example.com/repos and `repos` contains the value "my/repo", then a repositoryPathPattern of "{base}/{repo}" would mean that a repository at https://git.example.com/repos/my/repo is available on Sourcegraph at https://sourcegraph.example.com/git.example.com/repos/my/repo.
@@ -1336,6 +1435,14 @@ type OtherExternalServiceConnection struct {
 	RepositoryPathPattern string `json:"repositoryPathPattern,omitempty"`
 	Url                   string `json:"url,omitempty"`
 }
+
+// OtherGitLFS description: Fetch the Git LFS objects of the text files of the repositories, so that their content is searchable and shown instead of their pointer files. Only the objects referenced by the default branch are fetched.
+type OtherGitLFS struct {
+	// Enabled description: true if Git LFS objects are fetched.
+	Enabled bool `json:"enabled"`
+	// MaxFileSize description: The maximum size in bytes of the Git LFS objects to fetch. Larger files are left as pointer files.
+	MaxFileSize int `json:"maxFileSize,omitempty"`
+}
 type OutputVariable struct {
 	// Format description: The expected format of the output. If set, the output is being parsed in that format before being stored in the var. If not set, 'text' is assumed to the format.
 	Format string `json:"format,omitempty"`
@@ -2033,6 +2140,38 @@ type SubRepoPermissions struct {
 	UserCacheTTLSeconds int `json:"userCacheTTLSeconds,omitempty"`
 }
 
+// SubversionConnection description: Configuration for a connection to a Subversion server. Repositories are converted to Git repositories with git-svn.
+type SubversionConnection struct {
+	// Authors description: Maps Subversion usernames to Git authors in the form "Full Name <email@example.com>". Authors missing from the mapping are imported with their Subversion username as both name and email.
+	Authors map[string]string `json:"authors,omitempty"`
+	// Layout description: The directories of the repositories that contain the trunk, the branches and the tags. Defaults to the standard layout. Omitting `trunk`, `branches` and `tags` imports the whole repository as a single branch.
+	Layout *SubversionLayout `json:"layout,omitempty"`
+	// Password description: The password used to authenticate to the Subversion server.
+	Password string `json:"password,omitempty"`
+	// Repos description: The paths of the Subversion repositories to import, relative to `url`.
+	Repos []string `json:"repos"`
+	// RepositoryPathPattern description: The pattern used to generate the corresponding Sourcegraph repository name for the repositories. In the pattern, the variable "{base}" is replaced with the host and path of the Subversion server URL, and "{repo}" is replaced with the repository path taken from the `repos` field.
+	//
+	// For example, if your Subversion server URL is https://svn.example.com/repos and `repos` contains the value "my/repo", then a repositoryPathPattern of "{base}/{repo}" would mean that the repository is available on Sourcegraph at https://sourcegraph.example.com/svn.example.com/repos/my/repo.
+	//
+	// It is important that the Sourcegraph repository name generated with this pattern be unique to this Subversion server. If different code hosts generate repository names that collide, Sourcegraph's behavior is undefined.
+	RepositoryPathPattern string `json:"repositoryPathPattern,omitempty"`
+	// Url description: The root URL of the Subversion server. The paths in `repos` are relative to this URL.
+	Url string `json:"url"`
+	// Username description: The username used to authenticate to the Subversion server.
+	Username string `json:"username,omitempty"`
+}
+
+// SubversionLayout description: The directories of the repositories that contain the trunk, the branches and the tags. Defaults to the standard layout. Omitting `trunk`, `branches` and `tags` imports the whole repository as a single branch.
+type SubversionLayout struct {
+	// Branches description: The directories whose subdirectories are imported as branches.
+	Branches []string `json:"branches,omitempty"`
+	// Tags description: The directories whose subdirectories are imported as tags.
+	Tags []string `json:"tags,omitempty"`
+	// Trunk description: The directory of the trunk, which is imported as the master branch.
+	Trunk string `json:"trunk,omitempty"`
+}
+
 // SyntaxHighlighting description: Syntax highlighting configuration
 type SyntaxHighlighting struct {
 	Engine    SyntaxHighlightingEngine   `json:"engine"`
@@ -2089,6 +2228,37 @@ type UsernameIdentity struct {
 	Type string `json:"type"`
 }
 
+// VaultAppRole description: Authenticate with the AppRole auth method rather than with a token.
+type VaultAppRole struct {
+	// MountPath description: The path the AppRole auth method is mounted at.
+	MountPath string `json:"mountPath,omitempty"`
+	// RoleId description: The RoleID of the AppRole.
+	RoleId string `json:"roleId"`
+	// SecretIdEnvVarName description: The environment variable holding the SecretID of the AppRole. Mutually exclusive with secretIdFilepath.
+	SecretIdEnvVarName string `json:"secretIdEnvVarName,omitempty"`
+	// SecretIdFilepath description: The file holding the SecretID of the AppRole. Mutually exclusive with secretIdEnvVarName.
+	SecretIdFilepath string `json:"secretIdFilepath,omitempty"`
+}
+
+// VaultEncryptionKey description: HashiCorp Vault Transit Encryption Key, used to encrypt data with a key of the Transit secrets engine of a Vault server
+type VaultEncryptionKey struct {
+	// Address description: The address of the Vault server.
+	Address string `json:"address"`
+	// AppRole description: Authenticate with the AppRole auth method rather than with a token.
+	AppRole *VaultAppRole `json:"appRole,omitempty"`
+	// KeyName description: The name of the Transit key.
+	KeyName string `json:"keyName"`
+	// MountPath description: The path the Transit secrets engine is mounted at.
+	MountPath string `json:"mountPath,omitempty"`
+	// Namespace description: The Vault Enterprise namespace of the Transit secrets engine.
+	Namespace string `json:"namespace,omitempty"`
+	// TokenEnvVarName description: The environment variable holding the Vault token used to authenticate. Mutually exclusive with tokenFilepath and appRole.
+	TokenEnvVarName string `json:"tokenEnvVarName,omitempty"`
+	// TokenFilepath description: The file holding the Vault token used to authenticate. Mutually exclusive with tokenEnvVarName and appRole.
+	TokenFilepath string `json:"tokenFilepath,omitempty"`
+	Type          string `json:"type"`
+}
+
 // VersionContext description: Configuration of the version context
 type VersionContext struct {
 	// Description description: Description of the version context